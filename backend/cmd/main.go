@@ -2,19 +2,39 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
-	"strings"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
+	_ "time/tzdata" // provider timezones must load without system zoneinfo
 
-	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
 
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/auth"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/config"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/events"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/handlers"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/health"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/jobs"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/logging"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/metrics"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/middleware"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/migrate"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/notify"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/reminders"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/router"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/server"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/service"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/tracing"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/webhooks"
 )
 
 func main() {
@@ -22,76 +42,291 @@ func main() {
 		log.Println("Warning: .env file not found")
 	}
 
-	dbConn, err := db.ConnectDB(context.Background())
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			log.Fatal("migrate: ", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "materialize" {
+		if err := runMaterialize(os.Args[2:]); err != nil {
+			log.Fatal("materialize: ", err)
+		}
+		return
+	}
+
+	// run returns only once its deferred cleanup is done, so exiting here
+	// cannot skip it.
+	if err := run(os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run starts the server and serves until it is signalled to stop.
+func run(args []string) error {
+	cfg, err := config.Load(args, os.LookupEnv)
 	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+		return fmt.Errorf("invalid configuration:\n%w", err)
+	}
+
+	logger, err := logging.New(os.Stderr, cfg.Log)
+	if err != nil {
+		return err
+	}
+	// Route the standard log package through slog as well.
+	slog.SetDefault(logger)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, os.Stdout)
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Println("Failed to flush traces:", err)
+		}
+	}()
+
+	keys, err := auth.Load(cfg.JWT)
+	if err != nil {
+		return fmt.Errorf("failed to load JWT signing keys: %w", err)
+	}
+
+	dbConn, err := db.ConnectDB(context.Background(), cfg.Database)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
 	}
 	defer dbConn.Close()
 
-	queries := db.New(dbConn)
-	bookingSvc := service.NewBookingService(queries)
-	h := handlers.NewHandler(bookingSvc)
-	availabilitySvc := service.NewAvailabilityService(queries)
-	r := mux.NewRouter()
-
-	r.HandleFunc("/api/register", handlers.RegisterHandler(queries)).Methods("POST")
-	r.HandleFunc("/api/login", handlers.LoginHandler(queries)).Methods("POST")
-	r.HandleFunc("/api/availabilities/free", handlers.ListAllFreeSlotsHandler(queries)).Methods("GET")
-
-	bookings := r.PathPrefix("/api/bookings").Subrouter()
-	bookings.Use(middleware.AuthMiddleware)
-
-	bookings.Handle("/user", h.ListBookingsForUserHandler()).Methods("GET")
-	bookings.Handle("/create", h.CreateBookingHandler()).Methods("POST")
-	bookings.Handle("/{id}", h.RescheduleBookingHandler()).Methods("PUT")
-	bookings.Handle("/{id}", h.DeleteBookingHandler()).Methods("DELETE")
-
-	admins := r.PathPrefix("/api/admin").Subrouter()
-	admins.Use(middleware.AuthMiddleware)
-
-	admins.Handle("/bookings/all", h.ListAllBookingsHandler()).Methods("GET")
-	admins.Handle("/users/all", handlers.ListAllUsersHandler(queries)).Methods("GET")
-	admins.Handle("/avail-pattern/create", handlers.CreateAvailabilityPatternHandler(availabilitySvc)).Methods("POST")
-
-	// Logging middleware
-	r.Use(func(next http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			start := time.Now()
-			next.ServeHTTP(w, r)
-			log.Printf("%s %s %s", r.Method, r.URL.Path, time.Since(start))
-		})
+	if cfg.Database.AutoMigrate {
+		if err := migrate.Up(context.Background(), dbConn, log.Writer()); err != nil {
+			return fmt.Errorf("failed to apply migrations: %w", err)
+		}
+	}
+
+	checker := health.NewChecker(2*time.Second, map[string]health.Check{
+		"database": dbConn.PingContext,
+		"migrations": func(ctx context.Context) error {
+			return migrate.Check(ctx, dbConn)
+		},
 	})
 
-	// Add explicit 404 logger
-	r.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		log.Printf("404 Not Found: %s", r.URL.Path)
-		http.NotFound(w, r)
+	m := metrics.New()
+	m.RegisterDB("booking_app", dbConn)
+
+	store := db.NewStore(dbConn)
+
+	// Booking emails and reminders are only sent with a transport configured.
+	// Reminders are scheduled regardless, so a replica that has one sends
+	// them.
+	sender, err := notify.NewSender(cfg.Email)
+	if err != nil {
+		return fmt.Errorf("failed to set up email: %w", err)
+	}
+	var notifier *notify.Notifier
+	if sender != nil {
+		notifier, err = notify.New(store, sender, cfg.Email.From)
+		if err != nil {
+			return fmt.Errorf("failed to set up email: %w", err)
+		}
+	} else if len(cfg.Reminders.Offsets) > 0 && cfg.Reminders.Interval > 0 {
+		logger.Warn("Booking reminders are scheduled but not sent from here: no email transport is configured")
+	}
+	bookingOpts := []service.BookingOption{
+		service.WithBookingMetrics(m),
+		service.WithReminderOffsets(cfg.Reminders.Offsets...),
+	}
+
+	availability := service.NewAvailabilityService(store, service.WithSlotHorizon(cfg.Slots.HorizonWeeks))
+	r := router.New(router.Deps{
+		Queries:             store,
+		Tokens:              handlers.NewTokens(keys, cfg.JWT),
+		BookingService:      service.NewBookingService(store, bookingOpts...),
+		AvailabilityService: availability,
+		Health:              checker,
+		Metrics:             m,
+		Logger:              logger,
 	})
 
 	// Wrap router in CORS AFTER all routes
-	handlerWithCORS := middleware.CORS(
-		func(origin string) bool {
-			if origin == "https://fullstack-booking-app-hazel.vercel.app" ||
-				origin == "http://localhost:3000" {
-				return true
-			}
-			// Allow any vercel preview URL
-			return strings.HasSuffix(origin, ".vercel.app")
-		},
-	)(r)
-	// Serve
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
+	handlerWithCORS := middleware.CORS(middleware.AllowOrigins(cfg.CORS.AllowedOrigins))(r)
 
 	srv := &http.Server{
-		Addr:         ":" + port,
 		Handler:      handlerWithCORS,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+	}
+
+	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.Server.Port))
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Serve waits for the jobs' current runs before returning.
+	var background sync.WaitGroup
+	every := func(name string, interval time.Duration, fn func(context.Context) error) {
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			jobs.Every(ctx, name, interval, logger, fn)
+		}()
+	}
+
+	if cfg.Slots.MaterializeInterval > 0 {
+		every("materialize", cfg.Slots.MaterializeInterval, func(ctx context.Context) error {
+			result, err := availability.ExtendPatterns(ctx, cfg.Slots.HorizonWeeks)
+			if result.Created > 0 {
+				logger.Info("Materialized pattern slots", "patterns", result.Patterns, "slots", result.Created)
+			}
+			return err
+		})
 	}
 
-	log.Printf("Listening on port %s…\n", port)
-	log.Fatal(srv.ListenAndServe())
+	if cfg.JWT.CleanupInterval > 0 {
+		every("tokens", cfg.JWT.CleanupInterval, func(ctx context.Context) error {
+			n, err := handlers.PurgeExpiredTokens(ctx, store)
+			if n > 0 {
+				logger.Info("Deleted expired tokens", "rows", n)
+			}
+			return err
+		})
+	}
+
+	// Subscribers register on bus before the relay starts.
+	bus := events.NewBus()
+	bus.Subscribe("webhooks", webhooks.Enqueue(store))
+	if notifier != nil {
+		bus.Subscribe("email", notifier.Handler(), notify.BookingTypes...)
+	}
+	if cfg.Outbox.PollInterval > 0 {
+		relayOpts := []events.RelayOption{
+			events.WithBatchSize(cfg.Outbox.BatchSize),
+			events.WithMaxAttempts(cfg.Outbox.MaxAttempts),
+			events.WithLogger(logger),
+		}
+		if notifier != nil {
+			// The email subscriber sends inline, so each event in a batch
+			// may take up to the SMTP timeout.
+			relayOpts = append(relayOpts,
+				events.WithLease(time.Duration(cfg.Outbox.BatchSize)*cfg.Email.SMTP.Timeout+time.Minute))
+		}
+		relay := events.NewRelay(store, bus, relayOpts...)
+		every("outbox", cfg.Outbox.PollInterval, func(ctx context.Context) error {
+			_, err := relay.Flush(ctx)
+			return err
+		})
+	}
+
+	if cfg.Webhooks.DispatchInterval > 0 {
+		dispatcher := webhooks.NewDispatcher(store,
+			webhooks.WithHTTPClient(&http.Client{Timeout: cfg.Webhooks.Timeout}),
+			webhooks.WithBatchSize(cfg.Webhooks.BatchSize),
+			webhooks.WithMaxAttempts(cfg.Webhooks.MaxAttempts),
+			// Each send in a batch may take up to the client timeout.
+			webhooks.WithLease(time.Duration(cfg.Webhooks.BatchSize)*cfg.Webhooks.Timeout+time.Minute),
+			webhooks.WithLogger(logger))
+		every("webhooks", cfg.Webhooks.DispatchInterval, func(ctx context.Context) error {
+			_, err := dispatcher.Flush(ctx)
+			return err
+		})
+	}
+
+	if notifier != nil && cfg.Reminders.Interval > 0 {
+		dispatcher := reminders.NewDispatcher(store, notifier,
+			reminders.WithBatchSize(cfg.Reminders.BatchSize),
+			reminders.WithMaxAttempts(cfg.Reminders.MaxAttempts),
+			// Each send in a batch may take up to the SMTP timeout.
+			reminders.WithLease(time.Duration(cfg.Reminders.BatchSize)*cfg.Email.SMTP.Timeout+time.Minute),
+			reminders.WithLogger(logger))
+		every("reminders", cfg.Reminders.Interval, func(ctx context.Context) error {
+			result, err := dispatcher.Flush(ctx)
+			if result.Sent > 0 {
+				logger.Info("Sent booking reminders", "sent", result.Sent)
+			}
+			return err
+		})
+	}
+
+	log.Printf("Listening on port %d…\n", cfg.Server.Port)
+	if err := server.Serve(ctx, srv, ln, server.Shutdown{
+		Drain:          checker.Drain,
+		ReadinessDelay: cfg.Server.ReadinessDelay,
+		Timeout:        cfg.Server.ShutdownTimeout,
+		Background:     &background,
+	}); err != nil {
+		return fmt.Errorf("server stopped with error: %w", err)
+	}
+	log.Println("Server stopped")
+	return nil
+}
+
+// runMigrate handles "migrate up|down|status [flags]". Only the database
+// settings are needed, so JWT and CORS configuration may be absent.
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: %s migrate up|down|status [flags]", os.Args[0])
+	}
+	cmd, args := args[0], args[1:]
+
+	cfg, err := config.Parse(args, os.LookupEnv)
+	if err != nil {
+		return err
+	}
+	if err := cfg.Database.Validate(); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	dbConn, err := db.ConnectDB(ctx, cfg.Database)
+	if err != nil {
+		return err
+	}
+	defer dbConn.Close()
+
+	switch cmd {
+	case "up":
+		return migrate.Up(ctx, dbConn, os.Stdout)
+	case "down":
+		return migrate.Down(ctx, dbConn, os.Stdout)
+	case "status":
+		return migrate.Status(ctx, dbConn, os.Stdout)
+	default:
+		return fmt.Errorf("unknown command %q, want up, down or status", cmd)
+	}
+}
+
+// runMaterialize handles "materialize [flags]": it extends every pattern's
+// slots to the configured horizon once and exits, for running from cron
+// instead of, or as well as, the server's own schedule.
+func runMaterialize(args []string) error {
+	cfg, err := config.Parse(args, os.LookupEnv)
+	if err != nil {
+		return err
+	}
+	if err := errors.Join(cfg.Database.Validate(), cfg.Slots.Validate()); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	dbConn, err := db.ConnectDB(ctx, cfg.Database)
+	if err != nil {
+		return err
+	}
+	defer dbConn.Close()
+
+	result, err := service.NewAvailabilityService(db.NewStore(dbConn)).ExtendPatterns(ctx, cfg.Slots.HorizonWeeks)
+	if err != nil {
+		return err
+	}
+	if result.Skipped {
+		fmt.Println("Another materializer is running; nothing done")
+		return nil
+	}
+	fmt.Printf("Extended %d patterns to %d weeks ahead, creating %d slots\n",
+		result.Patterns, cfg.Slots.HorizonWeeks, result.Created)
+	return nil
 }
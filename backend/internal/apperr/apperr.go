@@ -0,0 +1,137 @@
+// Package apperr classifies errors by what the client can do about them, so
+// one mapper can turn any error into the right HTTP status.
+//
+// Domain code returns *Error values, usually package-level sentinels such as
+// service.ErrBookingConflict. Database errors are classified by their
+// Postgres SQLSTATE code, so handlers never inspect driver messages.
+package apperr
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgconn"
+)
+
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindTooLarge
+	KindUnsupportedMediaType
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindValidation:
+		return "validation"
+	case KindUnauthorized:
+		return "unauthorized"
+	case KindForbidden:
+		return "forbidden"
+	case KindNotFound:
+		return "not_found"
+	case KindConflict:
+		return "conflict"
+	case KindTooLarge:
+		return "too_large"
+	case KindUnsupportedMediaType:
+		return "unsupported_media_type"
+	default:
+		return "internal"
+	}
+}
+
+// FieldError describes one invalid request field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type Error struct {
+	Kind Kind
+	// Message is shown to clients, so it must not leak internals.
+	Message string
+	Fields  []FieldError
+	// Constraint names the database constraint that was violated, if any.
+	Constraint string
+	Err        error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func New(kind Kind, msg string) *Error {
+	return &Error{Kind: kind, Message: msg}
+}
+
+// Wrap classifies cause as kind with a client-safe message. The cause stays
+// reachable through errors.Is and errors.As.
+func Wrap(kind Kind, msg string, cause error) *Error {
+	return &Error{Kind: kind, Message: msg, Err: cause}
+}
+
+func Validation(msg string, fields ...FieldError) *Error {
+	return &Error{Kind: KindValidation, Message: msg, Fields: fields}
+}
+
+// Postgres SQLSTATE codes translated by From.
+const (
+	codeUniqueViolation     = "23505"
+	codeForeignKeyViolation = "23503"
+	codeExclusionViolation  = "23P01"
+)
+
+// From returns err as an *Error. Errors that are already classified are
+// returned as is; constraint violations and sql.ErrNoRows are translated;
+// anything else becomes KindInternal. From(nil) is nil.
+func From(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case codeUniqueViolation:
+			return &Error{Kind: KindConflict, Message: "Resource already exists", Constraint: pgErr.ConstraintName, Err: err}
+		case codeExclusionViolation:
+			return &Error{Kind: KindConflict, Message: "Conflicts with an existing resource", Constraint: pgErr.ConstraintName, Err: err}
+		case codeForeignKeyViolation:
+			return &Error{Kind: KindValidation, Message: "Referenced resource does not exist", Constraint: pgErr.ConstraintName, Err: err}
+		}
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return &Error{Kind: KindNotFound, Message: "Resource not found", Err: err}
+	}
+
+	return &Error{Kind: KindInternal, Message: "Internal server error", Err: err}
+}
+
+// KindOf is shorthand for From(err).Kind; it reports KindInternal for nil.
+func KindOf(err error) Kind {
+	if err == nil {
+		return KindInternal
+	}
+	return From(err).Kind
+}
@@ -0,0 +1,103 @@
+package apperr
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgconn"
+)
+
+func TestFrom(t *testing.T) {
+	notFound := New(KindNotFound, "Booking not found")
+
+	tests := []struct {
+		name           string
+		err            error
+		wantKind       Kind
+		wantConstraint string
+	}{
+		{
+			name:     "Already classified",
+			err:      notFound,
+			wantKind: KindNotFound,
+		},
+		{
+			name:     "Wrapped classified",
+			err:      fmt.Errorf("get booking: %w", notFound),
+			wantKind: KindNotFound,
+		},
+		{
+			name:           "Unique violation",
+			err:            &pgconn.PgError{Code: "23505", ConstraintName: "users_email_key"},
+			wantKind:       KindConflict,
+			wantConstraint: "users_email_key",
+		},
+		{
+			name:           "Exclusion violation",
+			err:            fmt.Errorf("insert: %w", &pgconn.PgError{Code: "23P01", ConstraintName: "no_overlap"}),
+			wantKind:       KindConflict,
+			wantConstraint: "no_overlap",
+		},
+		{
+			name:           "Foreign key violation",
+			err:            &pgconn.PgError{Code: "23503", ConstraintName: "bookings_user_id_fkey"},
+			wantKind:       KindValidation,
+			wantConstraint: "bookings_user_id_fkey",
+		},
+		{
+			name:     "Other SQLSTATE",
+			err:      &pgconn.PgError{Code: "40001"},
+			wantKind: KindInternal,
+		},
+		{
+			name:     "No rows",
+			err:      fmt.Errorf("lookup: %w", sql.ErrNoRows),
+			wantKind: KindNotFound,
+		},
+		{
+			name:     "Plain error",
+			err:      errors.New("boom"),
+			wantKind: KindInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := From(tt.err)
+			if got.Kind != tt.wantKind {
+				t.Errorf("kind: got %v, want %v", got.Kind, tt.wantKind)
+			}
+			if got.Constraint != tt.wantConstraint {
+				t.Errorf("constraint: got %q, want %q", got.Constraint, tt.wantConstraint)
+			}
+			// Classified errors are unwrapped; everything else is wrapped.
+			if !errors.Is(got, tt.err) && !errors.Is(tt.err, got) {
+				t.Errorf("From(%v) lost the original error", tt.err)
+			}
+		})
+	}
+}
+
+func TestFromNil(t *testing.T) {
+	if got := From(nil); got != nil {
+		t.Errorf("From(nil) = %v, want nil", got)
+	}
+	if got := KindOf(nil); got != KindInternal {
+		t.Errorf("KindOf(nil) = %v, want %v", got, KindInternal)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	cause := &pgconn.PgError{Code: "23505"}
+	err := Wrap(KindConflict, "Email already registered", cause)
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		t.Fatal("expected cause to be reachable through errors.As")
+	}
+	if got := KindOf(fmt.Errorf("register: %w", err)); got != KindConflict {
+		t.Errorf("KindOf: got %v, want %v", got, KindConflict)
+	}
+}
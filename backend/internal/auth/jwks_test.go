@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestPublicJWKS(t *testing.T) {
+	rs := newRSAKey(t, "rs")
+	ed := newEdKey(t, "ed")
+	hs, err := NewHMACKey("hs", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, err := NewKeySet(time.Hour, hs, rs, ed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jwks := PublicJWKS(set)
+	if len(jwks.Keys) != 2 {
+		t.Fatalf("expected 2 public keys (HMAC omitted), got %d", len(jwks.Keys))
+	}
+
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "sig" {
+			t.Errorf("%s: use = %q", jwk.Kid, jwk.Use)
+		}
+		switch jwk.Kid {
+		case "rs":
+			n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+			if err != nil {
+				t.Fatal(err)
+			}
+			e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := rs.verifyKey.(*rsa.PublicKey)
+			if jwk.Kty != "RSA" || jwk.Alg != "RS256" ||
+				new(big.Int).SetBytes(n).Cmp(want.N) != 0 || int(new(big.Int).SetBytes(e).Int64()) != want.E {
+				t.Errorf("RSA JWK does not match key: %+v", jwk)
+			}
+		case "ed":
+			x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if jwk.Kty != "OKP" || jwk.Crv != "Ed25519" || jwk.Alg != "EdDSA" ||
+				!ed25519.PublicKey(x).Equal(ed.verifyKey) {
+				t.Errorf("Ed25519 JWK does not match key: %+v", jwk)
+			}
+		default:
+			t.Errorf("unexpected key %q", jwk.Kid)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+// Package auth holds the keys used to sign and verify access tokens.
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrNoSigningKey = errors.New("no active signing key")
+	ErrUnknownKey   = errors.New("unknown signing key")
+)
+
+// KeyProvider hands out the key for new tokens and looks up the key a token
+// was signed with.
+type KeyProvider interface {
+	SigningKey() (*Key, error)
+	// Keyfunc resolves a parsed token's kid (or, for tokens issued without
+	// one, the current signing key) to its verification key.
+	Keyfunc(token *jwt.Token) (interface{}, error)
+	// PublicKeys lists the asymmetric keys that may still verify tokens.
+	PublicKeys() []*Key
+}
+
+// Key is a single signing key. Keys built with NewPublicKey can only verify.
+type Key struct {
+	ID     string
+	Method jwt.SigningMethod
+
+	// ActiveFrom is when the key starts signing; RetireAt is when it stops.
+	// A zero RetireAt means the key has not been retired.
+	ActiveFrom time.Time
+	RetireAt   time.Time
+
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+func NewHMACKey(id string, secret []byte) (*Key, error) {
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("key %q: empty HMAC secret", id)
+	}
+	return &Key{ID: id, Method: jwt.SigningMethodHS256, signKey: secret, verifyKey: secret}, nil
+}
+
+func NewRSAKey(id string, priv *rsa.PrivateKey) (*Key, error) {
+	if priv == nil {
+		return nil, fmt.Errorf("key %q: nil RSA key", id)
+	}
+	if priv.N.BitLen() < 2048 {
+		return nil, fmt.Errorf("key %q: RSA keys must be at least 2048 bits", id)
+	}
+	return &Key{ID: id, Method: jwt.SigningMethodRS256, signKey: priv, verifyKey: &priv.PublicKey}, nil
+}
+
+func NewEd25519Key(id string, priv ed25519.PrivateKey) (*Key, error) {
+	if len(priv) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("key %q: invalid Ed25519 key", id)
+	}
+	return &Key{ID: id, Method: jwt.SigningMethodEdDSA, signKey: priv, verifyKey: priv.Public()}, nil
+}
+
+// NewPublicKey wraps an RSA or Ed25519 public key that can verify tokens but
+// never sign them.
+func NewPublicKey(id string, pub interface{}) (*Key, error) {
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		return &Key{ID: id, Method: jwt.SigningMethodRS256, verifyKey: pub}, nil
+	case ed25519.PublicKey:
+		return &Key{ID: id, Method: jwt.SigningMethodEdDSA, verifyKey: pub}, nil
+	default:
+		return nil, fmt.Errorf("key %q: unsupported public key type %T", id, pub)
+	}
+}
+
+// SignKey is the private key (or HMAC secret) passed to jwt.Token.SignedString.
+func (k *Key) SignKey() interface{} { return k.signKey }
+
+func (k *Key) canSign() bool { return k.signKey != nil }
+
+func (k *Key) isAsymmetric() bool {
+	_, hmac := k.Method.(*jwt.SigningMethodHMAC)
+	return !hmac
+}
+
+// KeySet is an in-memory KeyProvider supporting rotation. After a key is
+// retired it stops signing but keeps verifying for the overlap window, so
+// tokens it already issued stay valid until they expire.
+type KeySet struct {
+	mu      sync.RWMutex
+	keys    []*Key
+	overlap time.Duration
+	now     func() time.Time
+}
+
+func NewKeySet(overlap time.Duration, keys ...*Key) (*KeySet, error) {
+	s := &KeySet{overlap: overlap, now: time.Now}
+	for _, k := range keys {
+		if err := s.Add(k); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Add registers a key. Key IDs must be unique.
+func (s *KeySet) Add(k *Key) error {
+	if k.ID == "" {
+		return errors.New("key ID is required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.keys {
+		if existing.ID == k.ID {
+			return fmt.Errorf("duplicate key ID %q", k.ID)
+		}
+	}
+	s.keys = append(s.keys, k)
+	sort.SliceStable(s.keys, func(i, j int) bool {
+		return s.keys[i].ActiveFrom.Before(s.keys[j].ActiveFrom)
+	})
+	return nil
+}
+
+// Rotate makes k the signing key from now on and retires every other key.
+func (s *KeySet) Rotate(k *Key) error {
+	if !k.canSign() {
+		return fmt.Errorf("key %q cannot sign", k.ID)
+	}
+	now := s.now()
+	k.ActiveFrom = now
+	k.RetireAt = time.Time{}
+	if err := s.Add(k); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, existing := range s.keys {
+		if existing != k && (existing.RetireAt.IsZero() || existing.RetireAt.After(now)) {
+			existing.RetireAt = now
+		}
+	}
+	return nil
+}
+
+// SigningKey returns the most recently activated key that can sign and has
+// not been retired.
+func (s *KeySet) SigningKey() (*Key, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	now := s.now()
+	for i := len(s.keys) - 1; i >= 0; i-- {
+		k := s.keys[i]
+		if k.canSign() && !k.ActiveFrom.After(now) && (k.RetireAt.IsZero() || now.Before(k.RetireAt)) {
+			return k, nil
+		}
+	}
+	return nil, ErrNoSigningKey
+}
+
+func (s *KeySet) verifies(k *Key, now time.Time) bool {
+	return k.RetireAt.IsZero() || now.Before(k.RetireAt.Add(s.overlap))
+}
+
+func (s *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	var key *Key
+	if kid == "" {
+		k, err := s.SigningKey()
+		if err != nil {
+			return nil, err
+		}
+		key = k
+	} else {
+		s.mu.RLock()
+		now := s.now()
+		for _, k := range s.keys {
+			if k.ID == kid && s.verifies(k, now) {
+				key = k
+				break
+			}
+		}
+		s.mu.RUnlock()
+		if key == nil {
+			return nil, fmt.Errorf("%w %q", ErrUnknownKey, kid)
+		}
+	}
+
+	// Pin the algorithm to the key so that, for example, an RSA public key
+	// can never be used as an HMAC secret.
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, jwt.ErrTokenSignatureInvalid
+	}
+	return key.verifyKey, nil
+}
+
+func (s *KeySet) PublicKeys() []*Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	now := s.now()
+	var out []*Key
+	for _, k := range s.keys {
+		if k.isAsymmetric() && s.verifies(k, now) {
+			out = append(out, k)
+		}
+	}
+	return out
+}
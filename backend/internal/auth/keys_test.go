@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newRSAKey(t *testing.T, id string) *Key {
+	t.Helper()
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate RSA key: %v", err)
+	}
+	k, err := NewRSAKey(id, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func newEdKey(t *testing.T, id string) *Key {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate Ed25519 key: %v", err)
+	}
+	k, err := NewEd25519Key(id, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func sign(t *testing.T, k *Key, kid string) string {
+	t.Helper()
+	tok := jwt.NewWithClaims(k.Method, jwt.MapClaims{"sub": "someone", "exp": time.Now().Add(time.Hour).Unix()})
+	if kid != "" {
+		tok.Header["kid"] = kid
+	}
+	s, err := tok.SignedString(k.SignKey())
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	return s
+}
+
+func verify(p KeyProvider, token string) error {
+	_, err := jwt.Parse(token, p.Keyfunc)
+	return err
+}
+
+func TestKeySet_SignAndVerify(t *testing.T) {
+	hmac, err := NewHMACKey("hs", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		key  *Key
+	}{
+		{"HS256", hmac},
+		{"RS256", newRSAKey(t, "rs")},
+		{"EdDSA", newEdKey(t, "ed")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := NewKeySet(time.Hour, tt.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			signing, err := set.SigningKey()
+			if err != nil || signing != tt.key {
+				t.Fatalf("SigningKey() = %v, %v", signing, err)
+			}
+			if err := verify(set, sign(t, tt.key, tt.key.ID)); err != nil {
+				t.Errorf("token with kid: %v", err)
+			}
+			if err := verify(set, sign(t, tt.key, "")); err != nil {
+				t.Errorf("token without kid: %v", err)
+			}
+		})
+	}
+}
+
+func TestKeySet_RejectsAlgorithmMismatch(t *testing.T) {
+	rs := newRSAKey(t, "rs")
+	set, err := NewKeySet(time.Hour, rs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An attacker who knows the public key tries to use it as an HMAC secret.
+	pub, _ := rs.JWK()
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "someone"})
+	forged.Header["kid"] = "rs"
+	s, err := forged.SignedString([]byte(pub.N))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verify(set, s); !errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+		t.Errorf("expected signature error, got %v", err)
+	}
+}
+
+func TestKeySet_RotationOverlap(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	oldKey := newRSAKey(t, "old")
+	set, err := NewKeySet(time.Hour, oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set.now = func() time.Time { return now }
+
+	oldToken := sign(t, oldKey, "old")
+
+	newKey := newEdKey(t, "new")
+	if err := set.Rotate(newKey); err != nil {
+		t.Fatal(err)
+	}
+
+	signing, err := set.SigningKey()
+	if err != nil || signing.ID != "new" {
+		t.Fatalf("expected new key to sign, got %v, %v", signing, err)
+	}
+	if len(set.PublicKeys()) != 2 {
+		t.Errorf("expected both keys published during overlap, got %d", len(set.PublicKeys()))
+	}
+
+	now = now.Add(30 * time.Minute)
+	if err := verify(set, oldToken); err != nil {
+		t.Errorf("old token inside overlap window: %v", err)
+	}
+
+	now = now.Add(time.Hour)
+	if err := verify(set, oldToken); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("old token after overlap window: expected ErrUnknownKey, got %v", err)
+	}
+	if keys := set.PublicKeys(); len(keys) != 1 || keys[0].ID != "new" {
+		t.Errorf("expected only the new key published, got %v", keys)
+	}
+	if err := verify(set, sign(t, newKey, "new")); err != nil {
+		t.Errorf("new token: %v", err)
+	}
+}
+
+func TestKeySet_Errors(t *testing.T) {
+	empty, err := NewKeySet(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := empty.SigningKey(); !errors.Is(err, ErrNoSigningKey) {
+		t.Errorf("empty set: expected ErrNoSigningKey, got %v", err)
+	}
+
+	rs := newRSAKey(t, "dup")
+	if _, err := NewKeySet(time.Hour, rs, newEdKey(t, "dup")); err == nil {
+		t.Error("expected duplicate key ID error")
+	}
+
+	pub, err := NewPublicKey("verify-only", &rs.signKey.(*rsa.PrivateKey).PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := empty.Rotate(pub); err == nil {
+		t.Error("expected rotating to a public key to fail")
+	}
+	if err := empty.Add(pub); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := empty.SigningKey(); !errors.Is(err, ErrNoSigningKey) {
+		t.Errorf("verify-only set: expected ErrNoSigningKey, got %v", err)
+	}
+	if err := verify(empty, sign(t, rs, "verify-only")); err != nil {
+		t.Errorf("verify-only key should verify: %v", err)
+	}
+
+	if _, err := NewHMACKey("hs", nil); err == nil {
+		t.Error("expected empty HMAC secret error")
+	}
+}
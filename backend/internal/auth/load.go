@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/config"
+)
+
+// Load builds the key set described by cfg. With a private key file, that
+// key signs and the optional previous key is retired as of startup, so it
+// keeps verifying for cfg.KeyOverlap. Otherwise cfg.Secret signs with HS256.
+func Load(cfg config.JWTConfig) (*KeySet, error) {
+	if cfg.PrivateKeyFile == "" {
+		key, err := NewHMACKey("default", []byte(cfg.Secret))
+		if err != nil {
+			return nil, err
+		}
+		return NewKeySet(cfg.KeyOverlap, key)
+	}
+
+	current, err := LoadKeyFile(cfg.KeyID, cfg.PrivateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	if !current.canSign() {
+		return nil, fmt.Errorf("%s holds a public key; a private key is needed to sign", cfg.PrivateKeyFile)
+	}
+
+	set, err := NewKeySet(cfg.KeyOverlap)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.PreviousKeyFile != "" {
+		prev, err := LoadKeyFile(cfg.PreviousKeyID, cfg.PreviousKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if err := set.Add(prev); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := set.Rotate(current); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// LoadKeyFile reads a PEM encoded private or public key. An empty id is
+// replaced by a thumbprint of the public key.
+func LoadKeyFile(id, path string) (*Key, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("read key: %w", err)
+	}
+	key, err := ParsePEM(id, data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return key, nil
+}
+
+func ParsePEM(id string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if id == "" {
+		pub := parsed
+		if signer, ok := parsed.(crypto.Signer); ok {
+			pub = signer.Public()
+		}
+		id, err = thumbprint(pub)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		return NewRSAKey(id, k)
+	case ed25519.PrivateKey:
+		return NewEd25519Key(id, k)
+	default:
+		return NewPublicKey(id, k)
+	}
+}
+
+func thumbprint(pub interface{}) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:8]), nil
+}
@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/config"
+)
+
+func writePEM(t *testing.T, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	rsPriv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsPath := writePEM(t, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsPriv))
+
+	_, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edDER, err := x509.MarshalPKCS8PrivateKey(edPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPath := writePEM(t, "PRIVATE KEY", edDER)
+
+	rsPubDER, err := x509.MarshalPKIXPublicKey(&rsPriv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsPubPath := writePEM(t, "PUBLIC KEY", rsPubDER)
+
+	tests := []struct {
+		name          string
+		cfg           config.JWTConfig
+		wantErr       bool
+		wantAlg       string
+		wantKID       string
+		wantPublished int
+	}{
+		{
+			name:    "HMAC fallback",
+			cfg:     config.JWTConfig{Secret: "secret"},
+			wantAlg: "HS256",
+			wantKID: "default",
+		},
+		{
+			name:          "RSA key with explicit kid",
+			cfg:           config.JWTConfig{PrivateKeyFile: rsPath, KeyID: "2026-10", Secret: "ignored"},
+			wantAlg:       "RS256",
+			wantKID:       "2026-10",
+			wantPublished: 1,
+		},
+		{
+			name: "Rotating from RSA to Ed25519",
+			cfg: config.JWTConfig{
+				PrivateKeyFile:  edPath,
+				KeyID:           "new",
+				PreviousKeyFile: rsPubPath,
+				PreviousKeyID:   "old",
+				KeyOverlap:      2 * time.Hour,
+			},
+			wantAlg:       "EdDSA",
+			wantKID:       "new",
+			wantPublished: 2,
+		},
+		{
+			name:    "Public key cannot sign",
+			cfg:     config.JWTConfig{PrivateKeyFile: rsPubPath},
+			wantErr: true,
+		},
+		{
+			name:    "Missing key file",
+			cfg:     config.JWTConfig{PrivateKeyFile: filepath.Join(t.TempDir(), "nope.pem")},
+			wantErr: true,
+		},
+		{
+			name:    "Nothing configured",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := Load(tt.cfg)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+
+			key, err := set.SigningKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if key.Method.Alg() != tt.wantAlg || key.ID != tt.wantKID {
+				t.Errorf("signing key = %s/%s, want %s/%s", key.ID, key.Method.Alg(), tt.wantKID, tt.wantAlg)
+			}
+			if got := len(set.PublicKeys()); got != tt.wantPublished {
+				t.Errorf("published %d keys, want %d", got, tt.wantPublished)
+			}
+		})
+	}
+}
+
+func TestParsePEM_DefaultKID(t *testing.T) {
+	_, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(edPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubDER, err := x509.MarshalPKIXPublicKey(edPriv.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	priv, err := ParsePEM("", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := ParsePEM("", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if priv.ID == "" || priv.ID != pub.ID {
+		t.Errorf("expected matching thumbprint kids, got %q and %q", priv.ID, pub.ID)
+	}
+
+	if _, err := ParsePEM("", []byte("not pem")); err == nil {
+		t.Error("expected an error for non-PEM input")
+	}
+}
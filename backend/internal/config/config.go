@@ -0,0 +1,585 @@
+// Package config loads the backend's settings once at startup.
+//
+// Values are layered, later sources overriding earlier ones:
+//
+//	defaults < config file (YAML or JSON) < environment < command-line flags
+//
+// The file is named by -config or CONFIG_FILE. Load validates the result so
+// a bad deployment fails before it starts serving.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	DefaultAccessTokenTTL  = 15 * time.Minute
+	DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+type Config struct {
+	Server    ServerConfig    `yaml:"server"`
+	Database  DatabaseConfig  `yaml:"database"`
+	JWT       JWTConfig       `yaml:"jwt"`
+	CORS      CORSConfig      `yaml:"cors"`
+	Log       LogConfig       `yaml:"log"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+	Slots     SlotsConfig     `yaml:"slots"`
+	Outbox    OutboxConfig    `yaml:"outbox"`
+	Webhooks  WebhooksConfig  `yaml:"webhooks"`
+	Email     EmailConfig     `yaml:"email"`
+	Reminders RemindersConfig `yaml:"reminders"`
+}
+
+type ServerConfig struct {
+	Port         int           `yaml:"port"`
+	ReadTimeout  time.Duration `yaml:"read_timeout"`
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+	// ReadinessDelay is how long the server keeps accepting requests after
+	// SIGTERM has failed readiness, so load balancers stop routing to it
+	// before the listener closes.
+	ReadinessDelay time.Duration `yaml:"readiness_delay"`
+	// ShutdownTimeout is how long in-flight requests, and then background
+	// jobs, get to finish once the listener has closed.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+}
+
+type DatabaseConfig struct {
+	URL             string        `yaml:"url"`
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	// AutoMigrate applies pending migrations before the server starts.
+	AutoMigrate bool `yaml:"auto_migrate"`
+}
+
+type JWTConfig struct {
+	// Secret signs HS256 tokens and is only used when PrivateKeyFile is empty.
+	Secret          string        `yaml:"secret"`
+	PrivateKeyFile  string        `yaml:"private_key_file"`
+	KeyID           string        `yaml:"key_id"`
+	PreviousKeyFile string        `yaml:"previous_key_file"`
+	PreviousKeyID   string        `yaml:"previous_key_id"`
+	KeyOverlap      time.Duration `yaml:"key_overlap"`
+	AccessTokenTTL  time.Duration `yaml:"access_token_ttl"`
+	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl"`
+	// CleanupInterval is how often expired refresh tokens and revocations
+	// are deleted. Zero leaves it to another replica.
+	CleanupInterval time.Duration `yaml:"cleanup_interval"`
+}
+
+type CORSConfig struct {
+	// AllowedOrigins are exact origins such as https://app.example.com. A
+	// leading "*." in the host matches any subdomain: https://*.vercel.app.
+	AllowedOrigins []string `yaml:"allowed_origins"`
+}
+
+type LogConfig struct {
+	Level  string `yaml:"level"`
+	Format string `yaml:"format"`
+	// RedactPII masks emails, names and credentials in log output.
+	RedactPII bool `yaml:"redact_pii"`
+}
+
+type TracingConfig struct {
+	// Exporter is "none", "otlp" or "stdout". The OTLP endpoint comes from
+	// the standard OTEL_EXPORTER_OTLP_ENDPOINT variable.
+	Exporter    string  `yaml:"exporter"`
+	ServiceName string  `yaml:"service_name"`
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+type SlotsConfig struct {
+	// MaterializeInterval is how often the server extends pattern slots.
+	// Zero leaves it to the materialize command, run from cron.
+	MaterializeInterval time.Duration `yaml:"materialize_interval"`
+	// HorizonWeeks is how far ahead pattern slots are kept generated.
+	HorizonWeeks int `yaml:"horizon_weeks"`
+}
+
+type OutboxConfig struct {
+	// PollInterval is how often the server relays stored domain events to
+	// their subscribers. Zero turns the relay off.
+	PollInterval time.Duration `yaml:"poll_interval"`
+	BatchSize    int           `yaml:"batch_size"`
+	// MaxAttempts is how many times an event is offered before it is marked
+	// failed.
+	MaxAttempts int `yaml:"max_attempts"`
+}
+
+type WebhooksConfig struct {
+	// DispatchInterval is how often the server sends queued webhook
+	// deliveries. Zero leaves sending to another replica.
+	DispatchInterval time.Duration `yaml:"dispatch_interval"`
+	BatchSize        int           `yaml:"batch_size"`
+	// MaxAttempts is how many times a delivery is sent before it is marked
+	// dead.
+	MaxAttempts int `yaml:"max_attempts"`
+	// Timeout bounds each request to an endpoint.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+type EmailConfig struct {
+	// Transport is "none", "smtp" or "file". "file" writes each message to
+	// Dir as an .eml file, for development.
+	Transport string `yaml:"transport"`
+	// From is the sender, e.g. "Booking App <no-reply@example.com>". Its
+	// display name is used as the app's name in messages.
+	From string     `yaml:"from"`
+	Dir  string     `yaml:"dir"`
+	SMTP SMTPConfig `yaml:"smtp"`
+}
+
+type RemindersConfig struct {
+	// Offsets are how long before an appointment its reminders are sent.
+	// Empty turns reminders off.
+	Offsets []time.Duration `yaml:"offsets"`
+	// Interval is how often the server sends due reminders. Zero leaves
+	// sending to another replica.
+	Interval  time.Duration `yaml:"interval"`
+	BatchSize int           `yaml:"batch_size"`
+	// MaxAttempts is how many times a reminder is tried before it is marked
+	// failed.
+	MaxAttempts int `yaml:"max_attempts"`
+}
+
+type SMTPConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	// Timeout bounds sending one message, connection included.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Port:            8080,
+			ReadTimeout:     15 * time.Second,
+			WriteTimeout:    15 * time.Second,
+			ReadinessDelay:  5 * time.Second,
+			ShutdownTimeout: 20 * time.Second,
+		},
+		Database: DatabaseConfig{
+			MaxOpenConns:    10,
+			MaxIdleConns:    2,
+			ConnMaxIdleTime: 5 * time.Minute,
+		},
+		JWT: JWTConfig{
+			KeyOverlap:      time.Hour,
+			AccessTokenTTL:  DefaultAccessTokenTTL,
+			RefreshTokenTTL: DefaultRefreshTokenTTL,
+			CleanupInterval: time.Hour,
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: []string{"http://localhost:3000"},
+		},
+		Log: LogConfig{
+			Level:     "info",
+			Format:    "json",
+			RedactPII: true,
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			ServiceName: "booking-app",
+			SampleRatio: 1,
+		},
+		Slots: SlotsConfig{
+			MaterializeInterval: time.Hour,
+			HorizonWeeks:        8,
+		},
+		Outbox: OutboxConfig{
+			PollInterval: time.Second,
+			BatchSize:    100,
+			MaxAttempts:  10,
+		},
+		Webhooks: WebhooksConfig{
+			DispatchInterval: 5 * time.Second,
+			BatchSize:        20,
+			MaxAttempts:      10,
+			Timeout:          10 * time.Second,
+		},
+		Email: EmailConfig{
+			Transport: "none",
+			From:      "Booking App <no-reply@localhost>",
+			Dir:       "mail",
+			SMTP: SMTPConfig{
+				Port:    587,
+				Timeout: 30 * time.Second,
+			},
+		},
+		Reminders: RemindersConfig{
+			Offsets:     []time.Duration{24 * time.Hour, time.Hour},
+			Interval:    time.Minute,
+			BatchSize:   20,
+			MaxAttempts: 5,
+		},
+	}
+}
+
+// Load builds the configuration from args (without the program name) and the
+// environment as seen through lookupEnv, usually os.LookupEnv.
+func Load(args []string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	cfg, err := Parse(args, lookupEnv)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Parse is Load without validation, for commands such as migrate that only
+// need part of the configuration and validate that part themselves.
+func Parse(args []string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	cfg := Default()
+
+	fs := flag.NewFlagSet("booking-app", flag.ContinueOnError)
+	configFile := fs.String("config", "", "path to a YAML or JSON config file")
+	port := fs.Int("port", 0, "HTTP listen port")
+	dbURL := fs.String("database-url", "", "Postgres connection string")
+	origins := fs.String("cors-origins", "", "comma separated list of allowed CORS origins")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	path := *configFile
+	if path == "" {
+		path, _ = lookupEnv("CONFIG_FILE")
+	}
+	if path != "" {
+		if err := loadFile(&cfg, path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := applyEnv(&cfg, lookupEnv); err != nil {
+		return nil, err
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "port":
+			cfg.Server.Port = *port
+		case "database-url":
+			cfg.Database.URL = *dbURL
+		case "cors-origins":
+			cfg.CORS.AllowedOrigins = splitList(*origins)
+		}
+	})
+
+	return &cfg, nil
+}
+
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+	dec := yaml.NewDecoder(strings.NewReader(string(data)))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+func applyEnv(cfg *Config, lookupEnv func(string) (string, bool)) error {
+	var errs []error
+
+	str := func(key string, dst *string) {
+		if v, ok := lookupEnv(key); ok && v != "" {
+			*dst = v
+		}
+	}
+	num := func(key string, dst *int) {
+		if v, ok := lookupEnv(key); ok && v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+				return
+			}
+			*dst = n
+		}
+	}
+	boolean := func(key string, dst *bool) {
+		if v, ok := lookupEnv(key); ok && v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+				return
+			}
+			*dst = b
+		}
+	}
+	ratio := func(key string, dst *float64) {
+		if v, ok := lookupEnv(key); ok && v != "" {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+				return
+			}
+			*dst = f
+		}
+	}
+	dur := func(key string, dst *time.Duration) {
+		if v, ok := lookupEnv(key); ok && v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+				return
+			}
+			*dst = d
+		}
+	}
+
+	num("PORT", &cfg.Server.Port)
+	dur("SERVER_READ_TIMEOUT", &cfg.Server.ReadTimeout)
+	dur("SERVER_WRITE_TIMEOUT", &cfg.Server.WriteTimeout)
+	dur("SERVER_READINESS_DELAY", &cfg.Server.ReadinessDelay)
+	dur("SERVER_SHUTDOWN_TIMEOUT", &cfg.Server.ShutdownTimeout)
+
+	str("DATABASE_URL", &cfg.Database.URL)
+	num("DB_MAX_OPEN_CONNS", &cfg.Database.MaxOpenConns)
+	num("DB_MAX_IDLE_CONNS", &cfg.Database.MaxIdleConns)
+	dur("DB_CONN_MAX_IDLE_TIME", &cfg.Database.ConnMaxIdleTime)
+	dur("DB_CONN_MAX_LIFETIME", &cfg.Database.ConnMaxLifetime)
+	boolean("DB_AUTO_MIGRATE", &cfg.Database.AutoMigrate)
+
+	str("JWT_SECRET", &cfg.JWT.Secret)
+	str("JWT_PRIVATE_KEY_FILE", &cfg.JWT.PrivateKeyFile)
+	str("JWT_KEY_ID", &cfg.JWT.KeyID)
+	str("JWT_PREVIOUS_KEY_FILE", &cfg.JWT.PreviousKeyFile)
+	str("JWT_PREVIOUS_KEY_ID", &cfg.JWT.PreviousKeyID)
+	dur("JWT_KEY_OVERLAP", &cfg.JWT.KeyOverlap)
+	dur("ACCESS_TOKEN_TTL", &cfg.JWT.AccessTokenTTL)
+	dur("REFRESH_TOKEN_TTL", &cfg.JWT.RefreshTokenTTL)
+	dur("TOKEN_CLEANUP_INTERVAL", &cfg.JWT.CleanupInterval)
+
+	if v, ok := lookupEnv("CORS_ALLOWED_ORIGINS"); ok && v != "" {
+		cfg.CORS.AllowedOrigins = splitList(v)
+	}
+
+	str("LOG_LEVEL", &cfg.Log.Level)
+	str("LOG_FORMAT", &cfg.Log.Format)
+	boolean("LOG_REDACT_PII", &cfg.Log.RedactPII)
+
+	str("TRACING_EXPORTER", &cfg.Tracing.Exporter)
+	str("OTEL_SERVICE_NAME", &cfg.Tracing.ServiceName)
+	ratio("TRACING_SAMPLE_RATIO", &cfg.Tracing.SampleRatio)
+
+	dur("SLOT_MATERIALIZE_INTERVAL", &cfg.Slots.MaterializeInterval)
+	num("SLOT_HORIZON_WEEKS", &cfg.Slots.HorizonWeeks)
+
+	dur("OUTBOX_POLL_INTERVAL", &cfg.Outbox.PollInterval)
+	num("OUTBOX_BATCH_SIZE", &cfg.Outbox.BatchSize)
+	num("OUTBOX_MAX_ATTEMPTS", &cfg.Outbox.MaxAttempts)
+
+	dur("WEBHOOK_DISPATCH_INTERVAL", &cfg.Webhooks.DispatchInterval)
+	num("WEBHOOK_BATCH_SIZE", &cfg.Webhooks.BatchSize)
+	num("WEBHOOK_MAX_ATTEMPTS", &cfg.Webhooks.MaxAttempts)
+	dur("WEBHOOK_TIMEOUT", &cfg.Webhooks.Timeout)
+
+	str("EMAIL_TRANSPORT", &cfg.Email.Transport)
+	str("EMAIL_FROM", &cfg.Email.From)
+	str("EMAIL_DIR", &cfg.Email.Dir)
+	str("SMTP_HOST", &cfg.Email.SMTP.Host)
+	num("SMTP_PORT", &cfg.Email.SMTP.Port)
+	str("SMTP_USERNAME", &cfg.Email.SMTP.Username)
+	str("SMTP_PASSWORD", &cfg.Email.SMTP.Password)
+	dur("SMTP_TIMEOUT", &cfg.Email.SMTP.Timeout)
+
+	// REMINDER_OFFSETS=none turns reminders off.
+	if v, ok := lookupEnv("REMINDER_OFFSETS"); ok && v != "" {
+		cfg.Reminders.Offsets = nil
+		if v != "none" {
+			for _, part := range splitList(v) {
+				d, err := time.ParseDuration(part)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("REMINDER_OFFSETS: %w", err))
+					continue
+				}
+				cfg.Reminders.Offsets = append(cfg.Reminders.Offsets, d)
+			}
+		}
+	}
+	dur("REMINDER_INTERVAL", &cfg.Reminders.Interval)
+	num("REMINDER_BATCH_SIZE", &cfg.Reminders.BatchSize)
+	num("REMINDER_MAX_ATTEMPTS", &cfg.Reminders.MaxAttempts)
+
+	return errors.Join(errs...)
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// Validate reports every problem with c at once.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Server.Port > 0 && c.Server.Port < 65536, "server port %d out of range", c.Server.Port)
+	check(c.Server.ReadTimeout > 0, "server read timeout must be positive")
+	check(c.Server.WriteTimeout > 0, "server write timeout must be positive")
+	check(c.Server.ReadinessDelay >= 0, "server readiness delay must not be negative")
+	check(c.Server.ShutdownTimeout > 0, "server shutdown timeout must be positive")
+
+	if err := c.Database.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	check(c.JWT.Secret != "" || c.JWT.PrivateKeyFile != "", "one of JWT_PRIVATE_KEY_FILE or JWT_SECRET must be set")
+	check(c.JWT.PreviousKeyFile == "" || c.JWT.PrivateKeyFile != "", "JWT_PREVIOUS_KEY_FILE requires JWT_PRIVATE_KEY_FILE")
+	check(c.JWT.AccessTokenTTL > 0, "access token TTL must be positive")
+	check(c.JWT.RefreshTokenTTL > c.JWT.AccessTokenTTL, "refresh token TTL must be longer than the access token TTL")
+	check(c.JWT.KeyOverlap >= c.JWT.AccessTokenTTL,
+		"JWT key overlap (%s) must cover the access token TTL (%s)", c.JWT.KeyOverlap, c.JWT.AccessTokenTTL)
+	check(c.JWT.CleanupInterval >= 0, "token cleanup interval must not be negative")
+
+	check(len(c.CORS.AllowedOrigins) > 0, "at least one CORS origin must be allowed")
+	for _, o := range c.CORS.AllowedOrigins {
+		if err := validateOrigin(o); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	var level slog.Level
+	check(level.UnmarshalText([]byte(c.Log.Level)) == nil, "log level %q must be debug, info, warn or error", c.Log.Level)
+	check(c.Log.Format == "json" || c.Log.Format == "text", "log format %q must be json or text", c.Log.Format)
+
+	check(c.Tracing.Exporter == "none" || c.Tracing.Exporter == "otlp" || c.Tracing.Exporter == "stdout",
+		"tracing exporter %q must be none, otlp or stdout", c.Tracing.Exporter)
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1,
+		"tracing sample ratio %g must be between 0 and 1", c.Tracing.SampleRatio)
+
+	if err := c.Slots.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	check(c.Outbox.PollInterval >= 0, "outbox poll interval must not be negative")
+	check(c.Outbox.BatchSize >= 1 && c.Outbox.BatchSize <= 1000,
+		"outbox batch size %d must be between 1 and 1000", c.Outbox.BatchSize)
+	check(c.Outbox.MaxAttempts >= 1, "outbox max attempts must be at least 1")
+
+	check(c.Webhooks.DispatchInterval >= 0, "webhook dispatch interval must not be negative")
+	check(c.Webhooks.BatchSize >= 1 && c.Webhooks.BatchSize <= 1000,
+		"webhook batch size %d must be between 1 and 1000", c.Webhooks.BatchSize)
+	check(c.Webhooks.MaxAttempts >= 1, "webhook max attempts must be at least 1")
+	check(c.Webhooks.Timeout > 0 && c.Webhooks.Timeout <= time.Minute,
+		"webhook timeout %s must be positive and at most a minute", c.Webhooks.Timeout)
+
+	if err := c.Email.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	seen := map[time.Duration]bool{}
+	for _, d := range c.Reminders.Offsets {
+		check(d >= time.Minute && d <= 30*24*time.Hour && d%time.Minute == 0,
+			"reminder offset %s must be whole minutes between 1m and 720h", d)
+		check(!seen[d], "reminder offset %s is listed twice", d)
+		seen[d] = true
+	}
+	check(c.Reminders.Interval >= 0, "reminder interval must not be negative")
+	check(c.Reminders.BatchSize >= 1 && c.Reminders.BatchSize <= 1000,
+		"reminder batch size %d must be between 1 and 1000", c.Reminders.BatchSize)
+	check(c.Reminders.MaxAttempts >= 1, "reminder max attempts must be at least 1")
+
+	return errors.Join(errs...)
+}
+
+func (d *DatabaseConfig) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(d.URL != "", "DATABASE_URL is not set")
+	check(d.MaxOpenConns > 0, "database max open conns must be positive")
+	check(d.MaxIdleConns >= 0 && d.MaxIdleConns <= d.MaxOpenConns,
+		"database max idle conns must be between 0 and max open conns (%d)", d.MaxOpenConns)
+	check(d.ConnMaxIdleTime >= 0, "database conn max idle time must not be negative")
+	check(d.ConnMaxLifetime >= 0, "database conn max lifetime must not be negative")
+
+	return errors.Join(errs...)
+}
+
+func (s *SlotsConfig) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(s.MaterializeInterval >= 0, "slot materialize interval must not be negative")
+	check(s.HorizonWeeks >= 1 && s.HorizonWeeks <= 104, "slot horizon of %d weeks must be between 1 and 104", s.HorizonWeeks)
+
+	return errors.Join(errs...)
+}
+
+func (e *EmailConfig) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(e.Transport == "none" || e.Transport == "smtp" || e.Transport == "file",
+		"email transport %q must be none, smtp or file", e.Transport)
+	if e.Transport == "none" {
+		return errors.Join(errs...)
+	}
+	_, err := mail.ParseAddress(e.From)
+	check(err == nil, "email sender %q is not a valid address", e.From)
+	switch e.Transport {
+	case "smtp":
+		check(e.SMTP.Host != "", "SMTP_HOST must be set for the smtp email transport")
+		check(e.SMTP.Port > 0 && e.SMTP.Port < 65536, "SMTP port %d out of range", e.SMTP.Port)
+		check(e.SMTP.Timeout > 0, "SMTP timeout must be positive")
+	case "file":
+		check(e.Dir != "", "EMAIL_DIR must be set for the file email transport")
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateOrigin(origin string) error {
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+		u.Path != "" || u.RawQuery != "" || u.User != nil {
+		return fmt.Errorf("CORS origin %q must look like https://host[:port]", origin)
+	}
+	if strings.Contains(strings.TrimPrefix(u.Host, "*."), "*") {
+		return fmt.Errorf("CORS origin %q: a wildcard is only allowed as the first label", origin)
+	}
+	return nil
+}
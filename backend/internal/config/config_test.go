@@ -0,0 +1,303 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := vars[key]
+		return v, ok
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+var minimalEnv = map[string]string{
+	"DATABASE_URL": "postgres://localhost/app",
+	"JWT_SECRET":   "secret",
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, err := Load(nil, env(minimalEnv))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	want := Default()
+	want.Database.URL = "postgres://localhost/app"
+	want.JWT.Secret = "secret"
+	if !reflect.DeepEqual(*cfg, want) {
+		t.Errorf("got %+v\nwant %+v", *cfg, want)
+	}
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	file := writeFile(t, "config.yaml", `
+server:
+  port: 9000
+  read_timeout: 30s
+database:
+  url: postgres://file/app
+  max_open_conns: 20
+  max_idle_conns: 5
+jwt:
+  secret: from-file
+cors:
+  allowed_origins:
+    - https://file.example.com
+reminders:
+  offsets: [48h, 2h]
+`)
+
+	tests := []struct {
+		name   string
+		args   []string
+		env    map[string]string
+		assert func(t *testing.T, cfg *Config)
+	}{
+		{
+			name: "File over defaults",
+			args: []string{"-config", file},
+			assert: func(t *testing.T, cfg *Config) {
+				if cfg.Server.Port != 9000 || cfg.Server.ReadTimeout != 30*time.Second ||
+					cfg.Server.WriteTimeout != 15*time.Second || cfg.Database.MaxOpenConns != 20 ||
+					cfg.JWT.Secret != "from-file" {
+					t.Errorf("file values not applied: %+v", cfg)
+				}
+				if want := []time.Duration{48 * time.Hour, 2 * time.Hour}; !reflect.DeepEqual(cfg.Reminders.Offsets, want) {
+					t.Errorf("reminder offsets = %v, want %v", cfg.Reminders.Offsets, want)
+				}
+			},
+		},
+		{
+			name: "CONFIG_FILE names the file",
+			env:  map[string]string{"CONFIG_FILE": file},
+			assert: func(t *testing.T, cfg *Config) {
+				if cfg.Database.URL != "postgres://file/app" {
+					t.Errorf("database URL = %q", cfg.Database.URL)
+				}
+			},
+		},
+		{
+			name: "Env over file",
+			args: []string{"-config", file},
+			env: map[string]string{
+				"PORT":                 "9100",
+				"DB_MAX_IDLE_CONNS":    "1",
+				"CORS_ALLOWED_ORIGINS": "https://a.example.com, https://*.vercel.app",
+				"ACCESS_TOKEN_TTL":     "5m",
+				"DB_AUTO_MIGRATE":      "true",
+			},
+			assert: func(t *testing.T, cfg *Config) {
+				if cfg.Server.Port != 9100 || cfg.Database.MaxIdleConns != 1 || cfg.JWT.AccessTokenTTL != 5*time.Minute ||
+					!cfg.Database.AutoMigrate {
+					t.Errorf("env values not applied: %+v", cfg)
+				}
+				want := []string{"https://a.example.com", "https://*.vercel.app"}
+				if !reflect.DeepEqual(cfg.CORS.AllowedOrigins, want) {
+					t.Errorf("origins = %v, want %v", cfg.CORS.AllowedOrigins, want)
+				}
+			},
+		},
+		{
+			name: "REMINDER_OFFSETS=none turns reminders off",
+			args: []string{"-config", file},
+			env:  map[string]string{"REMINDER_OFFSETS": "none"},
+			assert: func(t *testing.T, cfg *Config) {
+				if len(cfg.Reminders.Offsets) != 0 {
+					t.Errorf("reminder offsets = %v, want none", cfg.Reminders.Offsets)
+				}
+			},
+		},
+		{
+			name: "Flags over env",
+			args: []string{"-config", file, "-port", "9200", "-database-url", "postgres://flag/app", "-cors-origins", "https://flag.example.com"},
+			env:  map[string]string{"PORT": "9100", "DATABASE_URL": "postgres://env/app"},
+			assert: func(t *testing.T, cfg *Config) {
+				if cfg.Server.Port != 9200 || cfg.Database.URL != "postgres://flag/app" ||
+					!reflect.DeepEqual(cfg.CORS.AllowedOrigins, []string{"https://flag.example.com"}) {
+					t.Errorf("flag values not applied: %+v", cfg)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Load(tt.args, env(tt.env))
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			tt.assert(t, cfg)
+		})
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	with := func(extra map[string]string) map[string]string {
+		out := map[string]string{}
+		for k, v := range minimalEnv {
+			out[k] = v
+		}
+		for k, v := range extra {
+			out[k] = v
+		}
+		return out
+	}
+
+	tests := []struct {
+		name         string
+		args         []string
+		env          map[string]string
+		wantContains []string
+	}{
+		{
+			name:         "Nothing set",
+			env:          map[string]string{},
+			wantContains: []string{"DATABASE_URL is not set", "JWT_PRIVATE_KEY_FILE or JWT_SECRET"},
+		},
+		{
+			name:         "Bad number",
+			env:          with(map[string]string{"DB_MAX_OPEN_CONNS": "lots"}),
+			wantContains: []string{"DB_MAX_OPEN_CONNS"},
+		},
+		{
+			name:         "Bad duration",
+			env:          with(map[string]string{"JWT_KEY_OVERLAP": "soon"}),
+			wantContains: []string{"JWT_KEY_OVERLAP"},
+		},
+		{
+			name:         "Bad bool",
+			env:          with(map[string]string{"DB_AUTO_MIGRATE": "sometimes"}),
+			wantContains: []string{"DB_AUTO_MIGRATE"},
+		},
+		{
+			name:         "Idle above open",
+			env:          with(map[string]string{"DB_MAX_OPEN_CONNS": "2", "DB_MAX_IDLE_CONNS": "3"}),
+			wantContains: []string{"max idle conns"},
+		},
+		{
+			name:         "Overlap shorter than access tokens",
+			env:          with(map[string]string{"ACCESS_TOKEN_TTL": "2h"}),
+			wantContains: []string{"key overlap"},
+		},
+		{
+			name:         "Negative readiness delay",
+			env:          with(map[string]string{"SERVER_READINESS_DELAY": "-1s"}),
+			wantContains: []string{"readiness delay"},
+		},
+		{
+			name:         "Negative token cleanup interval",
+			env:          with(map[string]string{"TOKEN_CLEANUP_INTERVAL": "-1m"}),
+			wantContains: []string{"token cleanup interval"},
+		},
+		{
+			name:         "Bad log settings",
+			env:          with(map[string]string{"LOG_LEVEL": "loud", "LOG_FORMAT": "xml"}),
+			wantContains: []string{`log level "loud"`, `log format "xml"`},
+		},
+		{
+			name:         "Bad tracing settings",
+			env:          with(map[string]string{"TRACING_EXPORTER": "jaeger", "TRACING_SAMPLE_RATIO": "2"}),
+			wantContains: []string{`tracing exporter "jaeger"`, "sample ratio 2"},
+		},
+		{
+			name:         "Bad slot settings",
+			env:          with(map[string]string{"SLOT_MATERIALIZE_INTERVAL": "-1m", "SLOT_HORIZON_WEEKS": "0"}),
+			wantContains: []string{"materialize interval", "horizon of 0 weeks"},
+		},
+		{
+			name:         "Bad outbox settings",
+			env:          with(map[string]string{"OUTBOX_POLL_INTERVAL": "-1s", "OUTBOX_BATCH_SIZE": "5000", "OUTBOX_MAX_ATTEMPTS": "0"}),
+			wantContains: []string{"outbox poll interval", "batch size 5000", "max attempts"},
+		},
+		{
+			name:         "Bad webhook settings",
+			env:          with(map[string]string{"WEBHOOK_DISPATCH_INTERVAL": "-1s", "WEBHOOK_MAX_ATTEMPTS": "0", "WEBHOOK_TIMEOUT": "5m"}),
+			wantContains: []string{"webhook dispatch interval", "webhook max attempts", "webhook timeout 5m0s"},
+		},
+		{
+			name:         "Bad email transport",
+			env:          with(map[string]string{"EMAIL_TRANSPORT": "carrier-pigeon"}),
+			wantContains: []string{`email transport "carrier-pigeon"`},
+		},
+		{
+			name:         "SMTP without host",
+			env:          with(map[string]string{"EMAIL_TRANSPORT": "smtp", "EMAIL_FROM": "not an address"}),
+			wantContains: []string{"SMTP_HOST must be set", `email sender "not an address"`},
+		},
+		{
+			name:         "Bad reminder offsets",
+			env:          with(map[string]string{"REMINDER_OFFSETS": "24h, 30s, 24h", "REMINDER_BATCH_SIZE": "0"}),
+			wantContains: []string{"reminder offset 30s", "reminder offset 24h0m0s is listed twice", "reminder batch size 0"},
+		},
+		{
+			name:         "Bad ratio",
+			env:          with(map[string]string{"TRACING_SAMPLE_RATIO": "half"}),
+			wantContains: []string{"TRACING_SAMPLE_RATIO"},
+		},
+		{
+			name:         "Bad origins",
+			env:          with(map[string]string{"CORS_ALLOWED_ORIGINS": "localhost:3000,https://a.*.example.com,https://x.com/path"}),
+			wantContains: []string{`"localhost:3000"`, `"https://a.*.example.com"`, `"https://x.com/path"`},
+		},
+		{
+			name:         "Unknown flag",
+			args:         []string{"-nope"},
+			env:          minimalEnv,
+			wantContains: []string{"nope"},
+		},
+		{
+			name:         "Unknown file field",
+			args:         []string{"-config", writeFile(t, "bad.yaml", "server:\n  prot: 80\n")},
+			env:          minimalEnv,
+			wantContains: []string{"prot"},
+		},
+		{
+			name:         "Missing file",
+			args:         []string{"-config", filepath.Join(t.TempDir(), "missing.yaml")},
+			env:          minimalEnv,
+			wantContains: []string{"read config file"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(tt.args, env(tt.env))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			for _, want := range tt.wantContains {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParse_SkipsValidation(t *testing.T) {
+	cfg, err := Parse(nil, env(map[string]string{"DATABASE_URL": "postgres://localhost/app"}))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if err := cfg.Database.Validate(); err != nil {
+		t.Errorf("database config should be valid without JWT settings: %v", err)
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Error("full validation should still require JWT settings")
+	}
+}
@@ -7,18 +7,34 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const closeAvailability = `-- name: CloseAvailability :exec
+UPDATE availability
+SET capacity = 0, pattern_id = NULL, updated_at = now()
+WHERE id = $1
+`
+
+// Takes a slot with booking history out of its pattern and stops it taking
+// bookings, instead of deleting it and its bookings with it.
+func (q *Queries) CloseAvailability(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, closeAvailability, id)
+	return err
+}
+
 const createAvailability = `-- name: CreateAvailability :exec
-INSERT INTO availability (id, provider_id, start_time, end_time)
+INSERT INTO availability (id, provider_id, start_time, end_time, capacity, pattern_id)
 VALUES (
     $1,
     $2,
     $3,
-    $4
+    $4,
+    $5,
+    $6
 )
 `
 
@@ -27,6 +43,8 @@ type CreateAvailabilityParams struct {
 	ProviderID uuid.UUID
 	StartTime  time.Time
 	EndTime    time.Time
+	Capacity   int32
+	PatternID  uuid.NullUUID
 }
 
 func (q *Queries) CreateAvailability(ctx context.Context, arg CreateAvailabilityParams) error {
@@ -35,10 +53,49 @@ func (q *Queries) CreateAvailability(ctx context.Context, arg CreateAvailability
 		arg.ProviderID,
 		arg.StartTime,
 		arg.EndTime,
+		arg.Capacity,
+		arg.PatternID,
 	)
 	return err
 }
 
+const createPatternSlot = `-- name: CreatePatternSlot :execrows
+INSERT INTO availability (id, provider_id, start_time, end_time, capacity, pattern_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (provider_id, start_time) DO UPDATE
+SET end_time = EXCLUDED.end_time,
+    capacity = EXCLUDED.capacity,
+    pattern_id = EXCLUDED.pattern_id,
+    updated_at = now()
+WHERE availability.capacity = 0
+`
+
+type CreatePatternSlotParams struct {
+	ID         uuid.UUID
+	ProviderID uuid.UUID
+	StartTime  time.Time
+	EndTime    time.Time
+	Capacity   int32
+	PatternID  uuid.NullUUID
+}
+
+// Skips a slot the provider already has at that start, so pattern slots can
+// be materialized more than once. A closed slot there is reopened instead.
+func (q *Queries) CreatePatternSlot(ctx context.Context, arg CreatePatternSlotParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createPatternSlot,
+		arg.ID,
+		arg.ProviderID,
+		arg.StartTime,
+		arg.EndTime,
+		arg.Capacity,
+		arg.PatternID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const deleteAvailability = `-- name: DeleteAvailability :exec
 DELETE FROM availability WHERE id = $1
 AND provider_id = $2
@@ -54,18 +111,42 @@ func (q *Queries) DeleteAvailability(ctx context.Context, arg DeleteAvailability
 	return err
 }
 
+const getAvailabilityByID = `-- name: GetAvailabilityByID :one
+SELECT id, provider_id, start_time, end_time, created_at, updated_at, capacity, pattern_id FROM availability
+WHERE id = $1
+`
+
+func (q *Queries) GetAvailabilityByID(ctx context.Context, id uuid.UUID) (Availability, error) {
+	row := q.db.QueryRowContext(ctx, getAvailabilityByID, id)
+	var i Availability
+	err := row.Scan(
+		&i.ID,
+		&i.ProviderID,
+		&i.StartTime,
+		&i.EndTime,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Capacity,
+		&i.PatternID,
+	)
+	return i, err
+}
+
 const listAllFreeSlots = `-- name: ListAllFreeSlots :many
 SELECT
 s.id,
 s.start_time,
-s.end_time
+s.end_time,
+(s.capacity - COUNT(b.id))::integer AS remaining
 FROM availability AS s
 LEFT JOIN bookings AS b
   ON b.slot_id = s.id
-WHERE b.slot_id is NULL
-  AND s.provider_id = $1
+  AND b.status <> 'cancelled'
+WHERE s.provider_id = $1
   AND s.start_time >= $2
   AND s.end_time <= $3
+GROUP BY s.id
+HAVING COUNT(b.id) < s.capacity
 ORDER BY s.start_time
 `
 
@@ -79,6 +160,7 @@ type ListAllFreeSlotsRow struct {
 	ID        uuid.UUID
 	StartTime time.Time
 	EndTime   time.Time
+	Remaining int32
 }
 
 func (q *Queries) ListAllFreeSlots(ctx context.Context, arg ListAllFreeSlotsParams) ([]ListAllFreeSlotsRow, error) {
@@ -90,7 +172,12 @@ func (q *Queries) ListAllFreeSlots(ctx context.Context, arg ListAllFreeSlotsPara
 	var items []ListAllFreeSlotsRow
 	for rows.Next() {
 		var i ListAllFreeSlotsRow
-		if err := rows.Scan(&i.ID, &i.StartTime, &i.EndTime); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.StartTime,
+			&i.EndTime,
+			&i.Remaining,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -111,13 +198,37 @@ SELECT
   start_time,
   end_time,
   created_at,
-  updated_at
+  updated_at,
+  capacity,
+  pattern_id
 FROM availability
-WHERE provider_id = $1 ORDER BY start_time
+WHERE provider_id = $1
+  AND ($2::timestamptz IS NULL OR start_time >= $2)
+  AND ($3::timestamptz IS NULL OR start_time < $3)
+  AND ($4::timestamptz IS NULL
+       OR (start_time, id) > ($4::timestamptz, $5::uuid))
+ORDER BY start_time, id
+LIMIT $6
 `
 
-func (q *Queries) ListAvailabilityByProvider(ctx context.Context, providerID uuid.UUID) ([]Availability, error) {
-	rows, err := q.db.QueryContext(ctx, listAvailabilityByProvider, providerID)
+type ListAvailabilityByProviderParams struct {
+	ProviderID  uuid.UUID
+	StartFrom   sql.NullTime
+	StartBefore sql.NullTime
+	AfterStart  sql.NullTime
+	AfterID     uuid.NullUUID
+	PageLimit   int32
+}
+
+func (q *Queries) ListAvailabilityByProvider(ctx context.Context, arg ListAvailabilityByProviderParams) ([]Availability, error) {
+	rows, err := q.db.QueryContext(ctx, listAvailabilityByProvider,
+		arg.ProviderID,
+		arg.StartFrom,
+		arg.StartBefore,
+		arg.AfterStart,
+		arg.AfterID,
+		arg.PageLimit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -132,6 +243,8 @@ func (q *Queries) ListAvailabilityByProvider(ctx context.Context, providerID uui
 			&i.EndTime,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Capacity,
+			&i.PatternID,
 		); err != nil {
 			return nil, err
 		}
@@ -199,3 +312,80 @@ func (q *Queries) ListAvailabilityInRange(ctx context.Context, arg ListAvailabil
 	}
 	return items, nil
 }
+
+const listPatternSlotsFrom = `-- name: ListPatternSlotsFrom :many
+SELECT
+  s.id,
+  s.start_time,
+  s.end_time,
+  s.capacity,
+  (COUNT(b.id) FILTER (WHERE b.status <> 'cancelled'))::integer AS booked,
+  COUNT(b.id)::integer AS total_bookings
+FROM availability AS s
+LEFT JOIN bookings AS b
+  ON b.slot_id = s.id
+WHERE s.pattern_id = $1
+  AND s.start_time >= $2
+GROUP BY s.id
+ORDER BY s.start_time
+`
+
+type ListPatternSlotsFromParams struct {
+	PatternID uuid.NullUUID
+	StartFrom time.Time
+}
+
+type ListPatternSlotsFromRow struct {
+	ID            uuid.UUID
+	StartTime     time.Time
+	EndTime       time.Time
+	Capacity      int32
+	Booked        int32
+	TotalBookings int32
+}
+
+func (q *Queries) ListPatternSlotsFrom(ctx context.Context, arg ListPatternSlotsFromParams) ([]ListPatternSlotsFromRow, error) {
+	rows, err := q.db.QueryContext(ctx, listPatternSlotsFrom, arg.PatternID, arg.StartFrom)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPatternSlotsFromRow
+	for rows.Next() {
+		var i ListPatternSlotsFromRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.StartTime,
+			&i.EndTime,
+			&i.Capacity,
+			&i.Booked,
+			&i.TotalBookings,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateAvailabilityCapacity = `-- name: UpdateAvailabilityCapacity :exec
+UPDATE availability
+SET capacity = $1, updated_at = now()
+WHERE id = $2
+`
+
+type UpdateAvailabilityCapacityParams struct {
+	Capacity int32
+	ID       uuid.UUID
+}
+
+func (q *Queries) UpdateAvailabilityCapacity(ctx context.Context, arg UpdateAvailabilityCapacityParams) error {
+	_, err := q.db.ExecContext(ctx, updateAvailabilityCapacity, arg.Capacity, arg.ID)
+	return err
+}
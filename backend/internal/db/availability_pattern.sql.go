@@ -13,16 +13,26 @@ import (
 )
 
 const createAvailabilityPattern = `-- name: CreateAvailabilityPattern :exec
-INSERT INTO availability_pattern (id, provider_id, day_of_week, start_time, end_time)
-VALUES ($1, $2, $3, $4, $5)
+INSERT INTO availability_pattern (
+  id, provider_id, day_of_week, start_time, end_time,
+  slot_minutes, buffer_before_minutes, buffer_after_minutes, capacity,
+  generated_until, ends_on
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 `
 
 type CreateAvailabilityPatternParams struct {
-	ID         uuid.UUID
-	ProviderID uuid.UUID
-	DayOfWeek  int32
-	StartTime  time.Time
-	EndTime    time.Time
+	ID                  uuid.UUID
+	ProviderID          uuid.UUID
+	DayOfWeek           int32
+	StartTime           TimeOfDay
+	EndTime             TimeOfDay
+	SlotMinutes         int32
+	BufferBeforeMinutes int32
+	BufferAfterMinutes  int32
+	Capacity            int32
+	GeneratedUntil      time.Time
+	EndsOn              time.Time
 }
 
 func (q *Queries) CreateAvailabilityPattern(ctx context.Context, arg CreateAvailabilityPatternParams) error {
@@ -32,6 +42,12 @@ func (q *Queries) CreateAvailabilityPattern(ctx context.Context, arg CreateAvail
 		arg.DayOfWeek,
 		arg.StartTime,
 		arg.EndTime,
+		arg.SlotMinutes,
+		arg.BufferBeforeMinutes,
+		arg.BufferAfterMinutes,
+		arg.Capacity,
+		arg.GeneratedUntil,
+		arg.EndsOn,
 	)
 	return err
 }
@@ -53,7 +69,8 @@ func (q *Queries) DeleteAvailabilityPattern(ctx context.Context, arg DeleteAvail
 }
 
 const getAvailabilityPatternByID = `-- name: GetAvailabilityPatternByID :one
-SELECT id, provider_id, day_of_week, start_time, end_time, created_at, updated_at
+SELECT id, provider_id, day_of_week, start_time, end_time, created_at, updated_at,
+  slot_minutes, buffer_before_minutes, buffer_after_minutes, capacity, generated_until, ends_on
 FROM availability_pattern
 WHERE id = $1
 `
@@ -69,6 +86,12 @@ func (q *Queries) GetAvailabilityPatternByID(ctx context.Context, id uuid.UUID)
 		&i.EndTime,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SlotMinutes,
+		&i.BufferBeforeMinutes,
+		&i.BufferAfterMinutes,
+		&i.Capacity,
+		&i.GeneratedUntil,
+		&i.EndsOn,
 	)
 	return i, err
 }
@@ -80,19 +103,27 @@ SELECT
   start_time,
   end_time,
   created_at,
-  updated_at
+  updated_at,
+  slot_minutes,
+  buffer_before_minutes,
+  buffer_after_minutes,
+  capacity
 FROM availability_pattern
 WHERE provider_id = $1
 ORDER BY day_of_week, start_time
 `
 
 type ListPatternsByProviderRow struct {
-	ID        uuid.UUID
-	DayOfWeek int32
-	StartTime time.Time
-	EndTime   time.Time
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID                  uuid.UUID
+	DayOfWeek           int32
+	StartTime           TimeOfDay
+	EndTime             TimeOfDay
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	SlotMinutes         int32
+	BufferBeforeMinutes int32
+	BufferAfterMinutes  int32
+	Capacity            int32
 }
 
 func (q *Queries) ListPatternsByProvider(ctx context.Context, providerID uuid.UUID) ([]ListPatternsByProviderRow, error) {
@@ -111,6 +142,52 @@ func (q *Queries) ListPatternsByProvider(ctx context.Context, providerID uuid.UU
 			&i.EndTime,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.SlotMinutes,
+			&i.BufferBeforeMinutes,
+			&i.BufferAfterMinutes,
+			&i.Capacity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPatternsToExtend = `-- name: ListPatternsToExtend :many
+SELECT p.id, p.provider_id
+FROM availability_pattern AS p
+JOIN users AS u ON u.id = p.provider_id
+WHERE p.generated_until < $1::timestamptz
+  AND p.generated_until < ((p.ends_on + 1)::timestamp AT TIME ZONE u.timezone)
+ORDER BY p.id
+`
+
+type ListPatternsToExtendRow struct {
+	ID         uuid.UUID
+	ProviderID uuid.UUID
+}
+
+// Lists the patterns whose slots stop short of both horizon and the end of
+// their ends_on in the provider's timezone.
+func (q *Queries) ListPatternsToExtend(ctx context.Context, horizon time.Time) ([]ListPatternsToExtendRow, error) {
+	rows, err := q.db.QueryContext(ctx, listPatternsToExtend, horizon)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPatternsToExtendRow
+	for rows.Next() {
+		var i ListPatternsToExtendRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProviderID,
 		); err != nil {
 			return nil, err
 		}
@@ -125,29 +202,98 @@ func (q *Queries) ListPatternsByProvider(ctx context.Context, providerID uuid.UU
 	return items, nil
 }
 
-const updateAvailabilityPattern = `-- name: UpdateAvailabilityPattern :exec
+const setPatternGeneratedUntil = `-- name: SetPatternGeneratedUntil :exec
+UPDATE availability_pattern
+SET generated_until = $1
+WHERE id = $2
+`
+
+type SetPatternGeneratedUntilParams struct {
+	GeneratedUntil time.Time
+	ID             uuid.UUID
+}
+
+func (q *Queries) SetPatternGeneratedUntil(ctx context.Context, arg SetPatternGeneratedUntilParams) error {
+	_, err := q.db.ExecContext(ctx, setPatternGeneratedUntil, arg.GeneratedUntil, arg.ID)
+	return err
+}
+
+const tryLockSlotMaterializer = `-- name: TryLockSlotMaterializer :one
+SELECT pg_try_advisory_lock(hashtext('availability_materializer'))
+`
+
+// Takes a session lock, held until UnlockSlotMaterializer or the connection
+// closes, so it must be run on a connection set aside for the run.
+func (q *Queries) TryLockSlotMaterializer(ctx context.Context) (bool, error) {
+	row := q.db.QueryRowContext(ctx, tryLockSlotMaterializer)
+	var pg_try_advisory_lock bool
+	err := row.Scan(&pg_try_advisory_lock)
+	return pg_try_advisory_lock, err
+}
+
+const unlockSlotMaterializer = `-- name: UnlockSlotMaterializer :one
+SELECT pg_advisory_unlock(hashtext('availability_materializer'))
+`
+
+func (q *Queries) UnlockSlotMaterializer(ctx context.Context) (bool, error) {
+	row := q.db.QueryRowContext(ctx, unlockSlotMaterializer)
+	var pg_advisory_unlock bool
+	err := row.Scan(&pg_advisory_unlock)
+	return pg_advisory_unlock, err
+}
+
+const updateAvailabilityPattern = `-- name: UpdateAvailabilityPattern :one
 UPDATE availability_pattern
 SET 
   day_of_week = $1,
   start_time = $2,
   end_time = $3,
+  slot_minutes = $4,
+  buffer_before_minutes = $5,
+  buffer_after_minutes = $6,
+  capacity = $7,
   updated_at = CURRENT_TIMESTAMP
-WHERE id = $4
+WHERE id = $8
+RETURNING id, provider_id, day_of_week, start_time, end_time, created_at, updated_at, slot_minutes, buffer_before_minutes, buffer_after_minutes, capacity, generated_until, ends_on
 `
 
 type UpdateAvailabilityPatternParams struct {
-	DayOfWeek int32
-	StartTime time.Time
-	EndTime   time.Time
-	ID        uuid.UUID
+	DayOfWeek           int32
+	StartTime           TimeOfDay
+	EndTime             TimeOfDay
+	SlotMinutes         int32
+	BufferBeforeMinutes int32
+	BufferAfterMinutes  int32
+	Capacity            int32
+	ID                  uuid.UUID
 }
 
-func (q *Queries) UpdateAvailabilityPattern(ctx context.Context, arg UpdateAvailabilityPatternParams) error {
-	_, err := q.db.ExecContext(ctx, updateAvailabilityPattern,
+func (q *Queries) UpdateAvailabilityPattern(ctx context.Context, arg UpdateAvailabilityPatternParams) (AvailabilityPattern, error) {
+	row := q.db.QueryRowContext(ctx, updateAvailabilityPattern,
 		arg.DayOfWeek,
 		arg.StartTime,
 		arg.EndTime,
+		arg.SlotMinutes,
+		arg.BufferBeforeMinutes,
+		arg.BufferAfterMinutes,
+		arg.Capacity,
 		arg.ID,
 	)
-	return err
+	var i AvailabilityPattern
+	err := row.Scan(
+		&i.ID,
+		&i.ProviderID,
+		&i.DayOfWeek,
+		&i.StartTime,
+		&i.EndTime,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.SlotMinutes,
+		&i.BufferBeforeMinutes,
+		&i.BufferAfterMinutes,
+		&i.Capacity,
+		&i.GeneratedUntil,
+		&i.EndsOn,
+	)
+	return i, err
 }
@@ -7,11 +7,72 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const cancelBooking = `-- name: CancelBooking :one
+UPDATE bookings
+SET status = 'cancelled',
+    cancellation_reason = $1,
+    cancelled_by = $2,
+    cancelled_at = now(),
+    status_changed_at = now(),
+    updated_at = now(),
+    sequence = sequence + 1
+WHERE id = $3
+  AND status = $4
+RETURNING id, created_at, updated_at, appointment_start, duration_minutes, user_id, slot_id, status, status_changed_at, cancellation_reason, cancelled_by, cancelled_at, sequence
+`
+
+type CancelBookingParams struct {
+	CancellationReason *string
+	CancelledBy        *uuid.UUID
+	ID                 uuid.UUID
+	CurrentStatus      string
+}
+
+func (q *Queries) CancelBooking(ctx context.Context, arg CancelBookingParams) (Booking, error) {
+	row := q.db.QueryRowContext(ctx, cancelBooking,
+		arg.CancellationReason,
+		arg.CancelledBy,
+		arg.ID,
+		arg.CurrentStatus,
+	)
+	var i Booking
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AppointmentStart,
+		&i.DurationMinutes,
+		&i.UserID,
+		&i.SlotID,
+		&i.Status,
+		&i.StatusChangedAt,
+		&i.CancellationReason,
+		&i.CancelledBy,
+		&i.CancelledAt,
+		&i.Sequence,
+	)
+	return i, err
+}
+
+const countBookingsForSlot = `-- name: CountBookingsForSlot :one
+SELECT COUNT(*) FROM bookings
+WHERE slot_id = $1
+  AND status <> 'cancelled'
+`
+
+func (q *Queries) CountBookingsForSlot(ctx context.Context, slotID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countBookingsForSlot, slotID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createBooking = `-- name: CreateBooking :one
 INSERT INTO bookings (id, created_at, updated_at, appointment_start, duration_minutes, user_id, slot_id)
 VALUES (
@@ -23,7 +84,7 @@ VALUES (
     $4,
     $5
 )
-RETURNING id, created_at, updated_at, appointment_start, duration_minutes, user_id, slot_id
+RETURNING id, created_at, updated_at, appointment_start, duration_minutes, user_id, slot_id, status, status_changed_at, cancellation_reason, cancelled_by, cancelled_at, sequence
 `
 
 type CreateBookingParams struct {
@@ -51,29 +112,18 @@ func (q *Queries) CreateBooking(ctx context.Context, arg CreateBookingParams) (B
 		&i.DurationMinutes,
 		&i.UserID,
 		&i.SlotID,
+		&i.Status,
+		&i.StatusChangedAt,
+		&i.CancellationReason,
+		&i.CancelledBy,
+		&i.CancelledAt,
+		&i.Sequence,
 	)
 	return i, err
 }
 
-const deleteBooking = `-- name: DeleteBooking :exec
-DELETE FROM bookings 
-WHERE id = $1 
-AND (user_id = $2 or $3::boolean)
-`
-
-type DeleteBookingParams struct {
-	ID      uuid.UUID
-	UserID  uuid.UUID
-	Column3 bool
-}
-
-func (q *Queries) DeleteBooking(ctx context.Context, arg DeleteBookingParams) error {
-	_, err := q.db.ExecContext(ctx, deleteBooking, arg.ID, arg.UserID, arg.Column3)
-	return err
-}
-
 const getBookingByID = `-- name: GetBookingByID :one
-SELECT id, created_at, updated_at, appointment_start, duration_minutes, user_id, slot_id FROM bookings
+SELECT id, created_at, updated_at, appointment_start, duration_minutes, user_id, slot_id, status, status_changed_at, cancellation_reason, cancelled_by, cancelled_at, sequence FROM bookings
 WHERE id = $1
 `
 
@@ -88,25 +138,45 @@ func (q *Queries) GetBookingByID(ctx context.Context, id uuid.UUID) (Booking, er
 		&i.DurationMinutes,
 		&i.UserID,
 		&i.SlotID,
+		&i.Status,
+		&i.StatusChangedAt,
+		&i.CancellationReason,
+		&i.CancelledBy,
+		&i.CancelledAt,
+		&i.Sequence,
 	)
 	return i, err
 }
 
 const getOverlappingBookings = `-- name: GetOverlappingBookings :many
-SELECT id, created_at, updated_at, appointment_start, duration_minutes, user_id, slot_id
-FROM bookings
-WHERE 
-  appointment_start < $1
-  AND appointment_start + (duration_minutes || ' minutes')::interval > $2
+SELECT b.id, b.created_at, b.updated_at, b.appointment_start, b.duration_minutes, b.user_id, b.slot_id, b.status, b.status_changed_at, b.cancellation_reason, b.cancelled_by, b.cancelled_at, b.sequence
+FROM bookings AS b
+JOIN availability AS a
+  ON a.id = b.slot_id
+WHERE a.provider_id = $1
+  AND b.status <> 'cancelled'
+  AND ($2::uuid IS NULL OR b.slot_id <> $2)
+  AND ($3::uuid IS NULL OR b.id <> $3)
+  AND b.appointment_start < $4
+  AND b.appointment_start + (b.duration_minutes || ' minutes')::interval > $5
 `
 
 type GetOverlappingBookingsParams struct {
-	AppointmentStart   time.Time
-	AppointmentStart_2 time.Time
+	ProviderID       uuid.UUID
+	ExcludeSlotID    uuid.NullUUID
+	ExcludeBookingID uuid.NullUUID
+	RangeEnd         time.Time
+	RangeStart       time.Time
 }
 
 func (q *Queries) GetOverlappingBookings(ctx context.Context, arg GetOverlappingBookingsParams) ([]Booking, error) {
-	rows, err := q.db.QueryContext(ctx, getOverlappingBookings, arg.AppointmentStart, arg.AppointmentStart_2)
+	rows, err := q.db.QueryContext(ctx, getOverlappingBookings,
+		arg.ProviderID,
+		arg.ExcludeSlotID,
+		arg.ExcludeBookingID,
+		arg.RangeEnd,
+		arg.RangeStart,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -122,6 +192,12 @@ func (q *Queries) GetOverlappingBookings(ctx context.Context, arg GetOverlapping
 			&i.DurationMinutes,
 			&i.UserID,
 			&i.SlotID,
+			&i.Status,
+			&i.StatusChangedAt,
+			&i.CancellationReason,
+			&i.CancelledBy,
+			&i.CancelledAt,
+			&i.Sequence,
 		); err != nil {
 			return nil, err
 		}
@@ -137,12 +213,42 @@ func (q *Queries) GetOverlappingBookings(ctx context.Context, arg GetOverlapping
 }
 
 const listAllBookingsForAdmin = `-- name: ListAllBookingsForAdmin :many
-SELECT id, created_at, updated_at, appointment_start, duration_minutes, user_id, slot_id From bookings
-ORDER BY appointment_start
+SELECT b.id, b.created_at, b.updated_at, b.appointment_start, b.duration_minutes, b.user_id, b.slot_id, b.status, b.status_changed_at, b.cancellation_reason, b.cancelled_by, b.cancelled_at, b.sequence FROM bookings AS b
+JOIN availability AS a
+  ON a.id = b.slot_id
+WHERE ($1::uuid IS NULL OR b.user_id = $1)
+  AND ($2::uuid IS NULL OR a.provider_id = $2)
+  AND ($3::text IS NULL OR b.status = $3)
+  AND ($4::timestamptz IS NULL OR b.appointment_start >= $4)
+  AND ($5::timestamptz IS NULL OR b.appointment_start < $5)
+  AND ($6::timestamptz IS NULL
+       OR (b.appointment_start, b.id) > ($6::timestamptz, $7::uuid))
+ORDER BY b.appointment_start, b.id
+LIMIT $8
 `
 
-func (q *Queries) ListAllBookingsForAdmin(ctx context.Context) ([]Booking, error) {
-	rows, err := q.db.QueryContext(ctx, listAllBookingsForAdmin)
+type ListAllBookingsForAdminParams struct {
+	UserID      uuid.NullUUID
+	ProviderID  uuid.NullUUID
+	Status      sql.NullString
+	StartFrom   sql.NullTime
+	StartBefore sql.NullTime
+	AfterStart  sql.NullTime
+	AfterID     uuid.NullUUID
+	PageLimit   int32
+}
+
+func (q *Queries) ListAllBookingsForAdmin(ctx context.Context, arg ListAllBookingsForAdminParams) ([]Booking, error) {
+	rows, err := q.db.QueryContext(ctx, listAllBookingsForAdmin,
+		arg.UserID,
+		arg.ProviderID,
+		arg.Status,
+		arg.StartFrom,
+		arg.StartBefore,
+		arg.AfterStart,
+		arg.AfterID,
+		arg.PageLimit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -158,6 +264,12 @@ func (q *Queries) ListAllBookingsForAdmin(ctx context.Context) ([]Booking, error
 			&i.DurationMinutes,
 			&i.UserID,
 			&i.SlotID,
+			&i.Status,
+			&i.StatusChangedAt,
+			&i.CancellationReason,
+			&i.CancelledBy,
+			&i.CancelledAt,
+			&i.Sequence,
 		); err != nil {
 			return nil, err
 		}
@@ -173,13 +285,37 @@ func (q *Queries) ListAllBookingsForAdmin(ctx context.Context) ([]Booking, error
 }
 
 const listBookingsForUser = `-- name: ListBookingsForUser :many
-SELECT id, created_at, updated_at, appointment_start, duration_minutes, user_id, slot_id FROM bookings
+SELECT id, created_at, updated_at, appointment_start, duration_minutes, user_id, slot_id, status, status_changed_at, cancellation_reason, cancelled_by, cancelled_at, sequence FROM bookings
 WHERE user_id = $1
-ORDER BY appointment_start
+  AND ($2::text IS NULL OR status = $2)
+  AND ($3::timestamptz IS NULL OR appointment_start >= $3)
+  AND ($4::timestamptz IS NULL OR appointment_start < $4)
+  AND ($5::timestamptz IS NULL
+       OR (appointment_start, id) > ($5::timestamptz, $6::uuid))
+ORDER BY appointment_start, id
+LIMIT $7
 `
 
-func (q *Queries) ListBookingsForUser(ctx context.Context, userID uuid.UUID) ([]Booking, error) {
-	rows, err := q.db.QueryContext(ctx, listBookingsForUser, userID)
+type ListBookingsForUserParams struct {
+	UserID      uuid.UUID
+	Status      sql.NullString
+	StartFrom   sql.NullTime
+	StartBefore sql.NullTime
+	AfterStart  sql.NullTime
+	AfterID     uuid.NullUUID
+	PageLimit   int32
+}
+
+func (q *Queries) ListBookingsForUser(ctx context.Context, arg ListBookingsForUserParams) ([]Booking, error) {
+	rows, err := q.db.QueryContext(ctx, listBookingsForUser,
+		arg.UserID,
+		arg.Status,
+		arg.StartFrom,
+		arg.StartBefore,
+		arg.AfterStart,
+		arg.AfterID,
+		arg.PageLimit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -195,6 +331,12 @@ func (q *Queries) ListBookingsForUser(ctx context.Context, userID uuid.UUID) ([]
 			&i.DurationMinutes,
 			&i.UserID,
 			&i.SlotID,
+			&i.Status,
+			&i.StatusChangedAt,
+			&i.CancellationReason,
+			&i.CancelledBy,
+			&i.CancelledAt,
+			&i.Sequence,
 		); err != nil {
 			return nil, err
 		}
@@ -209,31 +351,42 @@ func (q *Queries) ListBookingsForUser(ctx context.Context, userID uuid.UUID) ([]
 	return items, nil
 }
 
+const lockProviderSchedule = `-- name: LockProviderSchedule :exec
+SELECT pg_advisory_xact_lock(hashtext($1::uuid::text))
+`
+
+func (q *Queries) LockProviderSchedule(ctx context.Context, providerID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, lockProviderSchedule, providerID)
+	return err
+}
+
 const rescheduleBooking = `-- name: RescheduleBooking :one
 UPDATE bookings
-SET appointment_start = $2,
-    duration_minutes = $3,
-    updated_at = now()
+SET slot_id = $2,
+    appointment_start = $3,
+    duration_minutes = $4,
+    updated_at = now(),
+    sequence = sequence + 1
 WHERE id = $1
-AND (user_id = $4 or $5::boolean)
-RETURNING id, created_at, updated_at, appointment_start, duration_minutes, user_id, slot_id
+  AND status IN ('pending', 'confirmed')
+RETURNING id, created_at, updated_at, appointment_start, duration_minutes, user_id, slot_id, status, status_changed_at, cancellation_reason, cancelled_by, cancelled_at, sequence
 `
 
 type RescheduleBookingParams struct {
 	ID               uuid.UUID
+	SlotID           uuid.UUID
 	AppointmentStart time.Time
 	DurationMinutes  int32
-	UserID           uuid.UUID
-	Column5          bool
 }
 
+// Moves an active booking onto another slot. The caller checks ownership,
+// overlap and the target slot's capacity in the same transaction.
 func (q *Queries) RescheduleBooking(ctx context.Context, arg RescheduleBookingParams) (Booking, error) {
 	row := q.db.QueryRowContext(ctx, rescheduleBooking,
 		arg.ID,
+		arg.SlotID,
 		arg.AppointmentStart,
 		arg.DurationMinutes,
-		arg.UserID,
-		arg.Column5,
 	)
 	var i Booking
 	err := row.Scan(
@@ -244,6 +397,64 @@ func (q *Queries) RescheduleBooking(ctx context.Context, arg RescheduleBookingPa
 		&i.DurationMinutes,
 		&i.UserID,
 		&i.SlotID,
+		&i.Status,
+		&i.StatusChangedAt,
+		&i.CancellationReason,
+		&i.CancelledBy,
+		&i.CancelledAt,
+		&i.Sequence,
+	)
+	return i, err
+}
+
+const slotHasBookings = `-- name: SlotHasBookings :one
+SELECT EXISTS (
+    SELECT 1 FROM bookings WHERE slot_id = $1
+) AS has_bookings
+`
+
+// Counts cancelled bookings too: a slot that has ever been booked keeps its
+// booking history and must not be deleted.
+func (q *Queries) SlotHasBookings(ctx context.Context, slotID uuid.UUID) (bool, error) {
+	row := q.db.QueryRowContext(ctx, slotHasBookings, slotID)
+	var has_bookings bool
+	err := row.Scan(&has_bookings)
+	return has_bookings, err
+}
+
+const updateBookingStatus = `-- name: UpdateBookingStatus :one
+UPDATE bookings
+SET status = $1,
+    status_changed_at = now(),
+    updated_at = now()
+WHERE id = $2
+  AND status = $3
+RETURNING id, created_at, updated_at, appointment_start, duration_minutes, user_id, slot_id, status, status_changed_at, cancellation_reason, cancelled_by, cancelled_at, sequence
+`
+
+type UpdateBookingStatusParams struct {
+	Status        string
+	ID            uuid.UUID
+	CurrentStatus string
+}
+
+func (q *Queries) UpdateBookingStatus(ctx context.Context, arg UpdateBookingStatusParams) (Booking, error) {
+	row := q.db.QueryRowContext(ctx, updateBookingStatus, arg.Status, arg.ID, arg.CurrentStatus)
+	var i Booking
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.AppointmentStart,
+		&i.DurationMinutes,
+		&i.UserID,
+		&i.SlotID,
+		&i.Status,
+		&i.StatusChangedAt,
+		&i.CancellationReason,
+		&i.CancelledBy,
+		&i.CancelledAt,
+		&i.Sequence,
 	)
 	return i, err
 }
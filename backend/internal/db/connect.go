@@ -4,31 +4,46 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"os"
-	"time"
+	"net/url"
 
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/config"
 	_ "github.com/jackc/pgx/v4/stdlib" // register the pgx driver with database/sql
 )
 
 // ConnectDB opens a *sql.DB using the pgx driver
-func ConnectDB(ctx context.Context) (*sql.DB, error) {
-	url := os.Getenv("DATABASE_URL")
-	if url == "" {
-		return nil, fmt.Errorf("DATABASE_URL is not set")
+func ConnectDB(ctx context.Context, cfg config.DatabaseConfig) (*sql.DB, error) {
+	dsn, err := withSimpleProtocol(cfg.URL)
+	if err != nil {
+		return nil, err
 	}
 
-	db, err := sql.Open("pgx", url+"&prefer_simple_protocol=true")
+	db, err := sql.Open("pgx", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("sql.Open: %w", err)
 	}
 
-	// optional tuning:
-	db.SetMaxOpenConns(10)
-	db.SetConnMaxIdleTime(5 * time.Minute)
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
 
 	if err := db.PingContext(ctx); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("db.Ping: %w", err)
 	}
 
 	return db, nil
 }
+
+// withSimpleProtocol turns on pgx's simple protocol. It avoids prepared
+// statements, which poolers such as PgBouncer in transaction mode reject.
+func withSimpleProtocol(dsn string) (string, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", fmt.Errorf("parse DATABASE_URL: %w", err)
+	}
+	q := u.Query()
+	q.Set("prefer_simple_protocol", "true")
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
@@ -1,9 +1,11 @@
 package db
 
-/*
 import (
+	"context"
 	"os"
 	"testing"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/config"
 )
 
 func TestConnectDB(t *testing.T) {
@@ -12,10 +14,43 @@ func TestConnectDB(t *testing.T) {
 		t.Skip("DATABASE_URL is not set; skipping database test")
 	}
 
-	db, err := ConnectDB()
+	cfg := config.Default().Database
+	cfg.URL = dbURL
+	db, err := ConnectDB(context.Background(), cfg)
 	if err != nil {
 		t.Fatalf("Failed to connect to database: %v", err)
 	}
 
 	defer db.Close()
-} */
+}
+
+func TestWithSimpleProtocol(t *testing.T) {
+	tests := []struct {
+		name string
+		dsn  string
+		want string
+	}{
+		{
+			name: "No query string",
+			dsn:  "postgres://u:p@localhost:5432/app",
+			want: "postgres://u:p@localhost:5432/app?prefer_simple_protocol=true",
+		},
+		{
+			name: "Existing parameters kept",
+			dsn:  "postgres://u:p@localhost:5432/app?sslmode=require",
+			want: "postgres://u:p@localhost:5432/app?prefer_simple_protocol=true&sslmode=require",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := withSimpleProtocol(tt.dsn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -9,9 +9,21 @@ import (
 type BookingQuerier interface {
 	CreateBooking(ctx context.Context, arg CreateBookingParams) (Booking, error)
 	GetOverlappingBookings(ctx context.Context, arg GetOverlappingBookingsParams) ([]Booking, error)
-	DeleteBooking(ctx context.Context, arg DeleteBookingParams) error
+	CancelBooking(ctx context.Context, arg CancelBookingParams) (Booking, error)
+	UpdateBookingStatus(ctx context.Context, arg UpdateBookingStatusParams) (Booking, error)
 	RescheduleBooking(ctx context.Context, arg RescheduleBookingParams) (Booking, error)
 	GetBookingByID(ctx context.Context, bookingID uuid.UUID) (Booking, error)
-	ListBookingsForUser(ctx context.Context, userID uuid.UUID) ([]Booking, error)
-	ListAllBookingsForAdmin(ctx context.Context) ([]Booking, error)
+	ListBookingsForUser(ctx context.Context, arg ListBookingsForUserParams) ([]Booking, error)
+	ListAllBookingsForAdmin(ctx context.Context, arg ListAllBookingsForAdminParams) ([]Booking, error)
+	GetAvailabilityByID(ctx context.Context, id uuid.UUID) (Availability, error)
+	LockProviderSchedule(ctx context.Context, providerID uuid.UUID) error
+	CountBookingsForSlot(ctx context.Context, slotID uuid.UUID) (int64, error)
+	ExecTx(ctx context.Context, fn func(Querier) error) error
+}
+
+// TxQuerier is a Querier that can also run several queries in a transaction.
+// *Store implements it.
+type TxQuerier interface {
+	Querier
+	ExecTx(ctx context.Context, fn func(Querier) error) error
 }
@@ -5,6 +5,7 @@
 package db
 
 import (
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,26 +18,88 @@ type Availability struct {
 	EndTime    time.Time
 	CreatedAt  time.Time
 	UpdatedAt  time.Time
+	Capacity   int32
+	PatternID  uuid.NullUUID
 }
 
 type AvailabilityPattern struct {
-	ID         uuid.UUID
-	ProviderID uuid.UUID
-	DayOfWeek  int32
-	StartTime  time.Time
-	EndTime    time.Time
-	CreatedAt  time.Time
-	UpdatedAt  time.Time
+	ID                  uuid.UUID
+	ProviderID          uuid.UUID
+	DayOfWeek           int32
+	StartTime           TimeOfDay
+	EndTime             TimeOfDay
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+	SlotMinutes         int32
+	BufferBeforeMinutes int32
+	BufferAfterMinutes  int32
+	Capacity            int32
+	GeneratedUntil      time.Time
+	EndsOn              time.Time
 }
 
 type Booking struct {
-	ID               uuid.UUID
-	CreatedAt        time.Time
-	UpdatedAt        time.Time
-	AppointmentStart time.Time
-	DurationMinutes  int32
-	UserID           uuid.UUID
-	SlotID           uuid.UUID
+	ID                 uuid.UUID
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+	AppointmentStart   time.Time
+	DurationMinutes    int32
+	UserID             uuid.UUID
+	SlotID             uuid.UUID
+	Status             string
+	StatusChangedAt    time.Time
+	CancellationReason *string
+	CancelledBy        *uuid.UUID
+	CancelledAt        *time.Time
+	Sequence           int64
+}
+
+type BookingReminder struct {
+	ID            uuid.UUID
+	BookingID     uuid.UUID
+	OffsetMinutes int32
+	RemindAt      time.Time
+	Status        string
+	Attempts      int32
+	NextAttemptAt time.Time
+	LastError     *string
+	CreatedAt     time.Time
+	SentAt        *time.Time
+}
+
+type Outbox struct {
+	ID            uuid.UUID
+	EventType     string
+	AggregateID   uuid.UUID
+	Payload       string
+	OccurredAt    time.Time
+	Status        string
+	Attempts      int32
+	NextAttemptAt time.Time
+	LastError     *string
+	DeliveredAt   *time.Time
+}
+
+type OutboxDelivery struct {
+	EventID     uuid.UUID
+	Subscriber  string
+	DeliveredAt time.Time
+}
+
+type RefreshToken struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	TokenHash string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	RevokedAt sql.NullTime
+}
+
+type RevokedToken struct {
+	Jti       string
+	UserID    uuid.UUID
+	RevokedAt time.Time
+	ExpiresAt time.Time
 }
 
 type User struct {
@@ -48,4 +111,41 @@ type User struct {
 	Email        string
 	PasswordHash string
 	UserRole     string
+	Timezone     string
+}
+
+type UserTokenRevocation struct {
+	UserID    uuid.UUID
+	RevokedAt time.Time
+	ExpiresAt time.Time
+}
+
+type WebhookDelivery struct {
+	ID             uuid.UUID
+	EndpointID     uuid.UUID
+	EventID        uuid.UUID
+	EventType      string
+	Payload        string
+	Status         string
+	Attempts       int32
+	NextAttemptAt  time.Time
+	ResponseStatus *int32
+	LastError      *string
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+}
+
+type WebhookEndpoint struct {
+	ID          uuid.UUID
+	URL         string
+	Secret      string
+	Description string
+	Active      bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type WebhookSubscription struct {
+	EndpointID uuid.UUID
+	EventType  string
 }
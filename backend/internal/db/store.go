@@ -0,0 +1,83 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+)
+
+// Store pairs the generated queries with the connection pool so that several
+// queries can be run inside a single transaction.
+//
+// Every query and transaction is recorded as a span on the global OpenTelemetry
+// tracer provider, which is a no-op unless tracing is configured.
+type Store struct {
+	*Queries
+	// pool is nil for a Store pinned to one connection by WithConn.
+	pool *sql.DB
+	conn interface {
+		BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+	}
+}
+
+func NewStore(conn *sql.DB) *Store {
+	return &Store{
+		Queries: New(TraceDBTX(conn)),
+		pool:    conn,
+		conn:    conn,
+	}
+}
+
+// ErrDirtyConn marks an error from a WithConn callback that could not undo
+// the session state it set up.
+var ErrDirtyConn = errors.New("connection left with session state")
+
+// WithConn runs fn with a Store whose queries and transactions all use one
+// connection from the pool, so session state such as an advisory lock taken
+// through it lasts until fn returns. fn must undo that state; if it cannot,
+// it returns an error wrapping ErrDirtyConn and the connection is closed
+// rather than reused.
+func (s *Store) WithConn(ctx context.Context, fn func(TxQuerier) error) error {
+	if s.pool == nil {
+		return fn(s)
+	}
+	conn, err := s.pool.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("get conn: %w", err)
+	}
+	defer conn.Close()
+
+	err = fn(&Store{Queries: New(TraceDBTX(conn)), conn: conn})
+	if errors.Is(err, ErrDirtyConn) {
+		_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+	}
+	return err
+}
+
+// ExecTx runs fn inside a transaction, committing if fn returns nil and
+// rolling back otherwise.
+func (s *Store) ExecTx(ctx context.Context, fn func(Querier) error) (err error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "db.tx")
+	defer func() { endQuerySpan(span, err) }()
+
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+
+	if err := fn(New(TraceDBTX(tx))); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return errors.Join(err, fmt.Errorf("rollback: %w", rbErr))
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
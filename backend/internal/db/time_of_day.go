@@ -0,0 +1,57 @@
+package db
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"time"
+)
+
+// timeOfDayLayout is how Postgres writes a TIME value as text.
+const timeOfDayLayout = "15:04:05.999999"
+
+// TimeOfDay holds a TIME column. The pgx stdlib driver returns TIME values
+// as text, which time.Time cannot scan, so sqlc maps those columns here.
+// Only the clock reading of the embedded time is meaningful.
+type TimeOfDay struct {
+	time.Time
+}
+
+// NewTimeOfDay keeps t's clock reading in its own location and drops the
+// date and zone.
+func NewTimeOfDay(t time.Time) TimeOfDay {
+	return TimeOfDay{time.Date(0, 1, 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)}
+}
+
+// On returns the instant this clock reading falls on date's calendar day in
+// loc. Times in a spring-forward gap are normalised as time.Date does.
+func (t TimeOfDay) On(date time.Time, loc *time.Location) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+// Scan implements sql.Scanner.
+func (t *TimeOfDay) Scan(src any) error {
+	switch v := src.(type) {
+	case time.Time:
+		*t = NewTimeOfDay(v)
+		return nil
+	case string:
+		return t.parse(v)
+	case []byte:
+		return t.parse(string(v))
+	}
+	return fmt.Errorf("cannot scan %T into TimeOfDay", src)
+}
+
+func (t *TimeOfDay) parse(s string) error {
+	parsed, err := time.Parse(timeOfDayLayout, s)
+	if err != nil {
+		return fmt.Errorf("parse time of day %q: %w", s, err)
+	}
+	*t = NewTimeOfDay(parsed)
+	return nil
+}
+
+// Value implements driver.Valuer.
+func (t TimeOfDay) Value() (driver.Value, error) {
+	return t.Format(timeOfDayLayout), nil
+}
@@ -0,0 +1,69 @@
+package db
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeOfDayScan(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		src     any
+		want    string
+		wantErr bool
+	}{
+		{name: "Text", src: "09:30:00", want: "09:30:00"},
+		{name: "Fractional seconds", src: []byte("17:45:12.5"), want: "17:45:12.5"},
+		{name: "Time keeps its clock reading", src: time.Date(2025, 3, 9, 8, 15, 0, 0, ny), want: "08:15:00"},
+		{name: "Malformed", src: "9am", wantErr: true},
+		{name: "Wrong type", src: int64(5), wantErr: true},
+		{name: "NULL", src: nil, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got TimeOfDay
+			err := got.Scan(tt.src)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			v, err := got.Value()
+			if err != nil {
+				t.Fatalf("value: %v", err)
+			}
+			if v != tt.want {
+				t.Errorf("got %v, want %s", v, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeOfDayOn(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+	tod := NewTimeOfDay(time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC))
+
+	// 09:00 is 14:00 UTC under EST and 13:00 UTC under EDT.
+	winter := tod.On(time.Date(2025, 3, 8, 0, 0, 0, 0, time.UTC), ny)
+	summer := tod.On(time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), ny)
+	if want := time.Date(2025, 3, 8, 14, 0, 0, 0, time.UTC); !winter.Equal(want) {
+		t.Errorf("winter: got %v, want %v", winter, want)
+	}
+	if want := time.Date(2025, 3, 10, 13, 0, 0, 0, time.UTC); !summer.Equal(want) {
+		t.Errorf("summer: got %v, want %v", summer, want)
+	}
+}
@@ -0,0 +1,91 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+type fakeDBTX struct {
+	DBTX
+	err error
+}
+
+func (f fakeDBTX) ExecContext(context.Context, string, ...interface{}) (sql.Result, error) {
+	return nil, f.err
+}
+
+func (f fakeDBTX) QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error) {
+	return nil, f.err
+}
+
+func TestQueryName(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: getBookingByID, want: "GetBookingByID"},
+		{query: "-- name: LockProviderSchedule :exec\nSELECT 1", want: "LockProviderSchedule"},
+		{query: "SELECT 1", want: "query"},
+	}
+	for _, tt := range tests {
+		if got := queryName(tt.query); got != tt.want {
+			t.Errorf("queryName(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestTraceDBTX(t *testing.T) {
+	exp := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp)))
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	tests := []struct {
+		name      string
+		err       error
+		run       func(q *Queries) error
+		wantSpan  string
+		wantError bool
+	}{
+		{
+			name:     "Exec",
+			run:      func(q *Queries) error { return q.DeleteUser(context.Background(), [16]byte{}) },
+			wantSpan: "db.DeleteUser",
+		},
+		{
+			name: "Query error",
+			err:  errors.New("connection reset"),
+			run: func(q *Queries) error {
+				_, err := q.ListUsers(context.Background(), ListUsersParams{PageLimit: 10})
+				return err
+			},
+			wantSpan:  "db.ListUsers",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exp.Reset()
+			_ = tt.run(New(TraceDBTX(fakeDBTX{err: tt.err})))
+
+			spans := exp.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(spans))
+			}
+			if spans[0].Name != tt.wantSpan {
+				t.Errorf("span name = %q, want %q", spans[0].Name, tt.wantSpan)
+			}
+			if (spans[0].Status.Code == codes.Error) != tt.wantError {
+				t.Errorf("span status = %v, wantError %v", spans[0].Status, tt.wantError)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Handler handles one event. Returning an error makes the relay offer the
+// event to this handler again later.
+type Handler func(ctx context.Context, m Message) error
+
+type subscription struct {
+	name    string
+	types   []string
+	handler Handler
+}
+
+// Bus routes events to the subscribers registered on it.
+type Bus struct {
+	mu   sync.RWMutex
+	subs []subscription
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe registers h for the given event types, or for every type if
+// none are given. Deliveries are recorded against name, so it must be unique
+// on the bus and stay the same across restarts.
+func (b *Bus) Subscribe(name string, h Handler, types ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, s := range b.subs {
+		if s.name == name {
+			panic(fmt.Sprintf("events: subscriber %q registered twice", name))
+		}
+	}
+	b.subs = append(b.subs, subscription{name: name, types: types, handler: h})
+}
+
+// subscribers returns the subscriptions that want eventType, in the order
+// they were registered.
+func (b *Bus) subscribers(eventType string) []subscription {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var out []subscription
+	for _, s := range b.subs {
+		if len(s.types) == 0 || slices.Contains(s.types, eventType) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// deliver runs the handler, turning a panic into an error so one bad
+// subscriber cannot stop the relay.
+func (s subscription) deliver(ctx context.Context, m Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return s.handler(ctx, m)
+}
@@ -0,0 +1,164 @@
+// Package events defines the domain events the services emit and carries
+// them from the outbox table to in-process subscribers.
+//
+// A service writes an event with Emit inside the transaction that makes the
+// change, so the event is stored exactly when the change commits. A Relay
+// then hands each stored event to every interested subscriber on a Bus,
+// retrying until all of them have handled it. Delivery is at-least-once:
+// subscribers must cope with seeing an event again.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/google/uuid"
+)
+
+// Event types, as stored in outbox.event_type.
+const (
+	TypeBookingCreated     = "booking.created"
+	TypeBookingRescheduled = "booking.rescheduled"
+	TypeBookingCancelled   = "booking.cancelled"
+	TypePatternChanged     = "pattern.changed"
+	TypeUserRegistered     = "user.registered"
+)
+
+// Types lists every event type.
+var Types = []string{
+	TypeBookingCreated,
+	TypeBookingRescheduled,
+	TypeBookingCancelled,
+	TypePatternChanged,
+	TypeUserRegistered,
+}
+
+// Event is the payload of a domain event.
+type Event interface {
+	// EventType is one of the Type constants.
+	EventType() string
+	// AggregateID is the entity the event is about.
+	AggregateID() uuid.UUID
+}
+
+// BookingCreated and the other booking events carry the booking's Sequence
+// after the change, which numbers the calendar invites sent for it.
+type BookingCreated struct {
+	BookingID        uuid.UUID `json:"booking_id"`
+	UserID           uuid.UUID `json:"user_id"`
+	ProviderID       uuid.UUID `json:"provider_id"`
+	SlotID           uuid.UUID `json:"slot_id"`
+	AppointmentStart time.Time `json:"appointment_start"`
+	DurationMinutes  int32     `json:"duration_minutes"`
+	Sequence         int64     `json:"sequence"`
+}
+
+func (BookingCreated) EventType() string        { return TypeBookingCreated }
+func (e BookingCreated) AggregateID() uuid.UUID { return e.BookingID }
+
+// BookingRescheduled moves a booking from PreviousSlotID to SlotID.
+// RescheduledBy is the owner or an admin.
+type BookingRescheduled struct {
+	BookingID                uuid.UUID `json:"booking_id"`
+	UserID                   uuid.UUID `json:"user_id"`
+	ProviderID               uuid.UUID `json:"provider_id"`
+	SlotID                   uuid.UUID `json:"slot_id"`
+	AppointmentStart         time.Time `json:"appointment_start"`
+	DurationMinutes          int32     `json:"duration_minutes"`
+	PreviousSlotID           uuid.UUID `json:"previous_slot_id"`
+	PreviousAppointmentStart time.Time `json:"previous_appointment_start"`
+	RescheduledBy            uuid.UUID `json:"rescheduled_by"`
+	Sequence                 int64     `json:"sequence"`
+}
+
+func (BookingRescheduled) EventType() string        { return TypeBookingRescheduled }
+func (e BookingRescheduled) AggregateID() uuid.UUID { return e.BookingID }
+
+type BookingCancelled struct {
+	BookingID        uuid.UUID `json:"booking_id"`
+	UserID           uuid.UUID `json:"user_id"`
+	ProviderID       uuid.UUID `json:"provider_id"`
+	SlotID           uuid.UUID `json:"slot_id"`
+	AppointmentStart time.Time `json:"appointment_start"`
+	DurationMinutes  int32     `json:"duration_minutes"`
+	CancelledBy      uuid.UUID `json:"cancelled_by"`
+	Reason           string    `json:"reason,omitempty"`
+	Sequence         int64     `json:"sequence"`
+}
+
+func (BookingCancelled) EventType() string        { return TypeBookingCancelled }
+func (e BookingCancelled) AggregateID() uuid.UUID { return e.BookingID }
+
+// PatternChanged.Change values.
+const (
+	PatternCreated = "created"
+	PatternUpdated = "updated"
+	PatternDeleted = "deleted"
+)
+
+// PatternChanged means a provider's weekly pattern, and so the slots
+// generated from it, changed.
+type PatternChanged struct {
+	PatternID  uuid.UUID `json:"pattern_id"`
+	ProviderID uuid.UUID `json:"provider_id"`
+	Change     string    `json:"change"`
+}
+
+func (PatternChanged) EventType() string        { return TypePatternChanged }
+func (e PatternChanged) AggregateID() uuid.UUID { return e.PatternID }
+
+type UserRegistered struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Email     string    `json:"email"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Role      string    `json:"role"`
+}
+
+func (UserRegistered) EventType() string        { return TypeUserRegistered }
+func (e UserRegistered) AggregateID() uuid.UUID { return e.UserID }
+
+// Message is an event as read back from the outbox.
+type Message struct {
+	ID          uuid.UUID
+	Type        string
+	AggregateID uuid.UUID
+	OccurredAt  time.Time
+	Payload     json.RawMessage
+}
+
+// Decode unmarshals the payload into v, usually a pointer to the event
+// struct for m.Type.
+func (m Message) Decode(v any) error {
+	if err := json.Unmarshal(m.Payload, v); err != nil {
+		return fmt.Errorf("decode %s event %s: %w", m.Type, m.ID, err)
+	}
+	return nil
+}
+
+// Writer is the part of a transaction Emit writes to.
+type Writer interface {
+	CreateOutboxEvent(ctx context.Context, arg db.CreateOutboxEventParams) error
+}
+
+// Emit stores e in the outbox through w, which should be the transaction
+// that makes the change e describes.
+func Emit(ctx context.Context, w Writer, e Event) error {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encode %s event: %w", e.EventType(), err)
+	}
+	err = w.CreateOutboxEvent(ctx, db.CreateOutboxEventParams{
+		ID:          uuid.New(),
+		EventType:   e.EventType(),
+		AggregateID: e.AggregateID(),
+		Payload:     string(payload),
+	})
+	if err != nil {
+		return fmt.Errorf("store %s event: %w", e.EventType(), err)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/google/uuid"
+)
+
+// Outbox statuses set by the relay. Events start out pending.
+const (
+	statusPending = "pending"
+	statusFailed  = "failed"
+)
+
+// RelayStore is what a Relay reads and updates the outbox through. *db.Store
+// implements it.
+type RelayStore interface {
+	ClaimOutboxEvents(ctx context.Context, arg db.ClaimOutboxEventsParams) ([]db.Outbox, error)
+	ListOutboxDeliveries(ctx context.Context, eventID uuid.UUID) ([]string, error)
+	RecordOutboxDelivery(ctx context.Context, arg db.RecordOutboxDeliveryParams) error
+	MarkOutboxEventDelivered(ctx context.Context, id uuid.UUID) error
+	MarkOutboxEventFailed(ctx context.Context, arg db.MarkOutboxEventFailedParams) error
+}
+
+// Relay moves events from the outbox to the subscribers on a Bus. Several
+// relays, in one process or many, may run against the same outbox.
+type Relay struct {
+	store       RelayStore
+	bus         *Bus
+	batchSize   int32
+	maxAttempts int32
+	lease       time.Duration
+	logger      *slog.Logger
+	now         func() time.Time
+}
+
+type RelayOption func(*Relay)
+
+// WithBatchSize sets how many events are claimed at a time. The default is
+// 100.
+func WithBatchSize(n int) RelayOption {
+	return func(r *Relay) { r.batchSize = int32(n) }
+}
+
+// WithMaxAttempts sets how many times an event is tried before it is marked
+// failed and left for an operator. The default is 10.
+func WithMaxAttempts(n int) RelayOption {
+	return func(r *Relay) { r.maxAttempts = int32(n) }
+}
+
+// WithLease sets how long claimed events are hidden from other relays; an
+// event whose relay dies mid-delivery is picked up again once it expires.
+// It must outlast handing a whole batch to the slowest subscribers, or
+// another relay may deliver the same event again. The default is a minute.
+func WithLease(lease time.Duration) RelayOption {
+	return func(r *Relay) { r.lease = lease }
+}
+
+// WithLogger sets where failed deliveries are logged. The default is
+// slog.Default.
+func WithLogger(l *slog.Logger) RelayOption {
+	return func(r *Relay) { r.logger = l }
+}
+
+func NewRelay(store RelayStore, bus *Bus, opts ...RelayOption) *Relay {
+	r := &Relay{
+		store:       store,
+		bus:         bus,
+		batchSize:   100,
+		maxAttempts: 10,
+		lease:       time.Minute,
+		logger:      slog.Default(),
+		now:         time.Now,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// RelayResult counts the events one Flush handled.
+type RelayResult struct {
+	Delivered int
+	// Retrying events had a subscriber fail and will be offered again.
+	Retrying int
+	// Failed events ran out of attempts.
+	Failed int
+}
+
+// Flush delivers due events batch by batch until none are left. Subscriber
+// failures are logged and scheduled for retry with backoff; only outbox
+// errors are returned.
+//
+// Once ctx is cancelled Flush finishes the event in hand, so a delivery is
+// not cut off halfway and repeated, and returns; the rest of the batch is
+// offered again when its lease runs out.
+func (r *Relay) Flush(ctx context.Context) (RelayResult, error) {
+	var result RelayResult
+	for ctx.Err() == nil {
+		batch, err := r.store.ClaimOutboxEvents(ctx, db.ClaimOutboxEventsParams{
+			LeaseUntil: r.now().Add(r.lease),
+			BatchLimit: r.batchSize,
+		})
+		if err != nil {
+			return result, fmt.Errorf("claim outbox events: %w", err)
+		}
+		slices.SortFunc(batch, func(a, b db.Outbox) int {
+			return a.OccurredAt.Compare(b.OccurredAt)
+		})
+
+		for _, event := range batch {
+			if ctx.Err() != nil {
+				break
+			}
+			if err := r.relay(context.WithoutCancel(ctx), event, &result); err != nil {
+				return result, fmt.Errorf("relay %s event %s: %w", event.EventType, event.ID, err)
+			}
+		}
+		if len(batch) < int(r.batchSize) {
+			break
+		}
+	}
+	return result, ctx.Err()
+}
+
+// relay offers event to every subscriber that has not handled it yet and
+// records the outcome.
+func (r *Relay) relay(ctx context.Context, event db.Outbox, result *RelayResult) error {
+	done, err := r.store.ListOutboxDeliveries(ctx, event.ID)
+	if err != nil {
+		return err
+	}
+
+	m := Message{
+		ID:          event.ID,
+		Type:        event.EventType,
+		AggregateID: event.AggregateID,
+		OccurredAt:  event.OccurredAt,
+		Payload:     json.RawMessage(event.Payload),
+	}
+	var failures []error
+	for _, s := range r.bus.subscribers(event.EventType) {
+		if slices.Contains(done, s.name) {
+			continue
+		}
+		if err := s.deliver(ctx, m); err != nil {
+			failures = append(failures, fmt.Errorf("%s: %w", s.name, err))
+			continue
+		}
+		if err := r.store.RecordOutboxDelivery(ctx, db.RecordOutboxDeliveryParams{
+			EventID:    event.ID,
+			Subscriber: s.name,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if len(failures) == 0 {
+		result.Delivered++
+		return r.store.MarkOutboxEventDelivered(ctx, event.ID)
+	}
+
+	failure := errors.Join(failures...)
+	msg := failure.Error()
+	attempts := event.Attempts + 1
+	status := statusPending
+	if attempts >= r.maxAttempts {
+		status = statusFailed
+		result.Failed++
+	} else {
+		result.Retrying++
+	}
+	r.logger.Warn("Event delivery failed",
+		"event_id", event.ID, "event_type", event.EventType,
+		"attempt", attempts, "status", status, "err", failure)
+
+	return r.store.MarkOutboxEventFailed(ctx, db.MarkOutboxEventFailedParams{
+		ID:            event.ID,
+		Status:        status,
+		LastError:     &msg,
+		NextAttemptAt: r.now().Add(backoff(attempts)),
+	})
+}
+
+// backoff is the wait before attempt n+1: ten seconds doubling with every
+// attempt, capped at an hour.
+func backoff(n int32) time.Duration {
+	d := 10 * time.Second
+	for i := int32(1); i < n && d < time.Hour; i++ {
+		d *= 2
+	}
+	return min(d, time.Hour)
+}
@@ -0,0 +1,347 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/google/uuid"
+)
+
+// memOutbox is an in-memory outbox that claims due pending events the way
+// ClaimOutboxEvents does.
+type memOutbox struct {
+	now        time.Time
+	events     []db.Outbox
+	deliveries map[uuid.UUID][]string
+}
+
+func newMemOutbox(now time.Time) *memOutbox {
+	return &memOutbox{now: now, deliveries: map[uuid.UUID][]string{}}
+}
+
+func (m *memOutbox) CreateOutboxEvent(ctx context.Context, arg db.CreateOutboxEventParams) error {
+	m.events = append(m.events, db.Outbox{
+		ID:            arg.ID,
+		EventType:     arg.EventType,
+		AggregateID:   arg.AggregateID,
+		Payload:       arg.Payload,
+		OccurredAt:    m.now,
+		Status:        statusPending,
+		NextAttemptAt: m.now,
+	})
+	return nil
+}
+
+func (m *memOutbox) ClaimOutboxEvents(ctx context.Context, arg db.ClaimOutboxEventsParams) ([]db.Outbox, error) {
+	var out []db.Outbox
+	for i, e := range m.events {
+		if len(out) == int(arg.BatchLimit) {
+			break
+		}
+		if e.Status == statusPending && !e.NextAttemptAt.After(m.now) {
+			m.events[i].NextAttemptAt = arg.LeaseUntil
+			out = append(out, m.events[i])
+		}
+	}
+	return out, nil
+}
+
+func (m *memOutbox) ListOutboxDeliveries(ctx context.Context, eventID uuid.UUID) ([]string, error) {
+	return m.deliveries[eventID], nil
+}
+
+func (m *memOutbox) RecordOutboxDelivery(ctx context.Context, arg db.RecordOutboxDeliveryParams) error {
+	if !slices.Contains(m.deliveries[arg.EventID], arg.Subscriber) {
+		m.deliveries[arg.EventID] = append(m.deliveries[arg.EventID], arg.Subscriber)
+	}
+	return nil
+}
+
+func (m *memOutbox) MarkOutboxEventDelivered(ctx context.Context, id uuid.UUID) error {
+	e := m.find(id)
+	e.Status = "delivered"
+	e.Attempts++
+	e.LastError = nil
+	e.DeliveredAt = &m.now
+	return nil
+}
+
+func (m *memOutbox) MarkOutboxEventFailed(ctx context.Context, arg db.MarkOutboxEventFailedParams) error {
+	e := m.find(arg.ID)
+	e.Status = arg.Status
+	e.Attempts++
+	e.LastError = arg.LastError
+	e.NextAttemptAt = arg.NextAttemptAt
+	return nil
+}
+
+func (m *memOutbox) find(id uuid.UUID) *db.Outbox {
+	for i := range m.events {
+		if m.events[i].ID == id {
+			return &m.events[i]
+		}
+	}
+	panic("no outbox event " + id.String())
+}
+
+func quietRelay(store RelayStore, bus *Bus, now func() time.Time, opts ...RelayOption) *Relay {
+	opts = append(opts, WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	r := NewRelay(store, bus, opts...)
+	r.now = now
+	return r
+}
+
+func TestRelayFlush(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 6, 3, 9, 0, 0, 0, time.UTC)
+	store := newMemOutbox(now)
+
+	booking := BookingCreated{BookingID: uuid.New(), UserID: uuid.New(), AppointmentStart: now.Add(time.Hour), DurationMinutes: 30}
+	user := UserRegistered{UserID: uuid.New(), Email: "ada@example.com"}
+	for _, e := range []Event{booking, user} {
+		if err := Emit(ctx, store, e); err != nil {
+			t.Fatalf("emit: %v", err)
+		}
+	}
+
+	bus := NewBus()
+	var all []string
+	var bookings []BookingCreated
+	bus.Subscribe("all", func(_ context.Context, m Message) error {
+		all = append(all, m.Type)
+		return nil
+	})
+	bus.Subscribe("bookings", func(_ context.Context, m Message) error {
+		var e BookingCreated
+		if err := m.Decode(&e); err != nil {
+			return err
+		}
+		if m.AggregateID != e.BookingID {
+			t.Errorf("aggregate id = %v, want %v", m.AggregateID, e.BookingID)
+		}
+		bookings = append(bookings, e)
+		return nil
+	}, TypeBookingCreated)
+
+	result, err := quietRelay(store, bus, func() time.Time { return now }, WithBatchSize(1)).Flush(ctx)
+	if err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+
+	if result != (RelayResult{Delivered: 2}) {
+		t.Errorf("result = %+v", result)
+	}
+	if want := []string{TypeBookingCreated, TypeUserRegistered}; !slices.Equal(all, want) {
+		t.Errorf("all saw %v, want %v", all, want)
+	}
+	if len(bookings) != 1 || bookings[0].BookingID != booking.BookingID || !bookings[0].AppointmentStart.Equal(booking.AppointmentStart) {
+		t.Errorf("bookings saw %+v, want %+v", bookings, booking)
+	}
+	for _, e := range store.events {
+		if e.Status != "delivered" || e.Attempts != 1 {
+			t.Errorf("event %s is %s after %d attempts", e.EventType, e.Status, e.Attempts)
+		}
+	}
+}
+
+func TestRelayFlushRetriesFailedSubscribers(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 6, 3, 9, 0, 0, 0, time.UTC)
+	store := newMemOutbox(now)
+	if err := Emit(ctx, store, PatternChanged{PatternID: uuid.New(), Change: PatternUpdated}); err != nil {
+		t.Fatalf("emit: %v", err)
+	}
+
+	bus := NewBus()
+	var steady, flaky int
+	flakyErr := errors.New("smtp down")
+	bus.Subscribe("steady", func(context.Context, Message) error {
+		steady++
+		return nil
+	})
+	bus.Subscribe("flaky", func(context.Context, Message) error {
+		flaky++
+		if flaky < 3 {
+			return flakyErr
+		}
+		return nil
+	})
+	relay := quietRelay(store, bus, func() time.Time { return store.now })
+
+	// The first attempt fails and is retried ten seconds later; the second
+	// waits twenty.
+	for i, wait := range []time.Duration{10 * time.Second, 20 * time.Second} {
+		result, err := relay.Flush(ctx)
+		if err != nil {
+			t.Fatalf("flush %d: %v", i+1, err)
+		}
+		if result != (RelayResult{Retrying: 1}) {
+			t.Errorf("flush %d: result = %+v", i+1, result)
+		}
+		e := store.events[0]
+		if e.Status != statusPending || e.LastError == nil || !strings.Contains(*e.LastError, "flaky: smtp down") {
+			t.Errorf("flush %d: event = %+v", i+1, e)
+		}
+		if want := store.now.Add(wait); !e.NextAttemptAt.Equal(want) {
+			t.Errorf("flush %d: next attempt at %v, want %v", i+1, e.NextAttemptAt, want)
+		}
+
+		// Nothing is due until the backoff has passed.
+		if result, _ := relay.Flush(ctx); result != (RelayResult{}) {
+			t.Errorf("flush %d: early retry: %+v", i+1, result)
+		}
+		store.now = store.now.Add(wait)
+	}
+
+	result, err := relay.Flush(ctx)
+	if err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if result != (RelayResult{Delivered: 1}) {
+		t.Errorf("result = %+v", result)
+	}
+	if steady != 1 || flaky != 3 {
+		t.Errorf("steady ran %d times and flaky %d, want 1 and 3", steady, flaky)
+	}
+	if e := store.events[0]; e.Status != "delivered" || e.Attempts != 3 || e.LastError != nil {
+		t.Errorf("event = %+v", e)
+	}
+}
+
+func TestRelayFlushGivesUp(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 6, 3, 9, 0, 0, 0, time.UTC)
+	store := newMemOutbox(now)
+	if err := Emit(ctx, store, UserRegistered{UserID: uuid.New()}); err != nil {
+		t.Fatalf("emit: %v", err)
+	}
+
+	bus := NewBus()
+	bus.Subscribe("broken", func(context.Context, Message) error {
+		panic("nil map")
+	})
+	relay := quietRelay(store, bus, func() time.Time { return store.now }, WithMaxAttempts(2))
+
+	var total RelayResult
+	for i := 0; i < 3; i++ {
+		result, err := relay.Flush(ctx)
+		if err != nil {
+			t.Fatalf("flush: %v", err)
+		}
+		total.Retrying += result.Retrying
+		total.Failed += result.Failed
+		store.now = store.now.Add(time.Hour)
+	}
+
+	if total != (RelayResult{Retrying: 1, Failed: 1}) {
+		t.Errorf("results = %+v", total)
+	}
+	e := store.events[0]
+	if e.Status != statusFailed || e.Attempts != 2 || !strings.Contains(*e.LastError, "panic: nil map") {
+		t.Errorf("event = %+v", e)
+	}
+}
+
+func TestRelayFlushFinishesEventOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	now := time.Date(2025, 6, 3, 9, 0, 0, 0, time.UTC)
+	store := newMemOutbox(now)
+	for range 2 {
+		if err := Emit(ctx, store, UserRegistered{UserID: uuid.New()}); err != nil {
+			t.Fatalf("emit: %v", err)
+		}
+	}
+
+	bus := NewBus()
+	var calls int
+	bus.Subscribe("slow", func(ctx context.Context, _ Message) error {
+		calls++
+		// Shutdown starts while the first event is being handled.
+		cancel()
+		return ctx.Err()
+	})
+
+	result, err := quietRelay(store, bus, func() time.Time { return now }).Flush(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("flush err = %v, want context.Canceled", err)
+	}
+	if calls != 1 || result != (RelayResult{Delivered: 1}) {
+		t.Errorf("calls = %d, result = %+v; want the first event delivered", calls, result)
+	}
+	if first, second := store.events[0], store.events[1]; first.Status != "delivered" || second.Status != statusPending || second.Attempts != 0 {
+		t.Errorf("events = %+v, %+v; want the second left for the next relay", first, second)
+	}
+}
+
+func TestRelayLease(t *testing.T) {
+	now := time.Date(2025, 6, 3, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		opts []RelayOption
+		want time.Duration
+	}{
+		{name: "Default", want: time.Minute},
+		{name: "Configured", opts: []RelayOption{WithLease(time.Hour)}, want: time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := newMemOutbox(now)
+			if err := Emit(ctx, store, UserRegistered{UserID: uuid.New()}); err != nil {
+				t.Fatalf("emit: %v", err)
+			}
+			// The claim is visible to subscribers while they handle the event.
+			var leased time.Time
+			bus := NewBus()
+			bus.Subscribe("peek", func(context.Context, Message) error {
+				leased = store.events[0].NextAttemptAt
+				return nil
+			})
+			relay := quietRelay(store, bus, func() time.Time { return now }, tt.opts...)
+			if _, err := relay.Flush(ctx); err != nil {
+				t.Fatalf("flush: %v", err)
+			}
+			if want := now.Add(tt.want); !leased.Equal(want) {
+				t.Errorf("leased until %v, want %v", leased, want)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int32
+		want    time.Duration
+	}{
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{5, 160 * time.Second},
+		{9, 2560 * time.Second},
+		{10, time.Hour},
+		{40, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := backoff(tt.attempt); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestBusSubscribeTwicePanics(t *testing.T) {
+	bus := NewBus()
+	bus.Subscribe("mail", func(context.Context, Message) error { return nil })
+	defer func() {
+		if recover() == nil {
+			t.Error("second Subscribe with the same name did not panic")
+		}
+	}()
+	bus.Subscribe("mail", func(context.Context, Message) error { return nil })
+}
@@ -2,14 +2,14 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
+	"errors"
 	"net/http"
-	"os"
-	"strings"
 	"time"
 
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/apperr"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/events"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/middleware"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/utils"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
@@ -17,16 +17,21 @@ import (
 )
 
 type userQuerier interface {
-	CreateUser(ctx context.Context, arg db.CreateUserParams) error
+	ExecTx(ctx context.Context, fn func(db.Querier) error) error
 	GetUserByEmail(ctx context.Context, email string) (db.User, error)
 }
 
+type loginQuerier interface {
+	GetUserByEmail(ctx context.Context, email string) (db.User, error)
+	CreateRefreshToken(ctx context.Context, arg db.CreateRefreshTokenParams) error
+}
+
 type RegisterRequest struct {
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
-	Email     string `json:"email"`
-	Password  string `json:"password"`
-	UserRole  string `json:"user_role"`
+	FirstName string `json:"first_name" validate:"required,max=100"`
+	LastName  string `json:"last_name" validate:"required,max=100"`
+	Email     string `json:"email" validate:"required,email,max=254"`
+	// bcrypt ignores everything past 72 bytes.
+	Password string `json:"password" validate:"required,max=72"`
 }
 
 type RegisterResponse struct {
@@ -40,17 +45,19 @@ type RegisterResponse struct {
 }
 
 type LoginRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email    string `json:"email" validate:"required"`
+	Password string `json:"password" validate:"required"`
 }
 
 type LoginResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
 }
 
 var HashPasswordFn = bcrypt.GenerateFromPassword
-var SignTokenFn = func(tok *jwt.Token, secret []byte) (string, error) {
-	return tok.SignedString(secret)
+var SignTokenFn = func(tok *jwt.Token, key interface{}) (string, error) {
+	return tok.SignedString(key)
 }
 
 func RegisterHandler(q userQuerier) http.HandlerFunc {
@@ -60,44 +67,29 @@ func RegisterHandler(q userQuerier) http.HandlerFunc {
 			RegisterResponse
 		}
 
-		decoder := json.NewDecoder(r.Body)
 		req := RegisterRequest{}
-		err := decoder.Decode(&req)
-		if err != nil {
-			utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body", err)
-			return
-		}
-
-		if req.FirstName == "" || req.LastName == "" {
-			utils.RespondWithError(w, http.StatusBadRequest, "First and last name required", nil)
+		if err := utils.DecodeJSON(w, r, &req); err != nil {
+			utils.RespondWithProblem(w, err)
 			return
 		}
 
-		if req.Email == "" || req.Password == "" {
-			utils.RespondWithError(w, http.StatusBadRequest, "Email and password required", nil)
-			return
-		}
-		if req.UserRole == "" {
-			req.UserRole = "user"
-		}
-
 		hashedPassword, err := HashPasswordFn([]byte(req.Password), bcrypt.DefaultCost)
 		if err != nil {
 			utils.RespondWithError(w, http.StatusInternalServerError, "Could not hash password", err)
 			return
 		}
 
-		err = q.CreateUser(r.Context(), db.CreateUserParams{
+		err = createUser(r.Context(), q, db.CreateUserParams{
 			ID:           uuid.New(),
 			FirstName:    req.FirstName,
 			LastName:     req.LastName,
 			Email:        req.Email,
 			PasswordHash: string(hashedPassword),
-			UserRole:     req.UserRole,
+			UserRole:     middleware.RoleUser,
 		})
 		if err != nil {
-			if strings.Contains(err.Error(), "UNIQUE constraint failed: users.email") {
-				utils.RespondWithError(w, http.StatusBadRequest, "Email already registered", nil)
+			if apperr.KindOf(err) == apperr.KindConflict {
+				utils.RespondWithProblem(w, apperr.Wrap(apperr.KindConflict, "Email already registered", err))
 				return
 			}
 			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to create user", err)
@@ -124,18 +116,27 @@ func RegisterHandler(q userQuerier) http.HandlerFunc {
 	}
 }
 
-func LoginHandler(q userQuerier) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		decoder := json.NewDecoder(r.Body)
-		req := LoginRequest{}
-		err := decoder.Decode(&req)
-		if err != nil {
-			utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body", err)
-			return
+// createUser stores a new user together with its UserRegistered event.
+func createUser(ctx context.Context, q userQuerier, arg db.CreateUserParams) error {
+	return q.ExecTx(ctx, func(tx db.Querier) error {
+		if err := tx.CreateUser(ctx, arg); err != nil {
+			return err
 		}
+		return events.Emit(ctx, tx, events.UserRegistered{
+			UserID:    arg.ID,
+			Email:     arg.Email,
+			FirstName: arg.FirstName,
+			LastName:  arg.LastName,
+			Role:      arg.UserRole,
+		})
+	})
+}
 
-		if req.Email == "" || req.Password == "" {
-			utils.RespondWithError(w, http.StatusBadRequest, "Email and password required", nil)
+func LoginHandler(q loginQuerier, tokens Tokens) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := LoginRequest{}
+		if err := utils.DecodeJSON(w, r, &req); err != nil {
+			utils.RespondWithProblem(w, err)
 			return
 		}
 
@@ -144,7 +145,6 @@ func LoginHandler(q userQuerier) http.HandlerFunc {
 			utils.RespondWithError(w, http.StatusUnauthorized, "Invalid credentials", err)
 			return
 		}
-		fmt.Println("Login user:", user.Email, "with role:", user.UserRole)
 
 		err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password))
 		if err != nil {
@@ -152,26 +152,17 @@ func LoginHandler(q userQuerier) http.HandlerFunc {
 			return
 		}
 
-		secret := os.Getenv("JWT_SECRET")
-		if secret == "" {
-			utils.RespondWithError(w, http.StatusInternalServerError, "Missing JWT_SECRET", nil)
-			return
-		}
-
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-			"sub":       user.ID.String(),
-			"user_role": user.UserRole,
-			"firstName": user.FirstName,
-			"iat":       jwt.NewNumericDate(time.Now()),
-			"exp":       jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-		})
-
-		tokenString, err := SignTokenFn(token, []byte(secret))
+		resp, err := tokens.issue(r.Context(), q, user)
 		if err != nil {
-			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to sign token", err)
+			var signErr tokenSignError
+			if errors.As(err, &signErr) {
+				utils.RespondWithError(w, http.StatusInternalServerError, "Failed to sign token", err)
+				return
+			}
+			utils.RespondWithError(w, http.StatusInternalServerError, "Unable to store refresh token", err)
 			return
 		}
 
-		utils.RespondWithJSON(w, http.StatusOK, LoginResponse{Token: tokenString})
+		utils.RespondWithJSON(w, http.StatusOK, resp)
 	}
 }
@@ -7,14 +7,15 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/events"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -22,20 +23,35 @@ type mockRegisterQueries struct {
 	db.Queries
 	shouldFailInsert bool
 	shouldFailFetch  bool
+	createdRole      string
+	events           []db.CreateOutboxEventParams
+}
+
+func (m *mockRegisterQueries) ExecTx(_ context.Context, fn func(db.Querier) error) error {
+	return fn(m)
+}
+
+func (m *mockRegisterQueries) CreateOutboxEvent(_ context.Context, arg db.CreateOutboxEventParams) error {
+	m.events = append(m.events, arg)
+	return nil
 }
 
 func (m *mockRegisterQueries) CreateUser(_ context.Context, user db.CreateUserParams) error {
 	if user.Email == usedEmail {
-		return errors.New("UNIQUE constraint failed: users.email")
+		return errEmailTaken
 	}
 	if m.shouldFailInsert {
 		return errInsertFailed
 	}
+	m.createdRole = user.UserRole
 	return nil
 }
 
 var usedEmail = "usedEmail@email.com"
 
+// errEmailTaken is what pgx returns when users_email_key is violated.
+var errEmailTaken = &pgconn.PgError{Code: "23505", ConstraintName: "users_email_key"}
+
 func (m *mockRegisterQueries) GetUserByEmail(_ context.Context, email string) (db.User, error) {
 	if m.shouldFailFetch {
 		return db.User{}, errors.New("Unable to fetch new user")
@@ -65,6 +81,8 @@ func TestRegisterHandler(t *testing.T) {
 		expectedCode     int
 		expectedContains string
 		shouldFailHash   bool
+		expectedRole     string
+		expectEvent      bool
 	}{
 		{
 			name: "Valid registration",
@@ -77,6 +95,21 @@ func TestRegisterHandler(t *testing.T) {
 			mockQuery:      &mockRegisterQueries{},
 			expectedCode:   http.StatusCreated,
 			shouldFailHash: false,
+			expectedRole:   "user",
+			expectEvent:    true,
+		},
+		{
+			name: "Requested admin role is rejected",
+			requestBody: map[string]string{
+				"first_name": "John",
+				"last_name":  "Doe",
+				"email":      "user@example.com",
+				"password":   "strongpassword",
+				"user_role":  "admin",
+			},
+			mockQuery:        &mockRegisterQueries{},
+			expectedCode:     http.StatusBadRequest,
+			expectedContains: `{"field":"user_role","message":"is not allowed"}`,
 		},
 		{
 			name:           "Invalid request body",
@@ -95,7 +128,7 @@ func TestRegisterHandler(t *testing.T) {
 			},
 			mockQuery:        &mockRegisterQueries{},
 			expectedCode:     http.StatusBadRequest,
-			expectedContains: "Email and password required",
+			expectedContains: `{"field":"email","message":"is required"}`,
 			shouldFailHash:   false,
 		},
 		{
@@ -108,7 +141,7 @@ func TestRegisterHandler(t *testing.T) {
 			},
 			mockQuery:        &mockRegisterQueries{},
 			expectedCode:     http.StatusBadRequest,
-			expectedContains: "Email and password required",
+			expectedContains: `{"field":"password","message":"is required"}`,
 			shouldFailHash:   false,
 		},
 		{
@@ -120,7 +153,7 @@ func TestRegisterHandler(t *testing.T) {
 			},
 			mockQuery:        &mockRegisterQueries{},
 			expectedCode:     http.StatusBadRequest,
-			expectedContains: "First and last name required",
+			expectedContains: `{"field":"first_name","message":"is required"}`,
 			shouldFailHash:   false,
 		},
 		{
@@ -132,7 +165,7 @@ func TestRegisterHandler(t *testing.T) {
 			},
 			mockQuery:        &mockRegisterQueries{},
 			expectedCode:     http.StatusBadRequest,
-			expectedContains: "First and last name required",
+			expectedContains: `{"field":"last_name","message":"is required"}`,
 			shouldFailHash:   false,
 		},
 		{
@@ -172,7 +205,7 @@ func TestRegisterHandler(t *testing.T) {
 				Password:  "strongpassword",
 			},
 			mockQuery:        &mockRegisterQueries{},
-			expectedCode:     http.StatusBadRequest,
+			expectedCode:     http.StatusConflict,
 			expectedContains: "Email already registered",
 			shouldFailHash:   false,
 		},
@@ -190,6 +223,7 @@ func TestRegisterHandler(t *testing.T) {
 			expectedCode:     http.StatusInternalServerError,
 			expectedContains: "Unable to fetch new user",
 			shouldFailHash:   false,
+			expectEvent:      true,
 		},
 	}
 
@@ -221,14 +255,25 @@ func TestRegisterHandler(t *testing.T) {
 			if tt.expectedContains != "" && !bytes.Contains(rr.Body.Bytes(), []byte(tt.expectedContains)) {
 				t.Errorf("expected response to contain %q, got %s", tt.expectedContains, rr.Body.String())
 			}
+			if tt.expectedRole != "" && tt.mockQuery.createdRole != tt.expectedRole {
+				t.Errorf("expected user created with role %q, got %q", tt.expectedRole, tt.mockQuery.createdRole)
+			}
+			if tt.expectEvent {
+				if len(tt.mockQuery.events) != 1 || tt.mockQuery.events[0].EventType != events.TypeUserRegistered {
+					t.Errorf("expected one %s event, got %+v", events.TypeUserRegistered, tt.mockQuery.events)
+				}
+			} else if len(tt.mockQuery.events) != 0 {
+				t.Errorf("expected no events, got %+v", tt.mockQuery.events)
+			}
 		})
 	}
 }
 
 type mockUserQuerier struct {
-	GetUserByEmailFn func(ctx context.Context, email string) (db.User, error)
-	DeleteUserFn     func(ctx context.Context, id uuid.UUID) error
-	ListUsersFunc    func(ctx context.Context) ([]db.User, error)
+	GetUserByEmailFn     func(ctx context.Context, email string) (db.User, error)
+	DeleteUserFn         func(ctx context.Context, id uuid.UUID) error
+	ListUsersFunc        func(ctx context.Context, arg db.ListUsersParams) ([]db.User, error)
+	CreateRefreshTokenFn func(ctx context.Context, arg db.CreateRefreshTokenParams) error
 }
 
 func (m *mockUserQuerier) CreateUser(ctx context.Context, p db.CreateUserParams) error {
@@ -244,6 +289,12 @@ func (m *mockUserQuerier) DeleteUser(ctx context.Context, id uuid.UUID) error {
 func (m *mockUserQuerier) UpdateUser(_ context.Context, _ db.UpdateUserParams) error {
 	return nil
 }
+func (m *mockUserQuerier) CreateRefreshToken(ctx context.Context, arg db.CreateRefreshTokenParams) error {
+	if m.CreateRefreshTokenFn == nil {
+		return nil
+	}
+	return m.CreateRefreshTokenFn(ctx, arg)
+}
 
 func TestLoginHandler(t *testing.T) {
 	plain := "plain-password"
@@ -264,6 +315,7 @@ func TestLoginHandler(t *testing.T) {
 		secret           string
 		body             any
 		mockGet          func(ctx context.Context, email string) (db.User, error)
+		mockStoreRefresh func(ctx context.Context, arg db.CreateRefreshTokenParams) error
 		expectedCode     int
 		expectedContains string
 		shouldFailSign   bool
@@ -276,9 +328,22 @@ func TestLoginHandler(t *testing.T) {
 				return mockUser, nil
 			},
 			expectedCode:     http.StatusOK,
-			expectedContains: `"token":`,
+			expectedContains: `"refresh_token":`,
 			shouldFailSign:   false,
 		},
+		{
+			name:   "Refresh token not stored",
+			secret: "testsecret",
+			body:   LoginRequest{Email: mockUser.Email, Password: plain},
+			mockGet: func(_ context.Context, email string) (db.User, error) {
+				return mockUser, nil
+			},
+			mockStoreRefresh: func(_ context.Context, _ db.CreateRefreshTokenParams) error {
+				return errors.New("db down")
+			},
+			expectedCode:     http.StatusInternalServerError,
+			expectedContains: "Unable to store refresh token",
+		},
 		{
 			name:   "Wrong password",
 			secret: "testsecret",
@@ -309,7 +374,7 @@ func TestLoginHandler(t *testing.T) {
 				return mockUser, nil
 			},
 			expectedCode:     http.StatusBadRequest,
-			expectedContains: "Email and password required",
+			expectedContains: `{"field":"email","message":"is required"}`,
 			shouldFailSign:   false,
 		},
 		{
@@ -321,14 +386,14 @@ func TestLoginHandler(t *testing.T) {
 			shouldFailSign: false,
 		},
 		{
-			name:   "Missing JWT_SECRET",
+			name:   "No signing key",
 			secret: "",
 			body:   LoginRequest{Email: mockUser.Email, Password: plain},
 			mockGet: func(_ context.Context, email string) (db.User, error) {
 				return mockUser, nil
 			},
 			expectedCode:     http.StatusInternalServerError,
-			expectedContains: "Missing JWT_SECRET",
+			expectedContains: "Failed to sign token",
 			shouldFailSign:   false,
 		},
 		{
@@ -349,18 +414,14 @@ func TestLoginHandler(t *testing.T) {
 
 			oldSign := SignTokenFn
 			if tt.shouldFailSign {
-				SignTokenFn = func(_ *jwt.Token, _ []byte) (string, error) {
+				SignTokenFn = func(_ *jwt.Token, _ interface{}) (string, error) {
 					return "", errors.New("simulated sign error")
 				}
 			}
 			defer func() { SignTokenFn = oldSign }()
 
-			old := os.Getenv("JWT_SECRET")
-			t.Cleanup(func() { os.Setenv("JWT_SECRET", old) })
-			os.Setenv("JWT_SECRET", tt.secret)
-
-			mockQ := &mockUserQuerier{GetUserByEmailFn: tt.mockGet}
-			handler := LoginHandler(mockQ)
+			mockQ := &mockUserQuerier{GetUserByEmailFn: tt.mockGet, CreateRefreshTokenFn: tt.mockStoreRefresh}
+			handler := LoginHandler(mockQ, testTokens(t, tt.secret))
 
 			var buf bytes.Buffer
 			if s, ok := tt.body.(string); ok {
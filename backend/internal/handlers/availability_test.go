@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/middleware"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/service"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -27,7 +28,7 @@ type mockAvailabilityStore struct {
 	}
 }
 
-func (m *mockAvailabilityStore) CreatePatternAndSlots(ctx context.Context, providerID uuid.UUID, dayOfWeek int32, start, end time.Time) error {
+func (m *mockAvailabilityStore) CreatePatternAndSlots(ctx context.Context, providerID uuid.UUID, dayOfWeek int32, start, end time.Time, settings service.SlotSettings) error {
 	m.called = true
 	m.got.providerID = providerID
 	m.got.dayOfWeek = dayOfWeek
@@ -46,7 +47,6 @@ func TestCreateAvailabilityPatternHandler_Style(t *testing.T) {
 		reqBody          any
 		expectedCode     int
 		expectedContains string
-		isAdmin          bool
 		injectUserID     bool
 		mockErr          error
 	}{
@@ -54,24 +54,14 @@ func TestCreateAvailabilityPatternHandler_Style(t *testing.T) {
 			name:         "Success",
 			reqBody:      map[string]any{"day_of_week": int32(start.Weekday()), "start_time": start, "end_time": end},
 			expectedCode: http.StatusCreated,
-			isAdmin:      true,
 			injectUserID: true,
 			mockErr:      nil,
 		},
-		{
-			name:             "Non-admin",
-			reqBody:          map[string]any{"day_of_week": 1, "start_time": start, "end_time": end},
-			expectedCode:     http.StatusForbidden,
-			expectedContains: "Forbidden",
-			isAdmin:          false,
-			injectUserID:     true,
-		},
 		{
 			name:             "Missing user ID",
 			reqBody:          map[string]any{"day_of_week": 1, "start_time": start, "end_time": end},
 			expectedCode:     http.StatusInternalServerError,
 			expectedContains: "Could not get user ID",
-			isAdmin:          true,
 			injectUserID:     false,
 		},
 		{
@@ -79,31 +69,27 @@ func TestCreateAvailabilityPatternHandler_Style(t *testing.T) {
 			reqBody:          "{ invalid json",
 			expectedCode:     http.StatusBadRequest,
 			expectedContains: "Invalid request body",
-			isAdmin:          true,
 			injectUserID:     true,
 		},
 		{
 			name:             "Bad day_of_week",
 			reqBody:          map[string]any{"day_of_week": 8, "start_time": start, "end_time": end},
 			expectedCode:     http.StatusBadRequest,
-			expectedContains: "day_of_week must be 0–6",
-			isAdmin:          true,
+			expectedContains: `{"field":"day_of_week","message":"must be at most 6"}`,
 			injectUserID:     true,
 		},
 		{
 			name:             "End before start",
 			reqBody:          map[string]any{"day_of_week": int32(start.Weekday()), "start_time": end, "end_time": start},
 			expectedCode:     http.StatusBadRequest,
-			expectedContains: "end_time must be after start_time",
-			isAdmin:          true,
+			expectedContains: `{"field":"end_time","message":"must be after start_time"}`,
 			injectUserID:     true,
 		},
 		{
 			name:             "Service error",
 			reqBody:          map[string]any{"day_of_week": 1, "start_time": start, "end_time": end},
 			expectedCode:     http.StatusInternalServerError,
-			expectedContains: "Failed to create pattern",
-			isAdmin:          true,
+			expectedContains: "Internal server error",
 			injectUserID:     true,
 			mockErr:          errors.New("some error"),
 		},
@@ -124,7 +110,6 @@ func TestCreateAvailabilityPatternHandler_Style(t *testing.T) {
 			req.Header.Set("Content-Type", "application/json")
 
 			ctx := req.Context()
-			ctx = context.WithValue(ctx, middleware.IsAdminKey, tt.isAdmin)
 			if tt.injectUserID {
 				ctx = context.WithValue(ctx, middleware.UserIDKey, providerID)
 			}
@@ -1,49 +1,26 @@
 package handlers
 
 import (
-	"context"
-	"encoding/json"
 	"net/http"
-	"strings"
 
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/apperr"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/middleware"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/utils"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
-type adminCreator interface {
-	CreateUser(ctx context.Context, arg db.CreateUserParams) error
-	GetUserByEmail(ctx context.Context, email string) (db.User, error)
-}
-
-func CreateAdminHandler(a adminCreator) http.HandlerFunc {
+func CreateAdminHandler(a userQuerier) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 
 		type response struct {
 			RegisterResponse
 		}
 
-		if !middleware.IsAdminFromContext(r.Context()) {
-			utils.RespondWithError(w, http.StatusForbidden, "Forbidden", nil)
-			return
-		}
-
-		decoder := json.NewDecoder(r.Body)
 		req := RegisterRequest{}
-		err := decoder.Decode(&req)
-		if err != nil {
-			utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body", err)
-			return
-		}
-
-		if req.FirstName == "" || req.LastName == "" {
-			utils.RespondWithError(w, http.StatusBadRequest, "First and last name required", nil)
-			return
-		}
-
-		if req.Email == "" || req.Password == "" {
-			utils.RespondWithError(w, http.StatusBadRequest, "Email and password required", nil)
+		if err := utils.DecodeJSON(w, r, &req); err != nil {
+			utils.RespondWithProblem(w, err)
 			return
 		}
 
@@ -53,16 +30,17 @@ func CreateAdminHandler(a adminCreator) http.HandlerFunc {
 			return
 		}
 
-		err = a.CreateUser(r.Context(), db.CreateUserParams{
+		err = createUser(r.Context(), a, db.CreateUserParams{
+			ID:           uuid.New(),
 			FirstName:    req.FirstName,
 			LastName:     req.LastName,
 			Email:        req.Email,
 			PasswordHash: string(hashedPassword),
-			UserRole:     "admin",
+			UserRole:     middleware.RoleAdmin,
 		})
 		if err != nil {
-			if strings.Contains(err.Error(), "UNIQUE constraint failed: users.email") {
-				utils.RespondWithError(w, http.StatusBadRequest, "Email already registered", nil)
+			if apperr.KindOf(err) == apperr.KindConflict {
+				utils.RespondWithProblem(w, apperr.Wrap(apperr.KindConflict, "Email already registered", err))
 				return
 			}
 			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to create user", err)
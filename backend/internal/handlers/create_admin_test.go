@@ -11,7 +11,6 @@ import (
 	"time"
 
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
-	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/middleware"
 	"github.com/google/uuid"
 )
 
@@ -21,9 +20,17 @@ type mockAdminRegisterQueries struct {
 	shouldFailFetch  bool
 }
 
+func (m *mockAdminRegisterQueries) ExecTx(_ context.Context, fn func(db.Querier) error) error {
+	return fn(m)
+}
+
+func (m *mockAdminRegisterQueries) CreateOutboxEvent(_ context.Context, _ db.CreateOutboxEventParams) error {
+	return nil
+}
+
 func (m *mockAdminRegisterQueries) CreateUser(_ context.Context, user db.CreateUserParams) error {
 	if user.Email == usedEmail {
-		return errors.New("UNIQUE constraint failed: users.email")
+		return errEmailTaken
 	}
 	if m.shouldFailInsert {
 		return errInsertFailed
@@ -52,7 +59,6 @@ type AdminRequest struct {
 	LastName  string `json:"last_name"`
 	Email     string `json:"email"`
 	Password  string `json:"password"`
-	UserRole  string `json:"user_role"`
 }
 
 var repeatEmail = "usedEmail@email.com"
@@ -65,7 +71,6 @@ func TestCreateAdminHandler(t *testing.T) {
 		expectedCode     int
 		expectedContains string
 		shouldFailHash   bool
-		injectAdmin      bool
 	}{
 		{
 			name: "Valid registration",
@@ -74,27 +79,10 @@ func TestCreateAdminHandler(t *testing.T) {
 				LastName:  "Doe",
 				Email:     "user@example.com",
 				Password:  "strongpassword",
-				UserRole:  "admin",
 			},
 			mockQuery:      &mockAdminRegisterQueries{},
 			expectedCode:   http.StatusCreated,
 			shouldFailHash: false,
-			injectAdmin:    true,
-		},
-		{
-			name: "Does not have role of admin",
-			requestBody: AdminRequest{
-				FirstName: "John",
-				LastName:  "Doe",
-				Email:     "user@example.com",
-				Password:  "strongpassword",
-				UserRole:  "admin",
-			},
-			mockQuery:        &mockAdminRegisterQueries{},
-			expectedCode:     http.StatusForbidden,
-			expectedContains: "Forbidden",
-			shouldFailHash:   false,
-			injectAdmin:      false,
 		},
 		{
 			name:           "Invalid request body",
@@ -102,7 +90,6 @@ func TestCreateAdminHandler(t *testing.T) {
 			mockQuery:      &mockAdminRegisterQueries{},
 			expectedCode:   http.StatusBadRequest,
 			shouldFailHash: false,
-			injectAdmin:    true,
 		},
 		{
 			name: "Missing email",
@@ -114,9 +101,8 @@ func TestCreateAdminHandler(t *testing.T) {
 			},
 			mockQuery:        &mockAdminRegisterQueries{},
 			expectedCode:     http.StatusBadRequest,
-			expectedContains: "Email and password required",
+			expectedContains: `{"field":"email","message":"is required"}`,
 			shouldFailHash:   false,
-			injectAdmin:      true,
 		},
 		{
 			name: "Missing password",
@@ -128,9 +114,8 @@ func TestCreateAdminHandler(t *testing.T) {
 			},
 			mockQuery:        &mockAdminRegisterQueries{},
 			expectedCode:     http.StatusBadRequest,
-			expectedContains: "Email and password required",
+			expectedContains: `{"field":"password","message":"is required"}`,
 			shouldFailHash:   false,
-			injectAdmin:      true,
 		},
 		{
 			name: "Missing first name",
@@ -141,9 +126,8 @@ func TestCreateAdminHandler(t *testing.T) {
 			},
 			mockQuery:        &mockAdminRegisterQueries{},
 			expectedCode:     http.StatusBadRequest,
-			expectedContains: "First and last name required",
+			expectedContains: `{"field":"first_name","message":"is required"}`,
 			shouldFailHash:   false,
-			injectAdmin:      true,
 		},
 		{
 			name: "Missing last name",
@@ -154,9 +138,8 @@ func TestCreateAdminHandler(t *testing.T) {
 			},
 			mockQuery:        &mockAdminRegisterQueries{},
 			expectedCode:     http.StatusBadRequest,
-			expectedContains: "First and last name required",
+			expectedContains: `{"field":"last_name","message":"is required"}`,
 			shouldFailHash:   false,
-			injectAdmin:      true,
 		},
 		{
 			name: "Hash failure",
@@ -170,7 +153,6 @@ func TestCreateAdminHandler(t *testing.T) {
 			expectedCode:     http.StatusInternalServerError,
 			expectedContains: "Could not hash password",
 			shouldFailHash:   true,
-			injectAdmin:      true,
 		},
 		{
 			name: "Insert failure",
@@ -186,7 +168,6 @@ func TestCreateAdminHandler(t *testing.T) {
 			expectedCode:     http.StatusInternalServerError,
 			expectedContains: "Failed to create user",
 			shouldFailHash:   false,
-			injectAdmin:      true,
 		},
 		{
 			name: "Email already registered",
@@ -197,10 +178,9 @@ func TestCreateAdminHandler(t *testing.T) {
 				Password:  "strongpassword",
 			},
 			mockQuery:        &mockAdminRegisterQueries{},
-			expectedCode:     http.StatusBadRequest,
+			expectedCode:     http.StatusConflict,
 			expectedContains: "Email already registered",
 			shouldFailHash:   false,
-			injectAdmin:      true,
 		},
 		{
 			name: "Unable to retrieve new user",
@@ -216,7 +196,6 @@ func TestCreateAdminHandler(t *testing.T) {
 			expectedCode:     http.StatusInternalServerError,
 			expectedContains: "Unable to fetch new admin",
 			shouldFailHash:   false,
-			injectAdmin:      true,
 		},
 	}
 
@@ -241,11 +220,6 @@ func TestCreateAdminHandler(t *testing.T) {
 
 			rr := httptest.NewRecorder()
 
-			if tt.injectAdmin {
-				ctx := context.WithValue(req.Context(), middleware.IsAdminKey, true)
-				req = req.WithContext(ctx)
-			}
-
 			handler.ServeHTTP(rr, req)
 
 			if rr.Code != tt.expectedCode {
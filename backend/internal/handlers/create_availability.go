@@ -2,10 +2,10 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
 	"net/http"
 	"time"
 
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/apperr"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/middleware"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/utils"
@@ -17,23 +17,18 @@ type availabilityCreator interface {
 }
 
 type createAvailabilityRequest struct {
-	StartTime time.Time `json:"start_time"`
-	EndTime   time.Time `json:"end_time"`
+	StartTime time.Time `json:"start_time" validate:"required"`
+	EndTime   time.Time `json:"end_time" validate:"required,gtfield=StartTime"`
+	// Capacity defaults to one booking.
+	Capacity int32 `json:"capacity" validate:"omitempty,min=1,max=1000"`
 }
 
 func CreateAvailabilityHandler(q availabilityCreator) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 
-		if !middleware.IsAdminFromContext(r.Context()) {
-			utils.RespondWithError(w, http.StatusForbidden, "Forbidden", nil)
-			return
-		}
-
-		decoder := json.NewDecoder(r.Body)
 		req := createAvailabilityRequest{}
-		err := decoder.Decode(&req)
-		if err != nil {
-			utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body", err)
+		if err := utils.DecodeJSON(w, r, &req); err != nil {
+			utils.RespondWithProblem(w, err)
 			return
 		}
 
@@ -43,14 +38,23 @@ func CreateAvailabilityHandler(q availabilityCreator) http.HandlerFunc {
 			return
 		}
 
+		if req.Capacity == 0 {
+			req.Capacity = 1
+		}
+
 		arg := db.CreateAvailabilityParams{
 			ID:         uuid.New(),
 			ProviderID: providerID,
 			StartTime:  req.StartTime,
 			EndTime:    req.EndTime,
+			Capacity:   req.Capacity,
 		}
 
 		if err := q.CreateAvailability(r.Context(), arg); err != nil {
+			if apperr.KindOf(err) == apperr.KindConflict {
+				utils.RespondWithProblem(w, apperr.Wrap(apperr.KindConflict, "A slot already starts at that time", err))
+				return
+			}
 			utils.RespondWithError(w, http.StatusInternalServerError, "Unable to create availability", err)
 			return
 		}
@@ -59,6 +63,7 @@ func CreateAvailabilityHandler(q availabilityCreator) http.HandlerFunc {
 			"id":         arg.ID,
 			"start_time": arg.StartTime,
 			"end_time":   arg.EndTime,
+			"capacity":   arg.Capacity,
 		})
 
 	}
@@ -2,41 +2,36 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
 	"net/http"
 	"time"
 
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/middleware"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/service"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/utils"
 	"github.com/google/uuid"
 )
 
 type AvailabilityPatternService interface {
-	CreatePatternAndSlots(ctx context.Context, providerID uuid.UUID, dayOfWeek int32, start, end time.Time) error
+	CreatePatternAndSlots(ctx context.Context, providerID uuid.UUID, dayOfWeek int32, start, end time.Time, settings service.SlotSettings) error
 }
 
+// CreateAvailabilityPatternHandler creates a weekly pattern and its slots.
+// slot_minutes defaults to 60 and capacity to 1.
 func CreateAvailabilityPatternHandler(svc AvailabilityPatternService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if !middleware.IsAdminFromContext(r.Context()) {
-			utils.RespondWithError(w, http.StatusForbidden, "Forbidden", nil)
-			return
-		}
 
 		var req struct {
-			DayOfWeek int32     `json:"day_of_week"`
-			StartTime time.Time `json:"start_time"`
-			EndTime   time.Time `json:"end_time"`
-		}
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body", err)
-			return
+			DayOfWeek int32     `json:"day_of_week" validate:"min=0,max=6"`
+			StartTime time.Time `json:"start_time" validate:"required"`
+			EndTime   time.Time `json:"end_time" validate:"required,gtfield=StartTime"`
+
+			SlotMinutes         int32 `json:"slot_minutes" validate:"omitempty,min=5,max=1440"`
+			BufferBeforeMinutes int32 `json:"buffer_before_minutes" validate:"min=0,max=1440"`
+			BufferAfterMinutes  int32 `json:"buffer_after_minutes" validate:"min=0,max=1440"`
+			Capacity            int32 `json:"capacity" validate:"omitempty,min=1,max=1000"`
 		}
-		if req.DayOfWeek < 0 || req.DayOfWeek > 6 {
-			utils.RespondWithError(w, http.StatusBadRequest, "day_of_week must be 0–6", nil)
-			return
-		}
-		if !req.EndTime.After(req.StartTime) {
-			utils.RespondWithError(w, http.StatusBadRequest, "end_time must be after start_time", nil)
+		if err := utils.DecodeJSON(w, r, &req); err != nil {
+			utils.RespondWithProblem(w, err)
 			return
 		}
 
@@ -46,9 +41,14 @@ func CreateAvailabilityPatternHandler(svc AvailabilityPatternService) http.Handl
 			return
 		}
 
-		err := svc.CreatePatternAndSlots(r.Context(), providerID, req.DayOfWeek, req.StartTime, req.EndTime)
+		err := svc.CreatePatternAndSlots(r.Context(), providerID, req.DayOfWeek, req.StartTime, req.EndTime, service.SlotSettings{
+			SlotMinutes:         req.SlotMinutes,
+			BufferBeforeMinutes: req.BufferBeforeMinutes,
+			BufferAfterMinutes:  req.BufferAfterMinutes,
+			Capacity:            req.Capacity,
+		})
 		if err != nil {
-			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to create pattern and slots", err)
+			utils.RespondWithProblem(w, err)
 			return
 		}
 
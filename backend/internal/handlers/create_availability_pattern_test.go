@@ -11,7 +11,9 @@ import (
 	"time"
 
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/middleware"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/service"
 	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -23,15 +25,17 @@ type mockAvailabilityService struct {
 		dayOfWeek  int32
 		startTime  time.Time
 		endTime    time.Time
+		settings   service.SlotSettings
 	}
 }
 
-func (m *mockAvailabilityService) CreatePatternAndSlots(ctx context.Context, providerID uuid.UUID, dayOfWeek int32, start, end time.Time) error {
+func (m *mockAvailabilityService) CreatePatternAndSlots(ctx context.Context, providerID uuid.UUID, dayOfWeek int32, start, end time.Time, settings service.SlotSettings) error {
 	m.called = true
 	m.got.providerID = providerID
 	m.got.dayOfWeek = dayOfWeek
 	m.got.startTime = start
 	m.got.endTime = end
+	m.got.settings = settings
 	return m.err
 }
 
@@ -45,24 +49,35 @@ func TestCreateAvailabilityPatternHandler(t *testing.T) {
 		reqBody          any
 		expectedCode     int
 		expectedContains string
-		isAdmin          bool
 		injectUserID     bool
 		mockErr          error
+		wantSettings     service.SlotSettings
 	}{
 		{
 			name:         "Success",
 			reqBody:      map[string]any{"day_of_week": int32(start.Weekday()), "start_time": start, "end_time": end},
 			expectedCode: http.StatusCreated,
-			isAdmin:      true,
 			injectUserID: true,
 			mockErr:      nil,
 		},
 		{
-			name:             "Non-admin",
-			reqBody:          map[string]any{"day_of_week": 1, "start_time": start, "end_time": end},
-			expectedCode:     http.StatusForbidden,
-			expectedContains: "Forbidden",
-			isAdmin:          false,
+			name: "Group session with buffers",
+			reqBody: map[string]any{
+				"day_of_week": int32(start.Weekday()), "start_time": start, "end_time": end,
+				"slot_minutes": 45, "buffer_after_minutes": 15, "capacity": 8,
+			},
+			expectedCode: http.StatusCreated,
+			injectUserID: true,
+			wantSettings: service.SlotSettings{SlotMinutes: 45, BufferAfterMinutes: 15, Capacity: 8},
+		},
+		{
+			name: "Invalid slot settings",
+			reqBody: map[string]any{
+				"day_of_week": 1, "start_time": start, "end_time": end,
+				"slot_minutes": 2, "buffer_before_minutes": -5, "capacity": -1,
+			},
+			expectedCode:     http.StatusBadRequest,
+			expectedContains: `"field":"slot_minutes","message":"must be at least 5"`,
 			injectUserID:     true,
 		},
 		{
@@ -70,7 +85,6 @@ func TestCreateAvailabilityPatternHandler(t *testing.T) {
 			reqBody:          map[string]any{"day_of_week": 1, "start_time": start, "end_time": end},
 			expectedCode:     http.StatusInternalServerError,
 			expectedContains: "Could not get user ID",
-			isAdmin:          true,
 			injectUserID:     false,
 		},
 		{
@@ -78,18 +92,24 @@ func TestCreateAvailabilityPatternHandler(t *testing.T) {
 			reqBody:          "{ invalid json",
 			expectedCode:     http.StatusBadRequest,
 			expectedContains: "Invalid request body",
-			isAdmin:          true,
 			injectUserID:     true,
 		},
 		{
 			name:             "Service error",
 			reqBody:          map[string]any{"day_of_week": 1, "start_time": start, "end_time": end},
 			expectedCode:     http.StatusInternalServerError,
-			expectedContains: "Failed to create pattern",
-			isAdmin:          true,
+			expectedContains: "Internal server error",
 			injectUserID:     true,
 			mockErr:          errors.New("some error"),
 		},
+		{
+			name:             "Provider no longer exists",
+			reqBody:          map[string]any{"day_of_week": 1, "start_time": start, "end_time": end},
+			expectedCode:     http.StatusBadRequest,
+			expectedContains: "Referenced resource does not exist",
+			injectUserID:     true,
+			mockErr:          &pgconn.PgError{Code: "23503", ConstraintName: "availability_pattern_provider_id_fkey"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -107,7 +127,6 @@ func TestCreateAvailabilityPatternHandler(t *testing.T) {
 			req.Header.Set("Content-Type", "application/json")
 
 			ctx := req.Context()
-			ctx = context.WithValue(ctx, middleware.IsAdminKey, tt.isAdmin)
 			if tt.injectUserID {
 				ctx = context.WithValue(ctx, middleware.UserIDKey, providerID)
 			}
@@ -123,6 +142,7 @@ func TestCreateAvailabilityPatternHandler(t *testing.T) {
 			if tt.expectedContains != "" {
 				assert.Contains(t, rr.Body.String(), tt.expectedContains)
 			}
+			assert.Equal(t, tt.wantSettings, mockSvc.got.settings)
 		})
 	}
 }
@@ -15,10 +15,12 @@ import (
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/middleware"
 	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
 )
 
 type mockAvailabilityQueries struct {
 	failCreate bool
+	createErr  error
 	called     bool
 	gotParams  db.CreateAvailabilityParams
 }
@@ -29,12 +31,13 @@ func (m *mockAvailabilityQueries) CreateAvailability(ctx context.Context, arg db
 	if m.failCreate {
 		return errors.New("failure")
 	}
-	return nil
+	return m.createErr
 }
 
 type AvailRequest struct {
 	StartTime time.Time `json:"start_time"`
 	EndTime   time.Time `json:"end_time"`
+	Capacity  int32     `json:"capacity,omitempty"`
 }
 
 func TestCreateAvailabilityHandler(t *testing.T) {
@@ -45,10 +48,11 @@ func TestCreateAvailabilityHandler(t *testing.T) {
 		requestBody      AvailRequest
 		expectedCode     int
 		expectedContains string
-		injectAdmin      bool
 		invalidReqBody   bool
 		injectUserID     bool
 		failCreate       bool
+		createErr        error
+		wantCapacity     int32
 	}{
 		{
 			name: "Successful availability creation",
@@ -57,51 +61,75 @@ func TestCreateAvailabilityHandler(t *testing.T) {
 				EndTime:   time.Now().Add(2 * time.Hour),
 			},
 			expectedCode:   http.StatusCreated,
-			injectAdmin:    true,
 			injectUserID:   true,
 			invalidReqBody: false,
 			failCreate:     false,
+			wantCapacity:   1,
 		},
 		{
-			name: "Does not have role of admin",
+			name: "Group session",
 			requestBody: AvailRequest{
-				StartTime: time.Now(),
-				EndTime:   time.Now().Add(time.Hour),
+				StartTime: time.Now().Add(time.Hour),
+				EndTime:   time.Now().Add(2 * time.Hour),
+				Capacity:  10,
 			},
-			expectedCode:     http.StatusForbidden,
-			expectedContains: "Forbidden",
-			injectAdmin:      false,
-			injectUserID:     true,
-			invalidReqBody:   false,
-			failCreate:       false,
+			expectedCode: http.StatusCreated,
+			injectUserID: true,
+			wantCapacity: 10,
 		},
 		{
 			name:             "Not a valid request body",
 			expectedCode:     http.StatusBadRequest,
 			expectedContains: "Invalid request body",
-			injectAdmin:      true,
 			injectUserID:     true,
 			invalidReqBody:   true,
 			failCreate:       false,
 		},
 		{
-			name:             "Can't find userID",
+			name: "End before start",
+			requestBody: AvailRequest{
+				StartTime: time.Now().Add(2 * time.Hour),
+				EndTime:   time.Now().Add(time.Hour),
+			},
+			expectedCode:     http.StatusBadRequest,
+			expectedContains: `{"field":"end_time","message":"must be after start_time"}`,
+			injectUserID:     true,
+		},
+		{
+			name: "Can't find userID",
+			requestBody: AvailRequest{
+				StartTime: time.Now().Add(time.Hour),
+				EndTime:   time.Now().Add(2 * time.Hour),
+			},
 			expectedCode:     http.StatusInternalServerError,
 			expectedContains: "Could not get user ID",
-			injectAdmin:      true,
 			injectUserID:     false,
 			invalidReqBody:   false,
 			failCreate:       false,
 		},
 		{
-			name:             "Failed availability creation",
+			name: "Failed availability creation",
+			requestBody: AvailRequest{
+				StartTime: time.Now().Add(time.Hour),
+				EndTime:   time.Now().Add(2 * time.Hour),
+			},
 			expectedCode:     http.StatusInternalServerError,
 			expectedContains: "Unable to create availability",
-			injectAdmin:      true,
 			injectUserID:     true,
 			invalidReqBody:   false,
 			failCreate:       true,
 		},
+		{
+			name: "Slot already starts then",
+			requestBody: AvailRequest{
+				StartTime: time.Now().Add(time.Hour),
+				EndTime:   time.Now().Add(2 * time.Hour),
+			},
+			expectedCode:     http.StatusConflict,
+			expectedContains: "A slot already starts at that time",
+			injectUserID:     true,
+			createErr:        &pgconn.PgError{Code: "23505", ConstraintName: "availability_provider_id_start_time_key"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -120,13 +148,12 @@ func TestCreateAvailabilityHandler(t *testing.T) {
 			req.Header.Set("Content-Type", "application/json")
 
 			ctx := req.Context()
-			ctx = context.WithValue(ctx, middleware.IsAdminKey, tt.injectAdmin)
 			if tt.injectUserID {
 				ctx = context.WithValue(ctx, middleware.UserIDKey, providerID)
 			}
 			req = req.WithContext(ctx)
 
-			mock := &mockAvailabilityQueries{failCreate: tt.failCreate}
+			mock := &mockAvailabilityQueries{failCreate: tt.failCreate, createErr: tt.createErr}
 			handler := CreateAvailabilityHandler(mock)
 
 			rr := httptest.NewRecorder()
@@ -136,6 +163,9 @@ func TestCreateAvailabilityHandler(t *testing.T) {
 				if !mock.called {
 					t.Error("expected CreateAvailability to be called")
 				}
+				if mock.gotParams.Capacity != tt.wantCapacity {
+					t.Errorf("expected capacity %d, got %d", tt.wantCapacity, mock.gotParams.Capacity)
+				}
 			}
 
 			if rr.Code != tt.expectedCode {
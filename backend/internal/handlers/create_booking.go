@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"encoding/json"
 	"net/http"
 	"time"
 
@@ -10,10 +9,12 @@ import (
 	"github.com/google/uuid"
 )
 
+// BookingRequest books a slot. AppointmentStart and DurationMinutes default
+// to the slot's own; when given they must match it.
 type BookingRequest struct {
-	ID               string    `json:"id"`
-	AppointmentStart time.Time `json:"appointment_start"`
-	DurationMinutes  int32     `json:"duration_minutes"`
+	SlotID           string    `json:"slot_id" validate:"required,uuid"`
+	AppointmentStart time.Time `json:"appointment_start,omitempty"`
+	DurationMinutes  *int32    `json:"duration_minutes,omitempty" validate:"omitempty,gt=0"`
 }
 
 func (h *Handler) CreateBookingHandler() http.HandlerFunc {
@@ -24,25 +25,23 @@ func (h *Handler) CreateBookingHandler() http.HandlerFunc {
 			return
 		}
 
-		decoder := json.NewDecoder(r.Body)
 		req := BookingRequest{}
-		err := decoder.Decode(&req)
-		if err != nil {
-			utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body", err)
+		if err := utils.DecodeJSON(w, r, &req); err != nil {
+			utils.RespondWithProblem(w, err)
 			return
 		}
 
-		id, err := uuid.Parse(req.ID)
-		if err != nil {
-			utils.RespondWithError(w, http.StatusBadRequest, "Unable to parse ID", err)
-			return
+		var durationMinutes int32
+		if req.DurationMinutes != nil {
+			durationMinutes = *req.DurationMinutes
 		}
-		booking, err := h.BookingService.CreateBooking(r.Context(), id, userID, req.AppointmentStart, req.DurationMinutes, id)
+
+		slotID := uuid.MustParse(req.SlotID)
+		booking, err := h.BookingService.CreateBooking(r.Context(), uuid.New(), userID, slotID, req.AppointmentStart, durationMinutes)
 		if err != nil {
-			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to create booking", err)
+			utils.RespondWithProblem(w, err)
 			return
 		}
-
 		utils.RespondWithJSON(w, http.StatusCreated, booking)
 	}
 }
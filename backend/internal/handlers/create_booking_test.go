@@ -3,6 +3,7 @@ package handlers
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -17,24 +18,49 @@ import (
 
 func TestCreateBookingHandler(t *testing.T) {
 	userID := uuid.New()
+	slotStart := time.Now().Add(time.Hour).Truncate(time.Minute)
+	slot := db.Availability{
+		ID:         uuid.New(),
+		ProviderID: uuid.New(),
+		StartTime:  slotStart,
+		EndTime:    slotStart.Add(time.Hour),
+		Capacity:   1,
+	}
+	findSlot := func(_ context.Context, _ uuid.UUID) (db.Availability, error) {
+		return slot, nil
+	}
+
 	validBody := BookingRequest{
-		ID:               uuid.NewString(),
-		AppointmentStart: time.Now().Add(time.Hour),
-		DurationMinutes:  60,
+		SlotID:           slot.ID.String(),
+		AppointmentStart: slotStart,
+		DurationMinutes:  int32Ptr(60),
 	}
 	jsonBody, _ := json.Marshal(validBody)
 
+	slotOnlyBody, _ := json.Marshal(BookingRequest{SlotID: slot.ID.String()})
+
 	invalidBody := BookingRequest{
-		ID:               "12345",
-		AppointmentStart: time.Now().Add(time.Hour),
-		DurationMinutes:  60,
+		SlotID:           "12345",
+		AppointmentStart: slotStart,
+		DurationMinutes:  int32Ptr(60),
 	}
 	invalidJsonBody, _ := json.Marshal(invalidBody)
 
+	negativeBody, _ := json.Marshal(BookingRequest{SlotID: slot.ID.String(), DurationMinutes: int32Ptr(-30)})
+
+	zeroBody, _ := json.Marshal(BookingRequest{SlotID: slot.ID.String(), DurationMinutes: int32Ptr(0)})
+
+	outsideBody, _ := json.Marshal(BookingRequest{
+		SlotID:           slot.ID.String(),
+		AppointmentStart: slotStart.Add(3 * time.Hour),
+		DurationMinutes:  int32Ptr(60),
+	})
+
 	tests := []struct {
 		name         string
 		ctxUserID    any
 		body         []byte
+		mockSlot     func(ctx context.Context, id uuid.UUID) (db.Availability, error)
 		mockOverlap  func(ctx context.Context, arg db.GetOverlappingBookingsParams) ([]db.Booking, error)
 		mockCreate   func(ctx context.Context, arg db.CreateBookingParams) (db.Booking, error)
 		expectStatus int
@@ -43,6 +69,7 @@ func TestCreateBookingHandler(t *testing.T) {
 			name:      "Valid booking",
 			ctxUserID: userID,
 			body:      jsonBody,
+			mockSlot:  findSlot,
 			mockOverlap: func(_ context.Context, _ db.GetOverlappingBookingsParams) ([]db.Booking, error) {
 				return nil, nil
 			},
@@ -51,6 +78,22 @@ func TestCreateBookingHandler(t *testing.T) {
 			},
 			expectStatus: http.StatusCreated,
 		},
+		{
+			name:      "Start and duration derived from slot",
+			ctxUserID: userID,
+			body:      slotOnlyBody,
+			mockSlot:  findSlot,
+			mockOverlap: func(_ context.Context, _ db.GetOverlappingBookingsParams) ([]db.Booking, error) {
+				return nil, nil
+			},
+			mockCreate: func(_ context.Context, arg db.CreateBookingParams) (db.Booking, error) {
+				if !arg.AppointmentStart.Equal(slot.StartTime) || arg.DurationMinutes != 60 || arg.SlotID != slot.ID {
+					t.Errorf("booking not derived from slot: %+v", arg)
+				}
+				return db.Booking{ID: arg.ID}, nil
+			},
+			expectStatus: http.StatusCreated,
+		},
 		{
 			name:         "Missing auth context",
 			ctxUserID:    nil,
@@ -69,14 +112,43 @@ func TestCreateBookingHandler(t *testing.T) {
 			body:         invalidJsonBody,
 			expectStatus: http.StatusBadRequest,
 		},
+		{
+			name:         "Negative duration",
+			ctxUserID:    userID,
+			body:         negativeBody,
+			expectStatus: http.StatusBadRequest,
+		},
+		{
+			name:         "Zero duration",
+			ctxUserID:    userID,
+			body:         zeroBody,
+			expectStatus: http.StatusBadRequest,
+		},
+		{
+			name:      "Slot does not exist",
+			ctxUserID: userID,
+			body:      jsonBody,
+			mockSlot: func(_ context.Context, _ uuid.UUID) (db.Availability, error) {
+				return db.Availability{}, sql.ErrNoRows
+			},
+			expectStatus: http.StatusNotFound,
+		},
+		{
+			name:         "Requested time outside slot",
+			ctxUserID:    userID,
+			body:         outsideBody,
+			mockSlot:     findSlot,
+			expectStatus: http.StatusBadRequest,
+		},
 		{
 			name:      "Overlapping booking",
 			ctxUserID: userID,
 			body:      jsonBody,
+			mockSlot:  findSlot,
 			mockOverlap: func(_ context.Context, _ db.GetOverlappingBookingsParams) ([]db.Booking, error) {
 				return []db.Booking{{ID: uuid.New()}}, nil
 			},
-			expectStatus: http.StatusInternalServerError,
+			expectStatus: http.StatusConflict,
 		},
 	}
 
@@ -85,6 +157,7 @@ func TestCreateBookingHandler(t *testing.T) {
 			mockQ := &mockBookingQueries{
 				CreateBookingFn:          tt.mockCreate,
 				GetOverlappingBookingsFn: tt.mockOverlap,
+				GetAvailabilityByIDFn:    tt.mockSlot,
 			}
 
 			bookingSvc := service.NewBookingService(mockQ)
@@ -93,6 +166,7 @@ func TestCreateBookingHandler(t *testing.T) {
 			handler := h.CreateBookingHandler()
 
 			req := httptest.NewRequest(http.MethodPost, "/api//bookings/create", bytes.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
 			if tt.ctxUserID != nil {
 				req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, tt.ctxUserID))
 			}
@@ -106,3 +180,7 @@ func TestCreateBookingHandler(t *testing.T) {
 		})
 	}
 }
+
+func int32Ptr(n int32) *int32 {
+	return &n
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/apperr"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/events"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/utils"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/webhooks"
+	"github.com/google/uuid"
+)
+
+type webhookEndpointCreator interface {
+	ExecTx(ctx context.Context, fn func(db.Querier) error) error
+}
+
+type CreateWebhookEndpointRequest struct {
+	URL         string   `json:"url" validate:"required,url"`
+	Description string   `json:"description" validate:"max=200"`
+	EventTypes  []string `json:"event_types"`
+}
+
+type WebhookEndpointResponse struct {
+	ID          uuid.UUID `json:"id"`
+	URL         string    `json:"url"`
+	Description string    `json:"description"`
+	EventTypes  []string  `json:"event_types"`
+	Active      bool      `json:"active"`
+	// Secret signs the endpoint's requests. It is only returned on create.
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func webhookEndpointResponse(e db.WebhookEndpoint, eventTypes []string) WebhookEndpointResponse {
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+	return WebhookEndpointResponse{
+		ID:          e.ID,
+		URL:         e.URL,
+		Description: e.Description,
+		EventTypes:  eventTypes,
+		Active:      e.Active,
+		CreatedAt:   e.CreatedAt,
+		UpdatedAt:   e.UpdatedAt,
+	}
+}
+
+// checkEventTypes validates the event_types field, which struct tags cannot
+// express, and returns it sorted without duplicates.
+func checkEventTypes(types []string) ([]string, error) {
+	if len(types) == 0 {
+		return nil, apperr.Validation("Request validation failed",
+			apperr.FieldError{Field: "event_types", Message: "is required"})
+	}
+	for _, t := range types {
+		if !slices.Contains(events.Types, t) {
+			return nil, apperr.Validation("Request validation failed",
+				apperr.FieldError{Field: "event_types", Message: "must only contain: " + strings.Join(events.Types, ", ")})
+		}
+	}
+	types = slices.Clone(types)
+	slices.Sort(types)
+	return slices.Compact(types), nil
+}
+
+// setWebhookSubscriptions replaces the event types endpointID receives.
+func setWebhookSubscriptions(ctx context.Context, tx db.Querier, endpointID uuid.UUID, types []string) error {
+	if err := tx.DeleteWebhookSubscriptions(ctx, endpointID); err != nil {
+		return err
+	}
+	for _, t := range types {
+		err := tx.AddWebhookSubscription(ctx, db.AddWebhookSubscriptionParams{
+			EndpointID: endpointID,
+			EventType:  t,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateWebhookEndpointHandler registers an endpoint for the given event
+// types and returns its signing secret, which is not shown again.
+func CreateWebhookEndpointHandler(q webhookEndpointCreator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := CreateWebhookEndpointRequest{}
+		if err := utils.DecodeJSON(w, r, &req); err != nil {
+			utils.RespondWithProblem(w, err)
+			return
+		}
+		eventTypes, err := checkEventTypes(req.EventTypes)
+		if err != nil {
+			utils.RespondWithProblem(w, err)
+			return
+		}
+
+		secret, err := webhooks.NewSecret()
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Could not generate webhook secret", err)
+			return
+		}
+
+		var endpoint db.WebhookEndpoint
+		err = q.ExecTx(r.Context(), func(tx db.Querier) error {
+			var err error
+			endpoint, err = tx.CreateWebhookEndpoint(r.Context(), db.CreateWebhookEndpointParams{
+				ID:          uuid.New(),
+				URL:         req.URL,
+				Secret:      secret,
+				Description: req.Description,
+			})
+			if err != nil {
+				return err
+			}
+			return setWebhookSubscriptions(r.Context(), tx, endpoint.ID, eventTypes)
+		})
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Unable to create webhook endpoint", err)
+			return
+		}
+
+		resp := webhookEndpointResponse(endpoint, eventTypes)
+		resp.Secret = endpoint.Secret
+		utils.RespondWithJSON(w, http.StatusCreated, resp)
+	}
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCreateWebhookEndpointHandler(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		mockErr      error
+		wantStatus   int
+		wantContains string
+		wantTypes    []string
+	}{
+		{
+			name:       "Success",
+			body:       `{"url":"https://crm.example.com/hooks","description":"CRM","event_types":["user.registered","booking.created","booking.created"]}`,
+			wantStatus: http.StatusCreated,
+			wantTypes:  []string{"booking.created", "user.registered"},
+		},
+		{
+			name:         "Not an http URL",
+			body:         `{"url":"crm.example.com","event_types":["booking.created"]}`,
+			wantStatus:   http.StatusBadRequest,
+			wantContains: `{"field":"url","message":"must be an http or https URL"}`,
+		},
+		{
+			name:         "No event types",
+			body:         `{"url":"https://crm.example.com/hooks","event_types":[]}`,
+			wantStatus:   http.StatusBadRequest,
+			wantContains: `{"field":"event_types","message":"is required"}`,
+		},
+		{
+			name:         "Unknown event type",
+			body:         `{"url":"https://crm.example.com/hooks","event_types":["booking.deleted"]}`,
+			wantStatus:   http.StatusBadRequest,
+			wantContains: `"field":"event_types","message":"must only contain: `,
+		},
+		{
+			name:         "DB error",
+			body:         `{"url":"https://crm.example.com/hooks","event_types":["booking.created"]}`,
+			mockErr:      errors.New("db down"),
+			wantStatus:   http.StatusInternalServerError,
+			wantContains: "Unable to create webhook endpoint",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newMockWebhookQueries()
+			mock.err = tt.mockErr
+
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/webhooks", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+			CreateWebhookEndpointHandler(mock).ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d; body=%q", tt.wantStatus, rr.Code, rr.Body.String())
+			}
+			if tt.wantContains != "" && !strings.Contains(rr.Body.String(), tt.wantContains) {
+				t.Errorf("expected body to contain %q, got %q", tt.wantContains, rr.Body.String())
+			}
+			if tt.wantStatus != http.StatusCreated {
+				return
+			}
+
+			var resp WebhookEndpointResponse
+			if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if !strings.HasPrefix(resp.Secret, "whsec_") || resp.Secret != mock.endpoints[0].Secret {
+				t.Errorf("secret = %q, stored %q", resp.Secret, mock.endpoints[0].Secret)
+			}
+			if !reflect.DeepEqual(resp.EventTypes, tt.wantTypes) || !reflect.DeepEqual(mock.subs[resp.ID], tt.wantTypes) {
+				t.Errorf("event types = %v, stored %v, want %v", resp.EventTypes, mock.subs[resp.ID], tt.wantTypes)
+			}
+			if !resp.Active || resp.URL != "https://crm.example.com/hooks" || resp.Description != "CRM" {
+				t.Errorf("unexpected response %+v", resp)
+			}
+		})
+	}
+}
@@ -2,8 +2,12 @@ package handlers
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"net/http"
 
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/apperr"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/middleware"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/utils"
 	"github.com/google/uuid"
@@ -11,23 +15,29 @@ import (
 )
 
 type availabilityDeleter interface {
-	DeleteAvailability(ctx context.Context, id, providerID uuid.UUID) error
+	ExecTx(ctx context.Context, fn func(db.Querier) error) error
 }
 
+var errSlotHasBookings = apperr.New(apperr.KindConflict, "Slot has bookings and cannot be deleted")
+
+var errSlotNotFound = apperr.New(apperr.KindNotFound, "Availability slot not found")
+
+// DeleteAvailabilityHandler deletes one of the provider's slots, or any
+// provider's slot for an admin. A slot that has ever been booked, even if
+// every booking was cancelled since, is kept with a 409 so its booking
+// history survives. A slot that does not exist, or belongs to someone else,
+// is a 404 either way.
 func DeleteAvailabilityHandler(q availabilityDeleter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 
-		if !middleware.IsAdminFromContext(r.Context()) {
-			utils.RespondWithError(w, http.StatusForbidden, "Forbidden", nil)
-			return
-		}
-
-		providerID, ok := middleware.UserIDFromContext(r.Context())
+		userID, ok := middleware.UserIDFromContext(r.Context())
 		if !ok {
 			utils.RespondWithError(w, http.StatusInternalServerError, "Could not get user ID", nil)
 			return
 		}
 
+		isAdmin := middleware.IsAdminFromContext(r.Context())
+
 		vars := mux.Vars(r)
 		slotIDStr, ok := vars["id"]
 		if !ok {
@@ -40,7 +50,36 @@ func DeleteAvailabilityHandler(q availabilityDeleter) http.HandlerFunc {
 			return
 		}
 
-		if err := q.DeleteAvailability(r.Context(), slotID, providerID); err != nil {
+		err = q.ExecTx(r.Context(), func(tx db.Querier) error {
+			slot, err := tx.GetAvailabilityByID(r.Context(), slotID)
+			if errors.Is(err, sql.ErrNoRows) || (err == nil && slot.ProviderID != userID && !isAdmin) {
+				return errSlotNotFound
+			}
+			if err != nil {
+				return err
+			}
+			// Bookings are made under the same lock, so none can slip in
+			// between the check and the delete.
+			if err := tx.LockProviderSchedule(r.Context(), slot.ProviderID); err != nil {
+				return err
+			}
+			booked, err := tx.SlotHasBookings(r.Context(), slotID)
+			if err != nil {
+				return err
+			}
+			if booked {
+				return errSlotHasBookings
+			}
+			return tx.DeleteAvailability(r.Context(), db.DeleteAvailabilityParams{
+				ID:         slotID,
+				ProviderID: slot.ProviderID,
+			})
+		})
+		if errors.Is(err, errSlotNotFound) || errors.Is(err, errSlotHasBookings) {
+			utils.RespondWithProblem(w, err)
+			return
+		}
+		if err != nil {
 			utils.RespondWithError(w, http.StatusInternalServerError, "Unable to delete availability", err)
 			return
 		}
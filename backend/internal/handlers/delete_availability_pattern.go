@@ -5,28 +5,33 @@ import (
 	"net/http"
 
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/middleware"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/service"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/utils"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
 
 type availabilityPatternDeleter interface {
-	DeleteAvailabilityPattern(ctx context.Context, id, providerID uuid.UUID) error
+	DeletePattern(ctx context.Context, patternID, actorID uuid.UUID, isAdmin bool) (service.SlotChanges, error)
 }
 
-func DeleteAvailabilityPatternHandler(q availabilityPatternDeleter) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
+type DeletePatternResponse struct {
+	RemovedSlots  int                    `json:"removed_slots"`
+	StrandedSlots []StrandedSlotResponse `json:"stranded_slots"`
+}
 
-		if !middleware.IsAdminFromContext(r.Context()) {
-			utils.RespondWithError(w, http.StatusForbidden, "Forbidden", nil)
-			return
-		}
+// DeleteAvailabilityPatternHandler deletes a pattern with its unbooked
+// future slots and lists the booked ones left behind. Providers may delete
+// their own patterns and admins any provider's.
+func DeleteAvailabilityPatternHandler(svc availabilityPatternDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
 
-		providerID, ok := middleware.UserIDFromContext(r.Context())
+		userID, ok := middleware.UserIDFromContext(r.Context())
 		if !ok {
 			utils.RespondWithError(w, http.StatusUnauthorized, "Authentication required", nil)
 			return
 		}
+		isAdmin := middleware.IsAdminFromContext(r.Context())
 
 		vars := mux.Vars(r)
 		patternIDStr, ok := vars["id"]
@@ -40,12 +45,15 @@ func DeleteAvailabilityPatternHandler(q availabilityPatternDeleter) http.Handler
 			return
 		}
 
-		if err := q.DeleteAvailabilityPattern(r.Context(), patternID, providerID); err != nil {
-			utils.RespondWithError(w, http.StatusInternalServerError, "Unable to delete availability pattern", err)
+		changes, err := svc.DeletePattern(r.Context(), patternID, userID, isAdmin)
+		if err != nil {
+			utils.RespondWithProblem(w, err)
 			return
 		}
 
-		w.WriteHeader(http.StatusNoContent)
-
+		utils.RespondWithJSON(w, http.StatusOK, DeletePatternResponse{
+			RemovedSlots:  changes.Removed,
+			StrandedSlots: strandedSlots(changes.Stranded),
+		})
 	}
 }
@@ -7,8 +7,11 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/middleware"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/service"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
@@ -17,54 +20,91 @@ type mockPatternDeleter struct {
 	called      bool
 	gotID       uuid.UUID
 	gotProvider uuid.UUID
+	gotAdmin    bool
+	changes     service.SlotChanges
 	returnErr   error
 }
 
-func (m *mockPatternDeleter) DeleteAvailabilityPattern(ctx context.Context, id, providerID uuid.UUID) error {
+func (m *mockPatternDeleter) DeletePattern(ctx context.Context, patternID, actorID uuid.UUID, isAdmin bool) (service.SlotChanges, error) {
 	m.called = true
-	m.gotID = id
-	m.gotProvider = providerID
-	return m.returnErr
+	m.gotID = patternID
+	m.gotProvider = actorID
+	m.gotAdmin = isAdmin
+	return m.changes, m.returnErr
 }
 
 func TestDeleteAvailabilityPatternHandler(t *testing.T) {
 	patternID := uuid.New()
 	providerID := uuid.New()
+	stranded := db.ListPatternSlotsFromRow{
+		ID:        uuid.New(),
+		StartTime: time.Date(2025, 6, 3, 9, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2025, 6, 3, 10, 0, 0, 0, time.UTC),
+		Capacity:  1,
+		Booked:    1,
+	}
 
 	tests := []struct {
 		name        string
 		url         string
 		vars        map[string]string
-		isAdmin     bool
 		injectUser  bool
+		admin       bool
+		changes     service.SlotChanges
 		dbErr       error
 		wantStatus  int
 		wantBodySub string
 	}{
 		{
-			name:       "Success",
-			url:        "/availability/pattern/" + patternID.String(),
-			vars:       map[string]string{"id": patternID.String()},
-			isAdmin:    true,
-			injectUser: true,
-			dbErr:      nil,
-			wantStatus: http.StatusNoContent,
+			name:        "Success",
+			url:         "/availability/pattern/" + patternID.String(),
+			vars:        map[string]string{"id": patternID.String()},
+			injectUser:  true,
+			changes:     service.SlotChanges{Removed: 3},
+			wantStatus:  http.StatusOK,
+			wantBodySub: `{"removed_slots":3,"stranded_slots":[]}`,
 		},
 		{
-			name:        "Not admin",
+			name:        "Booked slots reported",
 			url:         "/availability/pattern/" + patternID.String(),
 			vars:        map[string]string{"id": patternID.String()},
-			isAdmin:     false,
 			injectUser:  true,
-			dbErr:       nil,
+			changes:     service.SlotChanges{Removed: 1, Stranded: []db.ListPatternSlotsFromRow{stranded}},
+			wantStatus:  http.StatusOK,
+			wantBodySub: `"stranded_slots":[{"id":"` + stranded.ID.String() + `","start_time":"2025-06-03T09:00:00Z","end_time":"2025-06-03T10:00:00Z","booked":1}]`,
+		},
+		{
+			name:        "Admin",
+			url:         "/availability/pattern/" + patternID.String(),
+			vars:        map[string]string{"id": patternID.String()},
+			injectUser:  true,
+			admin:       true,
+			changes:     service.SlotChanges{Removed: 2},
+			wantStatus:  http.StatusOK,
+			wantBodySub: `"removed_slots":2`,
+		},
+		{
+			name:        "Not owner",
+			url:         "/availability/pattern/" + patternID.String(),
+			vars:        map[string]string{"id": patternID.String()},
+			injectUser:  true,
+			dbErr:       service.ErrPatternNotOwned,
 			wantStatus:  http.StatusForbidden,
-			wantBodySub: "Forbidden",
+			wantBodySub: "You do not own this pattern",
+		},
+		{
+			name:        "Not found",
+			url:         "/availability/pattern/" + patternID.String(),
+			vars:        map[string]string{"id": patternID.String()},
+			injectUser:  true,
+			dbErr:       service.ErrPatternNotFound,
+			wantStatus:  http.StatusNotFound,
+			wantBodySub: "Pattern not found",
 		},
 		{
 			name:        "Missing user",
 			url:         "/availability/pattern/" + patternID.String(),
 			vars:        map[string]string{"id": patternID.String()},
-			isAdmin:     true,
 			injectUser:  false,
 			dbErr:       nil,
 			wantStatus:  http.StatusUnauthorized,
@@ -74,17 +114,15 @@ func TestDeleteAvailabilityPatternHandler(t *testing.T) {
 			name:        "DB error",
 			url:         "/availability/pattern/" + patternID.String(),
 			vars:        map[string]string{"id": patternID.String()},
-			isAdmin:     true,
 			injectUser:  true,
 			dbErr:       errors.New("oops"),
 			wantStatus:  http.StatusInternalServerError,
-			wantBodySub: "Unable to delete availability pattern",
+			wantBodySub: "Internal server error",
 		},
 		{
 			name:        "Missing pattern ID param",
 			url:         "/availability/pattern/",
 			vars:        map[string]string{},
-			isAdmin:     true,
 			injectUser:  true,
 			wantStatus:  http.StatusBadRequest,
 			wantBodySub: "Missing pattern ID",
@@ -93,7 +131,6 @@ func TestDeleteAvailabilityPatternHandler(t *testing.T) {
 			name:        "Invalid pattern ID param",
 			url:         "/availability/pattern/not-a-uuid",
 			vars:        map[string]string{"id": "not-a-uuid"},
-			isAdmin:     true,
 			injectUser:  true,
 			wantStatus:  http.StatusBadRequest,
 			wantBodySub: "Invalid pattern ID",
@@ -106,41 +143,29 @@ func TestDeleteAvailabilityPatternHandler(t *testing.T) {
 			req = mux.SetURLVars(req, tt.vars)
 
 			ctx := req.Context()
-			ctx = context.WithValue(ctx, middleware.IsAdminKey, tt.isAdmin)
 			if tt.injectUser {
 				ctx = context.WithValue(ctx, middleware.UserIDKey, providerID)
+				ctx = context.WithValue(ctx, middleware.IsAdminKey, tt.admin)
 			}
 			req = req.WithContext(ctx)
 
-			mock := &mockPatternDeleter{returnErr: tt.dbErr}
+			mock := &mockPatternDeleter{changes: tt.changes, returnErr: tt.dbErr}
 			handler := DeleteAvailabilityPatternHandler(mock)
 
 			rr := httptest.NewRecorder()
 			handler.ServeHTTP(rr, req)
 
-			if tt.wantStatus == http.StatusNoContent {
-				if rr.Code != http.StatusNoContent {
-					t.Fatalf("expected 204; got %d", rr.Code)
-				}
-				if rr.Body.Len() != 0 {
-					t.Errorf("expected empty body; got %q", rr.Body.String())
-				}
-				if !mock.called {
-					t.Error("expected DeleteAvailabilityPattern to be called")
-				}
-				if mock.gotID != patternID || mock.gotProvider != providerID {
-					t.Errorf("called with (%v,%v); want (%v,%v)", mock.gotID, mock.gotProvider, patternID, providerID)
-				}
-				return
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rr.Code)
 			}
-
-			if tt.wantStatus != http.StatusNoContent {
-				if rr.Code != tt.wantStatus {
-					t.Fatalf("expected status %d, got %d", tt.wantStatus, rr.Code)
-				}
-				if tt.wantBodySub != "" && !strings.Contains(rr.Body.String(), tt.wantBodySub) {
-					t.Errorf("expected response to contain %q, got %q", tt.wantBodySub, rr.Body.String())
-				}
+			if tt.wantBodySub != "" && !strings.Contains(rr.Body.String(), tt.wantBodySub) {
+				t.Errorf("expected response to contain %q, got %q", tt.wantBodySub, rr.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK && (mock.gotID != patternID || mock.gotProvider != providerID) {
+				t.Errorf("called with (%v,%v); want (%v,%v)", mock.gotID, mock.gotProvider, patternID, providerID)
+			}
+			if mock.called && mock.gotAdmin != tt.admin {
+				t.Errorf("isAdmin = %v, want %v", mock.gotAdmin, tt.admin)
 			}
 		})
 	}
@@ -2,69 +2,135 @@ package handlers
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/middleware"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
 
 type mockDeleteQueries struct {
-	called      bool
-	gotID       uuid.UUID
-	gotProvider uuid.UUID
+	db.Querier
+	slots       map[uuid.UUID]db.Availability
+	hasBookings bool
+	locked      bool
+	deleted     []uuid.UUID
 	returnErr   error
 }
 
-func (m *mockDeleteQueries) DeleteAvailability(ctx context.Context, id, providerID uuid.UUID) error {
-	m.called = true
-	m.gotID = id
-	m.gotProvider = providerID
-	return m.returnErr
+func (m *mockDeleteQueries) ExecTx(ctx context.Context, fn func(db.Querier) error) error {
+	return fn(m)
+}
+
+func (m *mockDeleteQueries) GetAvailabilityByID(ctx context.Context, id uuid.UUID) (db.Availability, error) {
+	if m.returnErr != nil {
+		return db.Availability{}, m.returnErr
+	}
+	slot, ok := m.slots[id]
+	if !ok {
+		return db.Availability{}, sql.ErrNoRows
+	}
+	return slot, nil
+}
+
+func (m *mockDeleteQueries) LockProviderSchedule(ctx context.Context, providerID uuid.UUID) error {
+	m.locked = true
+	return nil
+}
+
+func (m *mockDeleteQueries) SlotHasBookings(ctx context.Context, slotID uuid.UUID) (bool, error) {
+	return m.hasBookings, nil
+}
+
+func (m *mockDeleteQueries) DeleteAvailability(ctx context.Context, arg db.DeleteAvailabilityParams) error {
+	if !m.locked {
+		return errors.New("deleted without the provider lock")
+	}
+	if slot, ok := m.slots[arg.ID]; ok && slot.ProviderID == arg.ProviderID {
+		m.deleted = append(m.deleted, arg.ID)
+	}
+	return nil
 }
 
 func TestDeleteAvailabilityHandler(t *testing.T) {
 	slotID := uuid.New()
 	providerID := uuid.New()
+	otherSlotID := uuid.New()
 
 	tests := []struct {
 		name        string
 		url         string
 		vars        map[string]string
-		isAdmin     bool
 		injectUser  bool
+		asAdmin     bool
+		hasBookings bool
 		dbErr       error
 		wantStatus  int
 		wantBodySub string
+		wantDeleted bool
 	}{
 		{
-			name:       "Success",
-			url:        "/availability/" + slotID.String(),
-			vars:       map[string]string{"id": slotID.String()},
-			isAdmin:    true,
-			injectUser: true,
-			dbErr:      nil,
-			wantStatus: http.StatusNoContent,
+			name:        "Success",
+			url:         "/availability/" + slotID.String(),
+			vars:        map[string]string{"id": slotID.String()},
+			injectUser:  true,
+			dbErr:       nil,
+			wantStatus:  http.StatusNoContent,
+			wantDeleted: true,
 		},
 		{
-			name:        "Not admin",
+			name:        "Slot has bookings",
 			url:         "/availability/" + slotID.String(),
 			vars:        map[string]string{"id": slotID.String()},
-			isAdmin:     false,
 			injectUser:  true,
-			dbErr:       nil,
-			wantStatus:  http.StatusForbidden,
-			wantBodySub: "Forbidden",
+			hasBookings: true,
+			wantStatus:  http.StatusConflict,
+			wantBodySub: "Slot has bookings and cannot be deleted",
+		},
+		{
+			name:        "Someone else's slot",
+			url:         "/availability/" + otherSlotID.String(),
+			vars:        map[string]string{"id": otherSlotID.String()},
+			injectUser:  true,
+			wantStatus:  http.StatusNotFound,
+			wantBodySub: "Availability slot not found",
+		},
+		{
+			name:        "Admin deletes someone else's slot",
+			url:         "/availability/" + otherSlotID.String(),
+			vars:        map[string]string{"id": otherSlotID.String()},
+			injectUser:  true,
+			asAdmin:     true,
+			wantStatus:  http.StatusNoContent,
+			wantDeleted: true,
+		},
+		{
+			name:        "Slot not found",
+			url:         "/availability/" + uuid.NewString(),
+			vars:        map[string]string{"id": uuid.NewString()},
+			injectUser:  true,
+			wantStatus:  http.StatusNotFound,
+			wantBodySub: "Availability slot not found",
+		},
+		{
+			name:        "Admin and slot not found",
+			url:         "/availability/" + uuid.NewString(),
+			vars:        map[string]string{"id": uuid.NewString()},
+			injectUser:  true,
+			asAdmin:     true,
+			wantStatus:  http.StatusNotFound,
+			wantBodySub: "Availability slot not found",
 		},
 		{
 			name:        "Missing user",
 			url:         "/availability/" + slotID.String(),
 			vars:        map[string]string{"id": slotID.String()},
-			isAdmin:     true,
 			injectUser:  false,
 			dbErr:       nil,
 			wantStatus:  http.StatusInternalServerError,
@@ -74,7 +140,6 @@ func TestDeleteAvailabilityHandler(t *testing.T) {
 			name:        "DB error",
 			url:         "/availability/" + slotID.String(),
 			vars:        map[string]string{"id": slotID.String()},
-			isAdmin:     true,
 			injectUser:  true,
 			dbErr:       errors.New("oops"),
 			wantStatus:  http.StatusInternalServerError,
@@ -84,7 +149,6 @@ func TestDeleteAvailabilityHandler(t *testing.T) {
 			name:        "Missing slot ID param",
 			url:         "/availability",
 			vars:        map[string]string{},
-			isAdmin:     true,
 			injectUser:  true,
 			wantStatus:  http.StatusBadRequest,
 			wantBodySub: "Missing slot ID",
@@ -93,7 +157,6 @@ func TestDeleteAvailabilityHandler(t *testing.T) {
 			name:        "Invalid slot ID param",
 			url:         "/availability/not-a-uuid",
 			vars:        map[string]string{"id": "not-a-uuid"},
-			isAdmin:     true,
 			injectUser:  true,
 			wantStatus:  http.StatusBadRequest,
 			wantBodySub: "Invalid slot ID",
@@ -106,13 +169,20 @@ func TestDeleteAvailabilityHandler(t *testing.T) {
 			req = mux.SetURLVars(req, tt.vars)
 
 			ctx := req.Context()
-			ctx = context.WithValue(ctx, middleware.IsAdminKey, tt.isAdmin)
 			if tt.injectUser {
 				ctx = context.WithValue(ctx, middleware.UserIDKey, providerID)
 			}
+			ctx = context.WithValue(ctx, middleware.IsAdminKey, tt.asAdmin)
 			req = req.WithContext(ctx)
 
-			mock := &mockDeleteQueries{returnErr: tt.dbErr}
+			mock := &mockDeleteQueries{
+				slots: map[uuid.UUID]db.Availability{
+					slotID:      {ID: slotID, ProviderID: providerID},
+					otherSlotID: {ID: otherSlotID, ProviderID: uuid.New()},
+				},
+				hasBookings: tt.hasBookings,
+				returnErr:   tt.dbErr,
+			}
 			handler := DeleteAvailabilityHandler(mock)
 
 			rr := httptest.NewRecorder()
@@ -124,6 +194,9 @@ func TestDeleteAvailabilityHandler(t *testing.T) {
 			if tt.wantBodySub != "" && !strings.Contains(rr.Body.String(), tt.wantBodySub) {
 				t.Errorf("expected response to contain %q, got %q", tt.wantBodySub, rr.Body.String())
 			}
+			if deleted := len(mock.deleted) > 0; deleted != tt.wantDeleted {
+				t.Errorf("deleted = %v, want %v", deleted, tt.wantDeleted)
+			}
 		})
 	}
 }
@@ -5,12 +5,18 @@ import (
 	"net/http"
 
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/middleware"
-	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/service"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/utils"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
 
+type CancelBookingRequest struct {
+	Reason string `json:"reason" validate:"max=500"`
+}
+
+// DeleteBookingHandler cancels a booking. The booking is kept with its
+// cancellation details and its slot becomes free again. The JSON body, with
+// an optional reason, may be omitted.
 func (h *Handler) DeleteBookingHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 
@@ -37,17 +43,15 @@ func (h *Handler) DeleteBookingHandler() http.HandlerFunc {
 			return
 		}
 
-		err = h.BookingService.DeleteBooking(r.Context(), slotID, userID, isAdmin)
-		if errors.Is(err, service.ErrBookingNotFound) {
-			utils.RespondWithError(w, http.StatusNotFound, "Booking not found", nil)
-			return
-		}
-		if errors.Is(err, service.ErrNotAuthorized) {
-			utils.RespondWithError(w, http.StatusForbidden, "Not allowed to delete this booking", nil)
+		req := CancelBookingRequest{}
+		if err := utils.DecodeJSON(w, r, &req); err != nil && !errors.Is(err, utils.ErrEmptyBody) {
+			utils.RespondWithProblem(w, err)
 			return
 		}
+
+		err = h.BookingService.CancelBooking(r.Context(), slotID, userID, isAdmin, req.Reason)
 		if err != nil {
-			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to delete booking", err)
+			utils.RespondWithProblem(w, err)
 			return
 		}
 
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -24,40 +25,73 @@ func TestDeleteBookingHandler(t *testing.T) {
 		name             string
 		ctxUserID        any
 		routeID          string
-		mockDelete       func(ctx context.Context, arg db.DeleteBookingParams) error
+		body             string
+		status           string
+		mockCancel       func(ctx context.Context, arg db.CancelBookingParams) (db.Booking, error)
 		expectStatus     int
 		expectedContains string
 	}{
 		{
-			name:      "Delete booking",
+			name:      "Cancel booking",
 			ctxUserID: userID,
 			routeID:   bookingID.String(),
-			mockDelete: func(_ context.Context, arg db.DeleteBookingParams) error {
+			mockCancel: func(_ context.Context, arg db.CancelBookingParams) (db.Booking, error) {
 				if arg.ID != bookingID {
 					t.Errorf("expected booking ID %v, got %v", bookingID, arg.ID)
 				}
-				if arg.UserID != userID {
-					t.Errorf("expected user ID %v, got %v", userID, arg.UserID)
+				if arg.CancelledBy == nil || *arg.CancelledBy != userID {
+					t.Errorf("expected cancelled by %v, got %v", userID, arg.CancelledBy)
 				}
-				return nil
+				if arg.CancellationReason != nil {
+					t.Errorf("expected no reason, got %q", *arg.CancellationReason)
+				}
+				return db.Booking{}, nil
+			},
+			expectStatus: http.StatusNoContent,
+		},
+		{
+			name:      "Cancel booking with reason",
+			ctxUserID: userID,
+			routeID:   bookingID.String(),
+			body:      `{"reason":"Running late"}`,
+			mockCancel: func(_ context.Context, arg db.CancelBookingParams) (db.Booking, error) {
+				if arg.CancellationReason == nil || *arg.CancellationReason != "Running late" {
+					t.Errorf("expected reason %q, got %v", "Running late", arg.CancellationReason)
+				}
+				return db.Booking{}, nil
 			},
 			expectStatus: http.StatusNoContent,
 		},
+		{
+			name:             "Reason too long",
+			ctxUserID:        userID,
+			routeID:          bookingID.String(),
+			body:             `{"reason":"` + strings.Repeat("a", 501) + `"}`,
+			expectStatus:     http.StatusBadRequest,
+			expectedContains: `{"field":"reason","message":"must be at most 500 characters"}`,
+		},
+		{
+			name:             "Already cancelled",
+			ctxUserID:        userID,
+			routeID:          bookingID.String(),
+			status:           service.StatusCancelled,
+			expectStatus:     http.StatusConflict,
+			expectedContains: "A cancelled booking cannot be marked cancelled",
+		},
 		{
 			name:      "DB error",
 			ctxUserID: userID,
 			routeID:   bookingID.String(),
-			mockDelete: func(_ context.Context, _ db.DeleteBookingParams) error {
-				return errors.New("simulated DB error")
+			mockCancel: func(_ context.Context, _ db.CancelBookingParams) (db.Booking, error) {
+				return db.Booking{}, errors.New("simulated DB error")
 			},
 			expectStatus:     http.StatusInternalServerError,
-			expectedContains: "Failed to delete booking",
+			expectedContains: "Internal server error",
 		},
 		{
 			name:             "No user ID in context",
 			ctxUserID:        nil,
 			routeID:          bookingID.String(),
-			mockDelete:       nil,
 			expectStatus:     http.StatusUnauthorized,
 			expectedContains: "User ID missing or not a UUID in context",
 		},
@@ -65,7 +99,6 @@ func TestDeleteBookingHandler(t *testing.T) {
 			name:             "Missing booking ID",
 			ctxUserID:        userID,
 			routeID:          "",
-			mockDelete:       nil,
 			expectStatus:     http.StatusBadRequest,
 			expectedContains: "Missing slot ID",
 		},
@@ -73,7 +106,6 @@ func TestDeleteBookingHandler(t *testing.T) {
 			name:             "Booking ID is nil",
 			ctxUserID:        userID,
 			routeID:          uuid.Nil.String(),
-			mockDelete:       nil,
 			expectStatus:     http.StatusBadRequest,
 			expectedContains: "Booking ID is required",
 		},
@@ -81,42 +113,46 @@ func TestDeleteBookingHandler(t *testing.T) {
 			name:             "Invalid booking ID",
 			ctxUserID:        userID,
 			routeID:          "4595",
-			mockDelete:       nil,
 			expectStatus:     http.StatusBadRequest,
 			expectedContains: "Invalid slot ID",
 		},
 		{
-			name:      "Booking not found",
-			ctxUserID: userID,
-			routeID:   bookingID.String(),
-			mockDelete: func(_ context.Context, _ db.DeleteBookingParams) error {
-				return service.ErrBookingNotFound
-			},
+			name:             "Booking not found",
+			ctxUserID:        userID,
+			routeID:          uuid.NewString(),
 			expectStatus:     http.StatusNotFound,
 			expectedContains: "Booking not found",
 		},
 		{
-			name:      "User not allowed to delete this booking",
-			ctxUserID: userID,
-			routeID:   bookingID.String(),
-			mockDelete: func(_ context.Context, _ db.DeleteBookingParams) error {
-				return service.ErrNotAuthorized
-			},
+			name:             "User not allowed to cancel this booking",
+			ctxUserID:        uuid.New(),
+			routeID:          bookingID.String(),
 			expectStatus:     http.StatusForbidden,
-			expectedContains: "Not allowed to delete this booking",
+			expectedContains: "Not allowed",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 
-			mockQ := &mockBookingQueries{}
-			if tt.mockDelete != nil {
-				mockQ.DeleteBookingFn = tt.mockDelete
+			status := tt.status
+			if status == "" {
+				status = service.StatusConfirmed
+			}
+			mockQ := &mockBookingQueries{
+				GetBookingByIDFn: func(_ context.Context, id uuid.UUID) (db.Booking, error) {
+					if id != bookingID {
+						return db.Booking{}, sql.ErrNoRows
+					}
+					return db.Booking{ID: bookingID, UserID: userID, Status: status}, nil
+				},
+			}
+			if tt.mockCancel != nil {
+				mockQ.CancelBookingFn = tt.mockCancel
 			} else {
-				mockQ.DeleteBookingFn = func(_ context.Context, _ db.DeleteBookingParams) error {
-					t.Fatalf("DeleteBooking should not have been called in test %q", tt.name)
-					return nil
+				mockQ.CancelBookingFn = func(_ context.Context, _ db.CancelBookingParams) (db.Booking, error) {
+					t.Fatalf("CancelBooking should not have been called in test %q", tt.name)
+					return db.Booking{}, nil
 				}
 			}
 
@@ -125,7 +161,8 @@ func TestDeleteBookingHandler(t *testing.T) {
 			h := &Handler{BookingService: bookingSvc}
 			handler := h.DeleteBookingHandler()
 
-			req := httptest.NewRequest(http.MethodDelete, "/api/bookings/"+tt.routeID, nil)
+			req := httptest.NewRequest(http.MethodDelete, "/api/bookings/"+tt.routeID, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
 
 			if tt.ctxUserID != nil {
 				req = req.WithContext(
@@ -3,7 +3,6 @@ package handlers
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"errors"
 	"net/http"
 
@@ -15,24 +14,18 @@ type userDeleter interface {
 	DeleteUser(ctx context.Context, id uuid.UUID) error
 }
 type DeleteRequest struct {
-	UserId uuid.UUID `json:"user_id"`
+	UserId uuid.UUID `json:"user_id" validate:"required"`
 }
 
 func DeleteUserHandler(q userDeleter) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		decoder := json.NewDecoder(r.Body)
 		req := DeleteRequest{}
-		err := decoder.Decode(&req)
-		if err != nil {
-			utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body", err)
-			return
-		}
-		if req.UserId == uuid.Nil {
-			utils.RespondWithError(w, http.StatusBadRequest, "Invalid user ID", nil)
+		if err := utils.DecodeJSON(w, r, &req); err != nil {
+			utils.RespondWithProblem(w, err)
 			return
 		}
 
-		err = q.DeleteUser(r.Context(), req.UserId)
+		err := q.DeleteUser(r.Context(), req.UserId)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				utils.RespondWithError(w, http.StatusNotFound, "User not found", nil)
@@ -44,7 +44,7 @@ func TestDeleteUserHandler(t *testing.T) {
 				return nil
 			},
 			expectedCode:     http.StatusBadRequest,
-			expectedContains: "Invalid user ID",
+			expectedContains: `{"field":"user_id","message":"is required"}`,
 		},
 		{
 			name: "No user in db",
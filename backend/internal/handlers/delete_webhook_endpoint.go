@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type webhookEndpointDeleter interface {
+	DeleteWebhookEndpoint(ctx context.Context, id uuid.UUID) (int64, error)
+}
+
+// DeleteWebhookEndpointHandler deletes an endpoint together with its
+// delivery log.
+func DeleteWebhookEndpointHandler(q webhookEndpointDeleter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		endpointID, err := uuid.Parse(mux.Vars(r)["id"])
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid webhook endpoint ID", err)
+			return
+		}
+
+		rows, err := q.DeleteWebhookEndpoint(r.Context(), endpointID)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Unable to delete webhook endpoint", err)
+			return
+		}
+		if rows == 0 {
+			utils.RespondWithError(w, http.StatusNotFound, "Webhook endpoint not found", nil)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
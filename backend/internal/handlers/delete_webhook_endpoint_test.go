@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+func TestDeleteWebhookEndpointHandler(t *testing.T) {
+	endpointID := uuid.New()
+
+	tests := []struct {
+		name         string
+		idVar        string
+		mockErr      error
+		wantStatus   int
+		wantContains string
+	}{
+		{name: "Deleted", idVar: endpointID.String(), wantStatus: http.StatusNoContent},
+		{name: "Invalid endpoint ID", idVar: "nope", wantStatus: http.StatusBadRequest, wantContains: "Invalid webhook endpoint ID"},
+		{name: "Endpoint not found", idVar: uuid.New().String(), wantStatus: http.StatusNotFound, wantContains: "Webhook endpoint not found"},
+		{name: "DB error", idVar: endpointID.String(), mockErr: errors.New("db down"), wantStatus: http.StatusInternalServerError, wantContains: "Unable to delete webhook endpoint"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newMockWebhookQueries(db.WebhookEndpoint{ID: endpointID})
+			mock.err = tt.mockErr
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/admin/webhooks/"+tt.idVar, nil)
+			req = mux.SetURLVars(req, map[string]string{"id": tt.idVar})
+			rr := httptest.NewRecorder()
+			DeleteWebhookEndpointHandler(mock).ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d; body=%q", tt.wantStatus, rr.Code, rr.Body.String())
+			}
+			if tt.wantContains != "" && !strings.Contains(rr.Body.String(), tt.wantContains) {
+				t.Errorf("expected body to contain %q, got %q", tt.wantContains, rr.Body.String())
+			}
+			if tt.wantStatus == http.StatusNoContent && len(mock.endpoints) != 0 {
+				t.Errorf("endpoint was not deleted")
+			}
+		})
+	}
+}
@@ -1,11 +1,9 @@
 package handlers
 
 import (
-	"errors"
 	"net/http"
 
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/middleware"
-	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/service"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/utils"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
@@ -37,15 +35,10 @@ func (h *Handler) GetBookingByIDHandler() http.HandlerFunc {
 		}
 
 		booking, err := h.BookingService.GetBookingByID(r.Context(), bookingID, userID)
-		switch {
-		case errors.Is(err, service.ErrBookingNotFound):
-			utils.RespondWithError(w, http.StatusNotFound, "Booking not found", nil)
-		case errors.Is(err, service.ErrNotAuthorized):
-			utils.RespondWithError(w, http.StatusForbidden, "Not allowed", nil)
-		case err != nil:
-			utils.RespondWithError(w, http.StatusInternalServerError, "Error fetching booking", err)
-		default:
-			utils.RespondWithJSON(w, http.StatusOK, booking)
+		if err != nil {
+			utils.RespondWithProblem(w, err)
+			return
 		}
+		utils.RespondWithJSON(w, http.StatusOK, booking)
 	}
 }
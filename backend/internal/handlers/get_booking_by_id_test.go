@@ -83,7 +83,7 @@ func TestGetBookingByIDHandler(t *testing.T) {
 				return db.Booking{}, errors.New("some db failure")
 			},
 			expectStatus:     http.StatusInternalServerError,
-			expectedContains: "Error fetching booking",
+			expectedContains: "Internal server error",
 		},
 		{
 			name:             "Missing booking id",
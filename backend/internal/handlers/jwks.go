@@ -0,0 +1,17 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/auth"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/utils"
+)
+
+// JWKSHandler publishes the public keys that verify our access tokens,
+// including retired keys that are still inside their overlap window.
+func JWKSHandler(keys auth.KeyProvider) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		utils.RespondWithJSON(w, http.StatusOK, auth.PublicJWKS(keys))
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/auth"
+)
+
+func TestJWKSHandler(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ed, err := auth.NewEd25519Key("ed-1", priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hs, err := auth.NewHMACKey("hs-1", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		keys     []*auth.Key
+		wantKIDs []string
+	}{
+		{
+			name:     "Publishes asymmetric keys",
+			keys:     []*auth.Key{ed},
+			wantKIDs: []string{"ed-1"},
+		},
+		{
+			name:     "Never publishes HMAC secrets",
+			keys:     []*auth.Key{hs},
+			wantKIDs: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := auth.NewKeySet(time.Hour, tt.keys...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+			rr := httptest.NewRecorder()
+			JWKSHandler(keys).ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+			}
+			var got auth.JWKS
+			if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if len(got.Keys) != len(tt.wantKIDs) {
+				t.Fatalf("expected %d keys, got %+v", len(tt.wantKIDs), got.Keys)
+			}
+			for i, kid := range tt.wantKIDs {
+				if got.Keys[i].Kid != kid {
+					t.Errorf("key %d: kid = %q, want %q", i, got.Keys[i].Kid, kid)
+				}
+			}
+		})
+	}
+}
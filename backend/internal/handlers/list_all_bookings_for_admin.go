@@ -3,12 +3,29 @@ package handlers
 import (
 	"net/http"
 
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/service"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/utils"
 )
 
+// ListAllBookingsHandler pages through every booking by appointment start.
+// Query parameters: limit, cursor, status, from, to, user_id and provider_id.
 func (h *Handler) ListAllBookingsHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		bookings, err := h.BookingService.ListAllBookings(r.Context())
+		q := utils.NewQueryParams(r)
+		filter := service.BookingFilter{
+			UserID:      q.UUID("user_id"),
+			ProviderID:  q.UUID("provider_id"),
+			Status:      q.OneOf("status", service.BookingStatuses...),
+			StartFrom:   q.Time("from"),
+			StartBefore: q.Time("to"),
+			Page:        q.Page(),
+		}
+		if err := q.Err(); err != nil {
+			utils.RespondWithProblem(w, err)
+			return
+		}
+
+		bookings, err := h.BookingService.ListAllBookings(r.Context(), filter)
 		if err != nil {
 			utils.RespondWithError(w, http.StatusInternalServerError,
 				"Failed to list all bookings", err)
@@ -10,14 +10,11 @@ import (
 	"time"
 
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/pagination"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/service"
 	"github.com/google/uuid"
 )
 
-func (m *mockBookingQueries) ListAllBookings(ctx context.Context) ([]db.Booking, error) {
-	return m.ListAllBookingsForAdminFn(ctx)
-}
-
 func TestListAllBookingsHandler(t *testing.T) {
 
 	now := time.Now()
@@ -38,24 +35,47 @@ func TestListAllBookingsHandler(t *testing.T) {
 		UpdatedAt:        now.Add(-36 * time.Hour),
 	}
 	fakeList := []db.Booking{b1, b2}
+	providerID := uuid.New()
+	from := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
 
 	tests := []struct {
 		name           string
-		mockListFn     func(ctx context.Context) ([]db.Booking, error)
+		query          string
+		mockListFn     func(ctx context.Context, arg db.ListAllBookingsForAdminParams) ([]db.Booking, error)
 		expectStatus   int
 		expectResponse []db.Booking
+		expectCursor   bool
 	}{
 		{
 			name: "success returns 200 and all bookings",
-			mockListFn: func(ctx context.Context) ([]db.Booking, error) {
+			mockListFn: func(ctx context.Context, arg db.ListAllBookingsForAdminParams) ([]db.Booking, error) {
 				return fakeList, nil
 			},
 			expectStatus:   http.StatusOK,
 			expectResponse: fakeList,
 		},
+		{
+			name:  "filters and page size",
+			query: "?limit=1&user_id=" + b1.UserID.String() + "&provider_id=" + providerID.String() + "&from=" + from.Format(time.RFC3339),
+			mockListFn: func(ctx context.Context, arg db.ListAllBookingsForAdminParams) ([]db.Booking, error) {
+				if arg.UserID.UUID != b1.UserID || arg.ProviderID.UUID != providerID ||
+					!arg.StartFrom.Time.Equal(from) || arg.StartBefore.Valid || arg.PageLimit != 2 {
+					t.Errorf("unexpected params %+v", arg)
+				}
+				return fakeList, nil
+			},
+			expectStatus:   http.StatusOK,
+			expectResponse: fakeList[:1],
+			expectCursor:   true,
+		},
+		{
+			name:         "invalid filter returns 400",
+			query:        "?provider_id=abc",
+			expectStatus: http.StatusBadRequest,
+		},
 		{
 			name: "service error returns 500",
-			mockListFn: func(ctx context.Context) ([]db.Booking, error) {
+			mockListFn: func(ctx context.Context, arg db.ListAllBookingsForAdminParams) ([]db.Booking, error) {
 				return nil, errors.New("db failure")
 			},
 			expectStatus:   http.StatusInternalServerError,
@@ -73,7 +93,7 @@ func TestListAllBookingsHandler(t *testing.T) {
 			h := &Handler{BookingService: bookingSvc}
 			handler := h.ListAllBookingsHandler()
 
-			req := httptest.NewRequest(http.MethodGet, "/api/bookings/all", nil)
+			req := httptest.NewRequest(http.MethodGet, "/api/bookings/all"+tt.query, nil)
 			rr := httptest.NewRecorder()
 			handler.ServeHTTP(rr, req)
 
@@ -82,10 +102,14 @@ func TestListAllBookingsHandler(t *testing.T) {
 			}
 
 			if tt.expectStatus == http.StatusOK {
-				var got []db.Booking
-				if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+				var page pagination.Page[db.Booking]
+				if err := json.NewDecoder(rr.Body).Decode(&page); err != nil {
 					t.Fatalf("failed to decode JSON: %v", err)
 				}
+				got := page.Items
+				if (page.NextCursor != "") != tt.expectCursor {
+					t.Errorf("next_cursor = %q, want cursor %v", page.NextCursor, tt.expectCursor)
+				}
 				if len(got) != len(tt.expectResponse) {
 					t.Fatalf("expected %d bookings, got %d", len(tt.expectResponse), len(got))
 				}
@@ -99,8 +123,8 @@ func TestListAllBookingsHandler(t *testing.T) {
 				if err := json.NewDecoder(rr.Body).Decode(&errResp); err != nil {
 					t.Fatalf("expected JSON error body, got %q", rr.Body.String())
 				}
-				if _, ok := errResp["error"]; !ok {
-					t.Errorf("expected top-level \"error\" key in response, got %v", errResp)
+				if _, ok := errResp["detail"]; !ok {
+					t.Errorf("expected top-level \"detail\" key in response, got %v", errResp)
 				}
 			}
 		})
@@ -19,6 +19,8 @@ type listResponse struct {
 	ID        uuid.UUID `json:"id"`
 	StartTime time.Time `json:"start_time"`
 	EndTime   time.Time `json:"end_time"`
+	// Remaining is how many more bookings the slot takes.
+	Remaining int32 `json:"remaining"`
 }
 
 func ListAllFreeSlotsHandler(l FreeSlotsLister) http.HandlerFunc {
@@ -76,6 +78,7 @@ func ListAllFreeSlotsHandler(l FreeSlotsLister) http.HandlerFunc {
 				ID:        slot.ID,
 				StartTime: slot.StartTime,
 				EndTime:   slot.EndTime,
+				Remaining: slot.Remaining,
 			})
 		}
 
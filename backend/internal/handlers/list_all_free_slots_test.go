@@ -36,6 +36,7 @@ func TestListAllFreeSlotsHandler(t *testing.T) {
 		ID:        uuid.New(),
 		StartTime: start,
 		EndTime:   end,
+		Remaining: 2,
 	}
 
 	tests := []struct {
@@ -55,7 +56,7 @@ func TestListAllFreeSlotsHandler(t *testing.T) {
 			mockSlots:  []db.ListAllFreeSlotsRow{sample},
 			mockErr:    nil,
 			wantStatus: http.StatusOK,
-			wantSlots:  []listResponse{{ID: sample.ID, StartTime: sample.StartTime, EndTime: sample.EndTime}},
+			wantSlots:  []listResponse{{ID: sample.ID, StartTime: sample.StartTime, EndTime: sample.EndTime, Remaining: 2}},
 		},
 		{
 			name:            "Invalid start time",
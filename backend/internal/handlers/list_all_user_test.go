@@ -12,51 +12,68 @@ import (
 	"time"
 
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
-	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/middleware"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/pagination"
 	"github.com/google/uuid"
 )
 
-func (m *mockUserQuerier) ListUsers(ctx context.Context) ([]db.User, error) {
-	return m.ListUsersFunc(ctx)
+func (m *mockUserQuerier) ListUsers(ctx context.Context, arg db.ListUsersParams) ([]db.User, error) {
+	return m.ListUsersFunc(ctx, arg)
 }
 
 func TestListAllUsersHandler(t *testing.T) {
 	tests := []struct {
 		name             string
+		url              string
 		mockQuery        *mockUserQuerier
-		injectAdmin      bool
 		expectedCode     int
 		expectedContains string
 	}{
-		{
-			name:             "Forbidden if not admin",
-			mockQuery:        &mockUserQuerier{},
-			injectAdmin:      false,
-			expectedCode:     http.StatusForbidden,
-			expectedContains: `"Forbidden"`,
-		},
 		{
 			name: "Unable to list users",
-			mockQuery: &mockUserQuerier{ListUsersFunc: func(ctx context.Context) ([]db.User, error) {
+			mockQuery: &mockUserQuerier{ListUsersFunc: func(ctx context.Context, arg db.ListUsersParams) ([]db.User, error) {
 				return []db.User{}, errors.New("simulated error")
 			}},
-			injectAdmin:      true,
 			expectedCode:     http.StatusInternalServerError,
 			expectedContains: "Unable to list users",
 		},
+		{
+			name:             "Unknown role",
+			url:              "/users?role=owner",
+			mockQuery:        &mockUserQuerier{},
+			expectedCode:     http.StatusBadRequest,
+			expectedContains: `{"field":"role","message":"must be one of: user, provider, admin"}`,
+		},
+		{
+			name:             "Bad cursor and limit",
+			url:              "/users?cursor=nope&limit=0",
+			mockQuery:        &mockUserQuerier{},
+			expectedCode:     http.StatusBadRequest,
+			expectedContains: `[{"field":"limit","message":"must be between 1 and 200"},{"field":"cursor","message":"is not a cursor returned by this endpoint"}]`,
+		},
+		{
+			name: "Role filter",
+			url:  "/users?role=provider&limit=5",
+			mockQuery: &mockUserQuerier{ListUsersFunc: func(ctx context.Context, arg db.ListUsersParams) ([]db.User, error) {
+				if arg.UserRole.String != "provider" || arg.PageLimit != 6 {
+					return nil, errors.New("unexpected params")
+				}
+				return []db.User{}, nil
+			}},
+			expectedCode:     http.StatusOK,
+			expectedContains: `{"items":[]}`,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			handler := ListAllUsersHandler(tt.mockQuery)
 
-			req := httptest.NewRequest(http.MethodGet, "/users", nil)
-			rr := httptest.NewRecorder()
-
-			if tt.injectAdmin {
-				ctx := context.WithValue(req.Context(), middleware.IsAdminKey, true)
-				req = req.WithContext(ctx)
+			url := tt.url
+			if url == "" {
+				url = "/users"
 			}
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			rr := httptest.NewRecorder()
 
 			handler.ServeHTTP(rr, req)
 
@@ -95,15 +112,16 @@ func TestListAllUsersHandler_Mapping(t *testing.T) {
 	}
 
 	mock := &mockUserQuerier{
-		ListUsersFunc: func(ctx context.Context) ([]db.User, error) {
-			return users, nil
+		ListUsersFunc: func(ctx context.Context, arg db.ListUsersParams) ([]db.User, error) {
+			if arg.PageLimit == 2 {
+				return users, nil
+			}
+			return users[:1], nil
 		},
 	}
 
 	handler := ListAllUsersHandler(mock)
-	req := httptest.NewRequest(http.MethodGet, "/users", nil)
-	ctx := context.WithValue(req.Context(), middleware.IsAdminKey, true)
-	req = req.WithContext(ctx)
+	req := httptest.NewRequest(http.MethodGet, "/users?limit=1", nil)
 	rr := httptest.NewRecorder()
 
 	handler.ServeHTTP(rr, req)
@@ -112,17 +130,20 @@ func TestListAllUsersHandler_Mapping(t *testing.T) {
 		t.Fatalf("expected 200 OK, got %d", rr.Code)
 	}
 
-	var got []UserResponse
+	var got pagination.Page[UserResponse]
 	if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
 		t.Fatalf("invalid JSON: %v", err)
 	}
 
 	want := []UserResponse{
 		{ID: users[0].ID, FirstName: "Alice", LastName: "Anderson", Email: "alice@example.com", CreatedAt: t0, UpdatedAt: t0, UserRole: "user"},
-		{ID: users[1].ID, FirstName: "Bob", LastName: "Brown", Email: "bob@example.com", CreatedAt: t0, UpdatedAt: t0, UserRole: "admin"},
 	}
 
-	if !reflect.DeepEqual(got, want) {
-		t.Errorf("mapped slice = %#v\nwant            = %#v", got, want)
+	if !reflect.DeepEqual(got.Items, want) {
+		t.Errorf("mapped slice = %#v\nwant            = %#v", got.Items, want)
+	}
+	wantCursor := pagination.Cursor{Time: t0, ID: users[0].ID}.String()
+	if got.NextCursor != wantCursor {
+		t.Errorf("next_cursor = %q, want %q", got.NextCursor, wantCursor)
 	}
 }
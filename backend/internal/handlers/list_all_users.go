@@ -7,12 +7,13 @@ import (
 
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/middleware"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/pagination"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/utils"
 	"github.com/google/uuid"
 )
 
 type userLister interface {
-	ListUsers(ctx context.Context) ([]db.User, error)
+	ListUsers(ctx context.Context, arg db.ListUsersParams) ([]db.User, error)
 }
 
 type UserResponse struct {
@@ -25,23 +26,34 @@ type UserResponse struct {
 	UserRole  string    `json:"user_role"`
 }
 
+// ListAllUsersHandler pages through users in sign-up order. Query
+// parameters: limit, cursor and role.
 func ListAllUsersHandler(u userLister) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-
-		if !middleware.IsAdminFromContext(r.Context()) {
-			utils.RespondWithError(w, http.StatusForbidden, "Forbidden", nil)
+		q := utils.NewQueryParams(r)
+		role := q.OneOf("role", middleware.RoleUser, middleware.RoleProvider, middleware.RoleAdmin)
+		page := q.Page()
+		if err := q.Err(); err != nil {
+			utils.RespondWithProblem(w, err)
 			return
 		}
 
-		users, err := u.ListUsers(r.Context())
+		users, err := u.ListUsers(r.Context(), db.ListUsersParams{
+			UserRole:       role,
+			AfterCreatedAt: page.AfterTime(),
+			AfterID:        page.AfterID(),
+			PageLimit:      page.FetchLimit(),
+		})
 		if err != nil {
 			utils.RespondWithError(w, http.StatusInternalServerError, "Unable to list users", err)
 			return
 		}
 
-		resp := make([]UserResponse, 0, len(users))
-		for _, u := range users {
-			resp = append(resp, UserResponse{
+		resp := pagination.New(users, page, func(u db.User) pagination.Cursor {
+			return pagination.Cursor{Time: u.CreatedAt, ID: u.ID}
+		})
+		utils.RespondWithJSON(w, http.StatusOK, pagination.Map(resp, func(u db.User) UserResponse {
+			return UserResponse{
 				ID:        u.ID,
 				FirstName: u.FirstName,
 				LastName:  u.LastName,
@@ -49,10 +61,7 @@ func ListAllUsersHandler(u userLister) http.HandlerFunc {
 				CreatedAt: u.CreatedAt,
 				UpdatedAt: u.UpdatedAt,
 				UserRole:  u.UserRole,
-			})
-		}
-
-		utils.RespondWithJSON(w, http.StatusOK, resp)
-
+			}
+		}))
 	}
 }
@@ -6,13 +6,14 @@ import (
 	"time"
 
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/pagination"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/utils"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
 
 type ProviderAvailabilityLister interface {
-	ListAvailabilityByProvider(ctx context.Context, providerID uuid.UUID) ([]db.Availability, error)
+	ListAvailabilityByProvider(ctx context.Context, arg db.ListAvailabilityByProviderParams) ([]db.Availability, error)
 }
 
 type AvailabilityResponse struct {
@@ -22,8 +23,11 @@ type AvailabilityResponse struct {
 	EndTime    time.Time `json:"end_time"`
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
+	Capacity   int32     `json:"capacity"`
 }
 
+// ListAvailabilityByProviderHandler pages through a provider's slots by
+// start time. Query parameters: limit, cursor, from and to.
 func ListAvailabilityByProviderHandler(q ProviderAvailabilityLister) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
@@ -39,24 +43,41 @@ func ListAvailabilityByProviderHandler(q ProviderAvailabilityLister) http.Handle
 			return
 		}
 
-		freeSlots, err := q.ListAvailabilityByProvider(r.Context(), providerID)
+		params := utils.NewQueryParams(r)
+		startFrom := params.Time("from")
+		startBefore := params.Time("to")
+		page := params.Page()
+		if err := params.Err(); err != nil {
+			utils.RespondWithProblem(w, err)
+			return
+		}
+
+		slots, err := q.ListAvailabilityByProvider(r.Context(), db.ListAvailabilityByProviderParams{
+			ProviderID:  providerID,
+			StartFrom:   startFrom,
+			StartBefore: startBefore,
+			AfterStart:  page.AfterTime(),
+			AfterID:     page.AfterID(),
+			PageLimit:   page.FetchLimit(),
+		})
 		if err != nil {
 			utils.RespondWithError(w, http.StatusInternalServerError, "Unable to retrieve availability", err)
 			return
 		}
 
-		resp := make([]AvailabilityResponse, 0, len(freeSlots))
-		for _, slot := range freeSlots {
-			resp = append(resp, AvailabilityResponse{
+		resp := pagination.New(slots, page, func(a db.Availability) pagination.Cursor {
+			return pagination.Cursor{Time: a.StartTime, ID: a.ID}
+		})
+		utils.RespondWithJSON(w, http.StatusOK, pagination.Map(resp, func(slot db.Availability) AvailabilityResponse {
+			return AvailabilityResponse{
 				ID:         slot.ID,
 				ProviderID: slot.ProviderID,
 				StartTime:  slot.StartTime,
 				EndTime:    slot.EndTime,
 				CreatedAt:  slot.CreatedAt,
 				UpdatedAt:  slot.UpdatedAt,
-			})
-		}
-
-		utils.RespondWithJSON(w, http.StatusOK, resp)
+				Capacity:   slot.Capacity,
+			}
+		}))
 	}
 }
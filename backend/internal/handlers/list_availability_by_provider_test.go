@@ -11,20 +11,21 @@ import (
 	"time"
 
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/pagination"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
 
 type mockProviderLister struct {
-	called        bool
-	gotProviderID uuid.UUID
-	returnSlots   []db.Availability
-	returnError   error
+	called      bool
+	gotParams   db.ListAvailabilityByProviderParams
+	returnSlots []db.Availability
+	returnError error
 }
 
-func (m *mockProviderLister) ListAvailabilityByProvider(ctx context.Context, providerID uuid.UUID) ([]db.Availability, error) {
+func (m *mockProviderLister) ListAvailabilityByProvider(ctx context.Context, arg db.ListAvailabilityByProviderParams) ([]db.Availability, error) {
 	m.called = true
-	m.gotProviderID = providerID
+	m.gotParams = arg
 	return m.returnSlots, m.returnError
 }
 
@@ -70,6 +71,13 @@ func TestListAvailabilityByProviderHandler(t *testing.T) {
 			wantStatus:   http.StatusBadRequest,
 			wantContains: "Invalid provider_id",
 		},
+		{
+			name:         "Invalid range",
+			url:          "/providers/" + sampleSlot.ProviderID.String() + "/availability?from=yesterday",
+			vars:         map[string]string{"provider_id": sampleSlot.ProviderID.String()},
+			wantStatus:   http.StatusBadRequest,
+			wantContains: `{"field":"from","message":"must be an RFC 3339 timestamp"}`,
+		},
 		{
 			name:         "DB error",
 			url:          "/providers/" + sampleSlot.ProviderID.String() + "/availability",
@@ -107,11 +115,15 @@ func TestListAvailabilityByProviderHandler(t *testing.T) {
 			}
 
 			decoder := json.NewDecoder(rr.Body)
-			var got []AvailabilityResponse
-			err := decoder.Decode(&got)
+			var page pagination.Page[AvailabilityResponse]
+			err := decoder.Decode(&page)
 			if err != nil {
 				t.Fatalf("failed to decode JSON: %v", err)
 			}
+			got := page.Items
+			if mock.gotParams.ProviderID != sampleSlot.ProviderID || mock.gotParams.PageLimit != pagination.DefaultLimit+1 {
+				t.Errorf("unexpected params %+v", mock.gotParams)
+			}
 			if len(got) != len(tt.mockSlots) {
 				t.Fatalf("expected %d items, got %d", len(tt.mockSlots), len(got))
 			}
@@ -4,9 +4,12 @@ import (
 	"net/http"
 
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/middleware"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/service"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/utils"
 )
 
+// ListBookingsForUserHandler pages through the caller's bookings by
+// appointment start. Query parameters: limit, cursor, status, from and to.
 func (h *Handler) ListBookingsForUserHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		userID, ok := middleware.UserIDFromContext(r.Context())
@@ -15,7 +18,19 @@ func (h *Handler) ListBookingsForUserHandler() http.HandlerFunc {
 			return
 		}
 
-		bookings, err := h.BookingService.ListUserBookings(r.Context(), userID)
+		q := utils.NewQueryParams(r)
+		filter := service.BookingFilter{
+			Status:      q.OneOf("status", service.BookingStatuses...),
+			StartFrom:   q.Time("from"),
+			StartBefore: q.Time("to"),
+			Page:        q.Page(),
+		}
+		if err := q.Err(); err != nil {
+			utils.RespondWithProblem(w, err)
+			return
+		}
+
+		bookings, err := h.BookingService.ListUserBookings(r.Context(), userID, filter)
 		if err != nil {
 			utils.RespondWithError(w, http.StatusInternalServerError, "Failed to list bookings", err)
 			return
@@ -11,6 +11,7 @@ import (
 
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/middleware"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/pagination"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/service"
 	"github.com/google/uuid"
 )
@@ -52,7 +53,7 @@ func TestListBookingsForUserHandler(t *testing.T) {
 	tests := []struct {
 		name           string
 		ctxUserID      interface{}
-		mockList       func(ctx context.Context, id uuid.UUID) ([]db.Booking, error)
+		mockList       func(ctx context.Context, arg db.ListBookingsForUserParams) ([]db.Booking, error)
 		expectStatus   int
 		expectResponse []db.Booking
 	}{
@@ -60,9 +61,9 @@ func TestListBookingsForUserHandler(t *testing.T) {
 
 			name:      "Success",
 			ctxUserID: userID,
-			mockList: func(ctx context.Context, id uuid.UUID) ([]db.Booking, error) {
-				if id != userID {
-					t.Errorf("ListBookingsForUser called with wrong userID: got %v, want %v", id, userID)
+			mockList: func(ctx context.Context, arg db.ListBookingsForUserParams) ([]db.Booking, error) {
+				if arg.UserID != userID {
+					t.Errorf("ListBookingsForUser called with wrong userID: got %v, want %v", arg.UserID, userID)
 				}
 				return fakeBookings, nil
 			},
@@ -73,7 +74,7 @@ func TestListBookingsForUserHandler(t *testing.T) {
 
 			name:      "User ID missing",
 			ctxUserID: nil,
-			mockList: func(ctx context.Context, id uuid.UUID) ([]db.Booking, error) {
+			mockList: func(ctx context.Context, arg db.ListBookingsForUserParams) ([]db.Booking, error) {
 				return fakeBookings, nil
 			},
 			expectStatus:   http.StatusUnauthorized,
@@ -82,7 +83,7 @@ func TestListBookingsForUserHandler(t *testing.T) {
 		{
 			name:      "List error",
 			ctxUserID: userID,
-			mockList: func(ctx context.Context, id uuid.UUID) ([]db.Booking, error) {
+			mockList: func(ctx context.Context, arg db.ListBookingsForUserParams) ([]db.Booking, error) {
 				return nil, fmt.Errorf("DB error")
 			},
 			expectStatus:   http.StatusInternalServerError,
@@ -116,13 +117,16 @@ func TestListBookingsForUserHandler(t *testing.T) {
 			}
 
 			if tt.expectStatus == http.StatusOK {
-				var got []db.Booking
+				var got pagination.Page[db.Booking]
 				if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
 					t.Fatalf("failed to decode JSON: %v", err)
 				}
-				for index, booking := range got {
-					if got[index].UserID != booking.UserID {
-						t.Errorf("unexpected user booking returned: %+v", got)
+				if len(got.Items) != len(tt.expectResponse) {
+					t.Fatalf("expected %d bookings, got %d", len(tt.expectResponse), len(got.Items))
+				}
+				for _, booking := range got.Items {
+					if booking.UserID != userID {
+						t.Errorf("unexpected user booking returned: %+v", booking)
 					}
 				}
 			}
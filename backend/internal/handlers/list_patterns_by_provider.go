@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/apperr"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/middleware"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/utils"
@@ -17,14 +18,22 @@ type providerPatternsLister interface {
 }
 
 type PatternsResponse struct {
-	ID        uuid.UUID `json:"id"`
-	DayOfWeek int32     `json:"day_of_week"`
-	StartTime time.Time `json:"start_time"`
-	EndTime   time.Time `json:"end_time"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID                  uuid.UUID `json:"id"`
+	DayOfWeek           int32     `json:"day_of_week"`
+	StartTime           time.Time `json:"start_time"`
+	EndTime             time.Time `json:"end_time"`
+	SlotMinutes         int32     `json:"slot_minutes"`
+	BufferBeforeMinutes int32     `json:"buffer_before_minutes"`
+	BufferAfterMinutes  int32     `json:"buffer_after_minutes"`
+	Capacity            int32     `json:"capacity"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
 }
 
+var errPatternsNotOwned = apperr.New(apperr.KindForbidden, "You can only list your own patterns")
+
+// ListPatternsByProviderHandler lists a provider's weekly patterns. Providers
+// may only list their own; admins may list anyone's.
 func ListPatternsByProviderHandler(q providerPatternsLister) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		vars := mux.Vars(r)
@@ -40,10 +49,15 @@ func ListPatternsByProviderHandler(q providerPatternsLister) http.HandlerFunc {
 			return
 		}
 
-		if _, ok := middleware.UserIDFromContext(r.Context()); !ok {
+		userID, ok := middleware.UserIDFromContext(r.Context())
+		if !ok {
 			utils.RespondWithError(w, http.StatusUnauthorized, "Authentication required", nil)
 			return
 		}
+		if providerID != userID && !middleware.IsAdminFromContext(r.Context()) {
+			utils.RespondWithProblem(w, errPatternsNotOwned)
+			return
+		}
 
 		patterns, err := q.ListPatternsByProvider(r.Context(), providerID)
 		if err != nil {
@@ -53,8 +67,8 @@ func ListPatternsByProviderHandler(q providerPatternsLister) http.HandlerFunc {
 
 		resp := make([]PatternsResponse, len(patterns))
 		for i, p := range patterns {
-			st := p.StartTime
-			et := p.EndTime
+			st := p.StartTime.Time
+			et := p.EndTime.Time
 
 			if st.IsZero() {
 				utils.RespondWithError(w, http.StatusInternalServerError,
@@ -68,12 +82,16 @@ func ListPatternsByProviderHandler(q providerPatternsLister) http.HandlerFunc {
 				return
 			}
 			resp[i] = PatternsResponse{
-				ID:        p.ID,
-				DayOfWeek: p.DayOfWeek,
-				StartTime: st,
-				EndTime:   et,
-				CreatedAt: p.CreatedAt,
-				UpdatedAt: p.UpdatedAt,
+				ID:                  p.ID,
+				DayOfWeek:           p.DayOfWeek,
+				StartTime:           st,
+				EndTime:             et,
+				SlotMinutes:         p.SlotMinutes,
+				BufferBeforeMinutes: p.BufferBeforeMinutes,
+				BufferAfterMinutes:  p.BufferAfterMinutes,
+				Capacity:            p.Capacity,
+				CreatedAt:           p.CreatedAt,
+				UpdatedAt:           p.UpdatedAt,
 			}
 		}
 
@@ -37,27 +37,30 @@ func TestListPatternsByProviderHandler(t *testing.T) {
 	now := time.Now()
 
 	sample := db.ListPatternsByProviderRow{
-		ID:        uuid.New(),
-		DayOfWeek: day,
-		StartTime: start,
-		EndTime:   end,
-		CreatedAt: now,
-		UpdatedAt: now,
+		ID:                 uuid.New(),
+		DayOfWeek:          day,
+		StartTime:          db.TimeOfDay{Time: start},
+		EndTime:            db.TimeOfDay{Time: end},
+		CreatedAt:          now,
+		UpdatedAt:          now,
+		SlotMinutes:        30,
+		BufferAfterMinutes: 10,
+		Capacity:           4,
 	}
 
 	invalidStart := db.ListPatternsByProviderRow{
 		ID:        uuid.New(),
 		DayOfWeek: day,
-		StartTime: time.Time{},
-		EndTime:   end,
+		StartTime: db.TimeOfDay{},
+		EndTime:   db.TimeOfDay{Time: end},
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
 	invalidEnd := db.ListPatternsByProviderRow{
 		ID:        uuid.New(),
 		DayOfWeek: day,
-		StartTime: start,
-		EndTime:   time.Time{},
+		StartTime: db.TimeOfDay{Time: start},
+		EndTime:   db.TimeOfDay{},
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
@@ -65,6 +68,8 @@ func TestListPatternsByProviderHandler(t *testing.T) {
 	tests := []struct {
 		name              string
 		injectUser        bool
+		otherCaller       bool
+		asAdmin           bool
 		noProviderID      bool
 		invalidProviderID bool
 		mockSlots         []db.ListPatternsByProviderRow
@@ -82,12 +87,15 @@ func TestListPatternsByProviderHandler(t *testing.T) {
 			mockErr:           nil,
 			wantStatus:        http.StatusOK,
 			wantSlots: []PatternsResponse{{
-				ID:        sample.ID,
-				DayOfWeek: sample.DayOfWeek,
-				StartTime: sample.StartTime,
-				EndTime:   sample.EndTime,
-				CreatedAt: sample.CreatedAt,
-				UpdatedAt: sample.UpdatedAt,
+				ID:                 sample.ID,
+				DayOfWeek:          sample.DayOfWeek,
+				StartTime:          sample.StartTime.Time,
+				EndTime:            sample.EndTime.Time,
+				SlotMinutes:        30,
+				BufferAfterMinutes: 10,
+				Capacity:           4,
+				CreatedAt:          sample.CreatedAt,
+				UpdatedAt:          sample.UpdatedAt,
 			}},
 		},
 		{
@@ -117,6 +125,33 @@ func TestListPatternsByProviderHandler(t *testing.T) {
 			wantStatus:        http.StatusUnauthorized,
 			wantContains:      "Authentication required",
 		},
+		{
+			name:         "Another provider's patterns",
+			injectUser:   true,
+			otherCaller:  true,
+			mockSlots:    []db.ListPatternsByProviderRow{sample},
+			wantStatus:   http.StatusForbidden,
+			wantContains: "You can only list your own patterns",
+		},
+		{
+			name:        "Admin lists another provider's patterns",
+			injectUser:  true,
+			otherCaller: true,
+			asAdmin:     true,
+			mockSlots:   []db.ListPatternsByProviderRow{sample},
+			wantStatus:  http.StatusOK,
+			wantSlots: []PatternsResponse{{
+				ID:                 sample.ID,
+				DayOfWeek:          sample.DayOfWeek,
+				StartTime:          sample.StartTime.Time,
+				EndTime:            sample.EndTime.Time,
+				SlotMinutes:        30,
+				BufferAfterMinutes: 10,
+				Capacity:           4,
+				CreatedAt:          sample.CreatedAt,
+				UpdatedAt:          sample.UpdatedAt,
+			}},
+		},
 		{
 			name:              "DB error",
 			injectUser:        true,
@@ -169,8 +204,13 @@ func TestListPatternsByProviderHandler(t *testing.T) {
 
 			ctx := req.Context()
 			if tt.injectUser {
-				ctx = context.WithValue(ctx, middleware.UserIDKey, providerID)
+				callerID := providerID
+				if tt.otherCaller {
+					callerID = uuid.New()
+				}
+				ctx = context.WithValue(ctx, middleware.UserIDKey, callerID)
 			}
+			ctx = context.WithValue(ctx, middleware.IsAdminKey, tt.asAdmin)
 			req = req.WithContext(ctx)
 
 			mock := &mockPatternLister{returnSlots: tt.mockSlots, returnError: tt.mockErr}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/pagination"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/utils"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/webhooks"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type webhookDeliveryLister interface {
+	GetWebhookEndpointByID(ctx context.Context, id uuid.UUID) (db.WebhookEndpoint, error)
+	ListWebhookDeliveries(ctx context.Context, arg db.ListWebhookDeliveriesParams) ([]db.WebhookDelivery, error)
+}
+
+type WebhookDeliveryResponse struct {
+	ID             uuid.UUID       `json:"id"`
+	EndpointID     uuid.UUID       `json:"endpoint_id"`
+	EventID        uuid.UUID       `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int32           `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	ResponseStatus *int32          `json:"response_status,omitempty"`
+	LastError      *string         `json:"last_error,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
+
+func webhookDeliveryResponse(d db.WebhookDelivery) WebhookDeliveryResponse {
+	resp := WebhookDeliveryResponse{
+		ID:             d.ID,
+		EndpointID:     d.EndpointID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Payload:        json.RawMessage(d.Payload),
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		ResponseStatus: d.ResponseStatus,
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt,
+		DeliveredAt:    d.DeliveredAt,
+	}
+	if d.Status == webhooks.StatusPending {
+		resp.NextAttemptAt = &d.NextAttemptAt
+	}
+	return resp
+}
+
+// ListWebhookDeliveriesHandler pages through an endpoint's delivery log,
+// newest first. Query parameters: limit, cursor and status.
+func ListWebhookDeliveriesHandler(q webhookDeliveryLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		endpointID, err := uuid.Parse(mux.Vars(r)["id"])
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid webhook endpoint ID", err)
+			return
+		}
+
+		params := utils.NewQueryParams(r)
+		status := params.OneOf("status", webhooks.StatusPending, webhooks.StatusDelivered, webhooks.StatusDead)
+		page := params.Page()
+		if err := params.Err(); err != nil {
+			utils.RespondWithProblem(w, err)
+			return
+		}
+
+		if _, err := q.GetWebhookEndpointByID(r.Context(), endpointID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				utils.RespondWithError(w, http.StatusNotFound, "Webhook endpoint not found", nil)
+				return
+			}
+			utils.RespondWithError(w, http.StatusInternalServerError, "Unable to list webhook deliveries", err)
+			return
+		}
+
+		deliveries, err := q.ListWebhookDeliveries(r.Context(), db.ListWebhookDeliveriesParams{
+			EndpointID:     endpointID,
+			Status:         status,
+			AfterCreatedAt: page.AfterTime(),
+			AfterID:        page.AfterID(),
+			PageLimit:      page.FetchLimit(),
+		})
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Unable to list webhook deliveries", err)
+			return
+		}
+
+		resp := pagination.New(deliveries, page, func(d db.WebhookDelivery) pagination.Cursor {
+			return pagination.Cursor{Time: d.CreatedAt, ID: d.ID}
+		})
+		utils.RespondWithJSON(w, http.StatusOK, pagination.Map(resp, webhookDeliveryResponse))
+	}
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/pagination"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+func TestListWebhookDeliveriesHandler(t *testing.T) {
+	endpointID := uuid.New()
+	now := time.Date(2025, 6, 3, 9, 0, 0, 0, time.UTC)
+	code, lastErr := int32(500), "endpoint responded 500 Internal Server Error"
+	dead := db.WebhookDelivery{
+		ID: uuid.New(), EndpointID: endpointID, EventID: uuid.New(), EventType: "booking.created",
+		Payload: `{"id":"1"}`, Status: "dead", Attempts: 10, NextAttemptAt: now,
+		ResponseStatus: &code, LastError: &lastErr, CreatedAt: now,
+	}
+
+	tests := []struct {
+		name         string
+		idVar        string
+		query        string
+		mockErr      error
+		wantStatus   int
+		wantContains string
+	}{
+		{
+			name:       "Dead deliveries",
+			idVar:      endpointID.String(),
+			query:      "?status=dead&limit=1",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:         "Invalid endpoint ID",
+			idVar:        "nope",
+			wantStatus:   http.StatusBadRequest,
+			wantContains: "Invalid webhook endpoint ID",
+		},
+		{
+			name:         "Unknown status",
+			idVar:        endpointID.String(),
+			query:        "?status=failed",
+			wantStatus:   http.StatusBadRequest,
+			wantContains: `{"field":"status","message":"must be one of: pending, delivered, dead"}`,
+		},
+		{
+			name:         "Endpoint not found",
+			idVar:        uuid.New().String(),
+			wantStatus:   http.StatusNotFound,
+			wantContains: "Webhook endpoint not found",
+		},
+		{
+			name:         "DB error",
+			idVar:        endpointID.String(),
+			mockErr:      errors.New("db down"),
+			wantStatus:   http.StatusInternalServerError,
+			wantContains: "Unable to list webhook deliveries",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newMockWebhookQueries(db.WebhookEndpoint{ID: endpointID})
+			mock.deliveries = []db.WebhookDelivery{dead, dead}
+			mock.err = tt.mockErr
+
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/webhooks/"+tt.idVar+"/deliveries"+tt.query, nil)
+			req = mux.SetURLVars(req, map[string]string{"id": tt.idVar})
+			rr := httptest.NewRecorder()
+			ListWebhookDeliveriesHandler(mock).ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d; body=%q", tt.wantStatus, rr.Code, rr.Body.String())
+			}
+			if tt.wantContains != "" && !strings.Contains(rr.Body.String(), tt.wantContains) {
+				t.Errorf("expected body to contain %q, got %q", tt.wantContains, rr.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			if mock.listArg.EndpointID != endpointID || mock.listArg.Status.String != "dead" || mock.listArg.PageLimit != 2 {
+				t.Errorf("ListWebhookDeliveries called with %+v", mock.listArg)
+			}
+			var resp pagination.Page[WebhookDeliveryResponse]
+			if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if len(resp.Items) != 1 || resp.NextCursor == "" {
+				t.Fatalf("unexpected page %+v", resp)
+			}
+			got := resp.Items[0]
+			if got.ID != dead.ID || string(got.Payload) != dead.Payload || got.NextAttemptAt != nil ||
+				got.ResponseStatus == nil || *got.ResponseStatus != 500 || got.LastError == nil {
+				t.Errorf("unexpected delivery %+v", got)
+			}
+		})
+	}
+}
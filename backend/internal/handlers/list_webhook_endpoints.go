@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/utils"
+	"github.com/google/uuid"
+)
+
+type webhookEndpointLister interface {
+	ListWebhookEndpoints(ctx context.Context) ([]db.WebhookEndpoint, error)
+	ListWebhookSubscriptions(ctx context.Context, endpointID uuid.NullUUID) ([]db.WebhookSubscription, error)
+}
+
+// ListWebhookEndpointsHandler lists every webhook endpoint with the event
+// types it receives. Secrets are not included.
+func ListWebhookEndpointsHandler(q webhookEndpointLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		endpoints, err := q.ListWebhookEndpoints(r.Context())
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Unable to list webhook endpoints", err)
+			return
+		}
+		subs, err := q.ListWebhookSubscriptions(r.Context(), uuid.NullUUID{})
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Unable to list webhook endpoints", err)
+			return
+		}
+
+		types := map[uuid.UUID][]string{}
+		for _, s := range subs {
+			types[s.EndpointID] = append(types[s.EndpointID], s.EventType)
+		}
+		resp := make([]WebhookEndpointResponse, len(endpoints))
+		for i, e := range endpoints {
+			resp[i] = webhookEndpointResponse(e, types[e.ID])
+		}
+
+		utils.RespondWithJSON(w, http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/google/uuid"
+)
+
+func TestListWebhookEndpointsHandler(t *testing.T) {
+	crm := db.WebhookEndpoint{ID: uuid.New(), URL: "https://crm.example.com/hooks", Secret: "whsec_crm", Active: true}
+	billing := db.WebhookEndpoint{ID: uuid.New(), URL: "https://billing.example.com/hooks", Secret: "whsec_billing"}
+
+	t.Run("Lists endpoints with their event types", func(t *testing.T) {
+		mock := newMockWebhookQueries(crm, billing)
+		mock.subs[crm.ID] = []string{"booking.created", "user.registered"}
+
+		rr := httptest.NewRecorder()
+		ListWebhookEndpointsHandler(mock).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/admin/webhooks", nil))
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d; body=%q", rr.Code, rr.Body.String())
+		}
+		if strings.Contains(rr.Body.String(), "whsec_") {
+			t.Errorf("response leaks a secret: %s", rr.Body.String())
+		}
+		var resp []WebhookEndpointResponse
+		if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if len(resp) != 2 || resp[0].ID != crm.ID || resp[1].ID != billing.ID {
+			t.Fatalf("unexpected endpoints %+v", resp)
+		}
+		if !reflect.DeepEqual(resp[0].EventTypes, []string{"booking.created", "user.registered"}) || len(resp[1].EventTypes) != 0 {
+			t.Errorf("unexpected event types %v and %v", resp[0].EventTypes, resp[1].EventTypes)
+		}
+	})
+
+	t.Run("DB error", func(t *testing.T) {
+		mock := newMockWebhookQueries()
+		mock.err = errors.New("db down")
+
+		rr := httptest.NewRecorder()
+		ListWebhookEndpointsHandler(mock).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/admin/webhooks", nil))
+
+		if rr.Code != http.StatusInternalServerError || !strings.Contains(rr.Body.String(), "Unable to list webhook endpoints") {
+			t.Errorf("got %d %q", rr.Code, rr.Body.String())
+		}
+	})
+}
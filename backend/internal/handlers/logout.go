@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/middleware"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/utils"
+	"github.com/google/uuid"
+)
+
+type tokenRevoker interface {
+	RevokeAccessToken(ctx context.Context, arg db.RevokeAccessTokenParams) error
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (db.RefreshToken, error)
+	RevokeRefreshToken(ctx context.Context, id uuid.UUID) (int64, error)
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// LogoutHandler denylists the access token used for the request until it
+// expires and, if one is supplied, revokes the caller's refresh token.
+func LogoutHandler(q tokenRevoker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := middleware.UserIDFromContext(r.Context())
+		if !ok {
+			utils.RespondWithError(w, http.StatusUnauthorized, "User ID missing from context", nil)
+			return
+		}
+		jti, expiresAt, ok := middleware.TokenFromContext(r.Context())
+		if !ok {
+			utils.RespondWithError(w, http.StatusUnauthorized, "Missing token ID", nil)
+			return
+		}
+
+		// The body is optional; without it only the access token is revoked.
+		req := LogoutRequest{}
+		if err := utils.DecodeJSON(w, r, &req); err != nil && !errors.Is(err, utils.ErrEmptyBody) {
+			utils.RespondWithProblem(w, err)
+			return
+		}
+
+		err := q.RevokeAccessToken(r.Context(), db.RevokeAccessTokenParams{
+			Jti:       jti,
+			UserID:    userID,
+			ExpiresAt: expiresAt,
+		})
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Unable to revoke token", err)
+			return
+		}
+
+		if req.RefreshToken != "" {
+			stored, err := q.GetRefreshTokenByHash(r.Context(), hashRefreshToken(req.RefreshToken))
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				// Unknown token: nothing to revoke.
+			case err != nil:
+				utils.RespondWithError(w, http.StatusInternalServerError, "Unable to revoke refresh token", err)
+				return
+			case stored.UserID == userID:
+				if _, err := q.RevokeRefreshToken(r.Context(), stored.ID); err != nil {
+					utils.RespondWithError(w, http.StatusInternalServerError, "Unable to revoke refresh token", err)
+					return
+				}
+			}
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/middleware"
+	"github.com/google/uuid"
+)
+
+func TestLogoutHandler(t *testing.T) {
+	userID := uuid.New()
+	otherID := uuid.New()
+	exp := time.Now().Add(10 * time.Minute)
+
+	tests := []struct {
+		name             string
+		body             string
+		injectUser       bool
+		injectToken      bool
+		setup            func(m *mockTokenStore)
+		expectedCode     int
+		expectedContains string
+		expectedActive   int
+		otherActive      int
+	}{
+		{
+			name:        "Revokes access and refresh token",
+			body:        `{"refresh_token":"mine"}`,
+			injectUser:  true,
+			injectToken: true,
+			setup: func(m *mockTokenStore) {
+				m.addRefreshToken("mine", userID, exp)
+			},
+			expectedCode:   http.StatusNoContent,
+			expectedActive: 0,
+		},
+		{
+			name:         "Empty body only revokes the access token",
+			body:         ``,
+			injectUser:   true,
+			injectToken:  true,
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:        "Another user's refresh token is left alone",
+			body:        `{"refresh_token":"theirs"}`,
+			injectUser:  true,
+			injectToken: true,
+			setup: func(m *mockTokenStore) {
+				m.addRefreshToken("theirs", otherID, exp)
+			},
+			expectedCode: http.StatusNoContent,
+			otherActive:  1,
+		},
+		{
+			name:             "Missing user",
+			injectToken:      true,
+			expectedCode:     http.StatusUnauthorized,
+			expectedContains: "User ID missing from context",
+		},
+		{
+			name:             "Token without ID",
+			injectUser:       true,
+			expectedCode:     http.StatusUnauthorized,
+			expectedContains: "Missing token ID",
+		},
+		{
+			name:             "Invalid request body",
+			body:             `{ not json`,
+			injectUser:       true,
+			injectToken:      true,
+			expectedCode:     http.StatusBadRequest,
+			expectedContains: "Invalid request body",
+		},
+		{
+			name:        "DB error",
+			injectUser:  true,
+			injectToken: true,
+			setup: func(m *mockTokenStore) {
+				m.err = errors.New("db down")
+			},
+			expectedCode:     http.StatusInternalServerError,
+			expectedContains: "Unable to revoke token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockTokenStore()
+			if tt.setup != nil {
+				tt.setup(store)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/logout", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			ctx := req.Context()
+			if tt.injectUser {
+				ctx = context.WithValue(ctx, middleware.UserIDKey, userID)
+			}
+			if tt.injectToken {
+				ctx = context.WithValue(ctx, middleware.TokenIDKey, "jti-1")
+				ctx = context.WithValue(ctx, middleware.TokenExpiryKey, exp)
+			}
+			req = req.WithContext(ctx)
+
+			rr := httptest.NewRecorder()
+			LogoutHandler(store).ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedCode {
+				t.Errorf("expected status code %d, got %d; body=%q", tt.expectedCode, rr.Code, rr.Body.String())
+			}
+			if tt.expectedContains != "" && !strings.Contains(rr.Body.String(), tt.expectedContains) {
+				t.Errorf("expected response to contain %q, got %s", tt.expectedContains, rr.Body.String())
+			}
+			if rr.Code == http.StatusNoContent {
+				got, ok := store.revoked["jti-1"]
+				if !ok || got.UserID != userID || !got.ExpiresAt.Equal(exp) {
+					t.Errorf("expected jti-1 to be denylisted until %v, got %+v", exp, got)
+				}
+				if n := store.activeTokens(userID); n != tt.expectedActive {
+					t.Errorf("expected %d active refresh tokens, got %d", tt.expectedActive, n)
+				}
+				if n := store.activeTokens(otherID); n != tt.otherActive {
+					t.Errorf("expected %d active refresh tokens for the other user, got %d", tt.otherActive, n)
+				}
+			}
+		})
+	}
+}
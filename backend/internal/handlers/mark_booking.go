@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/service"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// MarkBookingHandler records how a booking that has started turned out.
+// status is service.StatusCompleted or service.StatusNoShow.
+func (h *Handler) MarkBookingHandler(status string) http.HandlerFunc {
+	mark := h.BookingService.CompleteBooking
+	if status == service.StatusNoShow {
+		mark = h.BookingService.MarkNoShow
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		bookingIDStr, ok := mux.Vars(r)["id"]
+		if !ok {
+			utils.RespondWithError(w, http.StatusBadRequest, "Missing booking ID", nil)
+			return
+		}
+		bookingID, err := uuid.Parse(bookingIDStr)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid booking ID", err)
+			return
+		}
+
+		booking, err := mark(r.Context(), bookingID)
+		if err != nil {
+			utils.RespondWithProblem(w, err)
+			return
+		}
+		utils.RespondWithJSON(w, http.StatusOK, booking)
+	}
+}
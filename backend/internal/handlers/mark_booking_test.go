@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/service"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+func TestMarkBookingHandler(t *testing.T) {
+	bookingID := uuid.New()
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name             string
+		markAs           string
+		routeID          string
+		existing         db.Booking
+		expectStatus     int
+		expectedContains string
+	}{
+		{
+			name:             "Complete",
+			markAs:           service.StatusCompleted,
+			routeID:          bookingID.String(),
+			existing:         db.Booking{ID: bookingID, Status: service.StatusConfirmed, AppointmentStart: past},
+			expectStatus:     http.StatusOK,
+			expectedContains: `"Status":"completed"`,
+		},
+		{
+			name:             "No-show",
+			markAs:           service.StatusNoShow,
+			routeID:          bookingID.String(),
+			existing:         db.Booking{ID: bookingID, Status: service.StatusConfirmed, AppointmentStart: past},
+			expectStatus:     http.StatusOK,
+			expectedContains: `"Status":"no_show"`,
+		},
+		{
+			name:             "Not started",
+			markAs:           service.StatusNoShow,
+			routeID:          bookingID.String(),
+			existing:         db.Booking{ID: bookingID, Status: service.StatusConfirmed, AppointmentStart: time.Now().Add(time.Hour)},
+			expectStatus:     http.StatusBadRequest,
+			expectedContains: "Booking has not started yet",
+		},
+		{
+			name:             "Cancelled booking",
+			markAs:           service.StatusCompleted,
+			routeID:          bookingID.String(),
+			existing:         db.Booking{ID: bookingID, Status: service.StatusCancelled, AppointmentStart: past},
+			expectStatus:     http.StatusConflict,
+			expectedContains: "A cancelled booking cannot be marked completed",
+		},
+		{
+			name:             "Booking not found",
+			markAs:           service.StatusCompleted,
+			routeID:          uuid.NewString(),
+			expectStatus:     http.StatusNotFound,
+			expectedContains: "Booking not found",
+		},
+		{
+			name:             "Invalid booking ID",
+			markAs:           service.StatusCompleted,
+			routeID:          "4595",
+			expectStatus:     http.StatusBadRequest,
+			expectedContains: "Invalid booking ID",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockQ := &mockBookingQueries{
+				GetBookingByIDFn: func(_ context.Context, id uuid.UUID) (db.Booking, error) {
+					if id != tt.existing.ID {
+						return db.Booking{}, sql.ErrNoRows
+					}
+					return tt.existing, nil
+				},
+				UpdateBookingStatusFn: func(_ context.Context, arg db.UpdateBookingStatusParams) (db.Booking, error) {
+					if arg.Status != tt.markAs || arg.CurrentStatus != tt.existing.Status {
+						t.Errorf("unexpected params %+v", arg)
+					}
+					b := tt.existing
+					b.Status = arg.Status
+					return b, nil
+				},
+			}
+
+			h := &Handler{BookingService: service.NewBookingService(mockQ)}
+			handler := h.MarkBookingHandler(tt.markAs)
+
+			req := httptest.NewRequest(http.MethodPut, "/api/admin/bookings/"+tt.routeID+"/complete", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": tt.routeID})
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectStatus {
+				t.Errorf("expected status %d, got %d. Body: %q", tt.expectStatus, rr.Code, rr.Body.String())
+			}
+			if !strings.Contains(rr.Body.String(), tt.expectedContains) {
+				t.Errorf("expected response to contain %q, got %s", tt.expectedContains, rr.Body.String())
+			}
+		})
+	}
+}
@@ -8,14 +8,18 @@ import (
 )
 
 type mockBookingQueries struct {
+	db.Querier
 	CreateBookingFn           func(ctx context.Context, arg db.CreateBookingParams) (db.Booking, error)
 	GetOverlappingBookingsFn  func(ctx context.Context, arg db.GetOverlappingBookingsParams) ([]db.Booking, error)
-	DeleteBookingFn           func(ctx context.Context, arg db.DeleteBookingParams) error
+	CancelBookingFn           func(ctx context.Context, arg db.CancelBookingParams) (db.Booking, error)
+	UpdateBookingStatusFn     func(ctx context.Context, arg db.UpdateBookingStatusParams) (db.Booking, error)
 	RescheduleBookingFn       func(ctx context.Context, arg db.RescheduleBookingParams) (db.Booking, error)
 	GetBookingByIDFn          func(ctx context.Context, bookingID uuid.UUID) (db.Booking, error)
-	ListBookingsForUserFn     func(ctx context.Context, id uuid.UUID) ([]db.Booking, error)
-	ListAllBookingsForAdminFn func(ctx context.Context) ([]db.Booking, error)
+	ListBookingsForUserFn     func(ctx context.Context, arg db.ListBookingsForUserParams) ([]db.Booking, error)
+	ListAllBookingsForAdminFn func(ctx context.Context, arg db.ListAllBookingsForAdminParams) ([]db.Booking, error)
 	CreateAvailabilityFn      func(ctx context.Context, arg db.CreateAvailabilityParams) error
+	GetAvailabilityByIDFn     func(ctx context.Context, id uuid.UUID) (db.Availability, error)
+	CountBookingsForSlotFn    func(ctx context.Context, slotID uuid.UUID) (int64, error)
 }
 
 func (m *mockBookingQueries) CreateBooking(ctx context.Context, arg db.CreateBookingParams) (db.Booking, error) {
@@ -25,8 +29,12 @@ func (m *mockBookingQueries) GetOverlappingBookings(ctx context.Context, arg db.
 	return m.GetOverlappingBookingsFn(ctx, arg)
 }
 
-func (m *mockBookingQueries) DeleteBooking(ctx context.Context, arg db.DeleteBookingParams) error {
-	return m.DeleteBookingFn(ctx, arg)
+func (m *mockBookingQueries) CancelBooking(ctx context.Context, arg db.CancelBookingParams) (db.Booking, error) {
+	return m.CancelBookingFn(ctx, arg)
+}
+
+func (m *mockBookingQueries) UpdateBookingStatus(ctx context.Context, arg db.UpdateBookingStatusParams) (db.Booking, error) {
+	return m.UpdateBookingStatusFn(ctx, arg)
 }
 
 func (m *mockBookingQueries) RescheduleBooking(ctx context.Context, arg db.RescheduleBookingParams) (db.Booking, error) {
@@ -36,12 +44,39 @@ func (m *mockBookingQueries) RescheduleBooking(ctx context.Context, arg db.Resch
 func (m *mockBookingQueries) GetBookingByID(ctx context.Context, bookingID uuid.UUID) (db.Booking, error) {
 	return m.GetBookingByIDFn(ctx, bookingID)
 }
-func (m *mockBookingQueries) ListBookingsForUser(ctx context.Context, id uuid.UUID) ([]db.Booking, error) {
-	return m.ListBookingsForUserFn(ctx, id)
+func (m *mockBookingQueries) ListBookingsForUser(ctx context.Context, arg db.ListBookingsForUserParams) ([]db.Booking, error) {
+	return m.ListBookingsForUserFn(ctx, arg)
 }
-func (m *mockBookingQueries) ListAllBookingsForAdmin(ctx context.Context) ([]db.Booking, error) {
-	return m.ListAllBookingsForAdminFn(ctx)
+func (m *mockBookingQueries) ListAllBookingsForAdmin(ctx context.Context, arg db.ListAllBookingsForAdminParams) ([]db.Booking, error) {
+	return m.ListAllBookingsForAdminFn(ctx, arg)
 }
 func (m *mockBookingQueries) CreateAvailability(ctx context.Context, arg db.CreateAvailabilityParams) error {
 	return m.CreateAvailabilityFn(ctx, arg)
 }
+func (m *mockBookingQueries) GetAvailabilityByID(ctx context.Context, id uuid.UUID) (db.Availability, error) {
+	if m.GetAvailabilityByIDFn == nil {
+		return db.Availability{ID: id, ProviderID: uuid.New(), Capacity: 1}, nil
+	}
+	return m.GetAvailabilityByIDFn(ctx, id)
+}
+func (m *mockBookingQueries) LockProviderSchedule(ctx context.Context, providerID uuid.UUID) error {
+	return nil
+}
+func (m *mockBookingQueries) ExecTx(ctx context.Context, fn func(db.Querier) error) error {
+	return fn(m)
+}
+func (m *mockBookingQueries) CountBookingsForSlot(ctx context.Context, slotID uuid.UUID) (int64, error) {
+	if m.CountBookingsForSlotFn == nil {
+		return 0, nil
+	}
+	return m.CountBookingsForSlotFn(ctx, slotID)
+}
+func (m *mockBookingQueries) CreateOutboxEvent(ctx context.Context, arg db.CreateOutboxEventParams) error {
+	return nil
+}
+func (m *mockBookingQueries) CancelBookingReminders(ctx context.Context, bookingID uuid.UUID) error {
+	return nil
+}
+func (m *mockBookingQueries) ScheduleBookingReminder(ctx context.Context, arg db.ScheduleBookingReminderParams) error {
+	return nil
+}
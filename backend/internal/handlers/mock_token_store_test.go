@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/auth"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/google/uuid"
+)
+
+// testTokens signs with an HS256 key for secret, or with an empty key set
+// that cannot sign anything when secret is "".
+func testTokens(t *testing.T, secret string) Tokens {
+	t.Helper()
+	keys, err := auth.NewKeySet(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens := Tokens{Keys: keys, AccessTTL: 15 * time.Minute, RefreshTTL: 24 * time.Hour}
+	if secret == "" {
+		return tokens
+	}
+	key, err := auth.NewHMACKey("test", []byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := keys.Add(key); err != nil {
+		t.Fatal(err)
+	}
+	return tokens
+}
+
+// mockTokenStore keeps refresh tokens and the access token denylist in memory.
+type mockTokenStore struct {
+	db.Querier
+	users   map[uuid.UUID]db.User
+	tokens  map[string]*db.RefreshToken
+	revoked map[string]db.RevokeAccessTokenParams
+	// userRevocations holds the expiry passed to RevokeUserTokens per user.
+	userRevocations map[uuid.UUID]time.Time
+	err             error
+}
+
+func newMockTokenStore(users ...db.User) *mockTokenStore {
+	m := &mockTokenStore{
+		users:   map[uuid.UUID]db.User{},
+		tokens:  map[string]*db.RefreshToken{},
+		revoked: map[string]db.RevokeAccessTokenParams{},
+
+		userRevocations: map[uuid.UUID]time.Time{},
+	}
+	for _, u := range users {
+		m.users[u.ID] = u
+	}
+	return m
+}
+
+// addRefreshToken stores raw for userID and returns the stored row.
+func (m *mockTokenStore) addRefreshToken(raw string, userID uuid.UUID, expiresAt time.Time) *db.RefreshToken {
+	t := &db.RefreshToken{ID: uuid.New(), UserID: userID, TokenHash: hashRefreshToken(raw), ExpiresAt: expiresAt}
+	m.tokens[t.TokenHash] = t
+	return t
+}
+
+// ExecTx restores the refresh tokens if fn fails, like a rollback would.
+func (m *mockTokenStore) ExecTx(ctx context.Context, fn func(db.Querier) error) error {
+	saved := make(map[string]db.RefreshToken, len(m.tokens))
+	for k, t := range m.tokens {
+		saved[k] = *t
+	}
+	err := fn(m)
+	if err != nil {
+		m.tokens = make(map[string]*db.RefreshToken, len(saved))
+		for k, t := range saved {
+			m.tokens[k] = &t
+		}
+	}
+	return err
+}
+
+func (m *mockTokenStore) CreateRefreshToken(_ context.Context, arg db.CreateRefreshTokenParams) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.tokens[arg.TokenHash] = &db.RefreshToken{ID: arg.ID, UserID: arg.UserID, TokenHash: arg.TokenHash, ExpiresAt: arg.ExpiresAt}
+	return nil
+}
+
+func (m *mockTokenStore) GetRefreshTokenByHash(_ context.Context, hash string) (db.RefreshToken, error) {
+	if m.err != nil {
+		return db.RefreshToken{}, m.err
+	}
+	t, ok := m.tokens[hash]
+	if !ok {
+		return db.RefreshToken{}, sql.ErrNoRows
+	}
+	return *t, nil
+}
+
+func (m *mockTokenStore) RevokeRefreshToken(_ context.Context, id uuid.UUID) (int64, error) {
+	for _, t := range m.tokens {
+		if t.ID == id && !t.RevokedAt.Valid {
+			t.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (m *mockTokenStore) RevokeRefreshTokensForUser(_ context.Context, userID uuid.UUID) error {
+	for _, t := range m.tokens {
+		if t.UserID == userID && !t.RevokedAt.Valid {
+			t.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		}
+	}
+	return nil
+}
+
+func (m *mockTokenStore) GetUserByID(_ context.Context, id uuid.UUID) (db.User, error) {
+	u, ok := m.users[id]
+	if !ok {
+		return db.User{}, sql.ErrNoRows
+	}
+	return u, nil
+}
+
+func (m *mockTokenStore) RevokeAccessToken(_ context.Context, arg db.RevokeAccessTokenParams) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.revoked[arg.Jti] = arg
+	return nil
+}
+
+func (m *mockTokenStore) RevokeUserTokens(_ context.Context, arg db.RevokeUserTokensParams) (int64, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
+	if _, ok := m.users[arg.UserID]; !ok {
+		return 0, nil
+	}
+	m.userRevocations[arg.UserID] = arg.ExpiresAt
+	return 1, nil
+}
+
+func (m *mockTokenStore) activeTokens(userID uuid.UUID) int {
+	n := 0
+	for _, t := range m.tokens {
+		if t.UserID == userID && !t.RevokedAt.Valid {
+			n++
+		}
+	}
+	return n
+}
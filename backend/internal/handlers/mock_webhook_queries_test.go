@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/google/uuid"
+)
+
+// mockWebhookQueries keeps webhook endpoints and deliveries in memory. When
+// err is set every query fails with it.
+type mockWebhookQueries struct {
+	db.Querier
+	endpoints  []db.WebhookEndpoint
+	subs       map[uuid.UUID][]string
+	deliveries []db.WebhookDelivery
+	listArg    db.ListWebhookDeliveriesParams
+	err        error
+}
+
+func newMockWebhookQueries(endpoints ...db.WebhookEndpoint) *mockWebhookQueries {
+	return &mockWebhookQueries{endpoints: endpoints, subs: map[uuid.UUID][]string{}}
+}
+
+func (m *mockWebhookQueries) ExecTx(ctx context.Context, fn func(db.Querier) error) error {
+	return fn(m)
+}
+
+func (m *mockWebhookQueries) CreateWebhookEndpoint(ctx context.Context, arg db.CreateWebhookEndpointParams) (db.WebhookEndpoint, error) {
+	if m.err != nil {
+		return db.WebhookEndpoint{}, m.err
+	}
+	e := db.WebhookEndpoint{ID: arg.ID, URL: arg.URL, Secret: arg.Secret, Description: arg.Description, Active: true}
+	m.endpoints = append(m.endpoints, e)
+	return e, nil
+}
+
+func (m *mockWebhookQueries) GetWebhookEndpointByID(ctx context.Context, id uuid.UUID) (db.WebhookEndpoint, error) {
+	if m.err != nil {
+		return db.WebhookEndpoint{}, m.err
+	}
+	for _, e := range m.endpoints {
+		if e.ID == id {
+			return e, nil
+		}
+	}
+	return db.WebhookEndpoint{}, sql.ErrNoRows
+}
+
+func (m *mockWebhookQueries) ListWebhookEndpoints(ctx context.Context) ([]db.WebhookEndpoint, error) {
+	return m.endpoints, m.err
+}
+
+func (m *mockWebhookQueries) UpdateWebhookEndpoint(ctx context.Context, arg db.UpdateWebhookEndpointParams) (db.WebhookEndpoint, error) {
+	if m.err != nil {
+		return db.WebhookEndpoint{}, m.err
+	}
+	for i, e := range m.endpoints {
+		if e.ID == arg.ID {
+			m.endpoints[i].URL = arg.URL
+			m.endpoints[i].Description = arg.Description
+			m.endpoints[i].Active = arg.Active
+			return m.endpoints[i], nil
+		}
+	}
+	return db.WebhookEndpoint{}, sql.ErrNoRows
+}
+
+func (m *mockWebhookQueries) DeleteWebhookEndpoint(ctx context.Context, id uuid.UUID) (int64, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
+	n := len(m.endpoints)
+	m.endpoints = slices.DeleteFunc(m.endpoints, func(e db.WebhookEndpoint) bool { return e.ID == id })
+	return int64(n - len(m.endpoints)), nil
+}
+
+func (m *mockWebhookQueries) AddWebhookSubscription(ctx context.Context, arg db.AddWebhookSubscriptionParams) error {
+	m.subs[arg.EndpointID] = append(m.subs[arg.EndpointID], arg.EventType)
+	return m.err
+}
+
+func (m *mockWebhookQueries) DeleteWebhookSubscriptions(ctx context.Context, endpointID uuid.UUID) error {
+	delete(m.subs, endpointID)
+	return m.err
+}
+
+func (m *mockWebhookQueries) ListWebhookSubscriptions(ctx context.Context, endpointID uuid.NullUUID) ([]db.WebhookSubscription, error) {
+	var out []db.WebhookSubscription
+	for _, e := range m.endpoints {
+		for _, t := range m.subs[e.ID] {
+			out = append(out, db.WebhookSubscription{EndpointID: e.ID, EventType: t})
+		}
+	}
+	return out, m.err
+}
+
+func (m *mockWebhookQueries) ListWebhookDeliveries(ctx context.Context, arg db.ListWebhookDeliveriesParams) ([]db.WebhookDelivery, error) {
+	m.listArg = arg
+	return m.deliveries, m.err
+}
+
+func (m *mockWebhookQueries) RedeliverWebhookDelivery(ctx context.Context, id uuid.UUID) (db.WebhookDelivery, error) {
+	if m.err != nil {
+		return db.WebhookDelivery{}, m.err
+	}
+	for i, d := range m.deliveries {
+		if d.ID == id {
+			m.deliveries[i].Status = "pending"
+			m.deliveries[i].Attempts = 0
+			m.deliveries[i].DeliveredAt = nil
+			return m.deliveries[i], nil
+		}
+	}
+	return db.WebhookDelivery{}, sql.ErrNoRows
+}
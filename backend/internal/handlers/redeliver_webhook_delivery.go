@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type webhookRedeliverer interface {
+	RedeliverWebhookDelivery(ctx context.Context, id uuid.UUID) (db.WebhookDelivery, error)
+}
+
+// RedeliverWebhookDeliveryHandler queues a delivery, usually a dead one, to
+// be sent again with the same body and a fresh set of attempts.
+func RedeliverWebhookDeliveryHandler(q webhookRedeliverer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		deliveryID, err := uuid.Parse(mux.Vars(r)["id"])
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid webhook delivery ID", err)
+			return
+		}
+
+		delivery, err := q.RedeliverWebhookDelivery(r.Context(), deliveryID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				utils.RespondWithError(w, http.StatusNotFound, "Webhook delivery not found", nil)
+				return
+			}
+			utils.RespondWithError(w, http.StatusInternalServerError, "Unable to redeliver webhook", err)
+			return
+		}
+
+		utils.RespondWithJSON(w, http.StatusAccepted, webhookDeliveryResponse(delivery))
+	}
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+func TestRedeliverWebhookDeliveryHandler(t *testing.T) {
+	deliveryID := uuid.New()
+
+	tests := []struct {
+		name         string
+		idVar        string
+		mockErr      error
+		wantStatus   int
+		wantContains string
+	}{
+		{name: "Requeued", idVar: deliveryID.String(), wantStatus: http.StatusAccepted},
+		{name: "Invalid delivery ID", idVar: "nope", wantStatus: http.StatusBadRequest, wantContains: "Invalid webhook delivery ID"},
+		{name: "Delivery not found", idVar: uuid.New().String(), wantStatus: http.StatusNotFound, wantContains: "Webhook delivery not found"},
+		{name: "DB error", idVar: deliveryID.String(), mockErr: errors.New("db down"), wantStatus: http.StatusInternalServerError, wantContains: "Unable to redeliver webhook"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newMockWebhookQueries()
+			mock.deliveries = []db.WebhookDelivery{{ID: deliveryID, Payload: `{}`, Status: "dead", Attempts: 10}}
+			mock.err = tt.mockErr
+
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/webhooks/deliveries/"+tt.idVar+"/redeliver", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": tt.idVar})
+			rr := httptest.NewRecorder()
+			RedeliverWebhookDeliveryHandler(mock).ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d; body=%q", tt.wantStatus, rr.Code, rr.Body.String())
+			}
+			if tt.wantContains != "" && !strings.Contains(rr.Body.String(), tt.wantContains) {
+				t.Errorf("expected body to contain %q, got %q", tt.wantContains, rr.Body.String())
+			}
+			if tt.wantStatus != http.StatusAccepted {
+				return
+			}
+			var resp WebhookDeliveryResponse
+			if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if resp.Status != "pending" || resp.Attempts != 0 || resp.NextAttemptAt == nil {
+				t.Errorf("unexpected delivery %+v", resp)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/utils"
+	"github.com/google/uuid"
+)
+
+var (
+	errInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	errRefreshTokenReused  = errors.New("refresh token reused")
+)
+
+type refreshTokenStore interface {
+	ExecTx(ctx context.Context, fn func(db.Querier) error) error
+	RevokeRefreshTokensForUser(ctx context.Context, userID uuid.UUID) error
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// RefreshTokenHandler exchanges a refresh token for a new access token and a
+// new refresh token. The presented token is revoked in the same transaction.
+// Presenting a token that was already rotated is treated as theft and
+// revokes every refresh token the user holds.
+func RefreshTokenHandler(q refreshTokenStore, tokens Tokens) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := RefreshTokenRequest{}
+		if err := utils.DecodeJSON(w, r, &req); err != nil {
+			utils.RespondWithProblem(w, err)
+			return
+		}
+
+		var resp LoginResponse
+		var owner uuid.UUID
+		err := q.ExecTx(r.Context(), func(tx db.Querier) error {
+			stored, err := tx.GetRefreshTokenByHash(r.Context(), hashRefreshToken(req.RefreshToken))
+			if errors.Is(err, sql.ErrNoRows) {
+				return errInvalidRefreshToken
+			}
+			if err != nil {
+				return err
+			}
+			owner = stored.UserID
+
+			if stored.RevokedAt.Valid {
+				return errRefreshTokenReused
+			}
+			if time.Now().After(stored.ExpiresAt) {
+				return errInvalidRefreshToken
+			}
+
+			rows, err := tx.RevokeRefreshToken(r.Context(), stored.ID)
+			if err != nil {
+				return err
+			}
+			if rows == 0 {
+				// Rotated by a concurrent request between the read and now.
+				return errRefreshTokenReused
+			}
+
+			user, err := tx.GetUserByID(r.Context(), stored.UserID)
+			if errors.Is(err, sql.ErrNoRows) {
+				return errInvalidRefreshToken
+			}
+			if err != nil {
+				return err
+			}
+
+			resp, err = tokens.issue(r.Context(), tx, user)
+			return err
+		})
+
+		switch {
+		case errors.Is(err, errRefreshTokenReused):
+			if err := q.RevokeRefreshTokensForUser(r.Context(), owner); err != nil {
+				utils.RespondWithError(w, http.StatusInternalServerError, "Unable to revoke refresh tokens", err)
+				return
+			}
+			utils.RespondWithError(w, http.StatusUnauthorized, "Refresh token has been revoked", nil)
+			return
+		case errors.Is(err, errInvalidRefreshToken):
+			utils.RespondWithError(w, http.StatusUnauthorized, "Invalid or expired refresh token", nil)
+			return
+		case err != nil:
+			utils.RespondWithError(w, http.StatusInternalServerError, "Unable to refresh token", err)
+			return
+		}
+
+		utils.RespondWithJSON(w, http.StatusOK, resp)
+	}
+}
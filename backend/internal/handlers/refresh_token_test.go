@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/google/uuid"
+)
+
+func TestRefreshTokenHandler(t *testing.T) {
+	user := db.User{ID: uuid.New(), FirstName: "John", UserRole: "user"}
+
+	tests := []struct {
+		name             string
+		secret           string
+		body             string
+		setup            func(m *mockTokenStore)
+		expectedCode     int
+		expectedContains string
+		expectedActive   int
+	}{
+		{
+			name:   "Rotates a valid token",
+			secret: "testsecret",
+			body:   `{"refresh_token":"valid"}`,
+			setup: func(m *mockTokenStore) {
+				m.addRefreshToken("valid", user.ID, time.Now().Add(time.Hour))
+			},
+			expectedCode:     http.StatusOK,
+			expectedContains: `"refresh_token":`,
+			expectedActive:   1,
+		},
+		{
+			name:   "Reused token revokes the whole family",
+			secret: "testsecret",
+			body:   `{"refresh_token":"stolen"}`,
+			setup: func(m *mockTokenStore) {
+				old := m.addRefreshToken("stolen", user.ID, time.Now().Add(time.Hour))
+				m.RevokeRefreshToken(context.Background(), old.ID)
+				m.addRefreshToken("current", user.ID, time.Now().Add(time.Hour))
+			},
+			expectedCode:     http.StatusUnauthorized,
+			expectedContains: "Refresh token has been revoked",
+			expectedActive:   0,
+		},
+		{
+			name:   "Expired token",
+			secret: "testsecret",
+			body:   `{"refresh_token":"old"}`,
+			setup: func(m *mockTokenStore) {
+				m.addRefreshToken("old", user.ID, time.Now().Add(-time.Minute))
+			},
+			expectedCode:     http.StatusUnauthorized,
+			expectedContains: "Invalid or expired refresh token",
+			expectedActive:   1,
+		},
+		{
+			name:             "Unknown token",
+			secret:           "testsecret",
+			body:             `{"refresh_token":"nope"}`,
+			expectedCode:     http.StatusUnauthorized,
+			expectedContains: "Invalid or expired refresh token",
+		},
+		{
+			name:             "Missing token",
+			secret:           "testsecret",
+			body:             `{}`,
+			expectedCode:     http.StatusBadRequest,
+			expectedContains: `{"field":"refresh_token","message":"is required"}`,
+		},
+		{
+			name:             "Invalid request body",
+			secret:           "testsecret",
+			body:             `{ not json`,
+			expectedCode:     http.StatusBadRequest,
+			expectedContains: "Invalid request body",
+		},
+		{
+			name:   "No signing key",
+			secret: "",
+			body:   `{"refresh_token":"valid"}`,
+			setup: func(m *mockTokenStore) {
+				m.addRefreshToken("valid", user.ID, time.Now().Add(time.Hour))
+			},
+			expectedCode:     http.StatusInternalServerError,
+			expectedContains: "Unable to refresh token",
+			expectedActive:   1,
+		},
+		{
+			name:   "DB error",
+			secret: "testsecret",
+			body:   `{"refresh_token":"valid"}`,
+			setup: func(m *mockTokenStore) {
+				m.err = errors.New("db down")
+			},
+			expectedCode:     http.StatusInternalServerError,
+			expectedContains: "Unable to refresh token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockTokenStore(user)
+			if tt.setup != nil {
+				tt.setup(store)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/token/refresh", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+			RefreshTokenHandler(store, testTokens(t, tt.secret)).ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedCode {
+				t.Errorf("expected status code %d, got %d; body=%q", tt.expectedCode, rr.Code, rr.Body.String())
+			}
+			if tt.expectedContains != "" && !strings.Contains(rr.Body.String(), tt.expectedContains) {
+				t.Errorf("expected response to contain %q, got %s", tt.expectedContains, rr.Body.String())
+			}
+			if got := store.activeTokens(user.ID); got != tt.expectedActive {
+				t.Errorf("expected %d active refresh tokens, got %d", tt.expectedActive, got)
+			}
+		})
+	}
+}
+
+func TestRefreshTokenHandler_OldTokenUnusableAfterRotation(t *testing.T) {
+	user := db.User{ID: uuid.New(), UserRole: "user"}
+	store := newMockTokenStore(user)
+	tokens := testTokens(t, "testsecret")
+	store.addRefreshToken("first", user.ID, time.Now().Add(time.Hour))
+
+	refresh := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/token/refresh", strings.NewReader(`{"refresh_token":"`+token+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		RefreshTokenHandler(store, tokens).ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := refresh("first")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("first refresh: expected 200, got %d", rr.Code)
+	}
+	var resp LoginResponse
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Token == "" || resp.RefreshToken == "" || resp.RefreshToken == "first" {
+		t.Fatalf("expected a new token pair, got %+v", resp)
+	}
+
+	if rr := refresh(resp.RefreshToken); rr.Code != http.StatusOK {
+		t.Fatalf("second refresh: expected 200, got %d", rr.Code)
+	}
+	if rr := refresh(resp.RefreshToken); rr.Code != http.StatusUnauthorized {
+		t.Fatalf("replayed refresh: expected 401, got %d", rr.Code)
+	}
+	if got := store.activeTokens(user.ID); got != 0 {
+		t.Errorf("expected replay to revoke all refresh tokens, %d still active", got)
+	}
+}
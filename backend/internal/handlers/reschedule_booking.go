@@ -1,21 +1,22 @@
 package handlers
 
 import (
-	"encoding/json"
-	"errors"
 	"net/http"
 	"time"
 
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/middleware"
-	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/service"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/utils"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
 
+// RescheduleBookingRequest moves a booking onto another slot.
+// AppointmentStart and DurationMinutes default to the slot's own; when given
+// they must match it.
 type RescheduleBookingRequest struct {
-	AppointmentStart time.Time `json:"appointment_start"`
-	DurationMinutes  int32     `json:"duration_minutes"`
+	SlotID           string    `json:"slot_id" validate:"required,uuid"`
+	AppointmentStart time.Time `json:"appointment_start,omitempty"`
+	DurationMinutes  *int32    `json:"duration_minutes,omitempty" validate:"omitempty,gt=0"`
 }
 
 func (h *Handler) RescheduleBookingHandler() http.HandlerFunc {
@@ -44,34 +45,27 @@ func (h *Handler) RescheduleBookingHandler() http.HandlerFunc {
 		}
 
 		var req RescheduleBookingRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body", err)
+		if err := utils.DecodeJSON(w, r, &req); err != nil {
+			utils.RespondWithProblem(w, err)
 			return
 		}
 
-		if req.DurationMinutes <= 0 {
-			utils.RespondWithError(w, http.StatusBadRequest, "Duration minutes must be greater than zero", nil)
-			return
+		var durationMinutes int32
+		if req.DurationMinutes != nil {
+			durationMinutes = *req.DurationMinutes
 		}
 
 		updated, err := h.BookingService.RescheduleBooking(
 			r.Context(),
 			bookingID,
 			userID,
+			uuid.MustParse(req.SlotID),
 			req.AppointmentStart,
-			req.DurationMinutes,
+			durationMinutes,
 			isAdmin,
 		)
-		if errors.Is(err, service.ErrBookingConflict) {
-			utils.RespondWithError(w, http.StatusConflict, "Time slot already booked", nil)
-			return
-		}
-		if errors.Is(err, service.ErrNotAuthorized) {
-			utils.RespondWithError(w, http.StatusForbidden, "You are not authorized to reschedule this booking", nil)
-			return
-		}
 		if err != nil {
-			utils.RespondWithError(w, http.StatusInternalServerError, "Couldn't reschedule", err)
+			utils.RespondWithProblem(w, err)
 			return
 		}
 
@@ -22,16 +22,24 @@ func TestRescheduleBookingHandler(t *testing.T) {
 	userID := uuid.New()
 	bookingID := uuid.New()
 	now := time.Now()
+	slot := db.Availability{
+		ID:         uuid.New(),
+		ProviderID: uuid.New(),
+		StartTime:  now.Add(time.Hour),
+		EndTime:    now.Add(time.Hour + 30*time.Minute),
+		Capacity:   1,
+	}
 
 	reqBody := RescheduleBookingRequest{
-		AppointmentStart: now.Add(time.Hour),
-		DurationMinutes:  30,
+		SlotID:           slot.ID.String(),
+		AppointmentStart: slot.StartTime,
 	}
 	jsonBody, _ := json.Marshal(reqBody)
 
+	invalidMinutes := int32(-10)
 	invalidReq := RescheduleBookingRequest{
-		AppointmentStart: now.Add(time.Hour),
-		DurationMinutes:  -10,
+		SlotID:          slot.ID.String(),
+		DurationMinutes: &invalidMinutes,
 	}
 	invalidBody, _ := json.Marshal(invalidReq)
 
@@ -40,8 +48,10 @@ func TestRescheduleBookingHandler(t *testing.T) {
 		UserID:           userID,
 		AppointmentStart: now.Add(time.Hour),
 		DurationMinutes:  30,
+		SlotID:           uuid.New(),
 		CreatedAt:        now.Add(-time.Hour),
 		UpdatedAt:        now.Add(-time.Minute),
+		Status:           service.StatusConfirmed,
 	}
 
 	tests := []struct {
@@ -50,6 +60,7 @@ func TestRescheduleBookingHandler(t *testing.T) {
 		ctxUserID        any
 		body             []byte
 		mockReschedule   func(ctx context.Context, arg db.RescheduleBookingParams) (db.Booking, error)
+		slotBookings     int64
 		expectStatus     int
 		expectedContains string
 	}{
@@ -62,6 +73,9 @@ func TestRescheduleBookingHandler(t *testing.T) {
 				if arg.ID != bookingID {
 					t.Errorf("expected ID %s, got %s", bookingID, arg.ID)
 				}
+				if arg.SlotID != slot.ID {
+					t.Errorf("expected slot %s, got %s", slot.ID, arg.SlotID)
+				}
 				if !arg.AppointmentStart.Equal(fakeBooking.AppointmentStart) {
 					t.Errorf("expected start %s, got %s", fakeBooking.AppointmentStart, arg.AppointmentStart)
 				}
@@ -109,14 +123,21 @@ func TestRescheduleBookingHandler(t *testing.T) {
 			expectStatus:     http.StatusBadRequest,
 			expectedContains: "Invalid request body",
 		},
-
+		{
+			name:             "Missing slot ID",
+			routeID:          bookingID.String(),
+			ctxUserID:        userID,
+			body:             []byte(`{"appointment_start":"2025-06-01T08:00:00Z"}`),
+			expectStatus:     http.StatusBadRequest,
+			expectedContains: `{"field":"slot_id","message":"is required"}`,
+		},
 		{
 			name:             "Duration minutes invalid",
 			routeID:          bookingID.String(),
 			ctxUserID:        userID,
 			body:             invalidBody,
 			expectStatus:     http.StatusBadRequest,
-			expectedContains: "Duration minutes must be greater than zero",
+			expectedContains: `{"field":"duration_minutes","message":"must be greater than 0"}`,
 		},
 		{
 			name:      "Booking conflict",
@@ -127,7 +148,16 @@ func TestRescheduleBookingHandler(t *testing.T) {
 				return db.Booking{}, service.ErrBookingConflict
 			},
 			expectStatus:     http.StatusConflict,
-			expectedContains: "Time slot already booked",
+			expectedContains: "Booking time slot conflict",
+		},
+		{
+			name:             "Slot taken",
+			routeID:          bookingID.String(),
+			ctxUserID:        userID,
+			body:             jsonBody,
+			slotBookings:     1,
+			expectStatus:     http.StatusConflict,
+			expectedContains: "Availability slot is fully booked",
 		},
 		{
 			name:      "Not authorized",
@@ -138,7 +168,7 @@ func TestRescheduleBookingHandler(t *testing.T) {
 				return db.Booking{}, service.ErrNotAuthorized
 			},
 			expectStatus:     http.StatusForbidden,
-			expectedContains: "You are not authorized to reschedule this booking",
+			expectedContains: "Not allowed",
 		},
 		{
 			name:      "Internal error",
@@ -149,7 +179,7 @@ func TestRescheduleBookingHandler(t *testing.T) {
 				return db.Booking{}, errors.New("boom")
 			},
 			expectStatus:     http.StatusInternalServerError,
-			expectedContains: "Couldn't reschedule",
+			expectedContains: "Internal server error",
 		},
 	}
 
@@ -162,6 +192,12 @@ func TestRescheduleBookingHandler(t *testing.T) {
 				GetOverlappingBookingsFn: func(ctx context.Context, arg db.GetOverlappingBookingsParams) ([]db.Booking, error) {
 					return nil, nil
 				},
+				GetAvailabilityByIDFn: func(ctx context.Context, id uuid.UUID) (db.Availability, error) {
+					return slot, nil
+				},
+				CountBookingsForSlotFn: func(ctx context.Context, slotID uuid.UUID) (int64, error) {
+					return tt.slotBookings, nil
+				},
 				RescheduleBookingFn: tt.mockReschedule,
 			}
 			bookingSvc := service.NewBookingService(mockQ)
@@ -170,6 +206,7 @@ func TestRescheduleBookingHandler(t *testing.T) {
 			handler := h.RescheduleBookingHandler()
 
 			req := httptest.NewRequest(http.MethodPut, "/api/bookings/"+tt.routeID, bytes.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
 
 			if tt.ctxUserID != nil {
 				req = req.WithContext(
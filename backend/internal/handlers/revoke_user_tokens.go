@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type userTokenRevoker interface {
+	ExecTx(ctx context.Context, fn func(db.Querier) error) error
+}
+
+// RevokeUserTokensHandler signs a user out everywhere: every access token
+// issued to them so far is rejected and their refresh tokens are revoked.
+// Tokens issued in the same second as the revocation are rejected too,
+// since access tokens only record their issue time to the second.
+func RevokeUserTokensHandler(q userTokenRevoker, tokens Tokens) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := mux.Vars(r)["id"]
+		if !ok {
+			utils.RespondWithError(w, http.StatusBadRequest, "Missing user ID", nil)
+			return
+		}
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid user ID", err)
+			return
+		}
+
+		var rows int64
+		err = q.ExecTx(r.Context(), func(tx db.Querier) error {
+			rows, err = tx.RevokeUserTokens(r.Context(), db.RevokeUserTokensParams{
+				// The last access token issued before now expires by then.
+				ExpiresAt: time.Now().Add(tokens.AccessTTL),
+				UserID:    userID,
+			})
+			if err != nil || rows == 0 {
+				return err
+			}
+			return tx.RevokeRefreshTokensForUser(r.Context(), userID)
+		})
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Unable to revoke tokens", err)
+			return
+		}
+		if rows == 0 {
+			utils.RespondWithError(w, http.StatusNotFound, "User not found", nil)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+func TestRevokeUserTokensHandler(t *testing.T) {
+	user := db.User{ID: uuid.New()}
+	other := db.User{ID: uuid.New()}
+
+	tests := []struct {
+		name         string
+		idVar        string
+		mockErr      error
+		wantStatus   int
+		wantContains string
+	}{
+		{
+			name:       "Revokes the user's tokens",
+			idVar:      user.ID.String(),
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:         "Invalid user ID",
+			idVar:        "not-a-uuid",
+			wantStatus:   http.StatusBadRequest,
+			wantContains: "Invalid user ID",
+		},
+		{
+			name:         "User not found",
+			idVar:        uuid.NewString(),
+			wantStatus:   http.StatusNotFound,
+			wantContains: "User not found",
+		},
+		{
+			name:         "DB error",
+			idVar:        user.ID.String(),
+			mockErr:      errors.New("db down"),
+			wantStatus:   http.StatusInternalServerError,
+			wantContains: "Unable to revoke tokens",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockTokenStore(user, other)
+			store.addRefreshToken("mine", user.ID, time.Now().Add(time.Hour))
+			store.addRefreshToken("theirs", other.ID, time.Now().Add(time.Hour))
+			store.err = tt.mockErr
+			tokens := testTokens(t, "secret")
+
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/users/"+tt.idVar+"/revoke-tokens", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": tt.idVar})
+			rr := httptest.NewRecorder()
+			start := time.Now()
+			RevokeUserTokensHandler(store, tokens).ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d; body=%q", rr.Code, tt.wantStatus, rr.Body.String())
+			}
+			if tt.wantContains != "" && !strings.Contains(rr.Body.String(), tt.wantContains) {
+				t.Errorf("body %q does not contain %q", rr.Body.String(), tt.wantContains)
+			}
+
+			if tt.wantStatus != http.StatusNoContent {
+				if store.activeTokens(user.ID) != 1 || len(store.userRevocations) != 0 {
+					t.Errorf("tokens were revoked on failure")
+				}
+				return
+			}
+			if store.activeTokens(user.ID) != 0 || store.activeTokens(other.ID) != 1 {
+				t.Errorf("active refresh tokens: user %d, other %d; want 0 and 1",
+					store.activeTokens(user.ID), store.activeTokens(other.ID))
+			}
+			// Kept until every access token issued before now has expired.
+			if exp := store.userRevocations[user.ID]; exp.Before(start.Add(tokens.AccessTTL)) {
+				t.Errorf("revocation expires at %s, before the access token TTL has passed", exp)
+			}
+			if _, ok := store.userRevocations[other.ID]; ok {
+				t.Error("another user's tokens were revoked")
+			}
+		})
+	}
+}
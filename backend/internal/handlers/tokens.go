@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/auth"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/config"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Tokens issues access tokens signed by Keys and the refresh tokens that go
+// with them.
+type Tokens struct {
+	Keys       auth.KeyProvider
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+}
+
+func NewTokens(keys auth.KeyProvider, cfg config.JWTConfig) Tokens {
+	return Tokens{Keys: keys, AccessTTL: cfg.AccessTokenTTL, RefreshTTL: cfg.RefreshTokenTTL}
+}
+
+type refreshTokenCreator interface {
+	CreateRefreshToken(ctx context.Context, arg db.CreateRefreshTokenParams) error
+}
+
+type tokenSignError struct{ err error }
+
+func (e tokenSignError) Error() string { return e.err.Error() }
+func (e tokenSignError) Unwrap() error { return e.err }
+
+// issue signs a short-lived access token for user with the current key and
+// stores the hash of a fresh refresh token. Only the hash is persisted; the
+// raw refresh token is returned to the client once.
+func (t Tokens) issue(ctx context.Context, q refreshTokenCreator, user db.User) (LoginResponse, error) {
+	key, err := t.Keys.SigningKey()
+	if err != nil {
+		return LoginResponse{}, tokenSignError{err}
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(key.Method, jwt.MapClaims{
+		"sub":       user.ID.String(),
+		"jti":       uuid.NewString(),
+		"user_role": user.UserRole,
+		"firstName": user.FirstName,
+		"iat":       jwt.NewNumericDate(now),
+		"exp":       jwt.NewNumericDate(now.Add(t.AccessTTL)),
+	})
+	token.Header["kid"] = key.ID
+
+	tokenString, err := SignTokenFn(token, key.SignKey())
+	if err != nil {
+		return LoginResponse{}, tokenSignError{err}
+	}
+
+	refresh, err := newRefreshToken()
+	if err != nil {
+		return LoginResponse{}, err
+	}
+
+	err = q.CreateRefreshToken(ctx, db.CreateRefreshTokenParams{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		TokenHash: hashRefreshToken(refresh),
+		ExpiresAt: now.Add(t.RefreshTTL),
+	})
+	if err != nil {
+		return LoginResponse{}, err
+	}
+
+	return LoginResponse{
+		Token:        tokenString,
+		RefreshToken: refresh,
+		ExpiresIn:    int(t.AccessTTL.Seconds()),
+	}, nil
+}
+
+func newRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+type expiredTokenDeleter interface {
+	DeleteExpiredRefreshTokens(ctx context.Context) (int64, error)
+	DeleteExpiredRevokedTokens(ctx context.Context) (int64, error)
+	DeleteExpiredUserTokenRevocations(ctx context.Context) (int64, error)
+}
+
+// PurgeExpiredTokens deletes refresh tokens and revocations that have
+// expired, since an expired token is rejected without them. It returns how
+// many rows it deleted.
+func PurgeExpiredTokens(ctx context.Context, q expiredTokenDeleter) (int64, error) {
+	var total int64
+	for _, purge := range []func(context.Context) (int64, error){
+		q.DeleteExpiredRefreshTokens,
+		q.DeleteExpiredRevokedTokens,
+		q.DeleteExpiredUserTokenRevocations,
+	} {
+		n, err := purge(ctx)
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	return total, nil
+}
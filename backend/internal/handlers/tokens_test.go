@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type stubTokenPurger struct {
+	refresh, revoked, users int64
+	err                     error
+}
+
+func (s stubTokenPurger) DeleteExpiredRefreshTokens(ctx context.Context) (int64, error) {
+	return s.refresh, nil
+}
+
+func (s stubTokenPurger) DeleteExpiredRevokedTokens(ctx context.Context) (int64, error) {
+	return s.revoked, s.err
+}
+
+func (s stubTokenPurger) DeleteExpiredUserTokenRevocations(ctx context.Context) (int64, error) {
+	return s.users, nil
+}
+
+func TestPurgeExpiredTokens(t *testing.T) {
+	n, err := PurgeExpiredTokens(context.Background(), stubTokenPurger{refresh: 3, revoked: 2, users: 1})
+	if err != nil || n != 6 {
+		t.Errorf("PurgeExpiredTokens = %d, %v; want 6, nil", n, err)
+	}
+
+	boom := errors.New("db down")
+	n, err = PurgeExpiredTokens(context.Background(), stubTokenPurger{refresh: 3, err: boom})
+	if !errors.Is(err, boom) || n != 3 {
+		t.Errorf("PurgeExpiredTokens = %d, %v; want 3, %v", n, err, boom)
+	}
+}
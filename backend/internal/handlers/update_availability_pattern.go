@@ -2,37 +2,69 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
 	"net/http"
 	"time"
 
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/middleware"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/service"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/utils"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
 
 type patternUpdater interface {
-	GetAvailabilityPatternByID(ctx context.Context, id uuid.UUID) (db.AvailabilityPattern, error)
-	UpdateAvailabilityPattern(ctx context.Context, arg db.UpdateAvailabilityPatternParams) error
+	UpdatePattern(ctx context.Context, patternID, actorID uuid.UUID, isAdmin bool, u service.PatternUpdate) (db.AvailabilityPattern, service.SlotChanges, error)
 }
 
+// UpdateRequest replaces a pattern's window. Omitted slot settings keep
+// their current values.
 type UpdateRequest struct {
-	DayOfWeek int32     `json:"day_of_week"`
-	StartTime time.Time `json:"start_time"`
-	EndTime   time.Time `json:"end_time"`
+	DayOfWeek int32     `json:"day_of_week" validate:"min=0,max=6"`
+	StartTime time.Time `json:"start_time" validate:"required"`
+	EndTime   time.Time `json:"end_time" validate:"required,gtfield=StartTime"`
+
+	SlotMinutes         *int32 `json:"slot_minutes" validate:"omitempty,min=5,max=1440"`
+	BufferBeforeMinutes *int32 `json:"buffer_before_minutes" validate:"omitempty,min=0,max=1440"`
+	BufferAfterMinutes  *int32 `json:"buffer_after_minutes" validate:"omitempty,min=0,max=1440"`
+	Capacity            *int32 `json:"capacity" validate:"omitempty,min=1,max=1000"`
 }
 
 type UpdateResponse struct {
-	DayOfWeek int32     `json:"day_of_week"`
+	DayOfWeek           int32                  `json:"day_of_week"`
+	StartTime           time.Time              `json:"start_time"`
+	EndTime             time.Time              `json:"end_time"`
+	SlotMinutes         int32                  `json:"slot_minutes"`
+	BufferBeforeMinutes int32                  `json:"buffer_before_minutes"`
+	BufferAfterMinutes  int32                  `json:"buffer_after_minutes"`
+	Capacity            int32                  `json:"capacity"`
+	ID                  uuid.UUID              `json:"id"`
+	UpdatedAt           time.Time              `json:"updated_at"`
+	CreatedSlots        int                    `json:"created_slots"`
+	RemovedSlots        int                    `json:"removed_slots"`
+	StrandedSlots       []StrandedSlotResponse `json:"stranded_slots"`
+}
+
+// StrandedSlotResponse is a booked slot that no longer fits its pattern.
+type StrandedSlotResponse struct {
+	ID        uuid.UUID `json:"id"`
 	StartTime time.Time `json:"start_time"`
 	EndTime   time.Time `json:"end_time"`
-	ID        uuid.UUID `json:"id"`
-	UpdatedAt time.Time `json:"updated_at"`
+	Booked    int32     `json:"booked"`
 }
 
-func UpdateAvailabilityPatternHandler(q patternUpdater) http.HandlerFunc {
+func strandedSlots(slots []db.ListPatternSlotsFromRow) []StrandedSlotResponse {
+	resp := make([]StrandedSlotResponse, len(slots))
+	for i, s := range slots {
+		resp[i] = StrandedSlotResponse{ID: s.ID, StartTime: s.StartTime, EndTime: s.EndTime, Booked: s.Booked}
+	}
+	return resp
+}
+
+// UpdateAvailabilityPatternHandler changes a pattern and regenerates its
+// future slots, reporting booked slots that no longer fit. Providers may
+// change their own patterns and admins any provider's.
+func UpdateAvailabilityPatternHandler(svc patternUpdater) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 
 		userID, ok := middleware.UserIDFromContext(r.Context())
@@ -40,10 +72,7 @@ func UpdateAvailabilityPatternHandler(q patternUpdater) http.HandlerFunc {
 			utils.RespondWithError(w, http.StatusUnauthorized, "Authentication required", nil)
 			return
 		}
-		if !middleware.IsAdminFromContext(r.Context()) {
-			utils.RespondWithError(w, http.StatusForbidden, "Forbidden", nil)
-			return
-		}
+		isAdmin := middleware.IsAdminFromContext(r.Context())
 
 		patternIdStr, ok := mux.Vars(r)["id"]
 		if !ok {
@@ -57,43 +86,39 @@ func UpdateAvailabilityPatternHandler(q patternUpdater) http.HandlerFunc {
 			return
 		}
 
-		existing, err := q.GetAvailabilityPatternByID(r.Context(), patternID)
-		if err != nil {
-			utils.RespondWithError(w, http.StatusNotFound, "Pattern not found", err)
-			return
-		}
-		if existing.ProviderID != userID {
-			utils.RespondWithError(w, http.StatusForbidden, "You do not own this pattern", nil)
-			return
-		}
-
-		decoder := json.NewDecoder(r.Body)
 		req := UpdateRequest{}
-		err = decoder.Decode(&req)
-		if err != nil {
-			utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body", err)
+		if err := utils.DecodeJSON(w, r, &req); err != nil {
+			utils.RespondWithProblem(w, err)
 			return
 		}
 
-		arg := db.UpdateAvailabilityPatternParams{
-			DayOfWeek: req.DayOfWeek,
-			StartTime: req.StartTime,
-			EndTime:   req.EndTime,
-			ID:        patternID,
-		}
-
-		err = q.UpdateAvailabilityPattern(r.Context(), arg)
+		pattern, changes, err := svc.UpdatePattern(r.Context(), patternID, userID, isAdmin, service.PatternUpdate{
+			DayOfWeek:           req.DayOfWeek,
+			Start:               req.StartTime,
+			End:                 req.EndTime,
+			SlotMinutes:         req.SlotMinutes,
+			BufferBeforeMinutes: req.BufferBeforeMinutes,
+			BufferAfterMinutes:  req.BufferAfterMinutes,
+			Capacity:            req.Capacity,
+		})
 		if err != nil {
-			utils.RespondWithError(w, http.StatusInternalServerError, "Unable to update availability pattern", err)
+			utils.RespondWithProblem(w, err)
 			return
 		}
 
 		utils.RespondWithJSON(w, http.StatusOK, UpdateResponse{
-			DayOfWeek: req.DayOfWeek,
-			StartTime: req.StartTime,
-			EndTime:   req.EndTime,
-			ID:        patternID,
-			UpdatedAt: time.Now(),
+			DayOfWeek:           pattern.DayOfWeek,
+			StartTime:           req.StartTime,
+			EndTime:             req.EndTime,
+			SlotMinutes:         pattern.SlotMinutes,
+			BufferBeforeMinutes: pattern.BufferBeforeMinutes,
+			BufferAfterMinutes:  pattern.BufferAfterMinutes,
+			Capacity:            pattern.Capacity,
+			ID:                  pattern.ID,
+			UpdatedAt:           pattern.UpdatedAt,
+			CreatedSlots:        changes.Created,
+			RemovedSlots:        changes.Removed,
+			StrandedSlots:       strandedSlots(changes.Stranded),
 		})
 	}
 }
@@ -13,43 +13,55 @@ import (
 
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/middleware"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/service"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
 
 type mockPatternUpdater struct {
-	getPattern   db.AvailabilityPattern
-	getErr       error
-	updateArg    db.UpdateAvailabilityPatternParams
+	pattern      db.AvailabilityPattern
+	changes      service.SlotChanges
 	updateErr    error
-	calledGet    bool
 	calledUpdate bool
+	gotID        uuid.UUID
+	gotProvider  uuid.UUID
+	gotAdmin     bool
+	gotUpdate    service.PatternUpdate
 }
 
-func (m *mockPatternUpdater) GetAvailabilityPatternByID(ctx context.Context, id uuid.UUID) (db.AvailabilityPattern, error) {
-	m.calledGet = true
-	return m.getPattern, m.getErr
-}
-
-func (m *mockPatternUpdater) UpdateAvailabilityPattern(ctx context.Context, arg db.UpdateAvailabilityPatternParams) error {
+func (m *mockPatternUpdater) UpdatePattern(ctx context.Context, patternID, actorID uuid.UUID, isAdmin bool, u service.PatternUpdate) (db.AvailabilityPattern, service.SlotChanges, error) {
 	m.calledUpdate = true
-	m.updateArg = arg
-	return m.updateErr
+	m.gotID = patternID
+	m.gotProvider = actorID
+	m.gotAdmin = isAdmin
+	m.gotUpdate = u
+	return m.pattern, m.changes, m.updateErr
 }
 
 func TestUpdateAvailabilityPatternHandler(t *testing.T) {
 	patternID := uuid.New()
 	ownerID := uuid.New()
-	otherID := uuid.New()
 
-	existing := db.AvailabilityPattern{
-		ID:         patternID,
-		ProviderID: ownerID,
-		DayOfWeek:  2,
-		StartTime:  time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC),
-		EndTime:    time.Date(2025, 6, 1, 17, 0, 0, 0, time.UTC),
-		CreatedAt:  time.Now(),
-		UpdatedAt:  time.Now(),
+	updated := db.AvailabilityPattern{
+		ID:          patternID,
+		ProviderID:  ownerID,
+		DayOfWeek:   4,
+		StartTime:   db.TimeOfDay{Time: time.Date(0, 1, 1, 10, 0, 0, 0, time.UTC)},
+		EndTime:     db.TimeOfDay{Time: time.Date(0, 1, 1, 12, 0, 0, 0, time.UTC)},
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+		SlotMinutes: 30,
+		Capacity:    1,
+	}
+	regrouped := updated
+	regrouped.SlotMinutes = 90
+	regrouped.Capacity = 6
+	stranded := db.ListPatternSlotsFromRow{
+		ID:        uuid.New(),
+		StartTime: time.Date(2025, 6, 3, 9, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2025, 6, 3, 9, 30, 0, 0, time.UTC),
+		Capacity:  1,
+		Booked:    1,
 	}
 
 	validBody := map[string]interface{}{
@@ -77,41 +89,78 @@ func TestUpdateAvailabilityPatternHandler(t *testing.T) {
 			},
 			setupContext: func(req *http.Request) *http.Request {
 				ctx := context.WithValue(req.Context(), middleware.UserIDKey, ownerID)
-				ctx = context.WithValue(ctx, middleware.IsAdminKey, true)
 				return req.WithContext(ctx)
 			},
 			mock: &mockPatternUpdater{
-				getPattern: existing,
+				pattern: updated,
+				changes: service.SlotChanges{Created: 2, Removed: 1},
 			},
-			wantStatus: http.StatusOK,
+			wantStatus:      http.StatusOK,
+			wantBodyContain: `"created_slots":2,"removed_slots":1,"stranded_slots":[]`,
 			checkUpdate: func(t *testing.T, m *mockPatternUpdater) {
 				if !m.calledUpdate {
-					t.Error("expected UpdateAvailabilityPattern to be called")
+					t.Fatal("expected UpdatePattern to be called")
+				}
+				if m.gotID != patternID || m.gotProvider != ownerID || m.gotAdmin {
+					t.Errorf("called with (%v,%v,%v); want (%v,%v,false)", m.gotID, m.gotProvider, m.gotAdmin, patternID, ownerID)
 				}
-				if m.updateArg.ID != patternID {
-					t.Errorf("got update ID %v; want %v", m.updateArg.ID, patternID)
+				if m.gotUpdate.DayOfWeek != 4 || !m.gotUpdate.Start.Equal(time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)) {
+					t.Errorf("got update %+v", m.gotUpdate)
 				}
-				if m.updateArg.DayOfWeek != 4 {
-					t.Errorf("got day %d; want 4", m.updateArg.DayOfWeek)
+				if m.gotUpdate.SlotMinutes != nil || m.gotUpdate.BufferAfterMinutes != nil || m.gotUpdate.Capacity != nil {
+					t.Errorf("omitted slot settings were sent: %+v", m.gotUpdate)
 				}
 			},
 		},
 		{
-			name: "Missing auth",
+			name: "Change slot settings",
 			setupRequest: func() *http.Request {
-				req := httptest.NewRequest(http.MethodPut, "/availability/patterns/"+patternID.String(), bytes.NewReader(bodyBytes))
+				body := `{"day_of_week":4,"start_time":"2025-06-01T10:00:00Z","end_time":"2025-06-01T12:00:00Z","slot_minutes":90,"buffer_after_minutes":0,"capacity":6}`
+				req := httptest.NewRequest(http.MethodPut, "/availability/patterns/"+patternID.String(), strings.NewReader(body))
 				req = mux.SetURLVars(req, map[string]string{"id": patternID.String()})
 				return req
 			},
 			setupContext: func(req *http.Request) *http.Request {
+				ctx := context.WithValue(req.Context(), middleware.UserIDKey, ownerID)
+				return req.WithContext(ctx)
+			},
+			mock: &mockPatternUpdater{
+				pattern: regrouped,
+				changes: service.SlotChanges{Stranded: []db.ListPatternSlotsFromRow{stranded}},
+			},
+			wantStatus:      http.StatusOK,
+			wantBodyContain: `"capacity":6`,
+			checkUpdate: func(t *testing.T, m *mockPatternUpdater) {
+				u := m.gotUpdate
+				if u.SlotMinutes == nil || *u.SlotMinutes != 90 || u.BufferAfterMinutes == nil || *u.BufferAfterMinutes != 0 ||
+					u.Capacity == nil || *u.Capacity != 6 || u.BufferBeforeMinutes != nil {
+					t.Errorf("got update %+v", u)
+				}
+			},
+		},
+		{
+			name: "Admin changes a provider's pattern",
+			setupRequest: func() *http.Request {
+				req := httptest.NewRequest(http.MethodPut, "/availability/patterns/"+patternID.String(), bytes.NewReader(bodyBytes))
+				req = mux.SetURLVars(req, map[string]string{"id": patternID.String()})
 				return req
 			},
-			mock:            &mockPatternUpdater{},
-			wantStatus:      http.StatusUnauthorized,
-			wantBodyContain: "Authentication required",
+			setupContext: func(req *http.Request) *http.Request {
+				ctx := context.WithValue(req.Context(), middleware.UserIDKey, uuid.New())
+				ctx = context.WithValue(ctx, middleware.IsAdminKey, true)
+				return req.WithContext(ctx)
+			},
+			mock:            &mockPatternUpdater{pattern: updated},
+			wantStatus:      http.StatusOK,
+			wantBodyContain: `"id":"` + patternID.String() + `"`,
+			checkUpdate: func(t *testing.T, m *mockPatternUpdater) {
+				if !m.gotAdmin {
+					t.Error("expected UpdatePattern to be called as admin")
+				}
+			},
 		},
 		{
-			name: "User not admin",
+			name: "Booked slots reported",
 			setupRequest: func() *http.Request {
 				req := httptest.NewRequest(http.MethodPut, "/availability/patterns/"+patternID.String(), bytes.NewReader(bodyBytes))
 				req = mux.SetURLVars(req, map[string]string{"id": patternID.String()})
@@ -119,12 +168,28 @@ func TestUpdateAvailabilityPatternHandler(t *testing.T) {
 			},
 			setupContext: func(req *http.Request) *http.Request {
 				ctx := context.WithValue(req.Context(), middleware.UserIDKey, ownerID)
-				ctx = context.WithValue(ctx, middleware.IsAdminKey, false)
 				return req.WithContext(ctx)
 			},
+			mock: &mockPatternUpdater{
+				pattern: updated,
+				changes: service.SlotChanges{Stranded: []db.ListPatternSlotsFromRow{stranded}},
+			},
+			wantStatus:      http.StatusOK,
+			wantBodyContain: `"stranded_slots":[{"id":"` + stranded.ID.String() + `","start_time":"2025-06-03T09:00:00Z","end_time":"2025-06-03T09:30:00Z","booked":1}]`,
+		},
+		{
+			name: "Missing auth",
+			setupRequest: func() *http.Request {
+				req := httptest.NewRequest(http.MethodPut, "/availability/patterns/"+patternID.String(), bytes.NewReader(bodyBytes))
+				req = mux.SetURLVars(req, map[string]string{"id": patternID.String()})
+				return req
+			},
+			setupContext: func(req *http.Request) *http.Request {
+				return req
+			},
 			mock:            &mockPatternUpdater{},
-			wantStatus:      http.StatusForbidden,
-			wantBodyContain: "Forbidden",
+			wantStatus:      http.StatusUnauthorized,
+			wantBodyContain: "Authentication required",
 		},
 		{
 			name: "Missing pattern id",
@@ -133,7 +198,6 @@ func TestUpdateAvailabilityPatternHandler(t *testing.T) {
 			},
 			setupContext: func(req *http.Request) *http.Request {
 				ctx := context.WithValue(req.Context(), middleware.UserIDKey, ownerID)
-				ctx = context.WithValue(ctx, middleware.IsAdminKey, true)
 				return req.WithContext(ctx)
 			},
 			mock:            &mockPatternUpdater{},
@@ -149,7 +213,6 @@ func TestUpdateAvailabilityPatternHandler(t *testing.T) {
 			},
 			setupContext: func(req *http.Request) *http.Request {
 				ctx := context.WithValue(req.Context(), middleware.UserIDKey, ownerID)
-				ctx = context.WithValue(ctx, middleware.IsAdminKey, true)
 				return req.WithContext(ctx)
 			},
 			mock:            &mockPatternUpdater{},
@@ -165,11 +228,10 @@ func TestUpdateAvailabilityPatternHandler(t *testing.T) {
 			},
 			setupContext: func(req *http.Request) *http.Request {
 				ctx := context.WithValue(req.Context(), middleware.UserIDKey, ownerID)
-				ctx = context.WithValue(ctx, middleware.IsAdminKey, true)
 				return req.WithContext(ctx)
 			},
 			mock: &mockPatternUpdater{
-				getErr: errors.New("sql: no rows"),
+				updateErr: service.ErrPatternNotFound,
 			},
 			wantStatus:      http.StatusNotFound,
 			wantBodyContain: "Pattern not found",
@@ -182,12 +244,11 @@ func TestUpdateAvailabilityPatternHandler(t *testing.T) {
 				return req
 			},
 			setupContext: func(req *http.Request) *http.Request {
-				ctx := context.WithValue(req.Context(), middleware.UserIDKey, otherID)
-				ctx = context.WithValue(ctx, middleware.IsAdminKey, true)
+				ctx := context.WithValue(req.Context(), middleware.UserIDKey, ownerID)
 				return req.WithContext(ctx)
 			},
 			mock: &mockPatternUpdater{
-				getPattern: existing,
+				updateErr: service.ErrPatternNotOwned,
 			},
 			wantStatus:      http.StatusForbidden,
 			wantBodyContain: "You do not own",
@@ -202,13 +263,33 @@ func TestUpdateAvailabilityPatternHandler(t *testing.T) {
 			},
 			setupContext: func(req *http.Request) *http.Request {
 				ctx := context.WithValue(req.Context(), middleware.UserIDKey, ownerID)
-				ctx = context.WithValue(ctx, middleware.IsAdminKey, true)
 				return req.WithContext(ctx)
 			},
-			mock:            &mockPatternUpdater{getPattern: existing},
+			mock:            &mockPatternUpdater{},
 			wantStatus:      http.StatusBadRequest,
 			wantBodyContain: "Invalid request body",
 		},
+		{
+			name: "End before start",
+			setupRequest: func() *http.Request {
+				req := httptest.NewRequest(http.MethodPut, "/availability/patterns/"+patternID.String(),
+					strings.NewReader(`{"day_of_week":4,"start_time":"2025-06-01T12:00:00Z","end_time":"2025-06-01T10:00:00Z"}`))
+				req = mux.SetURLVars(req, map[string]string{"id": patternID.String()})
+				return req
+			},
+			setupContext: func(req *http.Request) *http.Request {
+				ctx := context.WithValue(req.Context(), middleware.UserIDKey, ownerID)
+				return req.WithContext(ctx)
+			},
+			mock:            &mockPatternUpdater{},
+			wantStatus:      http.StatusBadRequest,
+			wantBodyContain: `{"field":"end_time","message":"must be after start_time"}`,
+			checkUpdate: func(t *testing.T, m *mockPatternUpdater) {
+				if m.calledUpdate {
+					t.Error("expected UpdatePattern not to be called")
+				}
+			},
+		},
 		{
 			name: "Update error",
 			setupRequest: func() *http.Request {
@@ -218,15 +299,13 @@ func TestUpdateAvailabilityPatternHandler(t *testing.T) {
 			},
 			setupContext: func(req *http.Request) *http.Request {
 				ctx := context.WithValue(req.Context(), middleware.UserIDKey, ownerID)
-				ctx = context.WithValue(ctx, middleware.IsAdminKey, true)
 				return req.WithContext(ctx)
 			},
 			mock: &mockPatternUpdater{
-				getPattern: existing,
-				updateErr:  errors.New("boom"),
+				updateErr: errors.New("boom"),
 			},
 			wantStatus:      http.StatusInternalServerError,
-			wantBodyContain: "Unable to update availability pattern",
+			wantBodyContain: "Internal server error",
 		},
 	}
 
@@ -234,6 +313,7 @@ func TestUpdateAvailabilityPatternHandler(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 
 			req := tt.setupRequest()
+			req.Header.Set("Content-Type", "application/json")
 			req = tt.setupContext(req)
 
 			rr := httptest.NewRecorder()
@@ -3,11 +3,10 @@ package handlers
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"errors"
 	"net/http"
-	"strings"
 
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/apperr"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/middleware"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/utils"
@@ -21,10 +20,10 @@ type userUpdater interface {
 }
 
 type UpdateUserRequest struct {
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
-	Email     string `json:"email"`
-	Password  string `json:"password"`
+	FirstName string `json:"first_name" validate:"required,max=100"`
+	LastName  string `json:"last_name" validate:"required,max=100"`
+	Email     string `json:"email" validate:"required,email,max=254"`
+	Password  string `json:"password" validate:"required,max=72"`
 }
 
 func UpdateUserHandler(u userUpdater) http.HandlerFunc {
@@ -38,21 +37,9 @@ func UpdateUserHandler(u userUpdater) http.HandlerFunc {
 			utils.RespondWithError(w, http.StatusUnauthorized, "Missing user in context", nil)
 			return
 		}
-		decoder := json.NewDecoder(r.Body)
 		req := UpdateUserRequest{}
-		err := decoder.Decode(&req)
-		if err != nil {
-			utils.RespondWithError(w, http.StatusBadRequest, "Invalid request body", err)
-			return
-		}
-
-		if req.FirstName == "" || req.LastName == "" {
-			utils.RespondWithError(w, http.StatusBadRequest, "First and last name required", nil)
-			return
-		}
-
-		if req.Email == "" || req.Password == "" {
-			utils.RespondWithError(w, http.StatusBadRequest, "Email and password required", nil)
+		if err := utils.DecodeJSON(w, r, &req); err != nil {
+			utils.RespondWithProblem(w, err)
 			return
 		}
 
@@ -74,8 +61,8 @@ func UpdateUserHandler(u userUpdater) http.HandlerFunc {
 			switch {
 			case errors.Is(err, sql.ErrNoRows):
 				utils.RespondWithError(w, http.StatusNotFound, "User not found", nil)
-			case strings.Contains(err.Error(), "UNIQUE constraint failed: users.email"):
-				utils.RespondWithError(w, http.StatusBadRequest, "Email already in use", nil)
+			case apperr.KindOf(err) == apperr.KindConflict:
+				utils.RespondWithProblem(w, apperr.Wrap(apperr.KindConflict, "Email already in use", err))
 			default:
 				utils.RespondWithError(w, http.StatusInternalServerError, "Failed to update user", err)
 			}
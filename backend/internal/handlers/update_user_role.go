@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type userRoleUpdater interface {
+	ExecTx(ctx context.Context, fn func(db.Querier) error) error
+}
+
+type UpdateUserRoleRequest struct {
+	UserRole string `json:"user_role" validate:"required,oneof=user provider admin"`
+}
+
+// UpdateUserRoleHandler changes a user's role. Access tokens carry the role,
+// so every one issued to the user so far is rejected; their next refresh
+// picks up the new role.
+func UpdateUserRoleHandler(q userRoleUpdater, tokens Tokens) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := mux.Vars(r)["id"]
+		if !ok {
+			utils.RespondWithError(w, http.StatusBadRequest, "Missing user ID", nil)
+			return
+		}
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid user ID", err)
+			return
+		}
+
+		req := UpdateUserRoleRequest{}
+		if err := utils.DecodeJSON(w, r, &req); err != nil {
+			utils.RespondWithProblem(w, err)
+			return
+		}
+
+		var rows int64
+		err = q.ExecTx(r.Context(), func(tx db.Querier) error {
+			rows, err = tx.UpdateUserRole(r.Context(), db.UpdateUserRoleParams{
+				UserRole: req.UserRole,
+				ID:       userID,
+			})
+			if err != nil || rows == 0 {
+				return err
+			}
+			_, err = tx.RevokeUserTokens(r.Context(), db.RevokeUserTokensParams{
+				// The last access token issued before now expires by then.
+				ExpiresAt: time.Now().Add(tokens.AccessTTL),
+				UserID:    userID,
+			})
+			return err
+		})
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Unable to update user role", err)
+			return
+		}
+		if rows == 0 {
+			utils.RespondWithError(w, http.StatusNotFound, "User not found", nil)
+			return
+		}
+
+		utils.RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+			"id":        userID,
+			"user_role": req.UserRole,
+		})
+	}
+}
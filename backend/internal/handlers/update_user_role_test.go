@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type mockRoleUpdater struct {
+	db.Querier
+	got     db.UpdateUserRoleParams
+	rows    int64
+	err     error
+	revoked []db.RevokeUserTokensParams
+}
+
+func (m *mockRoleUpdater) ExecTx(ctx context.Context, fn func(db.Querier) error) error {
+	return fn(m)
+}
+
+func (m *mockRoleUpdater) UpdateUserRole(ctx context.Context, arg db.UpdateUserRoleParams) (int64, error) {
+	m.got = arg
+	return m.rows, m.err
+}
+
+func (m *mockRoleUpdater) RevokeUserTokens(ctx context.Context, arg db.RevokeUserTokensParams) (int64, error) {
+	m.revoked = append(m.revoked, arg)
+	return 1, nil
+}
+
+func TestUpdateUserRoleHandler(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name         string
+		idVar        string
+		body         string
+		rows         int64
+		mockErr      error
+		wantStatus   int
+		wantContains string
+	}{
+		{
+			name:       "Promote to provider",
+			idVar:      userID.String(),
+			body:       `{"user_role":"provider"}`,
+			rows:       1,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:         "Invalid user ID",
+			idVar:        "not-a-uuid",
+			body:         `{"user_role":"provider"}`,
+			wantStatus:   http.StatusBadRequest,
+			wantContains: "Invalid user ID",
+		},
+		{
+			name:         "Invalid request body",
+			idVar:        userID.String(),
+			body:         `{ not json`,
+			wantStatus:   http.StatusBadRequest,
+			wantContains: "Invalid request body",
+		},
+		{
+			name:         "Unknown role",
+			idVar:        userID.String(),
+			body:         `{"user_role":"superuser"}`,
+			wantStatus:   http.StatusBadRequest,
+			wantContains: `{"field":"user_role","message":"must be one of: user, provider, admin"}`,
+		},
+		{
+			name:         "User not found",
+			idVar:        userID.String(),
+			body:         `{"user_role":"admin"}`,
+			rows:         0,
+			wantStatus:   http.StatusNotFound,
+			wantContains: "User not found",
+		},
+		{
+			name:         "DB error",
+			idVar:        userID.String(),
+			body:         `{"user_role":"admin"}`,
+			mockErr:      errors.New("db down"),
+			wantStatus:   http.StatusInternalServerError,
+			wantContains: "Unable to update user role",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockRoleUpdater{rows: tt.rows, err: tt.mockErr}
+			tokens := Tokens{AccessTTL: 15 * time.Minute}
+			handler := UpdateUserRoleHandler(mock, tokens)
+
+			req := httptest.NewRequest(http.MethodPut, "/api/admin/users/"+tt.idVar+"/role", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req = mux.SetURLVars(req, map[string]string{"id": tt.idVar})
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d; body=%q", tt.wantStatus, rr.Code, rr.Body.String())
+			}
+			if tt.wantContains != "" && !strings.Contains(rr.Body.String(), tt.wantContains) {
+				t.Errorf("expected body to contain %q, got %q", tt.wantContains, rr.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK && (mock.got.ID != userID || mock.got.UserRole != "provider") {
+				t.Errorf("UpdateUserRole called with %+v", mock.got)
+			}
+			// Only a role that was changed revokes the user's access tokens.
+			if tt.wantStatus == http.StatusOK {
+				if len(mock.revoked) != 1 || mock.revoked[0].UserID != userID || time.Until(mock.revoked[0].ExpiresAt) <= 0 {
+					t.Errorf("RevokeUserTokens called with %+v", mock.revoked)
+				}
+			} else if len(mock.revoked) != 0 {
+				t.Errorf("tokens revoked for a failed role change: %+v", mock.revoked)
+			}
+		})
+	}
+}
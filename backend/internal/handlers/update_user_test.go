@@ -30,7 +30,7 @@ func (m *mockUpdateQueries) UpdateUser(ctx context.Context, arg db.UpdateUserPar
 		return errors.New("Update failed")
 	}
 	if arg.Email == usedEmail {
-		return errors.New("UNIQUE constraint failed: users.email")
+		return errEmailTaken
 	}
 	return nil
 }
@@ -46,7 +46,7 @@ func (m *mockUpdateQueries) CreateUser(_ context.Context, _ db.CreateUserParams)
 func (m *mockUpdateQueries) DeleteUser(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
-func (m *mockUpdateQueries) ListUsers(ctx context.Context) ([]db.User, error) {
+func (m *mockUpdateQueries) ListUsers(ctx context.Context, arg db.ListUsersParams) ([]db.User, error) {
 	return []db.User{}, nil
 }
 
@@ -139,7 +139,7 @@ func TestUpdateUserHandler(t *testing.T) {
 			},
 			mockUpdate:       &mockUpdateQueries{},
 			expectedCode:     http.StatusBadRequest,
-			expectedContains: "Email and password required",
+			expectedContains: `{"field":"email","message":"is required"}`,
 			injectUserID:     true,
 			shouldFailHash:   false,
 		},
@@ -153,7 +153,7 @@ func TestUpdateUserHandler(t *testing.T) {
 			},
 			mockUpdate:       &mockUpdateQueries{},
 			expectedCode:     http.StatusBadRequest,
-			expectedContains: "Email and password required",
+			expectedContains: `{"field":"password","message":"is required"}`,
 			injectUserID:     true,
 			shouldFailHash:   false,
 		},
@@ -166,7 +166,7 @@ func TestUpdateUserHandler(t *testing.T) {
 			},
 			mockUpdate:       &mockUpdateQueries{},
 			expectedCode:     http.StatusBadRequest,
-			expectedContains: "First and last name required",
+			expectedContains: `{"field":"first_name","message":"is required"}`,
 			injectUserID:     true,
 			shouldFailHash:   false,
 		},
@@ -179,7 +179,7 @@ func TestUpdateUserHandler(t *testing.T) {
 			},
 			mockUpdate:       &mockUpdateQueries{},
 			expectedCode:     http.StatusBadRequest,
-			expectedContains: "First and last name required",
+			expectedContains: `{"field":"last_name","message":"is required"}`,
 			injectUserID:     true,
 			shouldFailHash:   false,
 		},
@@ -224,7 +224,7 @@ func TestUpdateUserHandler(t *testing.T) {
 				Password:  "strongpassword",
 			},
 			mockUpdate:       &mockUpdateQueries{},
-			expectedCode:     http.StatusBadRequest,
+			expectedCode:     http.StatusConflict,
 			expectedContains: "Email already in use",
 			injectUserID:     true,
 			shouldFailHash:   false,
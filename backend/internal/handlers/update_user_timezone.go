@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/middleware"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/utils"
+)
+
+type userTimezoneUpdater interface {
+	UpdateUserTimezone(ctx context.Context, arg db.UpdateUserTimezoneParams) (int64, error)
+}
+
+type UpdateUserTimezoneRequest struct {
+	Timezone string `json:"timezone" validate:"required,timezone"`
+}
+
+// UpdateUserTimezoneHandler sets the caller's IANA timezone. A provider's
+// availability patterns are read in it.
+func UpdateUserTimezoneHandler(q userTimezoneUpdater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := middleware.UserIDFromContext(r.Context())
+		if !ok {
+			utils.RespondWithError(w, http.StatusUnauthorized, "Missing user in context", nil)
+			return
+		}
+
+		req := UpdateUserTimezoneRequest{}
+		if err := utils.DecodeJSON(w, r, &req); err != nil {
+			utils.RespondWithProblem(w, err)
+			return
+		}
+
+		rows, err := q.UpdateUserTimezone(r.Context(), db.UpdateUserTimezoneParams{
+			Timezone: req.Timezone,
+			ID:       userID,
+		})
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Unable to update timezone", err)
+			return
+		}
+		if rows == 0 {
+			utils.RespondWithError(w, http.StatusNotFound, "User not found", nil)
+			return
+		}
+
+		utils.RespondWithJSON(w, http.StatusOK, map[string]interface{}{
+			"id":       userID,
+			"timezone": req.Timezone,
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/middleware"
+	"github.com/google/uuid"
+)
+
+type mockTimezoneUpdater struct {
+	got  db.UpdateUserTimezoneParams
+	rows int64
+	err  error
+}
+
+func (m *mockTimezoneUpdater) UpdateUserTimezone(ctx context.Context, arg db.UpdateUserTimezoneParams) (int64, error) {
+	m.got = arg
+	return m.rows, m.err
+}
+
+func TestUpdateUserTimezoneHandler(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name         string
+		body         string
+		rows         int64
+		mockErr      error
+		wantStatus   int
+		wantContains string
+	}{
+		{
+			name:         "Set timezone",
+			body:         `{"timezone":"America/New_York"}`,
+			rows:         1,
+			wantStatus:   http.StatusOK,
+			wantContains: `"timezone":"America/New_York"`,
+		},
+		{
+			name:         "Unknown timezone",
+			body:         `{"timezone":"Eastern"}`,
+			wantStatus:   http.StatusBadRequest,
+			wantContains: `{"field":"timezone","message":"must be an IANA timezone name"}`,
+		},
+		{
+			name:         "Missing timezone",
+			body:         `{}`,
+			wantStatus:   http.StatusBadRequest,
+			wantContains: `{"field":"timezone","message":"is required"}`,
+		},
+		{
+			name:         "User not found",
+			body:         `{"timezone":"Europe/Berlin"}`,
+			rows:         0,
+			wantStatus:   http.StatusNotFound,
+			wantContains: "User not found",
+		},
+		{
+			name:         "DB error",
+			body:         `{"timezone":"Europe/Berlin"}`,
+			mockErr:      errors.New("db down"),
+			wantStatus:   http.StatusInternalServerError,
+			wantContains: "Unable to update timezone",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockTimezoneUpdater{rows: tt.rows, err: tt.mockErr}
+			handler := UpdateUserTimezoneHandler(mock)
+
+			req := httptest.NewRequest(http.MethodPut, "/api/users/me/timezone", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, userID))
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d; body=%q", tt.wantStatus, rr.Code, rr.Body.String())
+			}
+			if tt.wantContains != "" && !strings.Contains(rr.Body.String(), tt.wantContains) {
+				t.Errorf("expected body to contain %q, got %q", tt.wantContains, rr.Body.String())
+			}
+			if tt.wantStatus == http.StatusOK && (mock.got.ID != userID || mock.got.Timezone != "America/New_York") {
+				t.Errorf("UpdateUserTimezone called with %+v", mock.got)
+			}
+		})
+	}
+}
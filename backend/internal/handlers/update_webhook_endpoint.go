@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/utils"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type webhookEndpointUpdater interface {
+	ExecTx(ctx context.Context, fn func(db.Querier) error) error
+}
+
+type UpdateWebhookEndpointRequest struct {
+	URL         string   `json:"url" validate:"required,url"`
+	Description string   `json:"description" validate:"max=200"`
+	EventTypes  []string `json:"event_types"`
+	// Active false pauses deliveries; they queue up until it is set again.
+	Active *bool `json:"active" validate:"required"`
+}
+
+// UpdateWebhookEndpointHandler replaces an endpoint's URL, description,
+// event types and active flag. The secret is kept.
+func UpdateWebhookEndpointHandler(q webhookEndpointUpdater) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		endpointID, err := uuid.Parse(mux.Vars(r)["id"])
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "Invalid webhook endpoint ID", err)
+			return
+		}
+
+		req := UpdateWebhookEndpointRequest{}
+		if err := utils.DecodeJSON(w, r, &req); err != nil {
+			utils.RespondWithProblem(w, err)
+			return
+		}
+		eventTypes, err := checkEventTypes(req.EventTypes)
+		if err != nil {
+			utils.RespondWithProblem(w, err)
+			return
+		}
+
+		var endpoint db.WebhookEndpoint
+		err = q.ExecTx(r.Context(), func(tx db.Querier) error {
+			var err error
+			endpoint, err = tx.UpdateWebhookEndpoint(r.Context(), db.UpdateWebhookEndpointParams{
+				ID:          endpointID,
+				URL:         req.URL,
+				Description: req.Description,
+				Active:      *req.Active,
+			})
+			if err != nil {
+				return err
+			}
+			return setWebhookSubscriptions(r.Context(), tx, endpointID, eventTypes)
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			utils.RespondWithError(w, http.StatusNotFound, "Webhook endpoint not found", nil)
+			return
+		}
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "Unable to update webhook endpoint", err)
+			return
+		}
+
+		utils.RespondWithJSON(w, http.StatusOK, webhookEndpointResponse(endpoint, eventTypes))
+	}
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+func TestUpdateWebhookEndpointHandler(t *testing.T) {
+	endpointID := uuid.New()
+
+	tests := []struct {
+		name         string
+		idVar        string
+		body         string
+		mockErr      error
+		wantStatus   int
+		wantContains string
+	}{
+		{
+			name:       "Pause and resubscribe",
+			idVar:      endpointID.String(),
+			body:       `{"url":"https://crm.example.com/v2/hooks","event_types":["booking.cancelled"],"active":false}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:         "Invalid endpoint ID",
+			idVar:        "nope",
+			body:         `{"url":"https://crm.example.com/hooks","event_types":["booking.created"],"active":true}`,
+			wantStatus:   http.StatusBadRequest,
+			wantContains: "Invalid webhook endpoint ID",
+		},
+		{
+			name:         "Active is required",
+			idVar:        endpointID.String(),
+			body:         `{"url":"https://crm.example.com/hooks","event_types":["booking.created"]}`,
+			wantStatus:   http.StatusBadRequest,
+			wantContains: `{"field":"active","message":"is required"}`,
+		},
+		{
+			name:         "Endpoint not found",
+			idVar:        uuid.New().String(),
+			body:         `{"url":"https://crm.example.com/hooks","event_types":["booking.created"],"active":true}`,
+			wantStatus:   http.StatusNotFound,
+			wantContains: "Webhook endpoint not found",
+		},
+		{
+			name:         "DB error",
+			idVar:        endpointID.String(),
+			body:         `{"url":"https://crm.example.com/hooks","event_types":["booking.created"],"active":true}`,
+			mockErr:      errors.New("db down"),
+			wantStatus:   http.StatusInternalServerError,
+			wantContains: "Unable to update webhook endpoint",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := newMockWebhookQueries(db.WebhookEndpoint{ID: endpointID, URL: "https://crm.example.com/hooks", Secret: "whsec_crm", Active: true})
+			mock.subs[endpointID] = []string{"booking.created"}
+			mock.err = tt.mockErr
+
+			req := httptest.NewRequest(http.MethodPut, "/api/admin/webhooks/"+tt.idVar, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req = mux.SetURLVars(req, map[string]string{"id": tt.idVar})
+			rr := httptest.NewRecorder()
+			UpdateWebhookEndpointHandler(mock).ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d; body=%q", tt.wantStatus, rr.Code, rr.Body.String())
+			}
+			if tt.wantContains != "" && !strings.Contains(rr.Body.String(), tt.wantContains) {
+				t.Errorf("expected body to contain %q, got %q", tt.wantContains, rr.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			e := mock.endpoints[0]
+			if e.Active || e.URL != "https://crm.example.com/v2/hooks" || e.Secret != "whsec_crm" {
+				t.Errorf("endpoint = %+v", e)
+			}
+			if !reflect.DeepEqual(mock.subs[endpointID], []string{"booking.cancelled"}) {
+				t.Errorf("subscriptions = %v", mock.subs[endpointID])
+			}
+			if strings.Contains(rr.Body.String(), "whsec_") {
+				t.Errorf("response leaks the secret: %s", rr.Body.String())
+			}
+		})
+	}
+}
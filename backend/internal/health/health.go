@@ -0,0 +1,78 @@
+// Package health serves the liveness and readiness probes.
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/utils"
+)
+
+// Check reports why a dependency is not ready, or nil if it is.
+type Check func(ctx context.Context) error
+
+// Checker answers /healthz and /readyz. Readiness fails while any check
+// fails and for good once Drain has been called, so load balancers stop
+// sending traffic before the server shuts down.
+type Checker struct {
+	checks   map[string]Check
+	timeout  time.Duration
+	draining atomic.Bool
+}
+
+func NewChecker(timeout time.Duration, checks map[string]Check) *Checker {
+	return &Checker{checks: checks, timeout: timeout}
+}
+
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+type response struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// Healthz reports that the process is up. It never touches dependencies, so
+// a database outage does not get the pod restarted.
+func (c *Checker) Healthz(w http.ResponseWriter, r *http.Request) {
+	utils.RespondWithJSON(w, http.StatusOK, response{Status: "ok"})
+}
+
+func (c *Checker) Readyz(w http.ResponseWriter, r *http.Request) {
+	if c.draining.Load() {
+		utils.RespondWithJSON(w, http.StatusServiceUnavailable, response{Status: "draining"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), c.timeout)
+	defer cancel()
+
+	type result struct {
+		name string
+		err  error
+	}
+	results := make(chan result, len(c.checks))
+	for name, check := range c.checks {
+		go func() {
+			results <- result{name, check(ctx)}
+		}()
+	}
+
+	resp := response{Status: "ok", Checks: make(map[string]string, len(c.checks))}
+	code := http.StatusOK
+	for range c.checks {
+		res := <-results
+		if res.err != nil {
+			resp.Status = "unavailable"
+			resp.Checks[res.name] = res.err.Error()
+			code = http.StatusServiceUnavailable
+			continue
+		}
+		resp.Checks[res.name] = "ok"
+	}
+
+	utils.RespondWithJSON(w, code, resp)
+}
@@ -0,0 +1,108 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestChecker(t *testing.T) {
+	ok := func(context.Context) error { return nil }
+	slow := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		checks     map[string]Check
+		drain      bool
+		wantStatus int
+		wantBody   response
+	}{
+		{
+			name:       "Healthz ignores failing checks",
+			path:       "/healthz",
+			checks:     map[string]Check{"database": func(context.Context) error { return errors.New("down") }},
+			wantStatus: http.StatusOK,
+			wantBody:   response{Status: "ok"},
+		},
+		{
+			name:       "Ready",
+			path:       "/readyz",
+			checks:     map[string]Check{"database": ok, "migrations": ok},
+			wantStatus: http.StatusOK,
+			wantBody:   response{Status: "ok", Checks: map[string]string{"database": "ok", "migrations": "ok"}},
+		},
+		{
+			name: "Migrations behind",
+			path: "/readyz",
+			checks: map[string]Check{
+				"database":   ok,
+				"migrations": func(context.Context) error { return errors.New("schema is at version 1, want 2") },
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody: response{Status: "unavailable", Checks: map[string]string{
+				"database":   "ok",
+				"migrations": "schema is at version 1, want 2",
+			}},
+		},
+		{
+			name:       "Check times out",
+			path:       "/readyz",
+			checks:     map[string]Check{"database": slow},
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   response{Status: "unavailable", Checks: map[string]string{"database": "context deadline exceeded"}},
+		},
+		{
+			name:       "Draining",
+			path:       "/readyz",
+			checks:     map[string]Check{"database": ok},
+			drain:      true,
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   response{Status: "draining"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewChecker(50*time.Millisecond, tt.checks)
+			if tt.drain {
+				c.Drain()
+			}
+
+			mux := http.NewServeMux()
+			mux.HandleFunc("/healthz", c.Healthz)
+			mux.HandleFunc("/readyz", c.Readyz)
+			srv := httptest.NewServer(mux)
+			defer srv.Close()
+
+			resp, err := srv.Client().Get(srv.URL + tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+			var got response
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Status != tt.wantBody.Status || len(got.Checks) != len(tt.wantBody.Checks) {
+				t.Fatalf("got %+v, want %+v", got, tt.wantBody)
+			}
+			for name, want := range tt.wantBody.Checks {
+				if got.Checks[name] != want {
+					t.Errorf("check %s = %q, want %q", name, got.Checks[name], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+// Package jobs runs background work on a fixed schedule inside the server.
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Every runs job straight away and then every interval until ctx is
+// cancelled. A failing run is logged and the schedule carries on; runs never
+// overlap, so a slow run delays the next one rather than stacking up.
+func Every(ctx context.Context, name string, interval time.Duration, logger *slog.Logger, job func(context.Context) error) {
+	logger = logger.With("job", name)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		start := time.Now()
+		if err := job(ctx); err != nil && ctx.Err() == nil {
+			logger.Error("Job failed", "err", err, "duration", time.Since(start))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Package logging builds the backend's slog logger and carries a
+// request-scoped logger through the context of each HTTP request.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/config"
+)
+
+// New returns a logger writing to w. With RedactPII set, attributes that
+// name credentials or contact details are masked, and email addresses are
+// masked wherever they appear in string and error values.
+func New(w io.Writer, opts config.LogConfig) (*slog.Logger, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(opts.Level)); err != nil {
+		return nil, fmt.Errorf("log level %q: %w", opts.Level, err)
+	}
+
+	hopts := &slog.HandlerOptions{Level: level}
+	if opts.RedactPII {
+		hopts.ReplaceAttr = redact
+	}
+
+	switch strings.ToLower(opts.Format) {
+	case "", "json":
+		return slog.New(slog.NewJSONHandler(w, hopts)), nil
+	case "text":
+		return slog.New(slog.NewTextHandler(w, hopts)), nil
+	default:
+		return nil, fmt.Errorf("log format %q: want json or text", opts.Format)
+	}
+}
+
+const redacted = "[REDACTED]"
+
+var sensitiveKeys = map[string]bool{
+	"email":         true,
+	"password":      true,
+	"password_hash": true,
+	"token":         true,
+	"refresh_token": true,
+	"authorization": true,
+	"cookie":        true,
+	"secret":        true,
+	"first_name":    true,
+	"last_name":     true,
+}
+
+var emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+func redact(_ []string, a slog.Attr) slog.Attr {
+	if sensitiveKeys[strings.ToLower(a.Key)] {
+		return slog.String(a.Key, redacted)
+	}
+	switch v := a.Value.Any().(type) {
+	case string:
+		if emailPattern.MatchString(v) {
+			return slog.String(a.Key, emailPattern.ReplaceAllString(v, redacted))
+		}
+	case error:
+		if s := v.Error(); emailPattern.MatchString(s) {
+			return slog.String(a.Key, emailPattern.ReplaceAllString(s, redacted))
+		}
+	}
+	return a
+}
+
+type ctxKey struct{}
+
+// entry is shared between the logging middleware, which writes the access
+// log, and everything further down the chain, which may add attributes.
+type entry struct {
+	logger *slog.Logger
+}
+
+// FromContext returns the request-scoped logger, or slog.Default outside a
+// request.
+func FromContext(ctx context.Context) *slog.Logger {
+	if e, ok := ctx.Value(ctxKey{}).(*entry); ok {
+		return e.logger
+	}
+	return slog.Default()
+}
+
+// FromResponseWriter returns the logger of the request w is answering,
+// looking through writers wrapped by later middleware. It lets helpers that
+// only see the writer, such as utils.RespondWithError, log with the request
+// ID attached.
+func FromResponseWriter(w http.ResponseWriter) *slog.Logger {
+	for {
+		switch rw := w.(type) {
+		case *responseWriter:
+			return rw.entry.logger
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = rw.Unwrap()
+		default:
+			return slog.Default()
+		}
+	}
+}
+
+// SetUserID attaches the authenticated user to the request's logger and
+// access log line.
+func SetUserID(ctx context.Context, userID string) {
+	if e, ok := ctx.Value(ctxKey{}).(*entry); ok {
+		e.logger = e.logger.With("user_id", userID)
+	}
+}
@@ -0,0 +1,99 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/config"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    config.LogConfig
+		wantErr bool
+		want    string
+	}{
+		{name: "JSON", opts: config.LogConfig{Level: "info", Format: "json"}, want: `"msg":"hello"`},
+		{name: "Text", opts: config.LogConfig{Level: "info", Format: "text"}, want: "msg=hello"},
+		{name: "Debug hidden at warn", opts: config.LogConfig{Level: "warn", Format: "json"}, want: ""},
+		{name: "Bad level", opts: config.LogConfig{Level: "loud"}, wantErr: true},
+		{name: "Bad format", opts: config.LogConfig{Level: "info", Format: "xml"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger, err := New(&buf, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			logger.Info("hello")
+			if tt.want == "" && buf.Len() != 0 {
+				t.Errorf("expected no output, got %q", buf.String())
+			}
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("output %q does not contain %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRedaction(t *testing.T) {
+	tests := []struct {
+		name      string
+		redact    bool
+		args      []any
+		wantGone  []string
+		wantStill []string
+	}{
+		{
+			name:      "Sensitive keys masked",
+			redact:    true,
+			args:      []any{"email", "jo@example.com", "password", "hunter2", "Authorization", "Bearer abc", "user_id", "42"},
+			wantGone:  []string{"jo@example.com", "hunter2", "Bearer abc"},
+			wantStill: []string{`"user_id":"42"`, redacted},
+		},
+		{
+			name:      "Emails inside values masked",
+			redact:    true,
+			args:      []any{"err", errors.New(`duplicate key (email)=(jo@example.com)`), "note", "ask jo@example.com"},
+			wantGone:  []string{"jo@example.com"},
+			wantStill: []string{"duplicate key (email)=(" + redacted + ")", "ask " + redacted},
+		},
+		{
+			name:      "Redaction off",
+			redact:    false,
+			args:      []any{"email", "jo@example.com"},
+			wantStill: []string{"jo@example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger, err := New(&buf, config.LogConfig{Level: "info", Format: "json", RedactPII: tt.redact})
+			if err != nil {
+				t.Fatal(err)
+			}
+			logger.Info("event", tt.args...)
+
+			out := buf.String()
+			for _, s := range tt.wantGone {
+				if strings.Contains(out, s) {
+					t.Errorf("output leaks %q: %s", s, out)
+				}
+			}
+			for _, s := range tt.wantStill {
+				if !strings.Contains(out, s) {
+					t.Errorf("output missing %q: %s", s, out)
+				}
+			}
+		})
+	}
+}
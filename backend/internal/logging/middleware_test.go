@@ -0,0 +1,141 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		requestID  string
+		handler    http.HandlerFunc
+		wantStatus int
+		wantBytes  int
+		wantLevel  string
+		wantUser   string
+		keepID     bool
+	}{
+		{
+			name:       "Implicit 200",
+			handler:    func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("hello")) },
+			wantStatus: http.StatusOK,
+			wantBytes:  5,
+			wantLevel:  "INFO",
+		},
+		{
+			name:      "Incoming request ID kept",
+			requestID: "abc-123",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			},
+			wantStatus: http.StatusNoContent,
+			wantLevel:  "INFO",
+			keepID:     true,
+		},
+		{
+			name:      "Unsafe request ID replaced",
+			requestID: "bad id\nwith newline",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+			wantLevel:  "INFO",
+		},
+		{
+			name: "Server error with user",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				SetUserID(r.Context(), "user-1")
+				FromContext(r.Context()).Info("inside")
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte("x"))
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantBytes:  1,
+			wantLevel:  "ERROR",
+			wantUser:   "user-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+			r := mux.NewRouter()
+			r.Use(Middleware(logger))
+			r.Handle("/items/{id}", tt.handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/items/7", nil)
+			if tt.requestID != "" {
+				req.Header.Set(RequestIDHeader, tt.requestID)
+			}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			id := rec.Header().Get(RequestIDHeader)
+			if id == "" || (tt.keepID && id != tt.requestID) || (!tt.keepID && id == tt.requestID) {
+				t.Errorf("response request ID = %q (incoming %q)", id, tt.requestID)
+			}
+
+			var lines []map[string]any
+			dec := json.NewDecoder(&buf)
+			for dec.More() {
+				var m map[string]any
+				if err := dec.Decode(&m); err != nil {
+					t.Fatal(err)
+				}
+				lines = append(lines, m)
+			}
+			access := lines[len(lines)-1]
+
+			if access["request_id"] != id {
+				t.Errorf("logged request_id = %v, want %q", access["request_id"], id)
+			}
+			if access["status"] != float64(tt.wantStatus) || access["bytes"] != float64(tt.wantBytes) {
+				t.Errorf("logged status/bytes = %v/%v", access["status"], access["bytes"])
+			}
+			if access["level"] != tt.wantLevel {
+				t.Errorf("level = %v, want %s", access["level"], tt.wantLevel)
+			}
+			if access["route"] != "/items/{id}" || access["path"] != "/items/7" {
+				t.Errorf("route/path = %v/%v", access["route"], access["path"])
+			}
+			if tt.wantUser != "" {
+				for _, l := range lines {
+					if l["user_id"] != tt.wantUser || l["request_id"] != id {
+						t.Errorf("line %v lacks user_id or request_id", l)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestFromResponseWriter(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	h := Middleware(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromResponseWriter(w).Warn("from writer")
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "req-9")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	if !bytes.Contains(buf.Bytes(), []byte(`"msg":"from writer","request_id":"req-9"`)) {
+		t.Errorf("writer logger lacks request ID: %s", buf.String())
+	}
+	if FromResponseWriter(httptest.NewRecorder()) != slog.Default() {
+		t.Error("plain writers should fall back to slog.Default")
+	}
+}
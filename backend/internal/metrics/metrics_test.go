@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"database/sql"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	_ "github.com/jackc/pgx/v4/stdlib"
+)
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("scrape status %d", rec.Code)
+	}
+	body, _ := io.ReadAll(rec.Body)
+	return string(body)
+}
+
+func TestMiddleware(t *testing.T) {
+	m := New()
+	r := mux.NewRouter()
+	r.Use(m.Middleware)
+	r.HandleFunc("/api/bookings/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}).Methods("GET")
+	r.NotFoundHandler = m.Middleware(http.NotFoundHandler())
+
+	for _, path := range []string{"/api/bookings/1", "/api/bookings/2", "/nowhere"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	out := scrape(t, m)
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Route template", `booking_app_http_request_duration_seconds_count{code="404",method="GET",route="/api/bookings/{id}"} 2`},
+		{"Unmatched", `booking_app_http_request_duration_seconds_count{code="404",method="GET",route="unmatched"} 1`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !strings.Contains(out, tt.want) {
+				t.Errorf("scrape missing %q", tt.want)
+			}
+		})
+	}
+	if strings.Contains(out, `route="/api/bookings/1"`) {
+		t.Error("raw paths must not become labels")
+	}
+}
+
+func TestBookingCounters(t *testing.T) {
+	m := New()
+	m.BookingCreated()
+	m.BookingCreated()
+	m.BookingCancelled()
+	m.BookingRescheduled()
+	m.BookingConflict()
+
+	out := scrape(t, m)
+	for _, want := range []string{
+		"booking_app_bookings_created_total 2",
+		"booking_app_bookings_cancelled_total 1",
+		"booking_app_bookings_rescheduled_total 1",
+		"booking_app_booking_conflicts_total 1",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("scrape missing %q", want)
+		}
+	}
+}
+
+func TestRegisterDB(t *testing.T) {
+	m := New()
+	// sql.Open does not connect, so Stats works without a server.
+	db, err := sql.Open("pgx", "postgres://localhost/unused")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	m.RegisterDB("booking_app", db)
+
+	out := scrape(t, m)
+	for _, want := range []string{
+		`go_sql_max_open_connections{db_name="booking_app"}`,
+		`go_sql_in_use_connections{db_name="booking_app"}`,
+		`go_sql_wait_count_total{db_name="booking_app"}`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("scrape missing %q", want)
+		}
+	}
+}
@@ -3,9 +3,12 @@ package middleware
 import (
 	"context"
 	"net/http"
-	"os"
 	"strings"
+	"time"
 
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/auth"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/logging"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/utils"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
@@ -15,12 +18,30 @@ type contextKey string
 
 const UserIDKey contextKey = "user_id"
 const IsAdminKey contextKey = "is_admin"
+const TokenIDKey contextKey = "token_id"
+const TokenExpiryKey contextKey = "token_expiry"
+
+// TokenRevocationChecker reports whether an access token has been revoked
+// before its expiry, either by logging out or by an admin revoking every
+// token its user holds.
+type TokenRevocationChecker interface {
+	IsAccessTokenRevoked(ctx context.Context, arg db.IsAccessTokenRevokedParams) (bool, error)
+}
 
 var ParseTokenFn = func(tokenString string, keyFunc jwt.Keyfunc) (*jwt.Token, error) {
-	return jwt.Parse(tokenString, keyFunc)
+	return jwt.Parse(tokenString, keyFunc, jwt.WithExpirationRequired())
+}
+
+// NewAuthMiddleware validates the bearer token against keys and, when revoked
+// is non-nil, rejects tokens without a jti and tokens revoked by jti or by
+// user. A token without an iat counts as issued before any revocation.
+func NewAuthMiddleware(keys auth.KeyProvider, revoked TokenRevocationChecker) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return authHandler(next, keys, revoked)
+	}
 }
 
-func AuthMiddleware(next http.Handler) http.Handler {
+func authHandler(next http.Handler, keys auth.KeyProvider, revoked TokenRevocationChecker) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
 		authHeader := r.Header.Get("Authorization")
@@ -30,18 +51,7 @@ func AuthMiddleware(next http.Handler) http.Handler {
 		}
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		secret := os.Getenv("JWT_SECRET")
-		if secret == "" {
-			utils.RespondWithError(w, http.StatusInternalServerError, "Missing JWT_SECRET", nil)
-			return
-		}
-
-		token, err := ParseTokenFn(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(secret), nil
-		})
+		token, err := ParseTokenFn(tokenString, keys.Keyfunc)
 		if err != nil || !token.Valid {
 			utils.RespondWithError(w, http.StatusUnauthorized, "Invalid or expired token", err)
 			return
@@ -63,11 +73,49 @@ func AuthMiddleware(next http.Handler) http.Handler {
 			utils.RespondWithError(w, http.StatusUnauthorized, "Invalid user ID format", err)
 			return
 		}
-		UserRole, _ := claims["user_role"].(string)
-		isAdmin := (UserRole == "admin")
+
+		jti, _ := claims["jti"].(string)
+		if revoked != nil {
+			if jti == "" {
+				utils.RespondWithError(w, http.StatusUnauthorized, "Missing token ID", nil)
+				return
+			}
+			var issuedAt time.Time
+			if iat, err := claims.GetIssuedAt(); err == nil && iat != nil {
+				issuedAt = iat.Time
+			}
+			isRevoked, err := revoked.IsAccessTokenRevoked(r.Context(), db.IsAccessTokenRevokedParams{
+				Jti:      jti,
+				UserID:   userUUID,
+				IssuedAt: issuedAt,
+			})
+			if err != nil {
+				utils.RespondWithError(w, http.StatusInternalServerError, "Unable to verify token", err)
+				return
+			}
+			if isRevoked {
+				utils.RespondWithError(w, http.StatusUnauthorized, "Token has been revoked", nil)
+				return
+			}
+		}
+
+		userRole, _ := claims["user_role"].(string)
+		if !ValidRole(userRole) {
+			userRole = RoleUser
+		}
+		isAdmin := (userRole == RoleAdmin)
+
+		logging.SetUserID(r.Context(), userUUID.String())
 
 		ctx := context.WithValue(r.Context(), UserIDKey, userUUID)
+		ctx = context.WithValue(ctx, RoleKey, userRole)
 		ctx = context.WithValue(ctx, IsAdminKey, isAdmin)
+		if jti != "" {
+			ctx = context.WithValue(ctx, TokenIDKey, jti)
+		}
+		if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+			ctx = context.WithValue(ctx, TokenExpiryKey, exp.Time)
+		}
 		next.ServeHTTP(w, r.WithContext(ctx))
 
 	})
@@ -84,3 +132,14 @@ func UserIDFromContext(ctx context.Context) (uuid.UUID, bool) {
 	id, ok := v.(uuid.UUID)
 	return id, ok
 }
+
+// TokenFromContext returns the jti and expiry of the access token that
+// authenticated the request.
+func TokenFromContext(ctx context.Context) (string, time.Time, bool) {
+	jti, ok := ctx.Value(TokenIDKey).(string)
+	if !ok {
+		return "", time.Time{}, false
+	}
+	exp, _ := ctx.Value(TokenExpiryKey).(time.Time)
+	return jti, exp, true
+}
@@ -4,13 +4,15 @@ import (
 	"context"
 	"crypto/rand"
 	"crypto/rsa"
+	"errors"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/auth"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
@@ -56,10 +58,21 @@ func TestAuthMiddleware(t *testing.T) {
 		t.Fatalf("Failed to sign RS256 token: %v", err)
 	}
 
+	unknownKid := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": uuid.New().String(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	unknownKid.Header["kid"] = "retired-long-ago"
+	unknownKidString, err := unknownKid.SignedString([]byte("testsecret"))
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	keys := testKeys(t)
+
 	tests := []struct {
 		name             string
 		authHeader       string
-		secret           string
 		expectStatus     int
 		expectContains   string
 		shouldFailClaims bool
@@ -67,23 +80,20 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name:             "Valid token",
 			authHeader:       "Bearer " + tokenString,
-			secret:           "testsecret",
 			expectStatus:     http.StatusOK,
 			expectContains:   "user_id is: ",
 			shouldFailClaims: false,
 		},
 		{
-			name:             "Missing JWT_SECRET",
-			authHeader:       "Bearer " + tokenString,
-			secret:           "",
-			expectStatus:     http.StatusInternalServerError,
-			expectContains:   "Missing JWT_SECRET",
+			name:             "Unknown key ID",
+			authHeader:       "Bearer " + unknownKidString,
+			expectStatus:     http.StatusUnauthorized,
+			expectContains:   "Invalid or expired token",
 			shouldFailClaims: false,
 		},
 		{
 			name:             "Wrong signing method",
 			authHeader:       "Bearer " + rsString,
-			secret:           "testsecret",
 			expectStatus:     http.StatusUnauthorized,
 			expectContains:   "Invalid or expired token",
 			shouldFailClaims: false,
@@ -91,7 +101,6 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name:             "Invalid token claims",
 			authHeader:       "Bearer " + tokenString,
-			secret:           "testsecret",
 			expectStatus:     http.StatusUnauthorized,
 			expectContains:   "Invalid token claims",
 			shouldFailClaims: true,
@@ -99,7 +108,6 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name:             "Token missing subject claims",
 			authHeader:       "Bearer " + noSubTokenString,
-			secret:           "testsecret",
 			expectStatus:     http.StatusUnauthorized,
 			expectContains:   "Missing subject claim",
 			shouldFailClaims: false,
@@ -107,7 +115,6 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name:             "Invalid user ID format",
 			authHeader:       "Bearer " + badSubTokenString,
-			secret:           "testsecret",
 			expectStatus:     http.StatusUnauthorized,
 			expectContains:   "Invalid user ID format",
 			shouldFailClaims: false,
@@ -115,7 +122,6 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name:             "Missing token",
 			authHeader:       "",
-			secret:           "testsecret",
 			expectStatus:     http.StatusUnauthorized,
 			expectContains:   "Missing or malformed token",
 			shouldFailClaims: false,
@@ -123,7 +129,6 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name:             "Invalid token",
 			authHeader:       "Bearer " + "",
-			secret:           "testsecret",
 			expectStatus:     http.StatusUnauthorized,
 			expectContains:   "Invalid or expired token",
 			shouldFailClaims: false,
@@ -131,14 +136,12 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name:             "Missing Authorization header",
 			authHeader:       "",
-			secret:           "testsecret",
 			expectStatus:     http.StatusUnauthorized,
 			shouldFailClaims: false,
 		},
 		{
 			name:             "Malformed Authorization header",
 			authHeader:       "BadFormatToken",
-			secret:           "testsecret",
 			expectStatus:     http.StatusUnauthorized,
 			shouldFailClaims: false,
 		},
@@ -146,10 +149,6 @@ func TestAuthMiddleware(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			old := os.Getenv("JWT_SECRET")
-			t.Cleanup(func() { os.Setenv("JWT_SECRET", old) })
-			os.Setenv("JWT_SECRET", tt.secret)
-
 			oldParse := ParseTokenFn
 			if tt.shouldFailClaims {
 				ParseTokenFn = func(tokenString string, keyFunc jwt.Keyfunc) (*jwt.Token, error) {
@@ -161,7 +160,7 @@ func TestAuthMiddleware(t *testing.T) {
 			}
 			defer func() { ParseTokenFn = oldParse }()
 
-			handler := AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handler := NewAuthMiddleware(keys, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				val := r.Context().Value(UserIDKey)
 				userID, ok := val.(uuid.UUID)
 				if !ok || userID == uuid.Nil {
@@ -190,6 +189,124 @@ func TestAuthMiddleware(t *testing.T) {
 	}
 }
 
+func testKeys(t *testing.T) *auth.KeySet {
+	t.Helper()
+	key, err := auth.NewHMACKey("test", []byte("testsecret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := auth.NewKeySet(time.Hour, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
+
+type stubRevocations struct {
+	revoked map[string]bool
+	// userRevokedAt mirrors user_token_revocations for every user.
+	userRevokedAt time.Time
+	err           error
+}
+
+func (s stubRevocations) IsAccessTokenRevoked(_ context.Context, arg db.IsAccessTokenRevokedParams) (bool, error) {
+	byUser := !s.userRevokedAt.IsZero() && !s.userRevokedAt.Before(arg.IssuedAt)
+	return s.revoked[arg.Jti] || byUser, s.err
+}
+
+func TestNewAuthMiddlewareRevocation(t *testing.T) {
+	keys := testKeys(t)
+
+	sign := func(claims jwt.MapClaims) string {
+		s, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("testsecret"))
+		if err != nil {
+			t.Fatalf("Failed to generate token: %v", err)
+		}
+		return s
+	}
+	exp := time.Now().Add(time.Hour).Truncate(time.Second)
+	withJTI := func(jti string) string {
+		return sign(jwt.MapClaims{"sub": uuid.New().String(), "jti": jti, "exp": exp.Unix()})
+	}
+
+	tests := []struct {
+		name           string
+		token          string
+		revocations    stubRevocations
+		expectStatus   int
+		expectContains string
+	}{
+		{
+			name:         "Active token",
+			token:        withJTI("active"),
+			revocations:  stubRevocations{revoked: map[string]bool{"other": true}},
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:           "Revoked token",
+			token:          withJTI("revoked"),
+			revocations:    stubRevocations{revoked: map[string]bool{"revoked": true}},
+			expectStatus:   http.StatusUnauthorized,
+			expectContains: "Token has been revoked",
+		},
+		{
+			name:  "Issued before an admin revoked the user's tokens",
+			token: sign(jwt.MapClaims{"sub": uuid.New().String(), "jti": "old", "iat": exp.Add(-2 * time.Hour).Unix(), "exp": exp.Unix()}),
+			revocations: stubRevocations{
+				userRevokedAt: exp.Add(-time.Hour),
+			},
+			expectStatus:   http.StatusUnauthorized,
+			expectContains: "Token has been revoked",
+		},
+		{
+			name:  "Issued after an admin revoked the user's tokens",
+			token: sign(jwt.MapClaims{"sub": uuid.New().String(), "jti": "new", "iat": exp.Add(-time.Minute).Unix(), "exp": exp.Unix()}),
+			revocations: stubRevocations{
+				userRevokedAt: exp.Add(-time.Hour),
+			},
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:           "Token without jti",
+			token:          sign(jwt.MapClaims{"sub": uuid.New().String(), "exp": exp.Unix()}),
+			expectStatus:   http.StatusUnauthorized,
+			expectContains: "Missing token ID",
+		},
+		{
+			name:           "Denylist lookup fails",
+			token:          withJTI("active"),
+			revocations:    stubRevocations{err: errors.New("db down")},
+			expectStatus:   http.StatusInternalServerError,
+			expectContains: "Unable to verify token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewAuthMiddleware(keys, tt.revocations)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				jti, gotExp, ok := TokenFromContext(r.Context())
+				if !ok || jti == "" || !gotExp.Equal(exp) {
+					http.Error(w, "token missing from context", http.StatusInternalServerError)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectStatus {
+				t.Errorf("expected status %d, got %d; body=%q", tt.expectStatus, rr.Code, rr.Body.String())
+			}
+			if tt.expectContains != "" && !strings.Contains(rr.Body.String(), tt.expectContains) {
+				t.Errorf("expected response to contain %q, got %q", tt.expectContains, rr.Body.String())
+			}
+		})
+	}
+}
+
 func TestIsAdminFromContext(t *testing.T) {
 	tests := []struct {
 		name     string
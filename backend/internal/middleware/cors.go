@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"net/http"
+	"strings"
 )
 
 // CORS wraps any handler to add the proper headers.
@@ -13,7 +14,8 @@ func CORS(allowed func(string) bool) func(http.Handler) http.Handler {
 				w.Header().Set("Access-Control-Allow-Origin", origin)
 				w.Header().Set("Vary", "Origin")
 				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-				w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+				w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Request-ID")
+				w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
 			}
 			if r.Method == http.MethodOptions {
 				w.WriteHeader(http.StatusOK)
@@ -23,3 +25,35 @@ func CORS(allowed func(string) bool) func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// AllowOrigins builds the predicate CORS expects from a list of origins. An
+// origin whose host starts with "*." matches any subdomain of the rest, over
+// the same scheme: "https://*.vercel.app" allows "https://my-app.vercel.app"
+// but not "https://vercel.app" or "http://my-app.vercel.app".
+func AllowOrigins(origins []string) func(string) bool {
+	type wildcard struct{ scheme, suffix string }
+
+	exact := make(map[string]bool, len(origins))
+	var wildcards []wildcard
+	for _, o := range origins {
+		if scheme, host, ok := strings.Cut(o, "://*."); ok {
+			wildcards = append(wildcards, wildcard{scheme + "://", "." + host})
+			continue
+		}
+		exact[o] = true
+	}
+
+	return func(origin string) bool {
+		if exact[origin] {
+			return true
+		}
+		for _, w := range wildcards {
+			host, ok := strings.CutPrefix(origin, w.scheme)
+			if ok && len(host) > len(w.suffix) && strings.HasSuffix(host, w.suffix) &&
+				!strings.ContainsAny(host, "/?#@") {
+				return true
+			}
+		}
+		return false
+	}
+}
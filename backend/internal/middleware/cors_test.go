@@ -40,7 +40,7 @@ func TestCORSHandlesPreflight(t *testing.T) {
 		t.Errorf("wrong Access-Control-Allow-Methods: got %q", methods)
 	}
 
-	if headers := resp.Header.Get("Access-Control-Allow-Headers"); headers != "Content-Type, Authorization" {
+	if headers := resp.Header.Get("Access-Control-Allow-Headers"); headers != "Content-Type, Authorization, X-Request-ID" {
 		t.Errorf("wrong Access-Control-Allow-Headers: got %q", headers)
 	}
 
@@ -88,7 +88,7 @@ func TestCORSPassesThroughNonOptions(t *testing.T) {
 		t.Errorf("wrong Access-Control-Allow-Methods: got %q", methods)
 	}
 
-	if headers := resp.Header.Get("Access-Control-Allow-Headers"); headers != "Content-Type, Authorization" {
+	if headers := resp.Header.Get("Access-Control-Allow-Headers"); headers != "Content-Type, Authorization, X-Request-ID" {
 		t.Errorf("wrong Access-Control-Allow-Headers: got %q", headers)
 	}
 
@@ -96,3 +96,31 @@ func TestCORSPassesThroughNonOptions(t *testing.T) {
 		t.Errorf("expected body to include %q, got %q", "next ran", body)
 	}
 }
+
+func TestAllowOrigins(t *testing.T) {
+	allowed := AllowOrigins([]string{"http://localhost:3000", "https://*.vercel.app"})
+
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"http://localhost:3000", true},
+		{"http://localhost:3001", false},
+		{"https://my-app.vercel.app", true},
+		{"https://preview.my-app.vercel.app", true},
+		{"https://vercel.app", false},
+		{"http://my-app.vercel.app", false},
+		{"https://evilvercel.app", false},
+		{"https://attacker.com/.vercel.app", false},
+		{"https://user@evil.com@x.vercel.app", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			if got := allowed(tt.origin); got != tt.want {
+				t.Errorf("allowed(%q) = %v, want %v", tt.origin, got, tt.want)
+			}
+		})
+	}
+}
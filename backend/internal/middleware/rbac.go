@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/utils"
+)
+
+const (
+	RoleUser     = "user"
+	RoleProvider = "provider"
+	RoleAdmin    = "admin"
+)
+
+const RoleKey contextKey = "user_role"
+
+// ValidRole reports whether role is one of the roles the app knows about.
+func ValidRole(role string) bool {
+	return role == RoleUser || role == RoleProvider || role == RoleAdmin
+}
+
+// RequireRole only lets a request through if the role placed in the context
+// by NewAuthMiddleware is one of roles. Attach it to a subrouter with Use
+// after NewAuthMiddleware.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role, ok := RoleFromContext(r.Context())
+			if !ok {
+				utils.RespondWithError(w, http.StatusUnauthorized, "Authentication required", nil)
+				return
+			}
+			if !slices.Contains(roles, role) {
+				utils.RespondWithError(w, http.StatusForbidden, "Forbidden", nil)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func RoleFromContext(ctx context.Context) (string, bool) {
+	role, ok := ctx.Value(RoleKey).(string)
+	return role, ok && role != ""
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireRole(t *testing.T) {
+	tests := []struct {
+		name         string
+		ctxRole      any
+		allowed      []string
+		expectStatus int
+	}{
+		{
+			name:         "Allowed role",
+			ctxRole:      RoleAdmin,
+			allowed:      []string{RoleAdmin},
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:         "One of several allowed roles",
+			ctxRole:      RoleProvider,
+			allowed:      []string{RoleProvider, RoleAdmin},
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:         "Role not allowed",
+			ctxRole:      RoleUser,
+			allowed:      []string{RoleProvider, RoleAdmin},
+			expectStatus: http.StatusForbidden,
+		},
+		{
+			name:         "No role in context",
+			ctxRole:      nil,
+			allowed:      []string{RoleUser},
+			expectStatus: http.StatusUnauthorized,
+		},
+		{
+			name:         "Empty role in context",
+			ctxRole:      "",
+			allowed:      []string{RoleUser},
+			expectStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			handler := RequireRole(tt.allowed...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.ctxRole != nil {
+				req = req.WithContext(context.WithValue(req.Context(), RoleKey, tt.ctxRole))
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectStatus {
+				t.Errorf("expected status %d, got %d", tt.expectStatus, rr.Code)
+			}
+			if called != (tt.expectStatus == http.StatusOK) {
+				t.Errorf("next handler called = %v", called)
+			}
+		})
+	}
+}
+
+func TestValidRole(t *testing.T) {
+	for _, role := range []string{RoleUser, RoleProvider, RoleAdmin} {
+		if !ValidRole(role) {
+			t.Errorf("ValidRole(%q) = false; want true", role)
+		}
+	}
+	for _, role := range []string{"", "superuser", "Admin"} {
+		if ValidRole(role) {
+			t.Errorf("ValidRole(%q) = true; want false", role)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+// Package migrate applies the goose migrations embedded in sql/schema.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/sql/schema"
+)
+
+// NewProvider returns a goose provider for the embedded migrations. Up and
+// Down hold a Postgres advisory lock, so replicas that auto-migrate on start
+// take turns.
+func NewProvider(db *sql.DB) (*goose.Provider, error) {
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		return nil, err
+	}
+	return goose.NewProvider(goose.DialectPostgres, db, schema.FS, goose.WithSessionLocker(locker))
+}
+
+func Up(ctx context.Context, db *sql.DB, w io.Writer) error {
+	p, err := NewProvider(db)
+	if err != nil {
+		return err
+	}
+	results, err := p.Up(ctx)
+	for _, r := range results {
+		fmt.Fprintf(w, "applied %s in %s\n", path.Base(r.Source.Path), r.Duration)
+	}
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		fmt.Fprintln(w, "no pending migrations")
+	}
+	return nil
+}
+
+// Down rolls back the most recent migration.
+func Down(ctx context.Context, db *sql.DB, w io.Writer) error {
+	p, err := NewProvider(db)
+	if err != nil {
+		return err
+	}
+	r, err := p.Down(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "rolled back %s in %s\n", path.Base(r.Source.Path), r.Duration)
+	return nil
+}
+
+func Status(ctx context.Context, db *sql.DB, w io.Writer) error {
+	p, err := NewProvider(db)
+	if err != nil {
+		return err
+	}
+	statuses, err := p.Status(ctx)
+	if err != nil {
+		return err
+	}
+	for _, s := range statuses {
+		applied := "pending"
+		if s.State == goose.StateApplied {
+			applied = s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%-20s %s\n", applied, path.Base(s.Source.Path))
+	}
+	return nil
+}
+
+// Check returns an error unless every embedded migration has been applied.
+// It does not take the migration lock, so it is cheap enough for /readyz.
+func Check(ctx context.Context, db *sql.DB) error {
+	p, err := goose.NewProvider(goose.DialectPostgres, db, schema.FS)
+	if err != nil {
+		return err
+	}
+	current, target, err := p.GetVersions(ctx)
+	if err != nil {
+		return err
+	}
+	if current != target {
+		return fmt.Errorf("schema is at version %d, want %d", current, target)
+	}
+	return nil
+}
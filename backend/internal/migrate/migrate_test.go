@@ -0,0 +1,132 @@
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/config"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/sql/schema"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	files, err := fs.Glob(schema.FS, "*.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+	sort.Strings(files)
+
+	name := regexp.MustCompile(`^\d{14}_\d{3}_[a-z0-9_]+\.sql$`)
+	for i, f := range files {
+		if !name.MatchString(f) {
+			t.Errorf("%s does not match YYYYMMDDHHMMSS_NNN_name.sql", f)
+		}
+		if want := fmt.Sprintf("_%03d_", i+1); !strings.Contains(f, want) {
+			t.Errorf("%s: expected sequence %s", f, want)
+		}
+
+		body, err := fs.ReadFile(schema.FS, f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		up := strings.Index(string(body), "-- +goose Up")
+		down := strings.Index(string(body), "-- +goose Down")
+		if up < 0 || down < up {
+			t.Errorf("%s: needs a goose Up section followed by a Down section", f)
+			continue
+		}
+		if strings.TrimSpace(string(body)[down+len("-- +goose Down"):]) == "" {
+			t.Errorf("%s: Down section is empty", f)
+		}
+	}
+}
+
+// TestRoundTrip applies every migration, rolls them all back and applies
+// them again in a throwaway schema, so both directions stay runnable.
+func TestRoundTrip(t *testing.T) {
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("DATABASE_URL is not set; skipping database test")
+	}
+	ctx := context.Background()
+
+	cfg := config.Default().Database
+	cfg.URL = dbURL
+	admin, err := db.ConnectDB(ctx, cfg)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer admin.Close()
+
+	name := fmt.Sprintf("migrate_test_%d", time.Now().UnixNano())
+	if _, err := admin.ExecContext(ctx, "CREATE SCHEMA "+name); err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	defer admin.ExecContext(ctx, "DROP SCHEMA "+name+" CASCADE")
+
+	u, err := url.Parse(dbURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	q.Set("search_path", name)
+	u.RawQuery = q.Encode()
+	cfg.URL = u.String()
+	conn, err := db.ConnectDB(ctx, cfg)
+	if err != nil {
+		t.Fatalf("connect to %s: %v", name, err)
+	}
+	defer conn.Close()
+
+	files, _ := fs.Glob(schema.FS, "*.sql")
+	var out bytes.Buffer
+
+	if err := Check(ctx, conn); err == nil {
+		t.Error("Check should fail on an empty schema")
+	}
+	if err := Up(ctx, conn, &out); err != nil {
+		t.Fatalf("up: %v", err)
+	}
+	if err := Check(ctx, conn); err != nil {
+		t.Errorf("Check after up: %v", err)
+	}
+
+	out.Reset()
+	if err := Status(ctx, conn, &out); err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if strings.Contains(out.String(), "pending") {
+		t.Errorf("status after up reports pending migrations:\n%s", out.String())
+	}
+
+	for i := len(files) - 1; i >= 0; i-- {
+		if err := Down(ctx, conn, &out); err != nil {
+			t.Fatalf("down %s: %v", files[i], err)
+		}
+	}
+	var tables int
+	if err := conn.QueryRowContext(ctx,
+		"SELECT count(*) FROM information_schema.tables WHERE table_schema = $1 AND table_name <> 'goose_db_version'",
+		name).Scan(&tables); err != nil {
+		t.Fatal(err)
+	}
+	if tables != 0 {
+		t.Errorf("%d tables left after rolling everything back", tables)
+	}
+
+	if err := Up(ctx, conn, &out); err != nil {
+		t.Fatalf("up after down: %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+// Package notify emails users about their bookings.
+//
+// A Notifier renders a message for each booking notice from the templates
+// embedded in this package, attaches an iCalendar invite so the
+// appointment lands in the recipient's calendar, and hands the result to an
+// EmailSender. Senders are pluggable: SMTPSender for production, FileSender
+// for development and MemorySender for tests.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Email is a message ready to send. From and To are RFC 5322 addresses such
+// as "Jane Doe <jane@example.com>".
+type Email struct {
+	// MessageID is the Message-ID without angle brackets. A random one is
+	// used when it is empty.
+	MessageID   string
+	From        string
+	To          []string
+	Subject     string
+	Text        string
+	HTML        string
+	Attachments []Attachment
+}
+
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// EmailSender delivers an Email. Implementations must be safe for
+// concurrent use.
+type EmailSender interface {
+	Send(ctx context.Context, e Email) error
+}
+
+// Bytes encodes e as a MIME message: a text and HTML alternative, wrapped
+// with the attachments in a multipart/mixed body when there are any.
+func (e Email) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	header := func(k, v string) { fmt.Fprintf(&buf, "%s: %s\r\n", k, v) }
+	header("From", e.From)
+	header("To", strings.Join(e.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", e.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	id := e.MessageID
+	if id == "" {
+		id = fmt.Sprintf("%s@%s", uuid.New(), domain(e.From))
+	}
+	header("Message-ID", "<"+id+">")
+	header("MIME-Version", "1.0")
+
+	var alt bytes.Buffer
+	altWriter := multipart.NewWriter(&alt)
+	if err := writeQP(altWriter, "text/plain; charset=utf-8", e.Text); err != nil {
+		return nil, err
+	}
+	if err := writeQP(altWriter, "text/html; charset=utf-8", e.HTML); err != nil {
+		return nil, err
+	}
+	if err := altWriter.Close(); err != nil {
+		return nil, err
+	}
+	altType := "multipart/alternative; boundary=" + altWriter.Boundary()
+
+	if len(e.Attachments) == 0 {
+		header("Content-Type", altType)
+		buf.WriteString("\r\n")
+		buf.Write(alt.Bytes())
+		return buf.Bytes(), nil
+	}
+
+	mixed := multipart.NewWriter(&buf)
+	header("Content-Type", "multipart/mixed; boundary="+mixed.Boundary())
+	buf.WriteString("\r\n")
+	part, err := mixed.CreatePart(textproto.MIMEHeader{"Content-Type": {altType}})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(alt.Bytes()); err != nil {
+		return nil, err
+	}
+	for _, a := range e.Attachments {
+		part, err := mixed.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {a.ContentType},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeBase64(part, a.Data); err != nil {
+			return nil, err
+		}
+	}
+	if err := mixed.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeQP(w *multipart.Writer, contentType, body string) error {
+	part, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return err
+	}
+	qp := quotedprintable.NewWriter(part)
+	if _, err := io.WriteString(qp, body); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// writeBase64 writes data base64 encoded in 76 character lines, as RFC 2045
+// requires.
+func writeBase64(w io.Writer, data []byte) error {
+	enc := base64.StdEncoding.EncodeToString(data)
+	for len(enc) > 0 {
+		n := min(len(enc), 76)
+		if _, err := io.WriteString(w, enc[:n]+"\r\n"); err != nil {
+			return err
+		}
+		enc = enc[n:]
+	}
+	return nil
+}
+
+// domain returns the domain of an address for use in Message-IDs.
+func domain(addr string) string {
+	addr = strings.TrimSuffix(strings.TrimSpace(addr), ">")
+	if i := strings.LastIndex(addr, "@"); i >= 0 && i < len(addr)-1 {
+		return addr[i+1:]
+	}
+	return "localhost"
+}
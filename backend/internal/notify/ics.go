@@ -0,0 +1,116 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// iCalendar methods. A REQUEST adds or updates the event in the
+// recipient's calendar; a CANCEL removes it.
+const (
+	MethodRequest = "REQUEST"
+	MethodCancel  = "CANCEL"
+)
+
+// Invite is a single appointment as an iCalendar (RFC 5545) object.
+// Calendars match updates to the original by UID and apply them only when
+// Sequence has gone up.
+type Invite struct {
+	Method      string
+	UID         string
+	Sequence    int64
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	Organizer   mail.Address
+	Attendee    mail.Address
+	// Stamp is when the invite was created.
+	Stamp time.Time
+}
+
+// ContentType is the MIME type to attach the invite with.
+func (inv Invite) ContentType() string {
+	return "text/calendar; charset=utf-8; method=" + inv.Method
+}
+
+// Bytes encodes the invite with CRLF line endings and long lines folded.
+func (inv Invite) Bytes() []byte {
+	status := "CONFIRMED"
+	if inv.Method == MethodCancel {
+		status = "CANCELLED"
+	}
+
+	var buf bytes.Buffer
+	line := func(format string, args ...any) {
+		writeFolded(&buf, fmt.Sprintf(format, args...))
+	}
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//booking-app//notify//EN")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:%s", inv.Method)
+	line("BEGIN:VEVENT")
+	line("UID:%s", escapeText(inv.UID))
+	line("SEQUENCE:%d", inv.Sequence)
+	line("DTSTAMP:%s", icsTime(inv.Stamp))
+	line("DTSTART:%s", icsTime(inv.Start))
+	line("DTEND:%s", icsTime(inv.End))
+	line("SUMMARY:%s", escapeText(inv.Summary))
+	if inv.Description != "" {
+		line("DESCRIPTION:%s", escapeText(inv.Description))
+	}
+	line("ORGANIZER;CN=%s:mailto:%s", paramValue(inv.Organizer.Name), inv.Organizer.Address)
+	line("ATTENDEE;CN=%s;ROLE=REQ-PARTICIPANT;PARTSTAT=ACCEPTED:mailto:%s",
+		paramValue(inv.Attendee.Name), inv.Attendee.Address)
+	line("STATUS:%s", status)
+	line("END:VEVENT")
+	line("END:VCALENDAR")
+	return buf.Bytes()
+}
+
+func icsTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+// escapeText escapes a TEXT value.
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// paramValue quotes a parameter value. Quotes and control characters are
+// not allowed in one, so they are dropped.
+func paramValue(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r == '"' || r < ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, s)
+	return `"` + s + `"`
+}
+
+// writeFolded writes a content line, folding it onto continuation lines so
+// that none is longer than 75 octets. Folds never split a UTF-8 sequence.
+func writeFolded(buf *bytes.Buffer, s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		buf.WriteString(s[:cut])
+		buf.WriteString("\r\n ")
+		s = s[cut:]
+		// The leading space counts towards the next line's length.
+		limit = 74
+	}
+	buf.WriteString(s)
+	buf.WriteString("\r\n")
+}
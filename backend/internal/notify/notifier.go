@@ -0,0 +1,199 @@
+package notify
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/events"
+	"github.com/google/uuid"
+)
+
+// UserStore is what a Notifier looks recipients and providers up in.
+// *db.Store implements it.
+type UserStore interface {
+	GetUserByID(ctx context.Context, id uuid.UUID) (db.User, error)
+}
+
+// Notice is one message to send about a booking.
+type Notice struct {
+	Kind      Kind
+	BookingID uuid.UUID
+	UserID    uuid.UUID
+	// ProviderID may be uuid.Nil when the provider is unknown.
+	ProviderID       uuid.UUID
+	AppointmentStart time.Time
+	DurationMinutes  int32
+	// PreviousStart is set for Reschedule notices.
+	PreviousStart time.Time
+	// Reason is set for Cancellation notices when one was given.
+	Reason string
+	// Sequence orders the invites sent for one booking: it is the
+	// booking's Sequence, which each change to the booking raises.
+	Sequence int64
+	// Key, if set, identifies the notice across attempts to send it. It
+	// becomes the email's Message-ID, so a copy resent after an attempt
+	// that was cut short can be recognised as a duplicate.
+	Key string
+}
+
+// Notifier renders notices and sends them to the booking's owner.
+type Notifier struct {
+	store  UserStore
+	sender EmailSender
+	from   *mail.Address
+	now    func() time.Time
+}
+
+// New returns a Notifier sending from the address from. Its display name,
+// if any, is used as the app's name in messages.
+func New(store UserStore, sender EmailSender, from string) (*Notifier, error) {
+	addr, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("parse sender address: %w", err)
+	}
+	return &Notifier{store: store, sender: sender, from: addr, now: time.Now}, nil
+}
+
+// Send emails notice to the booking's owner. A notice for a user who
+// has since been deleted is dropped.
+func (n *Notifier) Send(ctx context.Context, notice Notice) error {
+	user, err := n.store.GetUserByID(ctx, notice.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("load user %s: %w", notice.UserID, err)
+	}
+	var provider string
+	if notice.ProviderID != uuid.Nil {
+		p, err := n.store.GetUserByID(ctx, notice.ProviderID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("load provider %s: %w", notice.ProviderID, err)
+		}
+		provider = strings.TrimSpace(p.FirstName + " " + p.LastName)
+	}
+
+	loc, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	appName := n.from.Name
+	if appName == "" {
+		appName = "Booking App"
+	}
+	data := Data{
+		AppName:       appName,
+		FirstName:     user.FirstName,
+		Provider:      provider,
+		Start:         notice.AppointmentStart.In(loc),
+		Minutes:       int(notice.DurationMinutes),
+		PreviousStart: notice.PreviousStart.In(loc),
+		Reason:        notice.Reason,
+	}
+	subject, text, html, err := Render(notice.Kind, data)
+	if err != nil {
+		return fmt.Errorf("render %s: %w", notice.Kind, err)
+	}
+
+	to := mail.Address{Name: strings.TrimSpace(user.FirstName + " " + user.LastName), Address: user.Email}
+	invite := Invite{
+		Method:    MethodRequest,
+		UID:       notice.BookingID.String() + "@booking-app",
+		Sequence:  notice.Sequence,
+		Start:     notice.AppointmentStart,
+		End:       notice.AppointmentStart.Add(time.Duration(notice.DurationMinutes) * time.Minute),
+		Summary:   "Appointment",
+		Organizer: *n.from,
+		Attendee:  to,
+		Stamp:     n.now(),
+	}
+	if provider != "" {
+		invite.Summary = "Appointment with " + provider
+	}
+	if notice.Kind == Cancellation {
+		invite.Method = MethodCancel
+	}
+
+	email := Email{
+		From:    n.from.String(),
+		To:      []string{to.String()},
+		Subject: subject,
+		Text:    text,
+		HTML:    html,
+		Attachments: []Attachment{{
+			Filename:    "invite.ics",
+			ContentType: invite.ContentType(),
+			Data:        invite.Bytes(),
+		}},
+	}
+	if notice.Key != "" {
+		email.MessageID = notice.Key + "@" + domain(n.from.Address)
+	}
+	if err := n.sender.Send(ctx, email); err != nil {
+		return fmt.Errorf("send %s for booking %s: %w", notice.Kind, notice.BookingID, err)
+	}
+	return nil
+}
+
+// BookingTypes are the event types Handler sends mail for.
+var BookingTypes = []string{events.TypeBookingCreated, events.TypeBookingRescheduled, events.TypeBookingCancelled}
+
+// Handler returns a bus handler that emails the owner of a booking when it
+// is created, rescheduled or cancelled. Subscribe it for BookingTypes.
+func (n *Notifier) Handler() events.Handler {
+	return func(ctx context.Context, m events.Message) error {
+		switch m.Type {
+		case events.TypeBookingCreated:
+			var e events.BookingCreated
+			if err := m.Decode(&e); err != nil {
+				return err
+			}
+			return n.Send(ctx, Notice{
+				Kind:             Confirmation,
+				BookingID:        e.BookingID,
+				UserID:           e.UserID,
+				ProviderID:       e.ProviderID,
+				AppointmentStart: e.AppointmentStart,
+				DurationMinutes:  e.DurationMinutes,
+				Sequence:         e.Sequence,
+			})
+		case events.TypeBookingRescheduled:
+			var e events.BookingRescheduled
+			if err := m.Decode(&e); err != nil {
+				return err
+			}
+			return n.Send(ctx, Notice{
+				Kind:             Reschedule,
+				BookingID:        e.BookingID,
+				UserID:           e.UserID,
+				ProviderID:       e.ProviderID,
+				AppointmentStart: e.AppointmentStart,
+				DurationMinutes:  e.DurationMinutes,
+				PreviousStart:    e.PreviousAppointmentStart,
+				Sequence:         e.Sequence,
+			})
+		case events.TypeBookingCancelled:
+			var e events.BookingCancelled
+			if err := m.Decode(&e); err != nil {
+				return err
+			}
+			return n.Send(ctx, Notice{
+				Kind:             Cancellation,
+				BookingID:        e.BookingID,
+				UserID:           e.UserID,
+				ProviderID:       e.ProviderID,
+				AppointmentStart: e.AppointmentStart,
+				DurationMinutes:  e.DurationMinutes,
+				Reason:           e.Reason,
+				Sequence:         e.Sequence,
+			})
+		}
+		return nil
+	}
+}
@@ -0,0 +1,439 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/config"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/events"
+	"github.com/google/uuid"
+)
+
+type memUsers map[uuid.UUID]db.User
+
+func (m memUsers) GetUserByID(ctx context.Context, id uuid.UUID) (db.User, error) {
+	u, ok := m[id]
+	if !ok {
+		return db.User{}, sql.ErrNoRows
+	}
+	return u, nil
+}
+
+func message(t *testing.T, e events.Event, at time.Time) events.Message {
+	t.Helper()
+	payload, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return events.Message{ID: uuid.New(), Type: e.EventType(), AggregateID: e.AggregateID(), OccurredAt: at, Payload: payload}
+}
+
+// parts parses an encoded Email back into its decoded bodies, keyed by
+// content type without parameters, and returns the top-level header.
+func parts(t *testing.T, raw []byte) (mail.Header, map[string]string) {
+	t.Helper()
+	msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatalf("read message: %v", err)
+	}
+	out := map[string]string{}
+	var walk func(contentType string, body io.Reader)
+	walk = func(contentType string, body io.Reader) {
+		mediaType, params, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			t.Fatalf("parse %q: %v", contentType, err)
+		}
+		if !strings.HasPrefix(mediaType, "multipart/") {
+			b, err := io.ReadAll(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out[mediaType] = string(b)
+			return
+		}
+		mr := multipart.NewReader(body, params["boundary"])
+		for {
+			p, err := mr.NextPart()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			// multipart.Reader decodes quoted-printable itself.
+			var r io.Reader = p
+			if p.Header.Get("Content-Transfer-Encoding") == "base64" {
+				r = base64.NewDecoder(base64.StdEncoding, p)
+			}
+			walk(p.Header.Get("Content-Type"), r)
+		}
+	}
+	walk(msg.Header.Get("Content-Type"), msg.Body)
+	return msg.Header, out
+}
+
+func TestRenderEveryKind(t *testing.T) {
+	loc, _ := time.LoadLocation("America/New_York")
+	data := Data{
+		AppName:       "Booking App",
+		FirstName:     "Ada",
+		Provider:      `Dr <script>alert(1)</script>`,
+		Start:         time.Date(2026, 11, 2, 14, 30, 0, 0, loc),
+		Minutes:       45,
+		PreviousStart: time.Date(2026, 11, 1, 9, 0, 0, 0, loc),
+		Reason:        "Feeling better",
+	}
+	for _, k := range Kinds {
+		t.Run(string(k), func(t *testing.T) {
+			subject, text, html, err := Render(k, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			when := "Monday, November 2, 2026 at 2:30 PM EST"
+			if !strings.Contains(subject, when) || strings.Contains(subject, "\n") {
+				t.Errorf("subject = %q, want one line mentioning %q", subject, when)
+			}
+			if !strings.HasPrefix(text, "Hi Ada,") || !strings.Contains(text, when) {
+				t.Errorf("text body = %q", text)
+			}
+			if strings.Contains(html, "<script>") || !strings.Contains(html, "&lt;script&gt;") {
+				t.Errorf("provider name not escaped in HTML: %q", html)
+			}
+		})
+	}
+
+	_, text, _, _ := Render(Reschedule, data)
+	if !strings.Contains(text, "Sunday, November 1, 2026 at 9:00 AM EST") {
+		t.Errorf("reschedule text does not mention the previous time: %q", text)
+	}
+	_, text, _, _ = Render(Cancellation, data)
+	if !strings.Contains(text, "Reason: Feeling better") {
+		t.Errorf("cancellation text does not mention the reason: %q", text)
+	}
+	if _, _, _, err := Render("unknown", data); err == nil {
+		t.Error("Render of an unknown kind succeeded")
+	}
+}
+
+func TestInviteBytes(t *testing.T) {
+	inv := Invite{
+		Method:      MethodCancel,
+		UID:         "b1@booking-app",
+		Sequence:    7,
+		Start:       time.Date(2026, 11, 2, 19, 30, 0, 0, time.UTC),
+		End:         time.Date(2026, 11, 2, 20, 15, 0, 0, time.UTC),
+		Summary:     "Appointment with Dr. Smith, Jr.; room 4",
+		Description: strings.Repeat("é", 60),
+		Organizer:   mail.Address{Name: `Booking "App"`, Address: "no-reply@example.com"},
+		Attendee:    mail.Address{Name: "Ada", Address: "ada@example.com"},
+		Stamp:       time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+	}
+	ics := string(inv.Bytes())
+
+	for _, want := range []string{
+		"METHOD:CANCEL\r\n",
+		"SEQUENCE:7\r\n",
+		"DTSTART:20261102T193000Z\r\n",
+		"DTEND:20261102T201500Z\r\n",
+		`SUMMARY:Appointment with Dr. Smith\, Jr.\; room 4` + "\r\n",
+		`ORGANIZER;CN="Booking App":mailto:no-reply@example.com` + "\r\n",
+		"STATUS:CANCELLED\r\n",
+	} {
+		if !strings.Contains(ics, want) {
+			t.Errorf("invite missing %q:\n%s", want, ics)
+		}
+	}
+	if strings.Count(ics, "\n") != strings.Count(ics, "\r\n") {
+		t.Error("invite has bare LF line endings")
+	}
+
+	var unfolded strings.Builder
+	for _, line := range strings.Split(strings.TrimSuffix(ics, "\r\n"), "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line of %d octets: %q", len(line), line)
+		}
+		if !strings.HasPrefix(line, " ") {
+			unfolded.WriteString("\n")
+		}
+		unfolded.WriteString(strings.TrimPrefix(line, " "))
+	}
+	if !strings.Contains(unfolded.String(), "DESCRIPTION:"+strings.Repeat("é", 60)) {
+		t.Errorf("folded description does not unfold to the original:\n%s", unfolded.String())
+	}
+}
+
+func TestEmailBytes(t *testing.T) {
+	e := Email{
+		From:        "Booking App <no-reply@example.com>",
+		To:          []string{"Ada Lovelace <ada@example.com>"},
+		Subject:     "Réservation confirmée",
+		Text:        "Hi Ada,\n" + strings.Repeat("long line ", 20),
+		HTML:        "<p>Hi Ada,</p>",
+		Attachments: []Attachment{{Filename: "invite.ics", ContentType: "text/calendar; method=REQUEST", Data: []byte("BEGIN:VCALENDAR\r\n")}},
+	}
+	raw, err := e.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, bodies := parts(t, raw)
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(header.Get("Subject"))
+	if err != nil || subject != e.Subject {
+		t.Errorf("subject = %q (%v), want %q", subject, err, e.Subject)
+	}
+	if !strings.HasSuffix(header.Get("Message-ID"), "@example.com>") {
+		t.Errorf("Message-ID = %q", header.Get("Message-ID"))
+	}
+	// Line breaks go on the wire as CRLF.
+	if bodies["text/plain"] != strings.ReplaceAll(e.Text, "\n", "\r\n") {
+		t.Errorf("text part = %q", bodies["text/plain"])
+	}
+	if bodies["text/html"] != e.HTML {
+		t.Errorf("html part = %q", bodies["text/html"])
+	}
+	if bodies["text/calendar"] != "BEGIN:VCALENDAR\r\n" {
+		t.Errorf("attachment = %q", bodies["text/calendar"])
+	}
+}
+
+func TestNotifierBookingEvents(t *testing.T) {
+	user := db.User{ID: uuid.New(), FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com", Timezone: "Europe/London"}
+	provider := db.User{ID: uuid.New(), FirstName: "Grace", LastName: "Hopper", Email: "grace@example.com", Timezone: "UTC"}
+	sender := &MemorySender{}
+	n, err := New(memUsers{user.ID: user, provider.ID: provider}, sender, "Acme Clinic <bookings@acme.test>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	handle := n.Handler()
+
+	bookingID := uuid.New()
+	start := time.Date(2026, 7, 1, 9, 0, 0, 0, time.UTC)
+	later := start.Add(24 * time.Hour)
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	msgs := []events.Message{
+		message(t, events.BookingCreated{BookingID: bookingID, UserID: user.ID, ProviderID: provider.ID, AppointmentStart: start, DurationMinutes: 30}, now),
+		message(t, events.BookingRescheduled{BookingID: bookingID, UserID: user.ID, ProviderID: provider.ID, AppointmentStart: later,
+			DurationMinutes: 30, PreviousAppointmentStart: start, Sequence: 1}, now.Add(time.Minute)),
+		message(t, events.BookingCancelled{BookingID: bookingID, UserID: user.ID, ProviderID: provider.ID, AppointmentStart: later,
+			DurationMinutes: 30, Reason: "Travelling", Sequence: 2}, now.Add(2*time.Minute)),
+	}
+	for _, m := range msgs {
+		if err := handle(context.Background(), m); err != nil {
+			t.Fatalf("%s: %v", m.Type, err)
+		}
+	}
+
+	sent := sender.Sent()
+	if len(sent) != 3 {
+		t.Fatalf("sent %d emails, want 3", len(sent))
+	}
+	wantSubjects := []string{
+		"Booking confirmed for Wednesday, July 1, 2026 at 10:00 AM BST",
+		"Booking moved to Thursday, July 2, 2026 at 10:00 AM BST",
+		"Booking cancelled for Thursday, July 2, 2026 at 10:00 AM BST",
+	}
+	wantMethods := []string{MethodRequest, MethodRequest, MethodCancel}
+	for i, e := range sent {
+		if e.Subject != wantSubjects[i] {
+			t.Errorf("email %d subject = %q, want %q", i, e.Subject, wantSubjects[i])
+		}
+		if len(e.To) != 1 || e.To[0] != `"Ada Lovelace" <ada@example.com>` {
+			t.Errorf("email %d to = %q", i, e.To)
+		}
+		if e.From != `"Acme Clinic" <bookings@acme.test>` || !strings.Contains(e.HTML, "Acme Clinic") {
+			t.Errorf("email %d from = %q", i, e.From)
+		}
+		if !strings.Contains(e.Text, "Grace Hopper") {
+			t.Errorf("email %d does not name the provider: %q", i, e.Text)
+		}
+		if len(e.Attachments) != 1 {
+			t.Fatalf("email %d has %d attachments, want the invite", i, len(e.Attachments))
+		}
+		ics := string(e.Attachments[0].Data)
+		if !strings.Contains(ics, "METHOD:"+wantMethods[i]+"\r\n") || !strings.Contains(ics, "UID:"+bookingID.String()+"@booking-app\r\n") {
+			t.Errorf("email %d invite:\n%s", i, ics)
+		}
+		seqLine := ics[strings.Index(ics, "SEQUENCE:")+len("SEQUENCE:"):]
+		seq, _ := strconv.ParseInt(seqLine[:strings.Index(seqLine, "\r")], 10, 64)
+		if seq != int64(i) {
+			t.Errorf("email %d invite sequence = %d, want the event's %d", i, seq, i)
+		}
+	}
+	if !strings.Contains(sent[2].Text, "Reason: Travelling") {
+		t.Errorf("cancellation text = %q", sent[2].Text)
+	}
+}
+
+func TestNotifierDropsDeletedUsers(t *testing.T) {
+	sender := &MemorySender{}
+	n, _ := New(memUsers{}, sender, "bookings@acme.test")
+	err := n.Send(context.Background(), Notice{Kind: Confirmation, BookingID: uuid.New(), UserID: uuid.New()})
+	if err != nil || len(sender.Sent()) != 0 {
+		t.Errorf("Send for a deleted user = %v, sent %d", err, len(sender.Sent()))
+	}
+}
+
+func TestNotifierSendErrorIsReturned(t *testing.T) {
+	user := db.User{ID: uuid.New(), FirstName: "Ada", Email: "ada@example.com"}
+	sender := &MemorySender{Err: errors.New("relay down")}
+	n, _ := New(memUsers{user.ID: user}, sender, "bookings@acme.test")
+	err := n.Handler()(context.Background(), message(t, events.BookingCreated{BookingID: uuid.New(), UserID: user.ID, DurationMinutes: 30}, time.Now()))
+	if err == nil || !strings.Contains(err.Error(), "relay down") {
+		t.Errorf("err = %v, want the sender's error so the relay retries", err)
+	}
+}
+
+func TestNotifierKeyIsMessageID(t *testing.T) {
+	user := db.User{ID: uuid.New(), FirstName: "Ada", Email: "ada@example.com"}
+	sender := &MemorySender{}
+	n, _ := New(memUsers{user.ID: user}, sender, "Acme <bookings@acme.test>")
+	notice := Notice{Kind: Reminder, BookingID: uuid.New(), UserID: user.ID, DurationMinutes: 30, Key: "reminder.1"}
+	for range 2 {
+		if err := n.Send(context.Background(), notice); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i, e := range sender.Sent() {
+		raw, err := e.Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header, _ := parts(t, raw); header.Get("Message-ID") != "<reminder.1@acme.test>" {
+			t.Errorf("email %d Message-ID = %q, want one derived from the key", i, header.Get("Message-ID"))
+		}
+	}
+}
+
+func TestFileSender(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	s, err := NewFileSender(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Send(context.Background(), Email{From: "a@example.com", To: []string{"b@example.com"}, Subject: "Hi", Text: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if len(files) != 1 {
+		t.Fatalf("found %d .eml files, want 1", len(files))
+	}
+	raw, _ := os.ReadFile(files[0])
+	if _, bodies := parts(t, raw); bodies["text/plain"] != "hello" {
+		t.Errorf("stored text part = %q", bodies["text/plain"])
+	}
+}
+
+// fakeSMTP accepts one session on a local port and reports the envelope and
+// message it received.
+func fakeSMTP(t *testing.T) (addr string, got <-chan []string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	ch := make(chan []string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { _, _ = io.WriteString(conn, s+"\r\n") }
+		var session []string
+		reply("220 fake ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+			switch cmd {
+			case "EHLO", "HELO":
+				reply("250 fake")
+			case "MAIL", "RCPT":
+				session = append(session, line)
+				reply("250 OK")
+			case "DATA":
+				reply("354 go ahead")
+				var data strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				session = append(session, data.String())
+				reply("250 queued")
+			case "QUIT":
+				reply("221 bye")
+				ch <- session
+				return
+			default:
+				reply("502 unsupported")
+			}
+		}
+	}()
+	return ln.Addr().String(), ch
+}
+
+func TestSMTPSender(t *testing.T) {
+	addr, got := fakeSMTP(t)
+	host, port, _ := net.SplitHostPort(addr)
+	p, _ := strconv.Atoi(port)
+	s := NewSMTPSender(config.SMTPConfig{Host: host, Port: p, Timeout: 5 * time.Second})
+
+	err := s.Send(context.Background(), Email{
+		From:    "Acme Clinic <bookings@acme.test>",
+		To:      []string{`"Ada Lovelace" <ada@example.com>`},
+		Subject: "Booking confirmed",
+		Text:    "See you then",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	session := <-got
+	if len(session) != 3 {
+		t.Fatalf("session = %q", session)
+	}
+	if session[0] != "MAIL FROM:<bookings@acme.test>" || !strings.HasPrefix(session[1], "RCPT TO:<ada@example.com>") {
+		t.Errorf("envelope = %q", session[:2])
+	}
+	if _, bodies := parts(t, []byte(session[2])); bodies["text/plain"] != "See you then" {
+		t.Errorf("delivered text = %q", bodies["text/plain"])
+	}
+}
+
+func TestNewSender(t *testing.T) {
+	cfg := config.Default().Email
+	if s, err := NewSender(cfg); s != nil || err != nil {
+		t.Errorf("none transport = %v, %v; want no sender", s, err)
+	}
+	cfg.Transport = "file"
+	cfg.Dir = t.TempDir()
+	if s, err := NewSender(cfg); err != nil {
+		t.Error(err)
+	} else if _, ok := s.(*FileSender); !ok {
+		t.Errorf("file transport = %T", s)
+	}
+}
@@ -0,0 +1,171 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/config"
+	"github.com/google/uuid"
+)
+
+// NewSender returns the sender cfg.Transport names, or nil for "none".
+func NewSender(cfg config.EmailConfig) (EmailSender, error) {
+	switch cfg.Transport {
+	case "none":
+		return nil, nil
+	case "smtp":
+		return NewSMTPSender(cfg.SMTP), nil
+	case "file":
+		return NewFileSender(cfg.Dir)
+	default:
+		return nil, fmt.Errorf("unknown email transport %q", cfg.Transport)
+	}
+}
+
+// SMTPSender sends through an SMTP relay, upgrading to TLS with STARTTLS
+// when the server offers it. Credentials are only sent over TLS, or to a
+// relay on localhost.
+type SMTPSender struct {
+	addr    string
+	host    string
+	auth    smtp.Auth
+	timeout time.Duration
+}
+
+func NewSMTPSender(cfg config.SMTPConfig) *SMTPSender {
+	s := &SMTPSender{
+		addr:    net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		host:    cfg.Host,
+		timeout: cfg.Timeout,
+	}
+	if cfg.Username != "" {
+		s.auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	return s
+}
+
+func (s *SMTPSender) Send(ctx context.Context, e Email) error {
+	from, err := mail.ParseAddress(e.From)
+	if err != nil {
+		return fmt.Errorf("parse sender: %w", err)
+	}
+	to := make([]string, len(e.To))
+	for i, addr := range e.To {
+		a, err := mail.ParseAddress(addr)
+		if err != nil {
+			return fmt.Errorf("parse recipient: %w", err)
+		}
+		to[i] = a.Address
+	}
+	msg, err := e.Bytes()
+	if err != nil {
+		return err
+	}
+
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	c, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: s.host}); err != nil {
+			return err
+		}
+	}
+	if s.auth != nil {
+		if err := c.Auth(s.auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(from.Address); err != nil {
+		return err
+	}
+	for _, addr := range to {
+		if err := c.Rcpt(addr); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// FileSender writes each message to its own .eml file in a directory, where
+// any mail client can open it. It is meant for development.
+type FileSender struct {
+	dir string
+}
+
+// NewFileSender creates dir if it does not exist.
+func NewFileSender(dir string) (*FileSender, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	return &FileSender{dir: dir}, nil
+}
+
+func (s *FileSender) Send(ctx context.Context, e Email) error {
+	msg, err := e.Bytes()
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405"), uuid.New())
+	return os.WriteFile(filepath.Join(s.dir, name), msg, 0o640)
+}
+
+// MemorySender keeps sent messages in memory, for tests. Err, if set, is
+// returned instead of sending.
+type MemorySender struct {
+	mu   sync.Mutex
+	sent []Email
+	Err  error
+}
+
+func (s *MemorySender) Send(ctx context.Context, e Email) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	s.sent = append(s.sent, e)
+	return nil
+}
+
+// Sent returns the messages sent so far.
+func (s *MemorySender) Sent() []Email {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Email(nil), s.sent...)
+}
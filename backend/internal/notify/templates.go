@@ -0,0 +1,100 @@
+package notify
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// Kind names a message and the templates it is rendered from.
+type Kind string
+
+const (
+	Confirmation Kind = "confirmation"
+	Reschedule   Kind = "reschedule"
+	Cancellation Kind = "cancellation"
+	Reminder     Kind = "reminder"
+)
+
+// Kinds lists every message kind.
+var Kinds = []Kind{Confirmation, Reschedule, Cancellation, Reminder}
+
+// Data is what the templates are rendered with. Times are in the
+// recipient's timezone.
+type Data struct {
+	AppName   string
+	FirstName string
+	// Provider is the provider's name, or empty if they have since been
+	// deleted.
+	Provider      string
+	Start         time.Time
+	Minutes       int
+	PreviousStart time.Time
+	Reason        string
+}
+
+// Each kind has <kind>.txt, which also defines "subject", and <kind>.html,
+// which defines "content" for layout.html.
+//
+//go:embed templates
+var templateFS embed.FS
+
+var funcs = map[string]any{
+	"when": func(t time.Time) string { return t.Format("Monday, January 2, 2006 at 3:04 PM MST") },
+}
+
+type kindTemplates struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+var templates = mustParseTemplates()
+
+func mustParseTemplates() map[Kind]kindTemplates {
+	out := map[Kind]kindTemplates{}
+	for _, k := range Kinds {
+		text, err := texttemplate.New(string(k)+".txt").Funcs(funcs).
+			ParseFS(templateFS, "templates/"+string(k)+".txt")
+		if err != nil {
+			panic(err)
+		}
+		html, err := htmltemplate.New(string(k)).Funcs(funcs).
+			ParseFS(templateFS, "templates/layout.html", "templates/"+string(k)+".html")
+		if err != nil {
+			panic(err)
+		}
+		out[k] = kindTemplates{text: text, html: html}
+	}
+	return out
+}
+
+// Render returns the subject, plain text body and HTML body for kind.
+func Render(kind Kind, data Data) (subject, text, html string, err error) {
+	t, ok := templates[kind]
+	if !ok {
+		return "", "", "", fmt.Errorf("no templates for %q", kind)
+	}
+
+	var buf bytes.Buffer
+	if err := t.text.ExecuteTemplate(&buf, "subject", data); err != nil {
+		return "", "", "", err
+	}
+	// A subject is one line whatever the data held.
+	subject = strings.Join(strings.Fields(buf.String()), " ")
+
+	buf.Reset()
+	if err := t.text.Execute(&buf, data); err != nil {
+		return "", "", "", err
+	}
+	text = buf.String()
+
+	buf.Reset()
+	if err := t.html.ExecuteTemplate(&buf, "layout", data); err != nil {
+		return "", "", "", err
+	}
+	return subject, text, buf.String(), nil
+}
@@ -0,0 +1,110 @@
+// Package pagination implements keyset pagination over lists ordered by a
+// timestamp and a UUID tie-breaker.
+//
+// A page is fetched with one extra row: if it comes back, the page is full
+// and the last row kept becomes the opaque cursor the client sends back to
+// continue after it.
+package pagination
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 200
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is the sort key of the last row of a page.
+type Cursor struct {
+	Time time.Time `json:"t"`
+	ID   uuid.UUID `json:"id"`
+}
+
+// String encodes c for use in a URL.
+func (c Cursor) String() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func ParseCursor(s string) (Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.ID == uuid.Nil || c.Time.IsZero() {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// Params selects one page. The zero value is the first page of
+// DefaultLimit rows.
+type Params struct {
+	Limit int32
+	After *Cursor
+}
+
+func (p Params) limit() int32 {
+	if p.Limit <= 0 {
+		return DefaultLimit
+	}
+	return min(p.Limit, MaxLimit)
+}
+
+// FetchLimit is the LIMIT to query with; it is one more than the page size.
+func (p Params) FetchLimit() int32 {
+	return p.limit() + 1
+}
+
+func (p Params) AfterTime() sql.NullTime {
+	if p.After == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: p.After.Time, Valid: true}
+}
+
+func (p Params) AfterID() uuid.NullUUID {
+	if p.After == nil {
+		return uuid.NullUUID{}
+	}
+	return uuid.NullUUID{UUID: p.After.ID, Valid: true}
+}
+
+// Page is a list response. NextCursor is empty on the last page.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// New builds a page from rows fetched with p.FetchLimit. key returns the
+// sort key of a row.
+func New[T any](rows []T, p Params, key func(T) Cursor) Page[T] {
+	if rows == nil {
+		rows = []T{}
+	}
+	limit := int(p.limit())
+	if len(rows) <= limit {
+		return Page[T]{Items: rows}
+	}
+	rows = rows[:limit]
+	return Page[T]{Items: rows, NextCursor: key(rows[limit-1]).String()}
+}
+
+// Map converts the items of a page, keeping its cursor.
+func Map[T, U any](p Page[T], f func(T) U) Page[U] {
+	items := make([]U, 0, len(p.Items))
+	for _, item := range p.Items {
+		items = append(items, f(item))
+	}
+	return Page[U]{Items: items, NextCursor: p.NextCursor}
+}
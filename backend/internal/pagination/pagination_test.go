@@ -0,0 +1,86 @@
+package pagination
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	want := Cursor{Time: time.Date(2025, 6, 1, 9, 30, 0, 0, time.UTC), ID: uuid.New()}
+
+	got, err := ParseCursor(want.String())
+	if err != nil {
+		t.Fatalf("ParseCursor: %v", err)
+	}
+	if !got.Time.Equal(want.Time) || got.ID != want.ID {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestParseCursorInvalid(t *testing.T) {
+	for _, s := range []string{
+		"not base64!",
+		"e30",                           // {}
+		Cursor{ID: uuid.New()}.String(), // zero time
+	} {
+		if _, err := ParseCursor(s); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("ParseCursor(%q) = %v, want ErrInvalidCursor", s, err)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	t0 := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	rows := make([]Cursor, 3)
+	for i := range rows {
+		rows[i] = Cursor{Time: t0.Add(time.Duration(i) * time.Hour), ID: uuid.New()}
+	}
+	key := func(c Cursor) Cursor { return c }
+
+	tests := []struct {
+		name       string
+		rows       []Cursor
+		params     Params
+		wantLen    int
+		wantCursor string
+	}{
+		{name: "Nil rows", rows: nil, wantLen: 0},
+		{name: "Last page", rows: rows, params: Params{Limit: 3}, wantLen: 3},
+		{name: "More to come", rows: rows, params: Params{Limit: 2}, wantLen: 2, wantCursor: rows[1].String()},
+		{name: "Default limit", rows: rows, wantLen: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page := New(tt.rows, tt.params, key)
+			if page.Items == nil {
+				t.Error("Items must not be nil so it encodes as []")
+			}
+			if len(page.Items) != tt.wantLen {
+				t.Errorf("len(Items) = %d, want %d", len(page.Items), tt.wantLen)
+			}
+			if page.NextCursor != tt.wantCursor {
+				t.Errorf("NextCursor = %q, want %q", page.NextCursor, tt.wantCursor)
+			}
+		})
+	}
+}
+
+func TestFetchLimit(t *testing.T) {
+	tests := []struct {
+		limit int32
+		want  int32
+	}{
+		{0, DefaultLimit + 1},
+		{10, 11},
+		{MaxLimit + 50, MaxLimit + 1},
+	}
+	for _, tt := range tests {
+		if got := (Params{Limit: tt.limit}).FetchLimit(); got != tt.want {
+			t.Errorf("FetchLimit(%d) = %d, want %d", tt.limit, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+// Package reminders sends the appointment reminders the booking service
+// schedules.
+//
+// BookingService writes one booking_reminders row per configured offset when
+// a booking is made, rewrites them when it is rescheduled and drops them
+// when it is cancelled. A Dispatcher claims due rows with a lease. Just
+// before handing one to the notifier it marks it sending, in a statement
+// that also checks the booking is still confirmed for the same time, and
+// once the notifier returns it marks it sent, or pending again for a retry.
+//
+// Each reminder is sent until an attempt is recorded as sent. One whose
+// dispatcher stops mid-send stays in sending and is claimed and sent again
+// when its lease runs out. Every attempt at a reminder carries the same
+// Message-ID, so when the cut-short attempt did go out, the second copy is a
+// duplicate that mail systems recognise and drop. Reminders for bookings
+// that are no longer confirmed, or whose appointment has started, are
+// skipped rather than sent late.
+package reminders
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/notify"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/service"
+	"github.com/google/uuid"
+)
+
+// Reminder statuses, as stored in booking_reminders.status.
+const (
+	StatusPending = "pending"
+	StatusSending = "sending"
+	StatusSent    = "sent"
+	StatusSkipped = "skipped"
+	StatusFailed  = "failed"
+)
+
+// Store is what a Dispatcher reads and updates reminders through. *db.Store
+// implements it.
+type Store interface {
+	ClaimBookingReminders(ctx context.Context, arg db.ClaimBookingRemindersParams) ([]db.BookingReminder, error)
+	GetBookingByID(ctx context.Context, id uuid.UUID) (db.Booking, error)
+	GetAvailabilityByID(ctx context.Context, id uuid.UUID) (db.Availability, error)
+	MarkBookingReminderSending(ctx context.Context, arg db.MarkBookingReminderSendingParams) (int64, error)
+	MarkBookingReminderSent(ctx context.Context, arg db.MarkBookingReminderSentParams) error
+	MarkBookingReminderFailed(ctx context.Context, arg db.MarkBookingReminderFailedParams) error
+	SkipBookingReminder(ctx context.Context, arg db.SkipBookingReminderParams) (int64, error)
+}
+
+// Notifier sends one notice. *notify.Notifier implements it.
+type Notifier interface {
+	Send(ctx context.Context, notice notify.Notice) error
+}
+
+// Dispatcher sends due reminders. Several dispatchers may run against the
+// same table.
+type Dispatcher struct {
+	store       Store
+	notifier    Notifier
+	batchSize   int32
+	maxAttempts int32
+	lease       time.Duration
+	logger      *slog.Logger
+	now         func() time.Time
+}
+
+type Option func(*Dispatcher)
+
+// WithBatchSize sets how many reminders are claimed at a time. The default
+// is 20.
+func WithBatchSize(n int) Option {
+	return func(d *Dispatcher) { d.batchSize = int32(n) }
+}
+
+// WithMaxAttempts sets how many times a reminder is tried before it is
+// marked failed. The default is 5.
+func WithMaxAttempts(n int) Option {
+	return func(d *Dispatcher) { d.maxAttempts = int32(n) }
+}
+
+// WithLease sets how long claimed reminders are hidden from other
+// dispatchers, and how long one left in sending waits before it is tried
+// again. It should outlast sending a whole batch; otherwise another replica
+// claims the rest of it, and a reminder still being sent may go out twice.
+// The default is ten minutes.
+func WithLease(lease time.Duration) Option {
+	return func(d *Dispatcher) { d.lease = lease }
+}
+
+// WithLogger sets where failed reminders are logged. The default is
+// slog.Default.
+func WithLogger(l *slog.Logger) Option {
+	return func(d *Dispatcher) { d.logger = l }
+}
+
+func NewDispatcher(store Store, notifier Notifier, opts ...Option) *Dispatcher {
+	d := &Dispatcher{
+		store:       store,
+		notifier:    notifier,
+		batchSize:   20,
+		maxAttempts: 5,
+		lease:       10 * time.Minute,
+		logger:      slog.Default(),
+		now:         time.Now,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Result counts the reminders one Flush handled.
+type Result struct {
+	Sent int
+	// Retrying reminders failed and will be sent again.
+	Retrying int
+	// Failed reminders ran out of attempts.
+	Failed int
+	// Skipped reminders were no longer worth sending.
+	Skipped int
+}
+
+// Flush sends due reminders batch by batch until none are left. Failed
+// sends are logged and scheduled for retry; only database errors are
+// returned.
+//
+// Once ctx is cancelled Flush finishes the reminder in hand, so it is not
+// left in sending, and returns; the rest of the batch is sent when its
+// lease runs out.
+func (d *Dispatcher) Flush(ctx context.Context) (Result, error) {
+	var result Result
+	for ctx.Err() == nil {
+		batch, err := d.store.ClaimBookingReminders(ctx, db.ClaimBookingRemindersParams{
+			LeaseUntil: d.now().Add(d.lease),
+			BatchLimit: d.batchSize,
+		})
+		if err != nil {
+			return result, fmt.Errorf("claim booking reminders: %w", err)
+		}
+
+		for _, r := range batch {
+			if ctx.Err() != nil {
+				break
+			}
+			if err := d.dispatch(ctx, r, &result); err != nil {
+				return result, fmt.Errorf("booking reminder %s: %w", r.ID, err)
+			}
+		}
+		if len(batch) < int(d.batchSize) {
+			break
+		}
+	}
+	return result, ctx.Err()
+}
+
+// errInterrupted is recorded for a reminder whose every attempt was cut
+// short before its outcome was recorded.
+var errInterrupted = errors.New("interrupted while sending")
+
+// dispatch sends one reminder and records the outcome.
+func (d *Dispatcher) dispatch(ctx context.Context, r db.BookingReminder, result *Result) error {
+	booking, err := d.store.GetBookingByID(ctx, r.BookingID)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Deleted since the claim, taking its reminders with it.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	now := d.now()
+	offset := time.Duration(r.OffsetMinutes) * time.Minute
+	switch {
+	case booking.Status != service.StatusConfirmed:
+		return d.skip(ctx, r, "booking is "+booking.Status, result)
+	case !booking.AppointmentStart.After(now):
+		return d.skip(ctx, r, "appointment has started", result)
+	case !booking.AppointmentStart.Equal(r.RemindAt.Add(offset)):
+		// Rescheduled since the claim; its new reminders replace this one.
+		return d.skip(ctx, r, "booking was rescheduled", result)
+	case r.Status == StatusSending && r.Attempts >= d.maxAttempts:
+		return d.fail(ctx, r, r.Attempts, errInterrupted, result)
+	}
+
+	var providerID uuid.UUID
+	slot, err := d.store.GetAvailabilityByID(ctx, booking.SlotID)
+	switch {
+	case err == nil:
+		providerID = slot.ProviderID
+	case !errors.Is(err, sql.ErrNoRows):
+		return err
+	}
+
+	n, err := d.store.MarkBookingReminderSending(ctx, db.MarkBookingReminderSendingParams{
+		ID:         r.ID,
+		LeaseUntil: r.NextAttemptAt,
+		Sequence:   booking.Sequence,
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		// Claimed again, cancelled or rescheduled since it was read.
+		return nil
+	}
+
+	// From here the reminder is out of the queue until its lease runs out,
+	// so the send and its outcome are seen through even if shutdown has
+	// begun.
+	ctx = context.WithoutCancel(ctx)
+	sendErr := d.notifier.Send(ctx, notify.Notice{
+		Kind:             notify.Reminder,
+		BookingID:        booking.ID,
+		UserID:           booking.UserID,
+		ProviderID:       providerID,
+		AppointmentStart: booking.AppointmentStart,
+		DurationMinutes:  booking.DurationMinutes,
+		// The same number the invite for the booking's current time
+		// carried, so the reminder does not outrank it.
+		Sequence: booking.Sequence,
+		Key:      messageKey(r, booking),
+	})
+	if sendErr == nil {
+		result.Sent++
+		return d.store.MarkBookingReminderSent(ctx, db.MarkBookingReminderSentParams{
+			ID:         r.ID,
+			LeaseUntil: r.NextAttemptAt,
+		})
+	}
+	return d.fail(ctx, r, r.Attempts+1, sendErr, result)
+}
+
+// fail records that attempt number attempts at r failed, scheduling a retry
+// unless that was the last one.
+func (d *Dispatcher) fail(ctx context.Context, r db.BookingReminder, attempts int32, err error, result *Result) error {
+	msg := err.Error()
+	next := StatusPending
+	if attempts >= d.maxAttempts {
+		next = StatusFailed
+		result.Failed++
+	} else {
+		result.Retrying++
+	}
+	d.logger.Warn("Booking reminder failed",
+		"reminder_id", r.ID, "booking_id", r.BookingID, "offset_minutes", r.OffsetMinutes,
+		"attempt", attempts, "status", next, "err", err)
+
+	return d.store.MarkBookingReminderFailed(ctx, db.MarkBookingReminderFailedParams{
+		Status:        next,
+		LastError:     &msg,
+		NextAttemptAt: d.now().Add(backoff(attempts)),
+		ID:            r.ID,
+		LeaseUntil:    r.NextAttemptAt,
+	})
+}
+
+func (d *Dispatcher) skip(ctx context.Context, r db.BookingReminder, reason string, result *Result) error {
+	n, err := d.store.SkipBookingReminder(ctx, db.SkipBookingReminderParams{
+		LastError:  &reason,
+		ID:         r.ID,
+		LeaseUntil: r.NextAttemptAt,
+	})
+	if n > 0 {
+		result.Skipped++
+	}
+	return err
+}
+
+// messageKey identifies one reminder for one booking time: every attempt at
+// it gets the same Message-ID, and a reschedule, which raises the booking's
+// sequence, a new one.
+func messageKey(r db.BookingReminder, b db.Booking) string {
+	return fmt.Sprintf("reminder.%s.%d.%d", b.ID, r.OffsetMinutes, b.Sequence)
+}
+
+// backoff is the wait before attempt n+1: a minute doubling with every
+// attempt, capped at an hour so a reminder is not retried after it matters.
+func backoff(n int32) time.Duration {
+	d := time.Minute
+	for i := int32(1); i < n && d < time.Hour; i++ {
+		d *= 2
+	}
+	return min(d, time.Hour)
+}
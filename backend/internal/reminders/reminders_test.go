@@ -0,0 +1,495 @@
+package reminders
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/notify"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/service"
+	"github.com/google/uuid"
+)
+
+// memStore keeps bookings and reminders in memory. Its mutex plays the part
+// of the row locks ClaimBookingReminders takes, so concurrent dispatchers
+// race the way they would against Postgres.
+type memStore struct {
+	mu        sync.Mutex
+	now       time.Time
+	bookings  map[uuid.UUID]db.Booking
+	slots     map[uuid.UUID]db.Availability
+	reminders []db.BookingReminder
+}
+
+func newMemStore(now time.Time) *memStore {
+	return &memStore{now: now, bookings: map[uuid.UUID]db.Booking{}, slots: map[uuid.UUID]db.Availability{}}
+}
+
+// addBooking stores a confirmed booking starting at start with a reminder
+// at each offset before it.
+func (m *memStore) addBooking(start time.Time, offsets ...time.Duration) db.Booking {
+	slot := db.Availability{ID: uuid.New(), ProviderID: uuid.New(), StartTime: start, EndTime: start.Add(30 * time.Minute)}
+	b := db.Booking{
+		ID: uuid.New(), UserID: uuid.New(), SlotID: slot.ID, AppointmentStart: start, DurationMinutes: 30,
+		Status: service.StatusConfirmed, UpdatedAt: m.now.Add(-time.Hour), Sequence: 2,
+	}
+	m.slots[slot.ID] = slot
+	m.bookings[b.ID] = b
+	for _, off := range offsets {
+		m.reminders = append(m.reminders, db.BookingReminder{
+			ID: uuid.New(), BookingID: b.ID, OffsetMinutes: int32(off / time.Minute),
+			RemindAt: start.Add(-off), NextAttemptAt: start.Add(-off), Status: StatusPending,
+		})
+	}
+	return b
+}
+
+func (m *memStore) ClaimBookingReminders(ctx context.Context, arg db.ClaimBookingRemindersParams) ([]db.BookingReminder, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []db.BookingReminder
+	for i, r := range m.reminders {
+		if len(out) == int(arg.BatchLimit) {
+			break
+		}
+		if (r.Status == StatusPending || r.Status == StatusSending) && !r.NextAttemptAt.After(m.now) {
+			m.reminders[i].NextAttemptAt = arg.LeaseUntil
+			out = append(out, m.reminders[i])
+		}
+	}
+	return out, nil
+}
+
+func (m *memStore) GetBookingByID(ctx context.Context, id uuid.UUID) (db.Booking, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b, ok := m.bookings[id]
+	if !ok {
+		return db.Booking{}, sql.ErrNoRows
+	}
+	return b, nil
+}
+
+func (m *memStore) GetAvailabilityByID(ctx context.Context, id uuid.UUID) (db.Availability, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.slots[id]
+	if !ok {
+		return db.Availability{}, sql.ErrNoRows
+	}
+	return s, nil
+}
+
+func (m *memStore) MarkBookingReminderSending(ctx context.Context, arg db.MarkBookingReminderSendingParams) (int64, error) {
+	var n int64
+	m.update(arg.ID, func(r *db.BookingReminder) {
+		b := m.bookings[r.BookingID]
+		if (r.Status == StatusPending || r.Status == StatusSending) && r.NextAttemptAt.Equal(arg.LeaseUntil) &&
+			b.Status == service.StatusConfirmed && b.Sequence == arg.Sequence {
+			r.Status = StatusSending
+			r.Attempts++
+			n = 1
+		}
+	})
+	return n, nil
+}
+
+func (m *memStore) MarkBookingReminderSent(ctx context.Context, arg db.MarkBookingReminderSentParams) error {
+	m.update(arg.ID, func(r *db.BookingReminder) {
+		if r.Status != StatusSending || !r.NextAttemptAt.Equal(arg.LeaseUntil) {
+			return
+		}
+		r.Status = StatusSent
+		r.LastError = nil
+		r.SentAt = &m.now
+	})
+	return nil
+}
+
+func (m *memStore) MarkBookingReminderFailed(ctx context.Context, arg db.MarkBookingReminderFailedParams) error {
+	m.update(arg.ID, func(r *db.BookingReminder) {
+		if r.Status != StatusSending || !r.NextAttemptAt.Equal(arg.LeaseUntil) {
+			return
+		}
+		r.Status = arg.Status
+		r.LastError = arg.LastError
+		r.NextAttemptAt = arg.NextAttemptAt
+	})
+	return nil
+}
+
+func (m *memStore) SkipBookingReminder(ctx context.Context, arg db.SkipBookingReminderParams) (int64, error) {
+	var n int64
+	m.update(arg.ID, func(r *db.BookingReminder) {
+		if r.Status != StatusPending && r.Status != StatusSending || !r.NextAttemptAt.Equal(arg.LeaseUntil) {
+			return
+		}
+		r.Status = StatusSkipped
+		r.LastError = arg.LastError
+		n = 1
+	})
+	return n, nil
+}
+
+func (m *memStore) update(id uuid.UUID, fn func(*db.BookingReminder)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.reminders {
+		if m.reminders[i].ID == id {
+			fn(&m.reminders[i])
+			return
+		}
+	}
+}
+
+// fakeNotifier records notices, failing while err is set.
+type fakeNotifier struct {
+	mu      sync.Mutex
+	notices []notify.Notice
+	err     error
+}
+
+func (f *fakeNotifier) Send(ctx context.Context, n notify.Notice) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	f.notices = append(f.notices, n)
+	return nil
+}
+
+func quietDispatcher(store Store, n Notifier, now func() time.Time, opts ...Option) *Dispatcher {
+	opts = append(opts, WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	d := NewDispatcher(store, n, opts...)
+	d.now = now
+	return d
+}
+
+func TestFlushSendsEachReminderOnce(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	store := newMemStore(now)
+	// Due: the day-before reminder of a booking 23h away and both reminders
+	// of one 30 minutes away. Not due: the hour-before of the first.
+	first := store.addBooking(now.Add(23*time.Hour), 24*time.Hour, time.Hour)
+	second := store.addBooking(now.Add(30*time.Minute), 24*time.Hour, time.Hour)
+	notifier := &fakeNotifier{}
+
+	// Several replicas flushing at once must not send anything twice.
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d := quietDispatcher(store, notifier, func() time.Time { return now }, WithBatchSize(1))
+			if _, err := d.Flush(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(notifier.notices) != 3 {
+		t.Fatalf("sent %d reminders, want 3: %+v", len(notifier.notices), notifier.notices)
+	}
+	perBooking := map[uuid.UUID]int{}
+	for _, n := range notifier.notices {
+		perBooking[n.BookingID]++
+		b := store.bookings[n.BookingID]
+		if n.Kind != notify.Reminder || n.UserID != b.UserID || !n.AppointmentStart.Equal(b.AppointmentStart) ||
+			n.DurationMinutes != 30 || n.ProviderID != store.slots[b.SlotID].ProviderID || n.Sequence != b.Sequence {
+			t.Errorf("unexpected notice %+v", n)
+		}
+	}
+	if perBooking[first.ID] != 1 || perBooking[second.ID] != 2 {
+		t.Errorf("reminders per booking = %v", perBooking)
+	}
+
+	var pending int
+	for _, r := range store.reminders {
+		switch r.Status {
+		case StatusSent:
+			if r.Attempts != 1 || r.SentAt == nil {
+				t.Errorf("sent reminder %+v", r)
+			}
+		case StatusPending:
+			pending++
+			if r.BookingID != first.ID || r.OffsetMinutes != 60 {
+				t.Errorf("unexpected pending reminder %+v", r)
+			}
+		default:
+			t.Errorf("reminder status %q", r.Status)
+		}
+	}
+	if pending != 1 {
+		t.Errorf("%d reminders pending, want 1", pending)
+	}
+
+	result, err := quietDispatcher(store, notifier, func() time.Time { return now }).Flush(context.Background())
+	if err != nil || result != (Result{}) {
+		t.Errorf("second flush = %+v, %v; want nothing to do", result, err)
+	}
+}
+
+func TestFlushRetriesThenFails(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	store := newMemStore(now)
+	store.addBooking(now.Add(12*time.Hour), 24*time.Hour)
+	notifier := &fakeNotifier{err: errors.New("smtp: 451 try again later")}
+	d := quietDispatcher(store, notifier, func() time.Time { return store.now }, WithMaxAttempts(2))
+
+	result, err := d.Flush(context.Background())
+	if err != nil || result != (Result{Retrying: 1}) {
+		t.Fatalf("first flush = %+v, %v", result, err)
+	}
+	r := store.reminders[0]
+	if r.Status != StatusPending || r.Attempts != 1 || !r.NextAttemptAt.Equal(now.Add(time.Minute)) ||
+		r.LastError == nil || *r.LastError != "smtp: 451 try again later" {
+		t.Errorf("after one failure reminder = %+v", r)
+	}
+
+	// Not due again until the backoff has passed.
+	if result, _ := d.Flush(context.Background()); result != (Result{}) {
+		t.Errorf("flush during backoff = %+v", result)
+	}
+
+	store.now = now.Add(time.Minute)
+	result, err = d.Flush(context.Background())
+	if err != nil || result != (Result{Failed: 1}) {
+		t.Fatalf("second flush = %+v, %v", result, err)
+	}
+	if r := store.reminders[0]; r.Status != StatusFailed || r.Attempts != 2 {
+		t.Errorf("after max attempts reminder = %+v", r)
+	}
+}
+
+func TestFlushSkipsStaleReminders(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	store := newMemStore(now)
+
+	cancelled := store.addBooking(now.Add(time.Hour), 2*time.Hour)
+	b := store.bookings[cancelled.ID]
+	b.Status = service.StatusCancelled
+	store.bookings[b.ID] = b
+
+	// The dispatcher was down until after this one started.
+	store.addBooking(now.Add(-time.Minute), time.Hour)
+
+	moved := store.addBooking(now.Add(time.Hour), 2*time.Hour)
+	b = store.bookings[moved.ID]
+	b.AppointmentStart = now.Add(48 * time.Hour)
+	store.bookings[b.ID] = b
+
+	notifier := &fakeNotifier{}
+	result, err := quietDispatcher(store, notifier, func() time.Time { return now }).Flush(context.Background())
+	if err != nil || result != (Result{Skipped: 3}) {
+		t.Fatalf("flush = %+v, %v", result, err)
+	}
+	if len(notifier.notices) != 0 {
+		t.Errorf("sent %+v, want nothing", notifier.notices)
+	}
+	wantReasons := []string{"booking is cancelled", "appointment has started", "booking was rescheduled"}
+	for i, r := range store.reminders {
+		if r.Status != StatusSkipped || r.LastError == nil || *r.LastError != wantReasons[i] {
+			t.Errorf("reminder %d = %+v, want skipped because %q", i, r, wantReasons[i])
+		}
+	}
+}
+
+// cancellingNotifier stands in for a send that is under way when shutdown
+// begins: it cancels the flush and then succeeds.
+type cancellingNotifier struct {
+	fakeNotifier
+	cancel context.CancelFunc
+}
+
+func (c *cancellingNotifier) Send(ctx context.Context, n notify.Notice) error {
+	c.cancel()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.fakeNotifier.Send(ctx, n)
+}
+
+func TestFlushFinishesReminderOnCancel(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	store := newMemStore(now)
+	store.addBooking(now.Add(30*time.Minute), time.Hour)
+	store.addBooking(now.Add(40*time.Minute), time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	notifier := &cancellingNotifier{cancel: cancel}
+
+	result, err := quietDispatcher(store, notifier, func() time.Time { return now }).Flush(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("flush err = %v, want context.Canceled", err)
+	}
+	if result != (Result{Sent: 1}) || len(notifier.notices) != 1 {
+		t.Errorf("result = %+v, sent %d; want the first reminder sent", result, len(notifier.notices))
+	}
+	if first, second := store.reminders[0], store.reminders[1]; first.Status != StatusSent || second.Status != StatusPending {
+		t.Errorf("statuses = %s, %s; want the second left for the next flush", first.Status, second.Status)
+	}
+}
+
+// racingStore runs during, before each slot lookup, so a test can change a
+// reminder or its booking after the dispatcher has claimed and checked it.
+// While lost is set, recording a sent reminder fails, as if the dispatcher
+// had stopped before it could.
+type racingStore struct {
+	*memStore
+	during func()
+	lost   bool
+}
+
+func (s *racingStore) GetAvailabilityByID(ctx context.Context, id uuid.UUID) (db.Availability, error) {
+	if s.during != nil {
+		s.during()
+	}
+	return s.memStore.GetAvailabilityByID(ctx, id)
+}
+
+func (s *racingStore) MarkBookingReminderSent(ctx context.Context, arg db.MarkBookingReminderSentParams) error {
+	if s.lost {
+		return errors.New("connection lost")
+	}
+	return s.memStore.MarkBookingReminderSent(ctx, arg)
+}
+
+func TestFlushRetriesCutShortSends(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	store := &racingStore{memStore: newMemStore(now), lost: true}
+	store.addBooking(now.Add(30*time.Minute), time.Hour)
+	notifier := &fakeNotifier{}
+	flush := func() (Result, error) {
+		return quietDispatcher(store, notifier, func() time.Time { return store.now }, WithLease(time.Minute)).Flush(context.Background())
+	}
+
+	if _, err := flush(); err == nil {
+		t.Fatal("flush recorded the send it lost")
+	}
+	store.lost = false
+	if r := store.reminders[0]; r.Status != StatusSending || r.Attempts != 1 {
+		t.Fatalf("reminder = %+v, want it left sending after one attempt", r)
+	}
+
+	// Until the lease runs out nothing else picks it up.
+	if result, err := flush(); err != nil || result != (Result{}) {
+		t.Errorf("flush within the lease = %+v, %v; want nothing to do", result, err)
+	}
+
+	store.now = now.Add(2 * time.Minute)
+	if result, err := flush(); err != nil || result != (Result{Sent: 1}) {
+		t.Fatalf("flush after the lease = %+v, %v; want the reminder sent again", result, err)
+	}
+	if r := store.reminders[0]; r.Status != StatusSent || r.Attempts != 2 {
+		t.Errorf("reminder = %+v, want sent after two attempts", r)
+	}
+	if len(notifier.notices) != 2 || notifier.notices[0].Key == "" || notifier.notices[0].Key != notifier.notices[1].Key {
+		t.Errorf("notices = %+v, want both attempts under the same key", notifier.notices)
+	}
+}
+
+func TestFlushGivesUpOnInterruptedSends(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	store := newMemStore(now)
+	store.addBooking(now.Add(30*time.Minute), time.Hour)
+	store.reminders[0].Status = StatusSending
+	store.reminders[0].Attempts = 3
+	notifier := &fakeNotifier{}
+
+	result, err := quietDispatcher(store, notifier, func() time.Time { return now }, WithMaxAttempts(3)).Flush(context.Background())
+	if err != nil || result != (Result{Failed: 1}) || len(notifier.notices) != 0 {
+		t.Fatalf("flush = %+v, %v, sent %d; want the reminder failed unsent", result, err, len(notifier.notices))
+	}
+	if r := store.reminders[0]; r.Status != StatusFailed || r.LastError == nil || *r.LastError != errInterrupted.Error() {
+		t.Errorf("reminder = %+v, want failed as interrupted", r)
+	}
+}
+
+func TestFlushChangedSinceClaim(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		change func(s *memStore)
+	}{
+		{
+			name: "Claimed by another dispatcher",
+			change: func(s *memStore) {
+				s.reminders[0].NextAttemptAt = now.Add(time.Hour)
+			},
+		},
+		{
+			// ScheduleBookingReminder moves the row to the new time.
+			name: "Rescheduled",
+			change: func(s *memStore) {
+				b := s.bookings[s.reminders[0].BookingID]
+				b.Sequence++
+				s.bookings[b.ID] = b
+				s.reminders[0].RemindAt = now.Add(24 * time.Hour)
+				s.reminders[0].NextAttemptAt = now.Add(24 * time.Hour)
+			},
+		},
+		{
+			name: "Cancelled",
+			change: func(s *memStore) {
+				b := s.bookings[s.reminders[0].BookingID]
+				b.Status = service.StatusCancelled
+				s.bookings[b.ID] = b
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &racingStore{memStore: newMemStore(now)}
+			store.addBooking(now.Add(30*time.Minute), time.Hour)
+			store.during = func() { tt.change(store.memStore) }
+			notifier := &fakeNotifier{}
+
+			if _, err := quietDispatcher(store, notifier, func() time.Time { return now }).Flush(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if len(notifier.notices) != 0 || store.reminders[0].Status != StatusPending {
+				t.Errorf("sent %d, status %s; want nothing sent and the reminder left pending",
+					len(notifier.notices), store.reminders[0].Status)
+			}
+		})
+	}
+}
+
+func TestSkipLeavesReclaimedReminder(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	store := newMemStore(now)
+	store.addBooking(now.Add(-time.Minute), time.Hour)
+	// This dispatcher's claim ran out; another claimed the reminder again
+	// and sent it before the appointment started.
+	stale := store.reminders[0]
+	stale.NextAttemptAt = now.Add(-time.Minute)
+	store.reminders[0].NextAttemptAt = now.Add(time.Minute)
+	store.reminders[0].Status = StatusSent
+
+	var result Result
+	d := quietDispatcher(store, &fakeNotifier{}, func() time.Time { return now })
+	if err := d.dispatch(context.Background(), stale, &result); err != nil {
+		t.Fatal(err)
+	}
+	if result != (Result{}) || store.reminders[0].Status != StatusSent {
+		t.Errorf("result = %+v, status %s; want the sent reminder left alone", result, store.reminders[0].Status)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := map[int32]time.Duration{1: time.Minute, 2: 2 * time.Minute, 4: 8 * time.Minute, 7: time.Hour, 30: time.Hour}
+	for n, want := range tests {
+		if got := backoff(n); got != want {
+			t.Errorf("backoff(%d) = %s, want %s", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// Package router assembles every HTTP handler in the backend into a single
+// mux.Router.
+//
+// Routes:
+//
+//	POST   /api/register                                    RegisterHandler
+//	POST   /api/login                                       LoginHandler
+//	GET    /api/availabilities/free                         ListAllFreeSlotsHandler
+//	POST   /api/token/refresh                               RefreshTokenHandler
+//	GET    /.well-known/jwks.json                           JWKSHandler
+//	GET    /healthz                                         health.Checker.Healthz
+//	GET    /readyz                                          health.Checker.Readyz
+//	GET    /metrics                                         metrics.Metrics.Handler
+//
+//	POST   /api/logout                                      LogoutHandler
+//	GET    /api/availabilities/provider/{provider_id}       ListAvailabilityByProviderHandler
+//	GET    /api/bookings/user                               ListBookingsForUserHandler
+//	POST   /api/bookings/create                             CreateBookingHandler
+//	GET    /api/bookings/{id}                               GetBookingByIDHandler
+//	PUT    /api/bookings/{id}                               RescheduleBookingHandler
+//	DELETE /api/bookings/{id}                               DeleteBookingHandler
+//	PUT    /api/users/me                                    UpdateUserHandler
+//	PUT    /api/users/me/timezone                           UpdateUserTimezoneHandler
+//
+//	GET    /api/admin/bookings/all                          ListAllBookingsHandler            admin
+//	PUT    /api/admin/bookings/{id}/complete                MarkBookingHandler                admin
+//	PUT    /api/admin/bookings/{id}/no-show                 MarkBookingHandler                admin
+//	GET    /api/admin/users/all                             ListAllUsersHandler               admin
+//	DELETE /api/admin/users                                 DeleteUserHandler                 admin
+//	PUT    /api/admin/users/{id}/role                       UpdateUserRoleHandler             admin
+//	POST   /api/admin/users/{id}/revoke-tokens              RevokeUserTokensHandler           admin
+//	POST   /api/admin/admins/create                         CreateAdminHandler                admin
+//	GET    /api/admin/webhooks/all                          ListWebhookEndpointsHandler       admin
+//	POST   /api/admin/webhooks/create                       CreateWebhookEndpointHandler      admin
+//	PUT    /api/admin/webhooks/{id}                         UpdateWebhookEndpointHandler      admin
+//	DELETE /api/admin/webhooks/{id}                         DeleteWebhookEndpointHandler      admin
+//	GET    /api/admin/webhooks/{id}/deliveries              ListWebhookDeliveriesHandler      admin
+//	POST   /api/admin/webhooks/deliveries/{id}/redeliver    RedeliverWebhookDeliveryHandler   admin
+//	POST   /api/admin/availability/create                   CreateAvailabilityHandler         provider, admin
+//	GET    /api/admin/availability/range                    ListAvailabilityInRangeHandler    provider, admin
+//	DELETE /api/admin/availability/{id}                     DeleteAvailabilityHandler         provider, admin
+//	POST   /api/admin/avail-pattern/create                  CreateAvailabilityPatternHandler  provider, admin
+//	GET    /api/admin/avail-pattern/provider/{provider_id}  ListPatternsByProviderHandler     provider, admin
+//	PUT    /api/admin/avail-pattern/{id}                    UpdateAvailabilityPatternHandler  provider, admin
+//	DELETE /api/admin/avail-pattern/{id}                    DeleteAvailabilityPatternHandler  provider, admin
+//
+// Everything outside the first group requires a bearer token; the last
+// column lists the roles allowed on /api/admin routes.
+package router
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/handlers"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/health"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/logging"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/metrics"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/middleware"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/service"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/tracing"
+)
+
+// Deps holds everything the handlers need.
+type Deps struct {
+	Queries             db.TxQuerier
+	Tokens              handlers.Tokens
+	BookingService      *service.BookingService
+	AvailabilityService *service.AvailabilityService
+	Health              *health.Checker
+	Metrics             *metrics.Metrics
+	// Logger is the base for per-request loggers; nil means slog.Default.
+	Logger *slog.Logger
+}
+
+func New(deps Deps) *mux.Router {
+	q := deps.Queries
+	h := handlers.NewHandler(deps.BookingService)
+
+	r := mux.NewRouter()
+
+	r.HandleFunc("/api/register", handlers.RegisterHandler(q)).Methods("POST")
+	r.HandleFunc("/api/login", handlers.LoginHandler(q, deps.Tokens)).Methods("POST")
+	r.HandleFunc("/api/availabilities/free", handlers.ListAllFreeSlotsHandler(q)).Methods("GET")
+	r.HandleFunc("/api/token/refresh", handlers.RefreshTokenHandler(q, deps.Tokens)).Methods("POST")
+	r.HandleFunc("/.well-known/jwks.json", handlers.JWKSHandler(deps.Tokens.Keys)).Methods("GET")
+	r.HandleFunc("/healthz", deps.Health.Healthz).Methods("GET")
+	r.HandleFunc("/readyz", deps.Health.Readyz).Methods("GET")
+	r.Handle("/metrics", deps.Metrics.Handler()).Methods("GET")
+
+	authn := middleware.NewAuthMiddleware(deps.Tokens.Keys, q)
+
+	r.Handle("/api/logout", authn(handlers.LogoutHandler(q))).Methods("POST")
+
+	availabilities := r.PathPrefix("/api/availabilities").Subrouter()
+	availabilities.Use(authn)
+
+	availabilities.Handle("/provider/{provider_id}", handlers.ListAvailabilityByProviderHandler(q)).Methods("GET")
+
+	bookings := r.PathPrefix("/api/bookings").Subrouter()
+	bookings.Use(authn)
+
+	bookings.Handle("/user", h.ListBookingsForUserHandler()).Methods("GET")
+	bookings.Handle("/create", h.CreateBookingHandler()).Methods("POST")
+	bookings.Handle("/{id}", h.GetBookingByIDHandler()).Methods("GET")
+	bookings.Handle("/{id}", h.RescheduleBookingHandler()).Methods("PUT")
+	bookings.Handle("/{id}", h.DeleteBookingHandler()).Methods("DELETE")
+
+	users := r.PathPrefix("/api/users").Subrouter()
+	users.Use(authn)
+
+	users.Handle("/me", handlers.UpdateUserHandler(q)).Methods("PUT")
+	users.Handle("/me/timezone", handlers.UpdateUserTimezoneHandler(q)).Methods("PUT")
+
+	admins := r.PathPrefix("/api/admin").Subrouter()
+	admins.Use(authn)
+
+	// Providers manage their own schedules; these subrouters must be
+	// registered before the admin-only catch-all below.
+	schedule := middleware.RequireRole(middleware.RoleProvider, middleware.RoleAdmin)
+
+	availability := admins.PathPrefix("/availability").Subrouter()
+	availability.Use(schedule)
+
+	availability.Handle("/create", handlers.CreateAvailabilityHandler(q)).Methods("POST")
+	availability.Handle("/range", handlers.ListAvailabilityInRangeHandler(q)).Methods("GET")
+	availability.Handle("/{id}", handlers.DeleteAvailabilityHandler(q)).Methods("DELETE")
+
+	patterns := admins.PathPrefix("/avail-pattern").Subrouter()
+	patterns.Use(schedule)
+
+	patterns.Handle("/create", handlers.CreateAvailabilityPatternHandler(deps.AvailabilityService)).Methods("POST")
+	patterns.Handle("/provider/{provider_id}", handlers.ListPatternsByProviderHandler(q)).Methods("GET")
+	patterns.Handle("/{id}", handlers.UpdateAvailabilityPatternHandler(deps.AvailabilityService)).Methods("PUT")
+	patterns.Handle("/{id}", handlers.DeleteAvailabilityPatternHandler(deps.AvailabilityService)).Methods("DELETE")
+
+	adminOnly := admins.NewRoute().Subrouter()
+	adminOnly.Use(middleware.RequireRole(middleware.RoleAdmin))
+
+	adminOnly.Handle("/bookings/all", h.ListAllBookingsHandler()).Methods("GET")
+	adminOnly.Handle("/bookings/{id}/complete", h.MarkBookingHandler(service.StatusCompleted)).Methods("PUT")
+	adminOnly.Handle("/bookings/{id}/no-show", h.MarkBookingHandler(service.StatusNoShow)).Methods("PUT")
+	adminOnly.Handle("/users/all", handlers.ListAllUsersHandler(q)).Methods("GET")
+	adminOnly.Handle("/users", handlers.DeleteUserHandler(q)).Methods("DELETE")
+	adminOnly.Handle("/users/{id}/role", handlers.UpdateUserRoleHandler(q, deps.Tokens)).Methods("PUT")
+	adminOnly.Handle("/users/{id}/revoke-tokens", handlers.RevokeUserTokensHandler(q, deps.Tokens)).Methods("POST")
+	adminOnly.Handle("/admins/create", handlers.CreateAdminHandler(q)).Methods("POST")
+	adminOnly.Handle("/webhooks/all", handlers.ListWebhookEndpointsHandler(q)).Methods("GET")
+	adminOnly.Handle("/webhooks/create", handlers.CreateWebhookEndpointHandler(q)).Methods("POST")
+	adminOnly.Handle("/webhooks/{id}", handlers.UpdateWebhookEndpointHandler(q)).Methods("PUT")
+	adminOnly.Handle("/webhooks/{id}", handlers.DeleteWebhookEndpointHandler(q)).Methods("DELETE")
+	adminOnly.Handle("/webhooks/{id}/deliveries", handlers.ListWebhookDeliveriesHandler(q)).Methods("GET")
+	adminOnly.Handle("/webhooks/deliveries/{id}/redeliver", handlers.RedeliverWebhookDeliveryHandler(q)).Methods("POST")
+
+	// Unmatched requests skip r.Use middleware, so wrap them explicitly.
+	observe := func(h http.Handler) http.Handler {
+		return tracing.Middleware(logging.Middleware(deps.Logger)(deps.Metrics.Middleware(h)))
+	}
+	r.Use(tracing.Middleware, logging.Middleware(deps.Logger), deps.Metrics.Middleware)
+	r.NotFoundHandler = observe(http.NotFoundHandler())
+	r.MethodNotAllowedHandler = observe(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+
+	return r
+}
@@ -0,0 +1,366 @@
+package router
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/auth"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/handlers"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/health"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/logging"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/metrics"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/middleware"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/service"
+)
+
+// stubQuerier answers every query a route can reach with an empty result.
+type stubQuerier struct {
+	db.Querier
+	userID uuid.UUID
+}
+
+func (s *stubQuerier) ExecTx(ctx context.Context, fn func(db.Querier) error) error {
+	return fn(s)
+}
+func (s *stubQuerier) WithConn(ctx context.Context, fn func(db.TxQuerier) error) error {
+	return fn(s)
+}
+func (s *stubQuerier) CreateOutboxEvent(ctx context.Context, arg db.CreateOutboxEventParams) error {
+	return nil
+}
+func (s *stubQuerier) CancelBookingReminders(ctx context.Context, bookingID uuid.UUID) error {
+	return nil
+}
+func (s *stubQuerier) ListAllFreeSlots(ctx context.Context, arg db.ListAllFreeSlotsParams) ([]db.ListAllFreeSlotsRow, error) {
+	return nil, nil
+}
+func (s *stubQuerier) ListAvailabilityByProvider(ctx context.Context, arg db.ListAvailabilityByProviderParams) ([]db.Availability, error) {
+	return nil, nil
+}
+func (s *stubQuerier) ListBookingsForUser(ctx context.Context, arg db.ListBookingsForUserParams) ([]db.Booking, error) {
+	return nil, nil
+}
+func (s *stubQuerier) ListAllBookingsForAdmin(ctx context.Context, arg db.ListAllBookingsForAdminParams) ([]db.Booking, error) {
+	return nil, nil
+}
+func (s *stubQuerier) GetBookingByID(ctx context.Context, id uuid.UUID) (db.Booking, error) {
+	return db.Booking{ID: id, UserID: s.userID, Status: service.StatusConfirmed}, nil
+}
+func (s *stubQuerier) GetAvailabilityByID(ctx context.Context, id uuid.UUID) (db.Availability, error) {
+	return db.Availability{ID: id}, nil
+}
+func (s *stubQuerier) CancelBooking(ctx context.Context, arg db.CancelBookingParams) (db.Booking, error) {
+	return db.Booking{ID: arg.ID, Status: service.StatusCancelled}, nil
+}
+func (s *stubQuerier) UpdateBookingStatus(ctx context.Context, arg db.UpdateBookingStatusParams) (db.Booking, error) {
+	return db.Booking{ID: arg.ID, Status: arg.Status}, nil
+}
+func (s *stubQuerier) ListUsers(ctx context.Context, arg db.ListUsersParams) ([]db.User, error) {
+	return nil, nil
+}
+func (s *stubQuerier) CreateAvailability(ctx context.Context, arg db.CreateAvailabilityParams) error {
+	return nil
+}
+func (s *stubQuerier) SlotHasBookings(ctx context.Context, slotID uuid.UUID) (bool, error) {
+	return false, nil
+}
+func (s *stubQuerier) DeleteAvailability(ctx context.Context, arg db.DeleteAvailabilityParams) error {
+	return nil
+}
+func (s *stubQuerier) ListPatternsByProvider(ctx context.Context, providerID uuid.UUID) ([]db.ListPatternsByProviderRow, error) {
+	return nil, nil
+}
+func (s *stubQuerier) GetAvailabilityPatternByID(ctx context.Context, id uuid.UUID) (db.AvailabilityPattern, error) {
+	return db.AvailabilityPattern{ID: id, ProviderID: s.userID}, nil
+}
+func (s *stubQuerier) UpdateAvailabilityPattern(ctx context.Context, arg db.UpdateAvailabilityPatternParams) (db.AvailabilityPattern, error) {
+	return db.AvailabilityPattern{ID: arg.ID, ProviderID: s.userID}, nil
+}
+func (s *stubQuerier) LockProviderSchedule(ctx context.Context, providerID uuid.UUID) error {
+	return nil
+}
+func (s *stubQuerier) ListPatternSlotsFrom(ctx context.Context, arg db.ListPatternSlotsFromParams) ([]db.ListPatternSlotsFromRow, error) {
+	return nil, nil
+}
+func (s *stubQuerier) DeleteAvailabilityPattern(ctx context.Context, arg db.DeleteAvailabilityPatternParams) error {
+	return nil
+}
+func (s *stubQuerier) IsAccessTokenRevoked(ctx context.Context, arg db.IsAccessTokenRevokedParams) (bool, error) {
+	return false, nil
+}
+func (s *stubQuerier) RevokeAccessToken(ctx context.Context, arg db.RevokeAccessTokenParams) error {
+	return nil
+}
+func (s *stubQuerier) ListWebhookEndpoints(ctx context.Context) ([]db.WebhookEndpoint, error) {
+	return nil, nil
+}
+func (s *stubQuerier) ListWebhookSubscriptions(ctx context.Context, endpointID uuid.NullUUID) ([]db.WebhookSubscription, error) {
+	return nil, nil
+}
+func (s *stubQuerier) GetWebhookEndpointByID(ctx context.Context, id uuid.UUID) (db.WebhookEndpoint, error) {
+	return db.WebhookEndpoint{}, sql.ErrNoRows
+}
+func (s *stubQuerier) DeleteWebhookEndpoint(ctx context.Context, id uuid.UUID) (int64, error) {
+	return 0, nil
+}
+func (s *stubQuerier) RedeliverWebhookDelivery(ctx context.Context, id uuid.UUID) (db.WebhookDelivery, error) {
+	return db.WebhookDelivery{}, sql.ErrNoRows
+}
+func (s *stubQuerier) RevokeUserTokens(ctx context.Context, arg db.RevokeUserTokensParams) (int64, error) {
+	return 1, nil
+}
+func (s *stubQuerier) RevokeRefreshTokensForUser(ctx context.Context, userID uuid.UUID) error {
+	return nil
+}
+func (s *stubQuerier) UpdateUserRole(ctx context.Context, arg db.UpdateUserRoleParams) (int64, error) {
+	return 1, nil
+}
+
+type routeCase struct {
+	method string
+	path   string
+	public bool
+	// roles restricts the route to these roles; nil means any signed-in user.
+	roles []string
+}
+
+var (
+	adminOnly    = []string{middleware.RoleAdmin}
+	scheduleRole = []string{middleware.RoleProvider, middleware.RoleAdmin}
+)
+
+// routes mirrors the table in the package doc; TestRoutesCovered keeps the two
+// in sync with what New actually registers.
+var routes = []routeCase{
+	{"POST", "/api/register", true, nil},
+	{"POST", "/api/login", true, nil},
+	{"GET", "/api/availabilities/free", true, nil},
+	{"POST", "/api/token/refresh", true, nil},
+	{"GET", "/.well-known/jwks.json", true, nil},
+	{"GET", "/healthz", true, nil},
+	{"GET", "/readyz", true, nil},
+	{"GET", "/metrics", true, nil},
+
+	{"POST", "/api/logout", false, nil},
+
+	{"GET", "/api/availabilities/provider/{provider_id}", false, nil},
+	{"GET", "/api/bookings/user", false, nil},
+	{"POST", "/api/bookings/create", false, nil},
+	{"GET", "/api/bookings/{id}", false, nil},
+	{"PUT", "/api/bookings/{id}", false, nil},
+	{"DELETE", "/api/bookings/{id}", false, nil},
+	{"PUT", "/api/users/me", false, nil},
+	{"PUT", "/api/users/me/timezone", false, nil},
+
+	{"GET", "/api/admin/bookings/all", false, adminOnly},
+	{"PUT", "/api/admin/bookings/{id}/complete", false, adminOnly},
+	{"PUT", "/api/admin/bookings/{id}/no-show", false, adminOnly},
+	{"GET", "/api/admin/users/all", false, adminOnly},
+	{"DELETE", "/api/admin/users", false, adminOnly},
+	{"PUT", "/api/admin/users/{id}/role", false, adminOnly},
+	{"POST", "/api/admin/users/{id}/revoke-tokens", false, adminOnly},
+	{"POST", "/api/admin/admins/create", false, adminOnly},
+	{"GET", "/api/admin/webhooks/all", false, adminOnly},
+	{"POST", "/api/admin/webhooks/create", false, adminOnly},
+	{"PUT", "/api/admin/webhooks/{id}", false, adminOnly},
+	{"DELETE", "/api/admin/webhooks/{id}", false, adminOnly},
+	{"GET", "/api/admin/webhooks/{id}/deliveries", false, adminOnly},
+	{"POST", "/api/admin/webhooks/deliveries/{id}/redeliver", false, adminOnly},
+	{"POST", "/api/admin/availability/create", false, scheduleRole},
+	{"GET", "/api/admin/availability/range", false, scheduleRole},
+	{"DELETE", "/api/admin/availability/{id}", false, scheduleRole},
+	{"POST", "/api/admin/avail-pattern/create", false, scheduleRole},
+	{"GET", "/api/admin/avail-pattern/provider/{provider_id}", false, scheduleRole},
+	{"PUT", "/api/admin/avail-pattern/{id}", false, scheduleRole},
+	{"DELETE", "/api/admin/avail-pattern/{id}", false, scheduleRole},
+}
+
+func newTestServer(t *testing.T, userID uuid.UUID) *httptest.Server {
+	t.Helper()
+
+	key, err := auth.NewHMACKey("test", []byte("testsecret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := auth.NewKeySet(time.Hour, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q := &stubQuerier{userID: userID}
+	srv := httptest.NewServer(New(Deps{
+		Queries:             q,
+		Tokens:              handlers.Tokens{Keys: keys, AccessTTL: 15 * time.Minute, RefreshTTL: time.Hour},
+		BookingService:      service.NewBookingService(q),
+		AvailabilityService: service.NewAvailabilityService(q),
+		Health:              health.NewChecker(time.Second, nil),
+		Metrics:             metrics.New(),
+		Logger:              slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func tokenFor(t *testing.T, userID uuid.UUID, role string) string {
+	t.Helper()
+	tok := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":       userID.String(),
+		"jti":       uuid.NewString(),
+		"user_role": role,
+		"exp":       time.Now().Add(time.Hour).Unix(),
+	})
+	s, err := tok.SignedString([]byte("testsecret"))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return s
+}
+
+// concretePath fills in path's parameters; {provider_id} is the caller, so
+// ownership checks pass for every role the route admits.
+func concretePath(path string, userID uuid.UUID) string {
+	path = strings.ReplaceAll(path, "{provider_id}", userID.String())
+	return strings.ReplaceAll(path, "{id}", uuid.NewString())
+}
+
+func TestRoutesReachable(t *testing.T) {
+	userID := uuid.New()
+	srv := newTestServer(t, userID)
+	token := tokenFor(t, userID, middleware.RoleAdmin)
+
+	for _, rt := range routes {
+		t.Run(rt.method+" "+rt.path, func(t *testing.T) {
+			req, err := http.NewRequest(rt.method, srv.URL+concretePath(rt.path, userID), strings.NewReader(`{}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			// Handlers answer with JSON (including problem+json), 200 or 204; mux's own 404/405
+			// responses do none of these.
+			handled := resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNoContent ||
+				strings.HasSuffix(strings.Split(resp.Header.Get("Content-Type"), ";")[0], "json")
+			if !handled || resp.StatusCode == http.StatusMethodNotAllowed {
+				t.Errorf("route not reached: status %d, content-type %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+			}
+			if resp.Header.Get(logging.RequestIDHeader) == "" {
+				t.Error("response has no request ID")
+			}
+		})
+	}
+}
+
+func TestProtectedRoutesRequireToken(t *testing.T) {
+	srv := newTestServer(t, uuid.New())
+
+	for _, rt := range routes {
+		if rt.public {
+			continue
+		}
+		t.Run(rt.method+" "+rt.path, func(t *testing.T) {
+			req, err := http.NewRequest(rt.method, srv.URL+concretePath(rt.path, uuid.New()), strings.NewReader(`{}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusUnauthorized {
+				t.Errorf("expected status %d, got %d", http.StatusUnauthorized, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestRoutePermissions(t *testing.T) {
+	userID := uuid.New()
+	srv := newTestServer(t, userID)
+
+	for _, role := range []string{middleware.RoleUser, middleware.RoleProvider, middleware.RoleAdmin} {
+		token := tokenFor(t, userID, role)
+		for _, rt := range routes {
+			if rt.public {
+				continue
+			}
+			allowed := rt.roles == nil || slices.Contains(rt.roles, role)
+			t.Run(role+" "+rt.method+" "+rt.path, func(t *testing.T) {
+				req, err := http.NewRequest(rt.method, srv.URL+concretePath(rt.path, userID), strings.NewReader(`{}`))
+				if err != nil {
+					t.Fatal(err)
+				}
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("Authorization", "Bearer "+token)
+
+				resp, err := srv.Client().Do(req)
+				if err != nil {
+					t.Fatalf("request failed: %v", err)
+				}
+				defer resp.Body.Close()
+
+				switch {
+				case !allowed && resp.StatusCode != http.StatusForbidden:
+					t.Errorf("expected status %d, got %d", http.StatusForbidden, resp.StatusCode)
+				case allowed && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden):
+					t.Errorf("role %s was refused with status %d", role, resp.StatusCode)
+				}
+			})
+		}
+	}
+}
+
+func TestRoutesCovered(t *testing.T) {
+	known := map[string]bool{}
+	for _, rt := range routes {
+		known[rt.method+" "+rt.path] = true
+	}
+
+	r := New(Deps{Queries: &stubQuerier{}, Metrics: metrics.New()})
+	err := r.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			// Subrouter prefixes carry no methods of their own.
+			return nil
+		}
+		for _, m := range methods {
+			if !known[m+" "+path] {
+				t.Errorf("route %s %s is not covered by the routes table", m, path)
+			}
+			delete(known, m+" "+path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k := range known {
+		t.Errorf("routes table lists %s but New does not register it", k)
+	}
+}
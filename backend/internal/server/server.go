@@ -0,0 +1,83 @@
+// Package server runs the HTTP server until it is told to stop.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Shutdown describes how Serve stops once its context is cancelled.
+type Shutdown struct {
+	// Drain, if set, runs first, e.g. to fail readiness.
+	Drain func()
+	// ReadinessDelay is how long the server keeps accepting requests after
+	// Drain, so load balancers see the failing readiness probe and stop
+	// sending traffic before the listener closes.
+	ReadinessDelay time.Duration
+	// Timeout is how long in-flight requests, and then Background, get to
+	// finish once the listener has closed.
+	Timeout time.Duration
+	// Background, if set, is waited for after the server has stopped, so
+	// background jobs can finish their current run.
+	Background *sync.WaitGroup
+}
+
+// Serve accepts connections on ln until ctx is cancelled, then shuts down
+// as sd describes: it drains, keeps serving for the readiness delay, stops
+// accepting new connections and waits up to sd.Timeout for in-flight
+// requests and background work to finish.
+func Serve(ctx context.Context, srv *http.Server, ln net.Listener, sd Shutdown) error {
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("Shutting down, draining for up to %s…", sd.ReadinessDelay+sd.Timeout)
+	if sd.Drain != nil {
+		sd.Drain()
+	}
+	if sd.ReadinessDelay > 0 {
+		select {
+		case err := <-errc:
+			return err
+		case <-time.After(sd.ReadinessDelay):
+		}
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), sd.Timeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		// Whatever is still running gets cut off.
+		closeErr := srv.Close()
+		return errors.Join(fmt.Errorf("drain: %w", err), closeErr)
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	if sd.Background != nil {
+		done := make(chan struct{})
+		go func() {
+			sd.Background.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-shutdownCtx.Done():
+			return fmt.Errorf("background jobs: %w", shutdownCtx.Err())
+		}
+	}
+	return nil
+}
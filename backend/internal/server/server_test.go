@@ -0,0 +1,225 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// startServer runs Serve on a loopback port with a handler that blocks until
+// release is closed, and reports when a request has reached it.
+func startServer(t *testing.T, ctx context.Context, drain time.Duration, beforeShutdown func()) (url string, started <-chan struct{}, release chan struct{}, done <-chan error) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	startedc := make(chan struct{}, 1)
+	release = make(chan struct{})
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		startedc <- struct{}{}
+		<-release
+		io.WriteString(w, "booked")
+	})}
+
+	donec := make(chan error, 1)
+	go func() { donec <- Serve(ctx, srv, ln, Shutdown{Drain: beforeShutdown, Timeout: drain}) }()
+
+	return "http://" + ln.Addr().String(), startedc, release, donec
+}
+
+func TestServe_DrainsInFlightRequests(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	drained := false
+	url, started, release, done := startServer(t, ctx, 5*time.Second, func() { drained = true })
+
+	type result struct {
+		body string
+		err  error
+	}
+	results := make(chan result, 1)
+	go func() {
+		resp, err := http.Get(url)
+		if err != nil {
+			results <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		results <- result{string(b), err}
+	}()
+
+	<-started
+	cancel() // SIGTERM arrives while the request is in flight.
+
+	// New connections are refused once shutdown starts.
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		conn, err := net.DialTimeout("tcp", url[len("http://"):], 100*time.Millisecond)
+		if err != nil {
+			break
+		}
+		conn.Close()
+		if time.Now().After(deadline) {
+			t.Fatal("listener still accepting connections after shutdown began")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	close(release)
+
+	res := <-results
+	if res.err != nil || res.body != "booked" {
+		t.Fatalf("in-flight request was cut off: body=%q err=%v", res.body, res.err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Serve returned %v", err)
+	}
+	if !drained {
+		t.Error("beforeShutdown was not called")
+	}
+}
+
+func TestServe_DrainTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	url, started, release, done := startServer(t, ctx, 50*time.Millisecond, nil)
+	defer close(release)
+
+	go func() {
+		resp, err := http.Get(url)
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+
+	<-started
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected drain deadline error, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not give up after the drain timeout")
+	}
+}
+
+func TestServe_ListenerError(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln.Close()
+
+	err = Serve(context.Background(), &http.Server{}, ln, Shutdown{Timeout: time.Second})
+	if err == nil || errors.Is(err, http.ErrServerClosed) {
+		t.Fatalf("expected the listener error, got %v", err)
+	}
+}
+
+func TestServe_KeepsServingForReadinessDelay(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	url := "http://" + ln.Addr().String()
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	})}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	drained := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- Serve(ctx, srv, ln, Shutdown{
+			Drain:          func() { close(drained) },
+			ReadinessDelay: 300 * time.Millisecond,
+			Timeout:        time.Second,
+		})
+	}()
+
+	cancel()
+	<-drained
+	// Load balancers still routing here until they see readiness fail must
+	// not get connection errors.
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("request during the readiness delay failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if err := <-done; err != nil {
+		t.Fatalf("Serve returned %v", err)
+	}
+}
+
+func TestServe_WaitsForBackgroundJobs(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		finish  bool
+		wantErr error
+	}{
+		{name: "Jobs finish", finish: true},
+		{name: "Jobs overrun the timeout", wantErr: context.DeadlineExceeded},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var jobs sync.WaitGroup
+			jobs.Add(1)
+			release := make(chan struct{})
+			defer close(release)
+			finished := make(chan struct{})
+			go func() {
+				defer jobs.Done()
+				<-ctx.Done()
+				if tt.finish {
+					time.Sleep(50 * time.Millisecond)
+					close(finished)
+					return
+				}
+				<-release
+			}()
+
+			done := make(chan error, 1)
+			go func() {
+				done <- Serve(ctx, &http.Server{}, ln, Shutdown{Timeout: 200 * time.Millisecond, Background: &jobs})
+			}()
+			cancel()
+
+			err = <-done
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Serve returned %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Serve returned %v", err)
+			}
+			select {
+			case <-finished:
+			default:
+				t.Error("Serve returned before the job finished")
+			}
+		})
+	}
+}
@@ -2,83 +2,719 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"slices"
 	"time"
 
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/apperr"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/events"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+var ErrPatternNotFound = apperr.New(apperr.KindNotFound, "Pattern not found")
+var ErrPatternNotOwned = apperr.New(apperr.KindForbidden, "You do not own this pattern")
+
 type AvailabilityStore interface {
-	CreateAvailabilityPattern(ctx context.Context, arg db.CreateAvailabilityPatternParams) error
-	CreateAvailability(ctx context.Context, arg db.CreateAvailabilityParams) error
+	timezoneGetter
+	ExecTx(ctx context.Context, fn func(db.Querier) error) error
+	WithConn(ctx context.Context, fn func(db.TxQuerier) error) error
+}
+
+// timezoneGetter is the part of a store or transaction providerLocation
+// reads from.
+type timezoneGetter interface {
+	GetUserTimezone(ctx context.Context, id uuid.UUID) (string, error)
+}
+
+// slotCreator is the part of a transaction generateSlots writes to.
+type slotCreator interface {
+	CreatePatternSlot(ctx context.Context, arg db.CreatePatternSlotParams) (int64, error)
 }
 
 type AvailabilityService struct {
-	store AvailabilityStore
+	store        AvailabilityStore
+	now          func() time.Time
+	horizonWeeks int
 }
 
-func NewAvailabilityService(store AvailabilityStore) *AvailabilityService {
-	return &AvailabilityService{store: store}
+type AvailabilityOption func(*AvailabilityService)
+
+// WithSlotHorizon has CreatePatternAndSlots generate slots only up to weeks
+// ahead, leaving the rest of a longer pattern to ExtendPatterns. Without it
+// every slot up to the pattern's end is generated at once.
+func WithSlotHorizon(weeks int) AvailabilityOption {
+	return func(s *AvailabilityService) { s.horizonWeeks = weeks }
 }
 
+func NewAvailabilityService(store AvailabilityStore, opts ...AvailabilityOption) *AvailabilityService {
+	s := &AvailabilityService{store: store, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// SlotSettings controls how a pattern's daily window is cut into slots. Zero
+// SlotMinutes and Capacity take the defaults of one hour and one booking.
+type SlotSettings struct {
+	SlotMinutes int32
+	// BufferBeforeMinutes and BufferAfterMinutes are kept free around every
+	// slot and must fit inside the window, so consecutive slots are
+	// BufferAfterMinutes+BufferBeforeMinutes apart. They only shape slot
+	// generation: bookings are checked against each other's times, not
+	// buffers, so a one-off slot may still be booked inside a buffer.
+	BufferBeforeMinutes int32
+	BufferAfterMinutes  int32
+	// Capacity is how many bookings each slot takes; above one it is a
+	// group session.
+	Capacity int32
+}
+
+func (c SlotSettings) withDefaults() SlotSettings {
+	if c.SlotMinutes == 0 {
+		c.SlotMinutes = 60
+	}
+	if c.Capacity == 0 {
+		c.Capacity = 1
+	}
+	return c
+}
+
+// CreatePatternAndSlots stores a weekly pattern and generates slots for it
+// on every matching day from start's date to end's date, the pattern's
+// ends_on. start and end are read in the provider's timezone: their dates
+// bound the range and their clock times give the daily window, so slots keep
+// the same wall-clock times across DST changes. With WithSlotHorizon, slots
+// beyond the horizon are left for ExtendPatterns. The pattern, its slots and
+// a PatternChanged event are stored in one transaction.
 func (s *AvailabilityService) CreatePatternAndSlots(
 	ctx context.Context,
 	providerID uuid.UUID,
 	dayOfWeek int32,
 	start, end time.Time,
-) error {
+	settings SlotSettings,
+) (err error) {
+	ctx, span := startSpan(ctx, "AvailabilityService.CreatePatternAndSlots",
+		attribute.String("availability.provider_id", providerID.String()),
+		attribute.Int("availability.day_of_week", int(dayOfWeek)))
+	defer endSpan(span, &err)
+
+	loc, err := providerLocation(ctx, s.store, providerID)
+	if err != nil {
+		return err
+	}
+	start, end = start.In(loc), end.In(loc)
+	settings = settings.withDefaults()
+	span.SetAttributes(
+		attribute.String("availability.timezone", loc.String()),
+		attribute.Int("availability.slot_minutes", int(settings.SlotMinutes)),
+		attribute.Int("availability.capacity", int(settings.Capacity)))
+
+	// Slots are generated through end's date, or up to the horizon if that
+	// comes first.
+	until := dayAfter(end, loc)
+	if s.horizonWeeks > 0 {
+		until = earliest(until, s.now().AddDate(0, 0, 7*s.horizonWeeks))
+	}
+
+	// TIME columns drop the offset, keeping the provider's wall-clock time.
 	pattern := db.CreateAvailabilityPatternParams{
-		ID:         uuid.New(),
-		ProviderID: providerID,
-		DayOfWeek:  dayOfWeek,
-		StartTime:  start,
-		EndTime:    end,
+		ID:                  uuid.New(),
+		ProviderID:          providerID,
+		DayOfWeek:           dayOfWeek,
+		StartTime:           db.NewTimeOfDay(start),
+		EndTime:             db.NewTimeOfDay(end),
+		SlotMinutes:         settings.SlotMinutes,
+		BufferBeforeMinutes: settings.BufferBeforeMinutes,
+		BufferAfterMinutes:  settings.BufferAfterMinutes,
+		Capacity:            settings.Capacity,
+		GeneratedUntil:      until,
+		EndsOn:              time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC),
 	}
-	if err := s.store.CreateAvailabilityPattern(ctx, pattern); err != nil {
-		return fmt.Errorf("create pattern: %w", err)
+	return s.store.ExecTx(ctx, func(q db.Querier) error {
+		if err := q.CreateAvailabilityPattern(ctx, pattern); err != nil {
+			return fmt.Errorf("create pattern: %w", err)
+		}
+
+		err := generateSlots(
+			ctx,
+			time.Weekday(dayOfWeek),
+			start,
+			end,
+			until,
+			loc,
+			settings,
+			providerID,
+			uuid.NullUUID{UUID: pattern.ID, Valid: true},
+			q,
+		)
+		if err != nil {
+			return err
+		}
+
+		return events.Emit(ctx, q, events.PatternChanged{
+			PatternID:  pattern.ID,
+			ProviderID: providerID,
+			Change:     events.PatternCreated,
+		})
+	})
+}
+
+// PatternUpdate replaces a pattern's day and daily window. Nil slot settings
+// keep their current values. Start and End are read as in
+// CreatePatternAndSlots, but only their clock times are used.
+type PatternUpdate struct {
+	DayOfWeek           int32
+	Start, End          time.Time
+	SlotMinutes         *int32
+	BufferBeforeMinutes *int32
+	BufferAfterMinutes  *int32
+	Capacity            *int32
+}
+
+// SlotChanges reports what regenerating a pattern did to its future slots.
+type SlotChanges struct {
+	Created int
+	// Removed counts slots taken out of the pattern: deleted if they were
+	// never booked, otherwise closed and kept for their cancelled bookings.
+	Removed int
+	// Stranded lists booked slots that no longer fit the pattern. They are
+	// kept, and unlinked from the pattern if it was deleted, until an admin
+	// cancels or reschedules their bookings.
+	Stranded []db.ListPatternSlotsFromRow
+}
+
+// UpdatePattern changes a pattern and regenerates its future slots in the
+// same transaction on behalf of actorID, who must own the pattern or be an
+// admin. Only slots up to the pattern's generated_until are
+// regenerated; ExtendPatterns takes it further. Slots that no longer fit
+// are removed as removeSlot describes, unless they have active bookings;
+// those are reported in SlotChanges.Stranded. A PatternChanged event is
+// stored in the same transaction.
+func (s *AvailabilityService) UpdatePattern(
+	ctx context.Context,
+	patternID uuid.UUID,
+	actorID uuid.UUID,
+	isAdmin bool,
+	u PatternUpdate,
+) (_ db.AvailabilityPattern, _ SlotChanges, err error) {
+	ctx, span := startSpan(ctx, "AvailabilityService.UpdatePattern",
+		attribute.String("availability.pattern_id", patternID.String()))
+	defer endSpan(span, &err)
+
+	var pattern db.AvailabilityPattern
+	var changes SlotChanges
+	err = s.store.ExecTx(ctx, func(q db.Querier) error {
+		existing, err := getOwnedPattern(ctx, q, patternID, actorID, isAdmin)
+		if err != nil {
+			return err
+		}
+		providerID := existing.ProviderID
+		loc, err := providerLocation(ctx, q, providerID)
+		if err != nil {
+			return err
+		}
+		if err := q.LockProviderSchedule(ctx, providerID); err != nil {
+			return err
+		}
+
+		pattern, err = q.UpdateAvailabilityPattern(ctx, db.UpdateAvailabilityPatternParams{
+			DayOfWeek:           u.DayOfWeek,
+			StartTime:           db.NewTimeOfDay(u.Start.In(loc)),
+			EndTime:             db.NewTimeOfDay(u.End.In(loc)),
+			SlotMinutes:         valueOr(u.SlotMinutes, existing.SlotMinutes),
+			BufferBeforeMinutes: valueOr(u.BufferBeforeMinutes, existing.BufferBeforeMinutes),
+			BufferAfterMinutes:  valueOr(u.BufferAfterMinutes, existing.BufferAfterMinutes),
+			Capacity:            valueOr(u.Capacity, existing.Capacity),
+			ID:                  patternID,
+		})
+		if err != nil {
+			return fmt.Errorf("update pattern: %w", err)
+		}
+
+		changes, err = regenerateSlots(ctx, q, pattern, loc, s.now())
+		if err != nil {
+			return err
+		}
+
+		return events.Emit(ctx, q, events.PatternChanged{
+			PatternID:  patternID,
+			ProviderID: providerID,
+			Change:     events.PatternUpdated,
+		})
+	})
+	if err != nil {
+		return db.AvailabilityPattern{}, SlotChanges{}, err
 	}
 
-	return generateSlots(
-		ctx,
-		time.Weekday(dayOfWeek),
-		start,
-		end,
-		providerID,
-		s.store,
-	)
+	span.SetAttributes(
+		attribute.Int("availability.slots_created", changes.Created),
+		attribute.Int("availability.slots_removed", changes.Removed),
+		attribute.Int("availability.slots_stranded", len(changes.Stranded)))
+	return pattern, changes, nil
 }
 
+// DeletePattern deletes a pattern and removes its future slots, as
+// removeSlot describes, in one transaction on behalf of actorID, who must
+// own the pattern or be an admin. Future slots with active bookings are
+// kept and reported in SlotChanges.Stranded; they and past slots lose their
+// link to the pattern.
+// A PatternChanged event is stored in the same transaction.
+func (s *AvailabilityService) DeletePattern(ctx context.Context, patternID, actorID uuid.UUID, isAdmin bool) (_ SlotChanges, err error) {
+	ctx, span := startSpan(ctx, "AvailabilityService.DeletePattern",
+		attribute.String("availability.pattern_id", patternID.String()))
+	defer endSpan(span, &err)
+
+	var changes SlotChanges
+	err = s.store.ExecTx(ctx, func(q db.Querier) error {
+		pattern, err := getOwnedPattern(ctx, q, patternID, actorID, isAdmin)
+		if err != nil {
+			return err
+		}
+		providerID := pattern.ProviderID
+		if err := q.LockProviderSchedule(ctx, providerID); err != nil {
+			return err
+		}
+
+		slots, err := q.ListPatternSlotsFrom(ctx, db.ListPatternSlotsFromParams{
+			PatternID: uuid.NullUUID{UUID: patternID, Valid: true},
+			StartFrom: s.now(),
+		})
+		if err != nil {
+			return fmt.Errorf("list pattern slots: %w", err)
+		}
+		for _, slot := range slots {
+			if slot.Booked > 0 {
+				changes.Stranded = append(changes.Stranded, slot)
+				continue
+			}
+			if err := removeSlot(ctx, q, slot, providerID); err != nil {
+				return err
+			}
+			changes.Removed++
+		}
+
+		err = q.DeleteAvailabilityPattern(ctx, db.DeleteAvailabilityPatternParams{
+			ID:         patternID,
+			ProviderID: providerID,
+		})
+		if err != nil {
+			return err
+		}
+
+		return events.Emit(ctx, q, events.PatternChanged{
+			PatternID:  patternID,
+			ProviderID: providerID,
+			Change:     events.PatternDeleted,
+		})
+	})
+	if err != nil {
+		return SlotChanges{}, err
+	}
+	return changes, nil
+}
+
+// getOwnedPattern loads a pattern, returning ErrPatternNotFound or
+// ErrPatternNotOwned unless it exists and belongs to actorID or actorID is
+// an admin.
+func getOwnedPattern(ctx context.Context, q db.Querier, patternID, actorID uuid.UUID, isAdmin bool) (db.AvailabilityPattern, error) {
+	pattern, err := q.GetAvailabilityPatternByID(ctx, patternID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.AvailabilityPattern{}, ErrPatternNotFound
+		}
+		return db.AvailabilityPattern{}, err
+	}
+	if !isAdmin && pattern.ProviderID != actorID {
+		return db.AvailabilityPattern{}, ErrPatternNotOwned
+	}
+	return pattern, nil
+}
+
+// regenerateSlots brings the pattern's slots from now up to its
+// generated_until in line with the pattern. Slots whose times still match
+// are kept, with their capacity updated but never lowered below their
+// bookings.
+func regenerateSlots(
+	ctx context.Context,
+	q db.Querier,
+	pattern db.AvailabilityPattern,
+	loc *time.Location,
+	now time.Time,
+) (SlotChanges, error) {
+	var changes SlotChanges
+	patternID := uuid.NullUUID{UUID: pattern.ID, Valid: true}
+
+	existing, err := q.ListPatternSlotsFrom(ctx, db.ListPatternSlotsFromParams{
+		PatternID: patternID,
+		StartFrom: now,
+	})
+	if err != nil {
+		return changes, fmt.Errorf("list pattern slots: %w", err)
+	}
+
+	planned := make(map[plannedSlot]bool)
+	for _, p := range planPatternSlots(pattern, loc, now, pattern.GeneratedUntil) {
+		planned[p] = true
+	}
+
+	for _, slot := range existing {
+		key := plannedSlot{Start: slot.StartTime.UTC(), End: slot.EndTime.UTC()}
+		if planned[key] {
+			delete(planned, key)
+			capacity := max(pattern.Capacity, slot.Booked)
+			if capacity != slot.Capacity {
+				if err := q.UpdateAvailabilityCapacity(ctx, db.UpdateAvailabilityCapacityParams{
+					Capacity: capacity,
+					ID:       slot.ID,
+				}); err != nil {
+					return changes, fmt.Errorf("update slot %s: %w", slot.ID, err)
+				}
+			}
+			continue
+		}
+		if slot.Booked > 0 {
+			changes.Stranded = append(changes.Stranded, slot)
+			continue
+		}
+		if err := removeSlot(ctx, q, slot, pattern.ProviderID); err != nil {
+			return changes, err
+		}
+		changes.Removed++
+	}
+
+	// Create what is left in start order so IDs and errors are predictable.
+	// A slot the provider already has at that start is left as it is.
+	for _, p := range sortedSlots(planned) {
+		n, err := q.CreatePatternSlot(ctx, db.CreatePatternSlotParams{
+			ID:         uuid.New(),
+			ProviderID: pattern.ProviderID,
+			StartTime:  p.Start,
+			EndTime:    p.End,
+			Capacity:   pattern.Capacity,
+			PatternID:  patternID,
+		})
+		if err != nil {
+			return changes, fmt.Errorf("create availability on %s: %w", p.Start.In(loc).Format("2006-01-02 15:04 MST"), err)
+		}
+		changes.Created += int(n)
+	}
+	return changes, nil
+}
+
+// removeSlot takes a slot without active bookings out of its pattern. A
+// slot that was never booked is deleted. One with cancelled bookings is
+// closed instead, since deleting it would delete them too; pattern slots
+// generated at the same start later reopen it.
+func removeSlot(ctx context.Context, q db.Querier, slot db.ListPatternSlotsFromRow, providerID uuid.UUID) error {
+	if slot.TotalBookings > 0 {
+		if err := q.CloseAvailability(ctx, slot.ID); err != nil {
+			return fmt.Errorf("close slot %s: %w", slot.ID, err)
+		}
+		return nil
+	}
+	if err := q.DeleteAvailability(ctx, db.DeleteAvailabilityParams{ID: slot.ID, ProviderID: providerID}); err != nil {
+		return fmt.Errorf("delete slot %s: %w", slot.ID, err)
+	}
+	return nil
+}
+
+// MaterializeResult reports one ExtendPatterns run.
+type MaterializeResult struct {
+	// Skipped is set when another process was already running it.
+	Skipped  bool
+	Patterns int
+	Created  int
+}
+
+// ExtendPatterns generates every pattern's slots up to weeks ahead of now,
+// or to the end of its ends_on if that comes first, carrying on from each
+// pattern's generated_until.
+//
+// Runs are serialised across processes by a Postgres session advisory lock;
+// a run that cannot take it returns at once with Skipped set. The run keeps
+// to the one connection holding the lock, extending each pattern in its own
+// transaction under its provider's schedule lock. Slots the provider already
+// has are left alone, so a failed or repeated run is safe to retry. A
+// failing pattern does not stop the others.
+func (s *AvailabilityService) ExtendPatterns(ctx context.Context, weeks int) (_ MaterializeResult, err error) {
+	now := s.now()
+	horizon := now.AddDate(0, 0, 7*weeks)
+	ctx, span := startSpan(ctx, "AvailabilityService.ExtendPatterns",
+		attribute.String("availability.horizon", horizon.Format(time.RFC3339)))
+	defer endSpan(span, &err)
+
+	var result MaterializeResult
+	err = s.store.WithConn(ctx, func(conn db.TxQuerier) (err error) {
+		locked, err := conn.TryLockSlotMaterializer(ctx)
+		if err != nil {
+			// The lock may have been taken all the same.
+			return fmt.Errorf("lock materializer: %w: %w", db.ErrDirtyConn, err)
+		}
+		if !locked {
+			result.Skipped = true
+			return nil
+		}
+		defer func() {
+			// Unlock even when ctx is done, or the lock outlives the run.
+			if _, unlockErr := conn.UnlockSlotMaterializer(context.WithoutCancel(ctx)); unlockErr != nil {
+				err = errors.Join(err, fmt.Errorf("unlock materializer: %w: %w", db.ErrDirtyConn, unlockErr))
+			}
+		}()
+
+		patterns, err := conn.ListPatternsToExtend(ctx, horizon)
+		if err != nil {
+			return fmt.Errorf("list patterns: %w", err)
+		}
+		var errs []error
+		for _, p := range patterns {
+			created, err := extendPattern(ctx, conn, p.ID, p.ProviderID, now, horizon)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("extend pattern %s: %w", p.ID, err))
+				continue
+			}
+			result.Patterns++
+			result.Created += created
+		}
+		return errors.Join(errs...)
+	})
+
+	span.SetAttributes(
+		attribute.Bool("availability.skipped", result.Skipped),
+		attribute.Int("availability.patterns", result.Patterns),
+		attribute.Int("availability.slots_created", result.Created))
+	return result, err
+}
+
+// extendPattern creates a pattern's slots from its generated_until, or now
+// if that has passed, up to horizon or the end of its ends_on, whichever is
+// first, and moves generated_until there.
+func extendPattern(ctx context.Context, store db.TxQuerier, patternID, providerID uuid.UUID, now, horizon time.Time) (int, error) {
+	loc, err := providerLocation(ctx, store, providerID)
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	err = store.ExecTx(ctx, func(q db.Querier) error {
+		if err := q.LockProviderSchedule(ctx, providerID); err != nil {
+			return err
+		}
+		// Read under the lock so a concurrent UpdatePattern is seen.
+		pattern, err := q.GetAvailabilityPatternByID(ctx, patternID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				// Deleted since it was listed.
+				return nil
+			}
+			return err
+		}
+
+		from := pattern.GeneratedUntil
+		if from.Before(now) {
+			from = now
+		}
+		until := earliest(horizon, dayAfter(pattern.EndsOn, loc))
+		if !pattern.GeneratedUntil.Before(until) {
+			return nil
+		}
+		for _, p := range planPatternSlots(pattern, loc, from, until) {
+			n, err := q.CreatePatternSlot(ctx, db.CreatePatternSlotParams{
+				ID:         uuid.New(),
+				ProviderID: pattern.ProviderID,
+				StartTime:  p.Start,
+				EndTime:    p.End,
+				Capacity:   pattern.Capacity,
+				PatternID:  uuid.NullUUID{UUID: pattern.ID, Valid: true},
+			})
+			if err != nil {
+				return fmt.Errorf("create availability on %s: %w", p.Start.In(loc).Format("2006-01-02 15:04 MST"), err)
+			}
+			created += int(n)
+		}
+
+		return q.SetPatternGeneratedUntil(ctx, db.SetPatternGeneratedUntilParams{
+			GeneratedUntil: until,
+			ID:             pattern.ID,
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+	return created, nil
+}
+
+// planPatternSlots lays out the pattern's slots starting in [from, until).
+func planPatternSlots(pattern db.AvailabilityPattern, loc *time.Location, from, until time.Time) []plannedSlot {
+	if !from.Before(until) {
+		return nil
+	}
+	var slots []plannedSlot
+	for _, p := range planSlots(
+		time.Weekday(pattern.DayOfWeek),
+		from,
+		until,
+		pattern.StartTime,
+		pattern.EndTime,
+		loc,
+		patternSettings(pattern),
+	) {
+		if !p.Start.Before(from) && p.Start.Before(until) {
+			slots = append(slots, p)
+		}
+	}
+	return slots
+}
+
+func patternSettings(p db.AvailabilityPattern) SlotSettings {
+	return SlotSettings{
+		SlotMinutes:         p.SlotMinutes,
+		BufferBeforeMinutes: p.BufferBeforeMinutes,
+		BufferAfterMinutes:  p.BufferAfterMinutes,
+		Capacity:            p.Capacity,
+	}
+}
+
+// dayAfter returns the midnight in loc that ends d's date. A DATE column
+// scanned as midnight UTC gives its own date.
+func dayAfter(d time.Time, loc *time.Location) time.Time {
+	return time.Date(d.Year(), d.Month(), d.Day()+1, 0, 0, 0, 0, loc)
+}
+
+func earliest(a, b time.Time) time.Time {
+	if b.Before(a) {
+		return b
+	}
+	return a
+}
+
+func valueOr(p *int32, fallback int32) int32 {
+	if p == nil {
+		return fallback
+	}
+	return *p
+}
+
+// providerLocation loads the IANA timezone the provider's patterns are
+// written in.
+func providerLocation(ctx context.Context, q timezoneGetter, providerID uuid.UUID) (*time.Location, error) {
+	tz, err := q.GetUserTimezone(ctx, providerID)
+	if err != nil {
+		return nil, fmt.Errorf("get provider timezone: %w", err)
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("load provider timezone %q: %w", tz, err)
+	}
+	return loc, nil
+}
+
+// plannedSlot is a slot's interval in UTC, comparable so plans can be
+// diffed against stored slots.
+type plannedSlot struct {
+	Start, End time.Time
+}
+
+func sortedSlots(set map[plannedSlot]bool) []plannedSlot {
+	slots := make([]plannedSlot, 0, len(set))
+	for p := range set {
+		slots = append(slots, p)
+	}
+	slices.SortFunc(slots, func(a, b plannedSlot) int { return a.Start.Compare(b.Start) })
+	return slots
+}
+
+// planSlots lays out slots on each dayToMatch from firstDay's date to
+// lastDay's date, both read in loc, running from opens to closes in loc and
+// cut as settings describes.
+//
+// Slots are laid out by wall-clock time. A slot whose start falls in a
+// spring-forward gap does not exist that day and is skipped. On a fall-back
+// day a repeated wall-clock time gets a single slot at its first occurrence.
+func planSlots(
+	dayToMatch time.Weekday,
+	firstDay, lastDay time.Time,
+	opens, closes db.TimeOfDay,
+	loc *time.Location,
+	settings SlotSettings,
+) []plannedSlot {
+	settings = settings.withDefaults()
+	firstDay, lastDay = firstDay.In(loc), lastDay.In(loc)
+	startMin := opens.Hour()*60 + opens.Minute()
+	endMin := closes.Hour()*60 + closes.Minute()
+	slotLen := int(settings.SlotMinutes)
+	before, after := int(settings.BufferBeforeMinutes), int(settings.BufferAfterMinutes)
+	step := before + slotLen + after
+
+	var slots []plannedSlot
+	last := time.Date(lastDay.Year(), lastDay.Month(), lastDay.Day(), 0, 0, 0, 0, time.UTC)
+	for day := time.Date(firstDay.Year(), firstDay.Month(), firstDay.Day(), 0, 0, 0, 0, time.UTC); !day.After(last); day = day.AddDate(0, 0, 1) {
+		// day only carries a calendar date; UTC keeps AddDate free of DST.
+		if day.Weekday() != dayToMatch {
+			continue
+		}
+
+		for block := startMin; block+step <= endMin; block += step {
+			// m is the slot's wall-clock start, in minutes after midnight.
+			m := block + before
+			slotStart := time.Date(day.Year(), day.Month(), day.Day(), 0, m, 0, 0, loc)
+			if slotStart.Hour()*60+slotStart.Minute() != m {
+				// Normalised out of a spring-forward gap.
+				continue
+			}
+			slots = append(slots, plannedSlot{
+				Start: slotStart.UTC(),
+				End:   slotStart.Add(time.Duration(slotLen) * time.Minute).UTC(),
+			})
+		}
+	}
+	return slots
+}
+
+// generateSlots creates the slots planSlots lays out between the dates of
+// startRange and endRange, running from startRange's clock time to
+// endRange's clock time in loc, that start before until. They are linked to
+// patternID if it is set, and skipped where the provider already has a slot
+// at the same start.
 func generateSlots(
 	ctx context.Context,
 	dayToMatch time.Weekday,
 	startRange, endRange time.Time,
+	until time.Time,
+	loc *time.Location,
+	settings SlotSettings,
 	providerID uuid.UUID,
-	store AvailabilityStore,
+	patternID uuid.NullUUID,
+	store slotCreator,
 ) error {
-	startHour, startMin := startRange.Hour(), startRange.Minute()
-	endHour, endMin := endRange.Hour(), endRange.Minute()
-
-	current := time.Date(startRange.Year(), startRange.Month(), startRange.Day(), 0, 0, 0, 0, startRange.Location())
-
-	for !current.After(endRange) {
-		if current.Weekday() == dayToMatch {
-			dayStart := time.Date(current.Year(), current.Month(), current.Day(), startHour, startMin, 0, 0, current.Location())
-			dayEnd := time.Date(current.Year(), current.Month(), current.Day(), endHour, endMin, 0, 0, current.Location())
-
-			for slotStart := dayStart; !slotStart.Add(time.Hour).After(dayEnd); slotStart = slotStart.Add(time.Hour) {
-				slotEnd := slotStart.Add(time.Hour)
-				if err := store.CreateAvailability(ctx, db.CreateAvailabilityParams{
-					ID:         uuid.New(),
-					ProviderID: providerID,
-					StartTime:  slotStart,
-					EndTime:    slotEnd,
-				}); err != nil {
-					return fmt.Errorf("create availability on %s: %w", slotStart.Format("2006‑01‑02 15:04"), err)
-				}
-			}
+	settings = settings.withDefaults()
+	startRange, endRange = startRange.In(loc), endRange.In(loc)
+	for _, p := range planSlots(
+		dayToMatch,
+		startRange,
+		endRange,
+		db.NewTimeOfDay(startRange),
+		db.NewTimeOfDay(endRange),
+		loc,
+		settings,
+	) {
+		if !p.Start.Before(until) {
+			break
+		}
+		if _, err := store.CreatePatternSlot(ctx, db.CreatePatternSlotParams{
+			ID:         uuid.New(),
+			ProviderID: providerID,
+			StartTime:  p.Start,
+			EndTime:    p.End,
+			Capacity:   settings.Capacity,
+			PatternID:  patternID,
+		}); err != nil {
+			return fmt.Errorf("create availability on %s: %w", p.Start.In(loc).Format("2006-01-02 15:04 MST"), err)
 		}
-		current = current.AddDate(0, 0, 1)
 	}
 	return nil
 }
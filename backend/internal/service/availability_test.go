@@ -2,34 +2,173 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"errors"
 	"testing"
 	"time"
 
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/events"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
 type mockStore struct {
+	db.Querier
 	failPattern  bool
 	failSlot     bool
+	timezone     string
 	createdSlots int
+	pattern      db.CreateAvailabilityPatternParams
+	slots        []db.CreatePatternSlotParams
+
+	// Used by UpdatePattern, DeletePattern and ExtendPatterns.
+	stored         db.AvailabilityPattern
+	otherPatterns  []db.AvailabilityPattern
+	materializing  bool
+	locked         bool // by this test's own run
+	patternSlots   []db.ListPatternSlotsFromRow
+	deletedSlots   []uuid.UUID
+	closedSlots    []uuid.UUID
+	capacities     map[uuid.UUID]int32
+	patternDeleted bool
+	events         []db.CreateOutboxEventParams
+}
+
+func (m *mockStore) ExecTx(ctx context.Context, fn func(db.Querier) error) error {
+	return fn(m)
+}
+
+func (m *mockStore) WithConn(ctx context.Context, fn func(db.TxQuerier) error) error {
+	return fn(m)
+}
+
+func (m *mockStore) CreateOutboxEvent(ctx context.Context, arg db.CreateOutboxEventParams) error {
+	m.events = append(m.events, arg)
+	return nil
+}
+
+func (m *mockStore) LockProviderSchedule(ctx context.Context, providerID uuid.UUID) error {
+	return nil
+}
+
+func (m *mockStore) GetAvailabilityPatternByID(ctx context.Context, id uuid.UUID) (db.AvailabilityPattern, error) {
+	if m.stored.ID != id {
+		return db.AvailabilityPattern{}, sql.ErrNoRows
+	}
+	return m.stored, nil
+}
+
+func (m *mockStore) TryLockSlotMaterializer(ctx context.Context) (bool, error) {
+	if m.materializing {
+		return false, nil
+	}
+	m.materializing = true
+	m.locked = true
+	return true, nil
+}
+
+func (m *mockStore) UnlockSlotMaterializer(ctx context.Context) (bool, error) {
+	if !m.locked {
+		return false, nil
+	}
+	m.materializing = false
+	m.locked = false
+	return true, nil
+}
+
+func (m *mockStore) ListPatternsToExtend(ctx context.Context, horizon time.Time) ([]db.ListPatternsToExtendRow, error) {
+	var rows []db.ListPatternsToExtendRow
+	for _, p := range append([]db.AvailabilityPattern{m.stored}, m.otherPatterns...) {
+		// Mirror the query's ends_on filter, taking the date in UTC.
+		if p.GeneratedUntil.Before(horizon) && p.GeneratedUntil.Before(p.EndsOn.AddDate(0, 0, 1)) {
+			rows = append(rows, db.ListPatternsToExtendRow{ID: p.ID, ProviderID: p.ProviderID})
+		}
+	}
+	return rows, nil
+}
+
+func (m *mockStore) SetPatternGeneratedUntil(ctx context.Context, arg db.SetPatternGeneratedUntilParams) error {
+	if m.stored.ID == arg.ID {
+		m.stored.GeneratedUntil = arg.GeneratedUntil
+	}
+	return nil
+}
+
+func (m *mockStore) UpdateAvailabilityPattern(ctx context.Context, arg db.UpdateAvailabilityPatternParams) (db.AvailabilityPattern, error) {
+	m.stored.DayOfWeek = arg.DayOfWeek
+	m.stored.StartTime = arg.StartTime
+	m.stored.EndTime = arg.EndTime
+	m.stored.SlotMinutes = arg.SlotMinutes
+	m.stored.BufferBeforeMinutes = arg.BufferBeforeMinutes
+	m.stored.BufferAfterMinutes = arg.BufferAfterMinutes
+	m.stored.Capacity = arg.Capacity
+	return m.stored, nil
+}
+
+func (m *mockStore) DeleteAvailabilityPattern(ctx context.Context, arg db.DeleteAvailabilityPatternParams) error {
+	// Mirror the query's provider filter.
+	m.patternDeleted = arg.ID == m.stored.ID && arg.ProviderID == m.stored.ProviderID
+	return nil
+}
+
+func (m *mockStore) ListPatternSlotsFrom(ctx context.Context, arg db.ListPatternSlotsFromParams) ([]db.ListPatternSlotsFromRow, error) {
+	var slots []db.ListPatternSlotsFromRow
+	for _, s := range m.patternSlots {
+		if arg.PatternID.UUID == m.stored.ID && !s.StartTime.Before(arg.StartFrom) {
+			slots = append(slots, s)
+		}
+	}
+	return slots, nil
+}
+
+func (m *mockStore) DeleteAvailability(ctx context.Context, arg db.DeleteAvailabilityParams) error {
+	m.deletedSlots = append(m.deletedSlots, arg.ID)
+	return nil
+}
+
+func (m *mockStore) CloseAvailability(ctx context.Context, id uuid.UUID) error {
+	m.closedSlots = append(m.closedSlots, id)
+	return nil
+}
+
+func (m *mockStore) UpdateAvailabilityCapacity(ctx context.Context, arg db.UpdateAvailabilityCapacityParams) error {
+	if m.capacities == nil {
+		m.capacities = map[uuid.UUID]int32{}
+	}
+	m.capacities[arg.ID] = arg.Capacity
+	return nil
 }
 
 func (m *mockStore) CreateAvailabilityPattern(ctx context.Context, arg db.CreateAvailabilityPatternParams) error {
 	if m.failPattern {
 		return errors.New("pattern insert failed")
 	}
+	m.pattern = arg
 	return nil
 }
 
-func (m *mockStore) CreateAvailability(ctx context.Context, arg db.CreateAvailabilityParams) error {
+func (m *mockStore) CreatePatternSlot(ctx context.Context, arg db.CreatePatternSlotParams) (int64, error) {
 	m.createdSlots++
 	if m.failSlot {
-		return errors.New("slot insert failed")
+		return 0, errors.New("slot insert failed")
 	}
-	return nil
+	// Mirror the unique key on provider and start.
+	for _, s := range m.slots {
+		if s.ProviderID == arg.ProviderID && s.StartTime.Equal(arg.StartTime) {
+			return 0, nil
+		}
+	}
+	m.slots = append(m.slots, arg)
+	return 1, nil
+}
+
+func (m *mockStore) GetUserTimezone(ctx context.Context, id uuid.UUID) (string, error) {
+	if m.timezone == "" {
+		return "UTC", nil
+	}
+	return m.timezone, nil
 }
 
 func TestCreatePatternAndSlots(t *testing.T) {
@@ -63,6 +202,7 @@ func TestCreatePatternAndSlots(t *testing.T) {
 				int32(start.Weekday()),
 				start,
 				end,
+				SlotSettings{},
 			)
 
 			if tt.expectErr {
@@ -77,6 +217,7 @@ func TestCreatePatternAndSlots(t *testing.T) {
 			} else if !tt.failSlot {
 				// Expect all slots: 3 days * 2 hours = 6
 				assert.Equal(t, 6, mock.createdSlots)
+				assert.Equal(t, []events.PatternChanged{{PatternID: mock.pattern.ID, ProviderID: providerID, Change: events.PatternCreated}}, patternEvents(t, mock))
 			} else {
 				// On slot failure, at least one slot was created before error
 				assert.Greater(t, mock.createdSlots, 0)
@@ -84,3 +225,536 @@ func TestCreatePatternAndSlots(t *testing.T) {
 		})
 	}
 }
+
+func TestCreatePatternAndSlotsUsesProviderTimezone(t *testing.T) {
+	// 13:00–15:00 UTC is 09:00–11:00 in New York during EDT.
+	start := time.Date(2025, 6, 3, 13, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 6, 3, 15, 0, 0, 0, time.UTC)
+
+	mock := &mockStore{timezone: "America/New_York"}
+	svc := NewAvailabilityService(mock)
+	err := svc.CreatePatternAndSlots(context.Background(), uuid.New(), int32(time.Tuesday), start, end, SlotSettings{})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "09:00", mock.pattern.StartTime.Format("15:04"))
+	assert.Equal(t, "11:00", mock.pattern.EndTime.Format("15:04"))
+	if assert.Len(t, mock.slots, 2) {
+		assert.True(t, mock.slots[0].StartTime.Equal(start))
+		assert.True(t, mock.slots[1].EndTime.Equal(end))
+		assert.Equal(t, uuid.NullUUID{UUID: mock.pattern.ID, Valid: true}, mock.slots[0].PatternID)
+	}
+}
+
+func TestCreatePatternAndSlotsWithSlotHorizon(t *testing.T) {
+	// A Monday; the pattern runs on Tuesdays through the end of the year.
+	now := time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC)
+	start := time.Date(2025, 6, 3, 9, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 12, 30, 10, 0, 0, 0, time.UTC)
+
+	mock := &mockStore{}
+	svc := NewAvailabilityService(mock, WithSlotHorizon(2))
+	svc.now = func() time.Time { return now }
+	err := svc.CreatePatternAndSlots(context.Background(), uuid.New(), int32(time.Tuesday), start, end, SlotSettings{})
+	assert.NoError(t, err)
+
+	// Only June 3 and 10 fall within two weeks; ExtendPatterns does the rest.
+	assert.Equal(t, 2, mock.createdSlots)
+	assert.Equal(t, now.AddDate(0, 0, 14), mock.pattern.GeneratedUntil)
+	assert.Equal(t, time.Date(2025, 12, 30, 0, 0, 0, 0, time.UTC), mock.pattern.EndsOn)
+}
+
+func TestCreatePatternAndSlotsUnknownTimezone(t *testing.T) {
+	mock := &mockStore{timezone: "Mars/Olympus_Mons"}
+	svc := NewAvailabilityService(mock)
+	start := time.Date(2025, 6, 3, 9, 0, 0, 0, time.UTC)
+	err := svc.CreatePatternAndSlots(context.Background(), uuid.New(), int32(time.Tuesday), start, start.Add(time.Hour), SlotSettings{})
+	assert.Error(t, err)
+	assert.Zero(t, mock.createdSlots)
+}
+
+func TestGenerateSlotsAcrossDST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+	utc := func(month time.Month, day, hour int) time.Time {
+		return time.Date(2025, month, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name       string
+		day        time.Weekday
+		start, end time.Time
+		wantStarts []time.Time
+		wantEnds   []time.Time
+	}{
+		{
+			// Clocks jump from 02:00 EST to 03:00 EDT on March 9, 2025.
+			name:       "Spring-forward gap is skipped",
+			day:        time.Sunday,
+			start:      time.Date(2025, 3, 9, 0, 0, 0, 0, ny),
+			end:        time.Date(2025, 3, 9, 4, 0, 0, 0, ny),
+			wantStarts: []time.Time{utc(3, 9, 5), utc(3, 9, 6), utc(3, 9, 7)},
+			wantEnds:   []time.Time{utc(3, 9, 6), utc(3, 9, 7), utc(3, 9, 8)},
+		},
+		{
+			// Clocks fall back from 02:00 EDT to 01:00 EST on November 2, 2025,
+			// so 01:00–02:00 happens twice.
+			name:       "Fall-back hour is not duplicated",
+			day:        time.Sunday,
+			start:      time.Date(2025, 11, 2, 0, 0, 0, 0, ny),
+			end:        time.Date(2025, 11, 2, 3, 0, 0, 0, ny),
+			wantStarts: []time.Time{utc(11, 2, 4), utc(11, 2, 5), utc(11, 2, 7)},
+			wantEnds:   []time.Time{utc(11, 2, 5), utc(11, 2, 6), utc(11, 2, 8)},
+		},
+		{
+			name:       "Wall-clock time is kept across the change",
+			day:        time.Sunday,
+			start:      time.Date(2025, 3, 2, 9, 0, 0, 0, ny),
+			end:        time.Date(2025, 3, 16, 10, 0, 0, 0, ny),
+			wantStarts: []time.Time{utc(3, 2, 14), utc(3, 9, 13), utc(3, 16, 13)},
+			wantEnds:   []time.Time{utc(3, 2, 15), utc(3, 9, 14), utc(3, 16, 14)},
+		},
+		{
+			// 23:00 UTC on Saturday is still Saturday in New York.
+			name:       "Range dates are read in the provider's zone",
+			day:        time.Saturday,
+			start:      time.Date(2025, 6, 7, 23, 0, 0, 0, time.UTC),
+			end:        time.Date(2025, 6, 8, 0, 0, 0, 0, time.UTC),
+			wantStarts: []time.Time{utc(6, 7, 23)},
+			wantEnds:   []time.Time{utc(6, 8, 0)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockStore{}
+			err := generateSlots(context.Background(), tt.day, tt.start, tt.end, tt.end.AddDate(0, 0, 1), ny, SlotSettings{}, uuid.New(), uuid.NullUUID{}, mock)
+			assert.NoError(t, err)
+
+			var starts, ends []time.Time
+			for _, s := range mock.slots {
+				starts = append(starts, s.StartTime.UTC())
+				ends = append(ends, s.EndTime.UTC())
+			}
+			assert.Equal(t, tt.wantStarts, starts)
+			assert.Equal(t, tt.wantEnds, ends)
+		})
+	}
+}
+
+func TestGenerateSlotsWithSettings(t *testing.T) {
+	at := func(hour, min int) time.Time {
+		return time.Date(2025, 6, 3, hour, min, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name         string
+		settings     SlotSettings
+		start, end   time.Time
+		wantStarts   []string
+		wantMinutes  int
+		wantCapacity int32
+	}{
+		{
+			name:         "Defaults to one-hour single slots",
+			start:        at(9, 0),
+			end:          at(11, 0),
+			wantStarts:   []string{"09:00", "10:00"},
+			wantMinutes:  60,
+			wantCapacity: 1,
+		},
+		{
+			name:         "Thirty-minute slots",
+			settings:     SlotSettings{SlotMinutes: 30},
+			start:        at(9, 0),
+			end:          at(10, 30),
+			wantStarts:   []string{"09:00", "09:30", "10:00"},
+			wantMinutes:  30,
+			wantCapacity: 1,
+		},
+		{
+			name:         "Buffer after each slot",
+			settings:     SlotSettings{SlotMinutes: 45, BufferAfterMinutes: 15},
+			start:        at(9, 0),
+			end:          at(12, 0),
+			wantStarts:   []string{"09:00", "10:00", "11:00"},
+			wantMinutes:  45,
+			wantCapacity: 1,
+		},
+		{
+			// The last block would end at 12:15 with its buffers.
+			name:         "Buffers must fit in the window",
+			settings:     SlotSettings{SlotMinutes: 90, BufferBeforeMinutes: 10, BufferAfterMinutes: 5},
+			start:        at(9, 0),
+			end:          at(12, 0),
+			wantStarts:   []string{"09:10"},
+			wantMinutes:  90,
+			wantCapacity: 1,
+		},
+		{
+			name:         "Group session capacity",
+			settings:     SlotSettings{SlotMinutes: 60, Capacity: 12},
+			start:        at(18, 0),
+			end:          at(19, 0),
+			wantStarts:   []string{"18:00"},
+			wantMinutes:  60,
+			wantCapacity: 12,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockStore{}
+			err := generateSlots(context.Background(), time.Tuesday, tt.start, tt.end, tt.end.AddDate(0, 0, 1), time.UTC, tt.settings, uuid.New(), uuid.NullUUID{}, mock)
+			assert.NoError(t, err)
+
+			var starts []string
+			for _, s := range mock.slots {
+				starts = append(starts, s.StartTime.Format("15:04"))
+				assert.Equal(t, time.Duration(tt.wantMinutes)*time.Minute, s.EndTime.Sub(s.StartTime))
+				assert.Equal(t, tt.wantCapacity, s.Capacity)
+			}
+			assert.Equal(t, tt.wantStarts, starts)
+		})
+	}
+}
+
+func TestUpdatePattern(t *testing.T) {
+	providerID := uuid.New()
+	patternID := uuid.New()
+	// A Monday; the pattern's slots are on Tuesdays.
+	now := time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC)
+	at := func(day, hour int) time.Time {
+		return time.Date(2025, 6, day, hour, 0, 0, 0, time.UTC)
+	}
+	slot := func(day, hour int, capacity, booked int32) db.ListPatternSlotsFromRow {
+		return db.ListPatternSlotsFromRow{ID: uuid.New(), StartTime: at(day, hour), EndTime: at(day, hour+1), Capacity: capacity, Booked: booked, TotalBookings: booked}
+	}
+	// withHistory gives s total bookings, counting cancelled ones.
+	withHistory := func(s db.ListPatternSlotsFromRow, total int32) db.ListPatternSlotsFromRow {
+		s.TotalBookings = total
+		return s
+	}
+	capacity := func(n int32) *int32 { return &n }
+
+	type want struct {
+		created    []time.Time
+		deleted    []int
+		closed     []int
+		stranded   []int
+		capacities map[int]int32
+	}
+
+	tests := []struct {
+		name     string
+		existing []db.ListPatternSlotsFromRow
+		// generatedUntil defaults to the end of the second Tuesday.
+		generatedUntil time.Time
+		update         PatternUpdate
+		want           want
+	}{
+		{
+			name: "Window moves later",
+			existing: []db.ListPatternSlotsFromRow{
+				slot(3, 9, 1, 0), slot(3, 10, 1, 1), slot(10, 9, 1, 0), slot(10, 10, 1, 0),
+			},
+			update: PatternUpdate{DayOfWeek: int32(time.Tuesday), Start: at(3, 10), End: at(3, 12)},
+			want: want{
+				created: []time.Time{at(3, 11), at(10, 11)},
+				deleted: []int{0, 2},
+			},
+		},
+		{
+			name: "Day changes",
+			existing: []db.ListPatternSlotsFromRow{
+				slot(3, 9, 1, 0), slot(3, 10, 1, 1), slot(10, 9, 1, 0),
+			},
+			update: PatternUpdate{DayOfWeek: int32(time.Wednesday), Start: at(4, 9), End: at(4, 11)},
+			want: want{
+				created:  []time.Time{at(4, 9), at(4, 10)},
+				deleted:  []int{0, 2},
+				stranded: []int{1},
+			},
+		},
+		{
+			name: "Slots with cancelled bookings are closed",
+			existing: []db.ListPatternSlotsFromRow{
+				withHistory(slot(3, 9, 1, 0), 2), withHistory(slot(3, 10, 2, 1), 3), slot(10, 9, 1, 0),
+			},
+			update: PatternUpdate{DayOfWeek: int32(time.Wednesday), Start: at(4, 9), End: at(4, 10)},
+			want: want{
+				created:  []time.Time{at(4, 9)},
+				deleted:  []int{2},
+				closed:   []int{0},
+				stranded: []int{1},
+			},
+		},
+		{
+			name: "Capacity kept at or above bookings",
+			existing: []db.ListPatternSlotsFromRow{
+				slot(3, 9, 4, 3), slot(3, 10, 4, 0),
+			},
+			generatedUntil: at(4, 0),
+			update:         PatternUpdate{DayOfWeek: int32(time.Tuesday), Start: at(3, 9), End: at(3, 11), Capacity: capacity(2)},
+			want: want{
+				capacities: map[int]int32{0: 3, 1: 2},
+			},
+		},
+		{
+			name:           "Nothing generated ahead",
+			existing:       []db.ListPatternSlotsFromRow{slot(2, 9, 1, 0)},
+			generatedUntil: now,
+			update:         PatternUpdate{DayOfWeek: int32(time.Wednesday), Start: at(4, 9), End: at(4, 11)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generatedUntil := tt.generatedUntil
+			if generatedUntil.IsZero() {
+				generatedUntil = at(11, 0)
+			}
+			mock := &mockStore{
+				stored: db.AvailabilityPattern{
+					ID:             patternID,
+					ProviderID:     providerID,
+					DayOfWeek:      int32(time.Tuesday),
+					StartTime:      db.NewTimeOfDay(at(3, 9)),
+					EndTime:        db.NewTimeOfDay(at(3, 11)),
+					SlotMinutes:    60,
+					Capacity:       1,
+					GeneratedUntil: generatedUntil,
+				},
+				patternSlots: tt.existing,
+			}
+			svc := NewAvailabilityService(mock)
+			svc.now = func() time.Time { return now }
+
+			pattern, changes, err := svc.UpdatePattern(context.Background(), patternID, providerID, false, tt.update)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.update.DayOfWeek, pattern.DayOfWeek)
+
+			var created []time.Time
+			for _, s := range mock.slots {
+				created = append(created, s.StartTime)
+				assert.Equal(t, uuid.NullUUID{UUID: patternID, Valid: true}, s.PatternID)
+			}
+			assert.Equal(t, tt.want.created, created)
+			assert.Equal(t, len(tt.want.created), changes.Created)
+
+			var deleted []uuid.UUID
+			for _, i := range tt.want.deleted {
+				deleted = append(deleted, tt.existing[i].ID)
+			}
+			assert.Equal(t, deleted, mock.deletedSlots)
+			var closed []uuid.UUID
+			for _, i := range tt.want.closed {
+				closed = append(closed, tt.existing[i].ID)
+			}
+			assert.Equal(t, closed, mock.closedSlots)
+			assert.Equal(t, len(deleted)+len(closed), changes.Removed)
+
+			var stranded []db.ListPatternSlotsFromRow
+			for _, i := range tt.want.stranded {
+				stranded = append(stranded, tt.existing[i])
+			}
+			assert.Equal(t, stranded, changes.Stranded)
+
+			for i, c := range tt.want.capacities {
+				assert.Equal(t, c, mock.capacities[tt.existing[i].ID], "capacity of slot %d", i)
+			}
+		})
+	}
+}
+
+func TestUpdatePatternOwnership(t *testing.T) {
+	providerID := uuid.New()
+	mock := &mockStore{stored: db.AvailabilityPattern{ID: uuid.New(), ProviderID: providerID}}
+	svc := NewAvailabilityService(mock)
+	update := PatternUpdate{DayOfWeek: 1, Start: time.Now(), End: time.Now().Add(time.Hour)}
+
+	_, _, err := svc.UpdatePattern(context.Background(), uuid.New(), providerID, false, update)
+	assert.ErrorIs(t, err, ErrPatternNotFound)
+
+	_, _, err = svc.UpdatePattern(context.Background(), mock.stored.ID, uuid.New(), false, update)
+	assert.ErrorIs(t, err, ErrPatternNotOwned)
+
+	_, err = svc.DeletePattern(context.Background(), mock.stored.ID, uuid.New(), false)
+	assert.ErrorIs(t, err, ErrPatternNotOwned)
+	assert.False(t, mock.patternDeleted)
+}
+
+func TestPatternAdminOverride(t *testing.T) {
+	providerID := uuid.New()
+	adminID := uuid.New()
+	mock := &mockStore{stored: db.AvailabilityPattern{ID: uuid.New(), ProviderID: providerID}}
+	svc := NewAvailabilityService(mock)
+	update := PatternUpdate{DayOfWeek: 1, Start: time.Now(), End: time.Now().Add(time.Hour)}
+
+	_, _, err := svc.UpdatePattern(context.Background(), mock.stored.ID, adminID, true, update)
+	assert.NoError(t, err)
+
+	_, err = svc.DeletePattern(context.Background(), mock.stored.ID, adminID, true)
+	assert.NoError(t, err)
+	assert.True(t, mock.patternDeleted)
+
+	// Events name the pattern's provider, not the admin.
+	for _, e := range patternEvents(t, mock) {
+		assert.Equal(t, providerID, e.ProviderID)
+	}
+}
+
+func TestDeletePattern(t *testing.T) {
+	providerID := uuid.New()
+	patternID := uuid.New()
+	now := time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC)
+	past := db.ListPatternSlotsFromRow{ID: uuid.New(), StartTime: now.Add(-24 * time.Hour), Booked: 1}
+	free := db.ListPatternSlotsFromRow{ID: uuid.New(), StartTime: now.Add(24 * time.Hour)}
+	booked := db.ListPatternSlotsFromRow{ID: uuid.New(), StartTime: now.Add(48 * time.Hour), Booked: 2, TotalBookings: 2}
+	cancelled := db.ListPatternSlotsFromRow{ID: uuid.New(), StartTime: now.Add(72 * time.Hour), TotalBookings: 1}
+
+	mock := &mockStore{
+		stored:       db.AvailabilityPattern{ID: patternID, ProviderID: providerID},
+		patternSlots: []db.ListPatternSlotsFromRow{past, free, booked, cancelled},
+	}
+	svc := NewAvailabilityService(mock)
+	svc.now = func() time.Time { return now }
+
+	changes, err := svc.DeletePattern(context.Background(), patternID, providerID, false)
+	assert.NoError(t, err)
+	assert.True(t, mock.patternDeleted)
+	assert.Equal(t, []uuid.UUID{free.ID}, mock.deletedSlots)
+	assert.Equal(t, []uuid.UUID{cancelled.ID}, mock.closedSlots)
+	assert.Equal(t, 2, changes.Removed)
+	assert.Equal(t, []db.ListPatternSlotsFromRow{booked}, changes.Stranded)
+	assert.Equal(t, []events.PatternChanged{{PatternID: patternID, ProviderID: providerID, Change: events.PatternDeleted}}, patternEvents(t, mock))
+}
+
+// patternEvents decodes the PatternChanged events stored through m.
+func patternEvents(t *testing.T, m *mockStore) []events.PatternChanged {
+	t.Helper()
+	var out []events.PatternChanged
+	for _, e := range m.events {
+		assert.Equal(t, events.TypePatternChanged, e.EventType)
+		var pc events.PatternChanged
+		if err := json.Unmarshal([]byte(e.Payload), &pc); err != nil {
+			t.Fatalf("decode event: %v", err)
+		}
+		out = append(out, pc)
+	}
+	return out
+}
+
+func TestExtendPatterns(t *testing.T) {
+	providerID := uuid.New()
+	// A Monday; the pattern runs 09:00–11:00 New York time on Tuesdays.
+	now := time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC)
+	pattern := db.AvailabilityPattern{
+		ID:             uuid.New(),
+		ProviderID:     providerID,
+		DayOfWeek:      int32(time.Tuesday),
+		StartTime:      db.NewTimeOfDay(time.Date(0, 1, 1, 9, 0, 0, 0, time.UTC)),
+		EndTime:        db.NewTimeOfDay(time.Date(0, 1, 1, 11, 0, 0, 0, time.UTC)),
+		SlotMinutes:    60,
+		Capacity:       1,
+		GeneratedUntil: time.Date(2025, 6, 4, 0, 0, 0, 0, time.UTC),
+		EndsOn:         time.Date(2025, 12, 30, 0, 0, 0, 0, time.UTC),
+	}
+
+	mock := &mockStore{timezone: "America/New_York", stored: pattern}
+	svc := NewAvailabilityService(mock)
+	svc.now = func() time.Time { return now }
+
+	result, err := svc.ExtendPatterns(context.Background(), 3)
+	assert.NoError(t, err)
+	assert.Equal(t, MaterializeResult{Patterns: 1, Created: 4}, result)
+
+	// June 3 was already generated; June 10 and 17 fall before the horizon.
+	var starts []time.Time
+	for _, s := range mock.slots {
+		starts = append(starts, s.StartTime)
+		assert.Equal(t, uuid.NullUUID{UUID: pattern.ID, Valid: true}, s.PatternID)
+	}
+	assert.Equal(t, []time.Time{
+		time.Date(2025, 6, 10, 13, 0, 0, 0, time.UTC),
+		time.Date(2025, 6, 10, 14, 0, 0, 0, time.UTC),
+		time.Date(2025, 6, 17, 13, 0, 0, 0, time.UTC),
+		time.Date(2025, 6, 17, 14, 0, 0, 0, time.UTC),
+	}, starts)
+	assert.Equal(t, now.AddDate(0, 0, 21), mock.stored.GeneratedUntil)
+
+	// Rerunning with the same clock finds nothing left to do.
+	result, err = svc.ExtendPatterns(context.Background(), 3)
+	assert.NoError(t, err)
+	assert.Equal(t, MaterializeResult{}, result)
+
+	// Slots that already exist are not duplicated when a run is repeated.
+	mock.stored.GeneratedUntil = pattern.GeneratedUntil
+	result, err = svc.ExtendPatterns(context.Background(), 3)
+	assert.NoError(t, err)
+	assert.Equal(t, MaterializeResult{Patterns: 1}, result)
+	assert.Len(t, mock.slots, 4)
+	assert.False(t, mock.materializing, "lock still held after the run")
+}
+
+func TestExtendPatternsStopsAtEndsOn(t *testing.T) {
+	// A Monday; the pattern's last Tuesday is June 10.
+	now := time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC)
+	pattern := db.AvailabilityPattern{
+		ID:             uuid.New(),
+		ProviderID:     uuid.New(),
+		DayOfWeek:      int32(time.Tuesday),
+		StartTime:      db.NewTimeOfDay(time.Date(0, 1, 1, 9, 0, 0, 0, time.UTC)),
+		EndTime:        db.NewTimeOfDay(time.Date(0, 1, 1, 11, 0, 0, 0, time.UTC)),
+		SlotMinutes:    60,
+		Capacity:       1,
+		GeneratedUntil: time.Date(2025, 6, 4, 0, 0, 0, 0, time.UTC),
+		EndsOn:         time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC),
+	}
+
+	mock := &mockStore{timezone: "America/New_York", stored: pattern}
+	svc := NewAvailabilityService(mock)
+	svc.now = func() time.Time { return now }
+
+	result, err := svc.ExtendPatterns(context.Background(), 3)
+	assert.NoError(t, err)
+	assert.Equal(t, MaterializeResult{Patterns: 1, Created: 2}, result)
+	if assert.Len(t, mock.slots, 2) {
+		assert.Equal(t, time.Date(2025, 6, 10, 13, 0, 0, 0, time.UTC), mock.slots[0].StartTime)
+	}
+	// generated_until stops at the end of June 10 in New York.
+	assert.Equal(t, time.Date(2025, 6, 11, 4, 0, 0, 0, time.UTC), mock.stored.GeneratedUntil.UTC())
+
+	// A later run leaves the finished pattern alone.
+	svc.now = func() time.Time { return now.AddDate(0, 1, 0) }
+	result, err = svc.ExtendPatterns(context.Background(), 3)
+	assert.NoError(t, err)
+	assert.Equal(t, MaterializeResult{}, result)
+	assert.Len(t, mock.slots, 2)
+}
+
+func TestExtendPatternsSkipsWhenLocked(t *testing.T) {
+	mock := &mockStore{materializing: true, stored: db.AvailabilityPattern{ID: uuid.New()}}
+	svc := NewAvailabilityService(mock)
+
+	result, err := svc.ExtendPatterns(context.Background(), 4)
+	assert.NoError(t, err)
+	assert.True(t, result.Skipped)
+	assert.Zero(t, mock.createdSlots)
+}
+
+func TestExtendPatternsCarriesOnPastFailures(t *testing.T) {
+	deleted := db.AvailabilityPattern{ID: uuid.New(), ProviderID: uuid.New()}
+	mock := &mockStore{
+		timezone:      "Mars/Olympus_Mons",
+		stored:        db.AvailabilityPattern{ID: uuid.New(), ProviderID: uuid.New()},
+		otherPatterns: []db.AvailabilityPattern{deleted},
+	}
+	svc := NewAvailabilityService(mock)
+
+	_, err := svc.ExtendPatterns(context.Background(), 4)
+	assert.ErrorContains(t, err, mock.stored.ID.String())
+	assert.ErrorContains(t, err, deleted.ID.String())
+	assert.False(t, mock.materializing, "lock still held after a failed run")
+}
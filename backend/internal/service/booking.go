@@ -4,107 +4,479 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"slices"
 	"time"
 
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/apperr"
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/events"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/pagination"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-var ErrBookingConflict = errors.New("booking time slot conflict")
-var ErrBookingNotFound = errors.New("booking not found")
-var ErrNotAuthorized = errors.New("not authorized")
-var ErrNoBookingsFound = errors.New("no bookings found")
+var ErrBookingConflict = apperr.New(apperr.KindConflict, "Booking time slot conflict")
+var ErrBookingNotFound = apperr.New(apperr.KindNotFound, "Booking not found")
+var ErrNotAuthorized = apperr.New(apperr.KindForbidden, "Not allowed")
+var ErrNoBookingsFound = apperr.New(apperr.KindNotFound, "No bookings found")
+var ErrSlotNotFound = apperr.New(apperr.KindNotFound, "Availability slot not found")
+var ErrOutsideAvailability = apperr.New(apperr.KindValidation, "Requested time is outside the availability slot")
+var ErrInvalidTransition = apperr.New(apperr.KindConflict, "Booking status cannot be changed that way")
+var ErrBookingNotStarted = apperr.New(apperr.KindValidation, "Booking has not started yet")
+
+// ErrSlotTaken means the slot has no room left. It is a booking conflict, so
+// errors.Is(err, ErrBookingConflict) also holds.
+var ErrSlotTaken = apperr.Wrap(apperr.KindConflict, "Availability slot is fully booked", ErrBookingConflict)
+
+// Booking statuses. New bookings are confirmed; pending is for bookings that
+// still await confirmation.
+const (
+	StatusPending   = "pending"
+	StatusConfirmed = "confirmed"
+	StatusCancelled = "cancelled"
+	StatusCompleted = "completed"
+	StatusNoShow    = "no_show"
+)
+
+// BookingStatuses lists every status in lifecycle order.
+var BookingStatuses = []string{StatusPending, StatusConfirmed, StatusCancelled, StatusCompleted, StatusNoShow}
+
+// bookingTransitions maps a status to the statuses it may move to.
+// Cancelled, completed and no_show are final.
+var bookingTransitions = map[string][]string{
+	StatusPending:   {StatusConfirmed, StatusCancelled},
+	StatusConfirmed: {StatusCancelled, StatusCompleted, StatusNoShow},
+}
+
+// checkTransition returns ErrInvalidTransition unless a booking in status
+// from may move to status to.
+func checkTransition(from, to string) error {
+	if slices.Contains(bookingTransitions[from], to) {
+		return nil
+	}
+	return apperr.Wrap(apperr.KindConflict,
+		fmt.Sprintf("A %s booking cannot be marked %s", from, to), ErrInvalidTransition)
+}
+
+// isActive reports whether a booking in status still holds its time.
+func isActive(status string) bool {
+	return status == StatusPending || status == StatusConfirmed
+}
+
+// BookingMetrics is told about each booking outcome once it is committed.
+type BookingMetrics interface {
+	BookingCreated()
+	BookingCancelled()
+	BookingRescheduled()
+	BookingConflict()
+}
+
+type nopBookingMetrics struct{}
+
+func (nopBookingMetrics) BookingCreated()     {}
+func (nopBookingMetrics) BookingCancelled()   {}
+func (nopBookingMetrics) BookingRescheduled() {}
+func (nopBookingMetrics) BookingConflict()    {}
 
 type BookingService struct {
-	queries db.BookingQuerier
+	queries         db.BookingQuerier
+	metrics         BookingMetrics
+	reminderOffsets []time.Duration
+}
+
+type BookingOption func(*BookingService)
+
+func WithBookingMetrics(m BookingMetrics) BookingOption {
+	return func(s *BookingService) { s.metrics = m }
 }
 
-func NewBookingService(q db.BookingQuerier) *BookingService {
-	return &BookingService{queries: q}
+// WithReminderOffsets schedules a reminder that long before each booking.
+// Without it no reminders are scheduled.
+func WithReminderOffsets(offsets ...time.Duration) BookingOption {
+	return func(s *BookingService) { s.reminderOffsets = offsets }
 }
 
+func NewBookingService(q db.BookingQuerier, opts ...BookingOption) *BookingService {
+	s := &BookingService{queries: q, metrics: nopBookingMetrics{}}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// observe records the outcome of a booking write.
+func (s *BookingService) observe(err error, success func()) {
+	switch {
+	case err == nil:
+		success()
+	case errors.Is(err, ErrBookingConflict):
+		s.metrics.BookingConflict()
+	}
+}
+
+// CreateBooking books the availability slot identified by slotID. The
+// appointment start and duration are taken from the slot; a non-zero
+// requestedStart or requestedMinutes must match it or ErrOutsideAvailability
+// is returned. A BookingCreated event and the booking's reminders are stored
+// with the booking.
 func (s *BookingService) CreateBooking(
 	ctx context.Context,
 	id uuid.UUID,
 	userID uuid.UUID,
-	start time.Time,
-	durationMinutes int32,
 	slotID uuid.UUID,
-) (db.Booking, error) {
+	requestedStart time.Time,
+	requestedMinutes int32,
+) (_ db.Booking, err error) {
+	ctx, span := startSpan(ctx, "BookingService.CreateBooking",
+		attribute.String("booking.slot_id", slotID.String()))
+	defer endSpan(span, &err)
+
+	var appointment db.Booking
+	err = s.queries.ExecTx(ctx, func(q db.Querier) error {
+		slot, durationMinutes, err := claimSlot(ctx, q, slotID, requestedStart, requestedMinutes, nil)
+		if err != nil {
+			return err
+		}
 
-	overlaps, err := s.queries.GetOverlappingBookings(ctx, db.GetOverlappingBookingsParams{
-		AppointmentStart:   start,
-		AppointmentStart_2: start.Add(time.Duration(durationMinutes) * time.Minute),
+		appointment, err = q.CreateBooking(ctx, db.CreateBookingParams{
+			ID:               id,
+			AppointmentStart: slot.StartTime,
+			DurationMinutes:  durationMinutes,
+			UserID:           userID,
+			SlotID:           slot.ID,
+		})
+		if err != nil {
+			return err
+		}
+		if err := s.scheduleReminders(ctx, q, appointment); err != nil {
+			return err
+		}
+
+		return events.Emit(ctx, q, events.BookingCreated{
+			BookingID:        appointment.ID,
+			UserID:           appointment.UserID,
+			ProviderID:       slot.ProviderID,
+			SlotID:           slot.ID,
+			AppointmentStart: appointment.AppointmentStart,
+			DurationMinutes:  appointment.DurationMinutes,
+			Sequence:         appointment.Sequence,
+		})
 	})
+	s.observe(err, s.metrics.BookingCreated)
 	if err != nil {
 		return db.Booking{}, err
 	}
-	if len(overlaps) > 0 {
-		return db.Booking{}, ErrBookingConflict
+
+	return appointment, nil
+}
+
+// scheduleReminders replaces the unsent reminders of b with one at each
+// configured offset before its appointment that is still in the future.
+func (s *BookingService) scheduleReminders(ctx context.Context, q db.Querier, b db.Booking) error {
+	if err := q.CancelBookingReminders(ctx, b.ID); err != nil {
+		return err
 	}
+	now := time.Now()
+	for _, offset := range s.reminderOffsets {
+		remindAt := b.AppointmentStart.Add(-offset)
+		if !remindAt.After(now) {
+			continue
+		}
+		err := q.ScheduleBookingReminder(ctx, db.ScheduleBookingReminderParams{
+			ID:            uuid.New(),
+			BookingID:     b.ID,
+			OffsetMinutes: int32(offset / time.Minute),
+			RemindAt:      remindAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	appointment, err := s.queries.CreateBooking(ctx, db.CreateBookingParams{
-		ID:               id,
-		AppointmentStart: start,
-		DurationMinutes:  int32(durationMinutes),
-		UserID:           userID,
-		SlotID:           slotID,
-	})
+// claimSlot checks that the slot identified by slotID can take one more
+// booking and returns it with its length in minutes. A non-zero
+// requestedStart or requestedMinutes must match the slot. moving, if not
+// nil, is an active booking being moved onto the slot: it neither overlaps
+// itself nor counts against the slot's capacity.
+func claimSlot(
+	ctx context.Context,
+	q db.Querier,
+	slotID uuid.UUID,
+	requestedStart time.Time,
+	requestedMinutes int32,
+	moving *db.Booking,
+) (db.Availability, int32, error) {
+	slot, err := q.GetAvailabilityByID(ctx, slotID)
 	if err != nil {
-		return db.Booking{}, err
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.Availability{}, 0, ErrSlotNotFound
+		}
+		return db.Availability{}, 0, err
 	}
 
-	return appointment, nil
+	durationMinutes := int32(slot.EndTime.Sub(slot.StartTime) / time.Minute)
+	if !requestedStart.IsZero() && !requestedStart.Equal(slot.StartTime) {
+		return db.Availability{}, 0, ErrOutsideAvailability
+	}
+	if requestedMinutes != 0 && requestedMinutes != durationMinutes {
+		return db.Availability{}, 0, ErrOutsideAvailability
+	}
+
+	// Other bookings of a group slot share its time, so only count them
+	// against its capacity.
+	excludeSlot := uuid.NullUUID{UUID: slot.ID, Valid: true}
+	var excludeBooking uuid.NullUUID
+	if moving != nil {
+		excludeBooking = uuid.NullUUID{UUID: moving.ID, Valid: true}
+	}
+	if err := checkProviderOverlap(ctx, q, slot.ProviderID, excludeSlot, excludeBooking, slot.StartTime, durationMinutes); err != nil {
+		return db.Availability{}, 0, err
+	}
+
+	taken, err := q.CountBookingsForSlot(ctx, slot.ID)
+	if err != nil {
+		return db.Availability{}, 0, err
+	}
+	if moving != nil && moving.SlotID == slot.ID {
+		// Moving onto the slot it already holds keeps its place.
+		taken--
+	}
+	if taken >= int64(slot.Capacity) {
+		return db.Availability{}, 0, ErrSlotTaken
+	}
+	return slot, durationMinutes, nil
 }
 
-func (s *BookingService) DeleteBooking(
+// checkProviderOverlap takes the provider's schedule lock for the rest of the
+// transaction and reports ErrBookingConflict if any of that provider's
+// bookings intersect [start, start+durationMinutes). Bookings of excludeSlot
+// and the booking excludeBooking, if set, are ignored. Pattern buffers play
+// no part here; they are kept by spacing the generated slots.
+func checkProviderOverlap(
 	ctx context.Context,
-	id uuid.UUID,
-	userID uuid.UUID,
-	isAdmin bool,
+	q db.Querier,
+	providerID uuid.UUID,
+	excludeSlot uuid.NullUUID,
+	excludeBooking uuid.NullUUID,
+	start time.Time,
+	durationMinutes int32,
 ) error {
-	err := s.queries.DeleteBooking(ctx, db.DeleteBookingParams{
-		ID:      id,
-		UserID:  userID,
-		Column3: isAdmin,
+	if err := q.LockProviderSchedule(ctx, providerID); err != nil {
+		return err
+	}
+
+	overlaps, err := q.GetOverlappingBookings(ctx, db.GetOverlappingBookingsParams{
+		ProviderID:       providerID,
+		ExcludeSlotID:    excludeSlot,
+		ExcludeBookingID: excludeBooking,
+		RangeStart:       start,
+		RangeEnd:         start.Add(time.Duration(durationMinutes) * time.Minute),
 	})
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return ErrBookingNotFound
-		}
 		return err
 	}
-
+	if len(overlaps) > 0 {
+		return ErrBookingConflict
+	}
 	return nil
 }
 
-func (s *BookingService) RescheduleBooking(
+// CancelBooking cancels a pending or confirmed booking on behalf of
+// actorID, freeing its slot. Only the booking's owner or an admin may cancel
+// it; reason is optional. A BookingCancelled event is stored with the change
+// and reminders not yet sent are dropped.
+func (s *BookingService) CancelBooking(
 	ctx context.Context,
-	bookingID uuid.UUID,
-	userID uuid.UUID,
-	newStart time.Time,
-	durationMinutes int32,
+	id uuid.UUID,
+	actorID uuid.UUID,
 	isAdmin bool,
-) (db.Booking, error) {
+	reason string,
+) (err error) {
+	ctx, span := startSpan(ctx, "BookingService.CancelBooking",
+		attribute.String("booking.id", id.String()))
+	defer endSpan(span, &err)
+
+	err = s.queries.ExecTx(ctx, func(q db.Querier) error {
+		existing, err := q.GetBookingByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrBookingNotFound
+			}
+			return err
+		}
+		if !isAdmin && existing.UserID != actorID {
+			return ErrNotAuthorized
+		}
+		if err := checkTransition(existing.Status, StatusCancelled); err != nil {
+			return err
+		}
 
-	overlaps, err := s.queries.GetOverlappingBookings(ctx, db.GetOverlappingBookingsParams{
-		AppointmentStart:   newStart,
-		AppointmentStart_2: newStart.Add(time.Duration(durationMinutes) * time.Minute),
+		params := db.CancelBookingParams{
+			ID:            id,
+			CurrentStatus: existing.Status,
+			CancelledBy:   &actorID,
+		}
+		if reason != "" {
+			params.CancellationReason = &reason
+		}
+		cancelled, err := q.CancelBooking(ctx, params)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				// The status changed after we read it.
+				return ErrInvalidTransition
+			}
+			return err
+		}
+		if err := q.CancelBookingReminders(ctx, id); err != nil {
+			return err
+		}
+
+		slot, err := q.GetAvailabilityByID(ctx, existing.SlotID)
+		if err != nil {
+			return err
+		}
+		return events.Emit(ctx, q, events.BookingCancelled{
+			BookingID:        id,
+			UserID:           existing.UserID,
+			ProviderID:       slot.ProviderID,
+			SlotID:           existing.SlotID,
+			AppointmentStart: existing.AppointmentStart,
+			DurationMinutes:  existing.DurationMinutes,
+			CancelledBy:      actorID,
+			Reason:           reason,
+			Sequence:         cancelled.Sequence,
+		})
 	})
 	if err != nil {
+		return err
+	}
+
+	s.metrics.BookingCancelled()
+	return nil
+}
+
+// CompleteBooking marks a confirmed booking whose appointment has started
+// as completed.
+func (s *BookingService) CompleteBooking(ctx context.Context, id uuid.UUID) (db.Booking, error) {
+	return s.markAttendance(ctx, id, StatusCompleted)
+}
+
+// MarkNoShow marks a confirmed booking whose appointment has started as a
+// no-show.
+func (s *BookingService) MarkNoShow(ctx context.Context, id uuid.UUID) (db.Booking, error) {
+	return s.markAttendance(ctx, id, StatusNoShow)
+}
+
+func (s *BookingService) markAttendance(ctx context.Context, id uuid.UUID, status string) (_ db.Booking, err error) {
+	ctx, span := startSpan(ctx, "BookingService.UpdateBookingStatus",
+		attribute.String("booking.id", id.String()),
+		attribute.String("booking.status", status))
+	defer endSpan(span, &err)
+
+	existing, err := s.queries.GetBookingByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.Booking{}, ErrBookingNotFound
+		}
 		return db.Booking{}, err
 	}
-	if len(overlaps) > 0 {
-		return db.Booking{}, ErrBookingConflict
+	if err := checkTransition(existing.Status, status); err != nil {
+		return db.Booking{}, err
+	}
+	if time.Now().Before(existing.AppointmentStart) {
+		return db.Booking{}, ErrBookingNotStarted
+	}
+
+	updated, err := s.queries.UpdateBookingStatus(ctx, db.UpdateBookingStatusParams{
+		ID:            id,
+		Status:        status,
+		CurrentStatus: existing.Status,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return db.Booking{}, ErrInvalidTransition
+		}
+		return db.Booking{}, err
 	}
+	return updated, nil
+}
+
+// RescheduleBooking moves a pending or confirmed booking onto the slot
+// identified by slotID, freeing the slot it held. The new start and duration
+// come from the slot, as for CreateBooking. Only the booking's owner or an
+// admin may move it. ErrSlotTaken is returned if the slot has no room left.
+// A BookingRescheduled event is stored with the change and the booking's
+// reminders are recomputed for its new time.
+func (s *BookingService) RescheduleBooking(
+	ctx context.Context,
+	bookingID uuid.UUID,
+	userID uuid.UUID,
+	slotID uuid.UUID,
+	requestedStart time.Time,
+	requestedMinutes int32,
+	isAdmin bool,
+) (_ db.Booking, err error) {
+	ctx, span := startSpan(ctx, "BookingService.RescheduleBooking",
+		attribute.String("booking.id", bookingID.String()),
+		attribute.String("booking.slot_id", slotID.String()))
+	defer endSpan(span, &err)
 
-	updated, err := s.queries.RescheduleBooking(ctx, db.RescheduleBookingParams{
-		ID:               bookingID,
-		AppointmentStart: newStart,
-		DurationMinutes:  durationMinutes,
-		UserID:           userID,
-		Column5:          isAdmin,
+	var updated db.Booking
+	err = s.queries.ExecTx(ctx, func(q db.Querier) error {
+		existing, err := q.GetBookingByID(ctx, bookingID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrBookingNotFound
+			}
+			return err
+		}
+		if !isAdmin && existing.UserID != userID {
+			return ErrNotAuthorized
+		}
+		if !isActive(existing.Status) {
+			return apperr.Wrap(apperr.KindConflict,
+				fmt.Sprintf("A %s booking cannot be rescheduled", existing.Status), ErrInvalidTransition)
+		}
+
+		slot, durationMinutes, err := claimSlot(ctx, q, slotID, requestedStart, requestedMinutes, &existing)
+		if err != nil {
+			return err
+		}
+
+		updated, err = q.RescheduleBooking(ctx, db.RescheduleBookingParams{
+			ID:               bookingID,
+			SlotID:           slot.ID,
+			AppointmentStart: slot.StartTime,
+			DurationMinutes:  durationMinutes,
+		})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				// The status changed after we read it.
+				return ErrInvalidTransition
+			}
+			return err
+		}
+		if err := s.scheduleReminders(ctx, q, updated); err != nil {
+			return err
+		}
+
+		return events.Emit(ctx, q, events.BookingRescheduled{
+			BookingID:                updated.ID,
+			UserID:                   updated.UserID,
+			ProviderID:               slot.ProviderID,
+			SlotID:                   slot.ID,
+			AppointmentStart:         updated.AppointmentStart,
+			DurationMinutes:          updated.DurationMinutes,
+			PreviousSlotID:           existing.SlotID,
+			PreviousAppointmentStart: existing.AppointmentStart,
+			RescheduledBy:            userID,
+			Sequence:                 updated.Sequence,
+		})
 	})
+	s.observe(err, s.metrics.BookingRescheduled)
 	if err != nil {
 		return db.Booking{}, err
 	}
@@ -115,7 +487,11 @@ func (s *BookingService) RescheduleBooking(
 func (s *BookingService) GetBookingByID(
 	ctx context.Context,
 	bookingID, userID uuid.UUID,
-) (db.Booking, error) {
+) (_ db.Booking, err error) {
+	ctx, span := startSpan(ctx, "BookingService.GetBookingByID",
+		attribute.String("booking.id", bookingID.String()))
+	defer endSpan(span, &err)
+
 	appt, err := s.queries.GetBookingByID(ctx, bookingID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -131,17 +507,62 @@ func (s *BookingService) GetBookingByID(
 	return appt, nil
 }
 
+// BookingFilter narrows a booking list. Zero fields do not filter.
+// StartFrom and StartBefore bound appointment_start as [from, before).
+type BookingFilter struct {
+	UserID      uuid.NullUUID
+	ProviderID  uuid.NullUUID
+	Status      sql.NullString
+	StartFrom   sql.NullTime
+	StartBefore sql.NullTime
+	Page        pagination.Params
+}
+
+func bookingCursor(b db.Booking) pagination.Cursor {
+	return pagination.Cursor{Time: b.AppointmentStart, ID: b.ID}
+}
+
+// ListUserBookings lists userID's bookings by appointment start. Only the
+// status, date range and page of f are used.
 func (s *BookingService) ListUserBookings(
 	ctx context.Context,
 	userID uuid.UUID,
-) ([]db.Booking, error) {
-	return s.queries.ListBookingsForUser(ctx, userID)
+	f BookingFilter,
+) (_ pagination.Page[db.Booking], err error) {
+	ctx, span := startSpan(ctx, "BookingService.ListUserBookings")
+	defer endSpan(span, &err)
+
+	bookings, err := s.queries.ListBookingsForUser(ctx, db.ListBookingsForUserParams{
+		UserID:      userID,
+		Status:      f.Status,
+		StartFrom:   f.StartFrom,
+		StartBefore: f.StartBefore,
+		AfterStart:  f.Page.AfterTime(),
+		AfterID:     f.Page.AfterID(),
+		PageLimit:   f.Page.FetchLimit(),
+	})
+	if err != nil {
+		return pagination.Page[db.Booking]{}, err
+	}
+	return pagination.New(bookings, f.Page, bookingCursor), nil
 }
 
-func (s *BookingService) ListAllBookings(ctx context.Context) ([]db.Booking, error) {
-	bookings, err := s.queries.ListAllBookingsForAdmin(ctx)
+func (s *BookingService) ListAllBookings(ctx context.Context, f BookingFilter) (_ pagination.Page[db.Booking], err error) {
+	ctx, span := startSpan(ctx, "BookingService.ListAllBookings")
+	defer endSpan(span, &err)
+
+	bookings, err := s.queries.ListAllBookingsForAdmin(ctx, db.ListAllBookingsForAdminParams{
+		UserID:      f.UserID,
+		ProviderID:  f.ProviderID,
+		Status:      f.Status,
+		StartFrom:   f.StartFrom,
+		StartBefore: f.StartBefore,
+		AfterStart:  f.Page.AfterTime(),
+		AfterID:     f.Page.AfterID(),
+		PageLimit:   f.Page.FetchLimit(),
+	})
 	if err != nil {
-		return []db.Booking{}, err
+		return pagination.Page[db.Booking]{}, err
 	}
-	return bookings, nil
+	return pagination.New(bookings, f.Page, bookingCursor), nil
 }
@@ -3,38 +3,67 @@ package service
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"reflect"
+	"slices"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/events"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/pagination"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 type fakeBookingRepo struct {
+	db.Querier
 	overlaps                  []db.Booking
 	overlapErr                error
 	created                   db.Booking
 	createErr                 error
-	DeleteBookingFn           func(ctx context.Context, arg db.DeleteBookingParams) error
+	CancelBookingFn           func(ctx context.Context, arg db.CancelBookingParams) (db.Booking, error)
+	UpdateBookingStatusFn     func(ctx context.Context, arg db.UpdateBookingStatusParams) (db.Booking, error)
 	RescheduleBookingFn       func(ctx context.Context, arg db.RescheduleBookingParams) (db.Booking, error)
 	GetBookingByIDFn          func(ctx context.Context, bookingID uuid.UUID) (db.Booking, error)
-	ListBookingsForUserFn     func(ctx context.Context, id uuid.UUID) ([]db.Booking, error)
+	ListBookingsForUserFn     func(ctx context.Context, arg db.ListBookingsForUserParams) ([]db.Booking, error)
 	CreateAvailabilityFn      func(ctx context.Context, arg db.CreateAvailabilityParams) error
-	ListAllBookingsForAdminFn func(ctx context.Context) ([]db.Booking, error)
+	ListAllBookingsForAdminFn func(ctx context.Context, arg db.ListAllBookingsForAdminParams) ([]db.Booking, error)
+	GetAvailabilityByIDFn     func(ctx context.Context, id uuid.UUID) (db.Availability, error)
+	onCreate                  func(arg db.CreateBookingParams)
+	onOverlap                 func(arg db.GetOverlappingBookingsParams)
+	slotBookings              int64
+	events                    []db.CreateOutboxEventParams
+	reminders                 []db.ScheduleBookingReminderParams
+	cancelledReminders        []uuid.UUID
 }
 
 func (f *fakeBookingRepo) CreateBooking(ctx context.Context, arg db.CreateBookingParams) (db.Booking, error) {
+	if f.onCreate != nil {
+		f.onCreate(arg)
+	}
 	return f.created, f.createErr
 }
 
 func (f *fakeBookingRepo) GetOverlappingBookings(ctx context.Context, arg db.GetOverlappingBookingsParams) ([]db.Booking, error) {
+	if f.onOverlap != nil {
+		f.onOverlap(arg)
+	}
 	return f.overlaps, f.overlapErr
 }
 
-func (f *fakeBookingRepo) DeleteBooking(ctx context.Context, arg db.DeleteBookingParams) error {
-	return f.DeleteBookingFn(ctx, arg)
+func (f *fakeBookingRepo) CancelBooking(ctx context.Context, arg db.CancelBookingParams) (db.Booking, error) {
+	return f.CancelBookingFn(ctx, arg)
+}
+
+func (f *fakeBookingRepo) UpdateBookingStatus(ctx context.Context, arg db.UpdateBookingStatusParams) (db.Booking, error) {
+	return f.UpdateBookingStatusFn(ctx, arg)
 }
 
 func (f *fakeBookingRepo) RescheduleBooking(ctx context.Context, arg db.RescheduleBookingParams) (db.Booking, error) {
@@ -42,36 +71,78 @@ func (f *fakeBookingRepo) RescheduleBooking(ctx context.Context, arg db.Reschedu
 }
 
 func (f *fakeBookingRepo) GetBookingByID(ctx context.Context, bookingID uuid.UUID) (db.Booking, error) {
+	if f.GetBookingByIDFn == nil {
+		return db.Booking{ID: bookingID, SlotID: uuid.New(), Status: StatusConfirmed}, nil
+	}
 	return f.GetBookingByIDFn(ctx, bookingID)
 }
-func (f *fakeBookingRepo) ListBookingsForUser(ctx context.Context, id uuid.UUID) ([]db.Booking, error) {
-	return f.ListBookingsForUserFn(ctx, id)
+func (f *fakeBookingRepo) ListBookingsForUser(ctx context.Context, arg db.ListBookingsForUserParams) ([]db.Booking, error) {
+	return f.ListBookingsForUserFn(ctx, arg)
 }
 func (f *fakeBookingRepo) CreateAvailability(ctx context.Context, arg db.CreateAvailabilityParams) error {
 	return nil
 }
-func (f *fakeBookingRepo) ListAllBookingsForAdmin(ctx context.Context) ([]db.Booking, error) {
-	return f.ListAllBookingsForAdminFn(ctx)
+func (f *fakeBookingRepo) ListAllBookingsForAdmin(ctx context.Context, arg db.ListAllBookingsForAdminParams) ([]db.Booking, error) {
+	return f.ListAllBookingsForAdminFn(ctx, arg)
+}
+func (f *fakeBookingRepo) GetAvailabilityByID(ctx context.Context, id uuid.UUID) (db.Availability, error) {
+	if f.GetAvailabilityByIDFn == nil {
+		return db.Availability{ID: id, ProviderID: uuid.New(), Capacity: 1}, nil
+	}
+	return f.GetAvailabilityByIDFn(ctx, id)
+}
+func (f *fakeBookingRepo) CountBookingsForSlot(ctx context.Context, slotID uuid.UUID) (int64, error) {
+	return f.slotBookings, nil
+}
+func (f *fakeBookingRepo) LockProviderSchedule(ctx context.Context, providerID uuid.UUID) error {
+	return nil
+}
+func (f *fakeBookingRepo) ExecTx(ctx context.Context, fn func(db.Querier) error) error {
+	return fn(f)
+}
+func (f *fakeBookingRepo) CreateOutboxEvent(ctx context.Context, arg db.CreateOutboxEventParams) error {
+	f.events = append(f.events, arg)
+	return nil
+}
+func (f *fakeBookingRepo) CancelBookingReminders(ctx context.Context, bookingID uuid.UUID) error {
+	f.cancelledReminders = append(f.cancelledReminders, bookingID)
+	f.reminders = slices.DeleteFunc(f.reminders, func(r db.ScheduleBookingReminderParams) bool { return r.BookingID == bookingID })
+	return nil
+}
+func (f *fakeBookingRepo) ScheduleBookingReminder(ctx context.Context, arg db.ScheduleBookingReminderParams) error {
+	f.reminders = append(f.reminders, arg)
+	return nil
 }
 
 var errSimulatedOverlap = errors.New("simulated error")
 var errSimulatedCreate = errors.New("could not create booking")
-var errDeleting = errors.New("could not delete booking")
+var errCancelling = errors.New("could not cancel booking")
 var errReschedule = errors.New("could not reschedule booking")
 
 func TestBookingService_CreateBooking(t *testing.T) {
 	now := time.Date(2025, 5, 14, 10, 0, 0, 0, time.UTC)
 	userID := uuid.New()
 	id := uuid.New()
-	slotID := uuid.New()
+	slot := db.Availability{
+		ID:         uuid.New(),
+		ProviderID: uuid.New(),
+		StartTime:  now,
+		EndTime:    now.Add(30 * time.Minute),
+		Capacity:   1,
+	}
 
 	tests := []struct {
-		name       string
-		overlaps   []db.Booking
-		overlapErr error
-		created    db.Booking
-		createErr  error
-		wantErr    error
+		name           string
+		capacity       int32
+		overlaps       []db.Booking
+		overlapErr     error
+		created        db.Booking
+		createErr      error
+		slotErr        error
+		slotBookings   int64
+		requestedStart time.Time
+		requestedMins  int32
+		wantErr        error
 	}{
 		{
 			name:     "Valid booking",
@@ -81,10 +152,23 @@ func TestBookingService_CreateBooking(t *testing.T) {
 				UserID:           userID,
 				AppointmentStart: now,
 				DurationMinutes:  30,
-				SlotID:           id,
+				SlotID:           slot.ID,
 			},
 			wantErr: nil,
 		},
+		{
+			name: "Requested time matches slot",
+			created: db.Booking{
+				ID:               id,
+				UserID:           userID,
+				AppointmentStart: now,
+				DurationMinutes:  30,
+				SlotID:           slot.ID,
+			},
+			requestedStart: now,
+			requestedMins:  30,
+			wantErr:        nil,
+		},
 		{
 			name:       "DB error fetching overlaps",
 			overlapErr: errSimulatedOverlap,
@@ -95,25 +179,73 @@ func TestBookingService_CreateBooking(t *testing.T) {
 			overlaps: []db.Booking{{ID: uuid.New()}},
 			wantErr:  ErrBookingConflict,
 		},
+		{
+			name:         "Slot already booked",
+			slotBookings: 1,
+			wantErr:      ErrBookingConflict,
+		},
+		{
+			name:         "Group slot has room",
+			capacity:     3,
+			slotBookings: 2,
+			created:      db.Booking{ID: id, UserID: userID, AppointmentStart: now, SlotID: slot.ID},
+		},
+		{
+			name:         "Group slot full",
+			capacity:     3,
+			slotBookings: 3,
+			wantErr:      ErrBookingConflict,
+		},
 		{
 			name:      "Create booking error",
 			overlaps:  nil,
 			createErr: errSimulatedCreate,
 			wantErr:   errSimulatedCreate,
 		},
+		{
+			name:    "Slot does not exist",
+			slotErr: sql.ErrNoRows,
+			wantErr: ErrSlotNotFound,
+		},
+		{
+			name:           "Start outside slot",
+			requestedStart: now.Add(time.Hour),
+			wantErr:        ErrOutsideAvailability,
+		},
+		{
+			name:          "Duration longer than slot",
+			requestedMins: 60,
+			wantErr:       ErrOutsideAvailability,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			var created db.CreateBookingParams
 			repo := &fakeBookingRepo{
-				overlaps:   tt.overlaps,
-				overlapErr: tt.overlapErr,
-				created:    tt.created,
-				createErr:  tt.createErr,
+				overlaps:     tt.overlaps,
+				overlapErr:   tt.overlapErr,
+				created:      tt.created,
+				createErr:    tt.createErr,
+				slotBookings: tt.slotBookings,
+				GetAvailabilityByIDFn: func(_ context.Context, _ uuid.UUID) (db.Availability, error) {
+					if tt.slotErr != nil {
+						return db.Availability{}, tt.slotErr
+					}
+					if tt.capacity != 0 {
+						slot := slot
+						slot.Capacity = tt.capacity
+						return slot, nil
+					}
+					return slot, nil
+				},
+				onCreate: func(arg db.CreateBookingParams) {
+					created = arg
+				},
 			}
 
 			svc := NewBookingService(repo)
-			got, err := svc.CreateBooking(context.Background(), id, userID, now, 30, slotID)
+			got, err := svc.CreateBooking(context.Background(), id, userID, slot.ID, tt.requestedStart, tt.requestedMins)
 
 			if tt.wantErr != nil {
 				if err == nil {
@@ -132,88 +264,235 @@ func TestBookingService_CreateBooking(t *testing.T) {
 				!got.AppointmentStart.Equal(tt.created.AppointmentStart) {
 				t.Errorf("got %+v, want %+v", got, tt.created)
 			}
+			if !created.AppointmentStart.Equal(slot.StartTime) || created.DurationMinutes != 30 {
+				t.Errorf("booking not derived from slot: %+v", created)
+			}
 
 		})
 	}
 }
 
-func TestBookingService_DeleteBooking(t *testing.T) {
+func TestBookingService_CancelBooking(t *testing.T) {
 	userID := uuid.New()
+	adminID := uuid.New()
 	bookingID := uuid.New()
 
 	tests := []struct {
 		name       string
-		mockDelete func(ctx context.Context, arg db.DeleteBookingParams) error
-		ctxUserID  uuid.UUID
+		status     string
+		getErr     error
+		mockCancel func(ctx context.Context, arg db.CancelBookingParams) (db.Booking, error)
+		actorID    uuid.UUID
 		isAdmin    bool
+		reason     string
 		wantErr    error
 	}{
 		{
-			name: "Successful user deletion",
-			mockDelete: func(_ context.Context, arg db.DeleteBookingParams) error {
-				if arg.ID != bookingID || arg.UserID != userID {
-					t.Errorf("expected ID=%v and UserID=%v, got ID=%v and UserID=%v", bookingID, userID, arg.ID, arg.UserID)
+			name:   "Owner cancels with a reason",
+			status: StatusConfirmed,
+			mockCancel: func(_ context.Context, arg db.CancelBookingParams) (db.Booking, error) {
+				if arg.ID != bookingID || arg.CurrentStatus != StatusConfirmed {
+					t.Errorf("unexpected params %+v", arg)
 				}
-				return nil
+				if arg.CancelledBy == nil || *arg.CancelledBy != userID {
+					t.Errorf("expected cancelled_by %v, got %v", userID, arg.CancelledBy)
+				}
+				if arg.CancellationReason == nil || *arg.CancellationReason != "Sick" {
+					t.Errorf("expected reason %q, got %v", "Sick", arg.CancellationReason)
+				}
+				return db.Booking{ID: bookingID, Sequence: 3}, nil
 			},
-			ctxUserID: userID,
-			isAdmin:   false,
-			wantErr:   nil,
+			actorID: userID,
+			reason:  "Sick",
 		},
 		{
-			name: "Successful admin deletion",
-			mockDelete: func(_ context.Context, arg db.DeleteBookingParams) error {
-				if arg.ID != bookingID {
-					t.Errorf("expected ID=%v and UserID=%v, got ID=%v and UserID=%v", bookingID, userID, arg.ID, arg.UserID)
+			name:   "Admin cancels another user's pending booking",
+			status: StatusPending,
+			mockCancel: func(_ context.Context, arg db.CancelBookingParams) (db.Booking, error) {
+				if arg.CancelledBy == nil || *arg.CancelledBy != adminID {
+					t.Errorf("expected cancelled_by %v, got %v", adminID, arg.CancelledBy)
+				}
+				if arg.CancellationReason != nil {
+					t.Errorf("expected no reason, got %q", *arg.CancellationReason)
 				}
-				return nil
+				return db.Booking{ID: bookingID, Sequence: 3}, nil
 			},
-			ctxUserID: uuid.Nil,
-			isAdmin:   true,
-			wantErr:   nil,
+			actorID: adminID,
+			isAdmin: true,
 		},
 		{
-			name: "Booking not found",
-			mockDelete: func(_ context.Context, arg db.DeleteBookingParams) error {
-				return sql.ErrNoRows
+			name:    "Booking not found",
+			getErr:  sql.ErrNoRows,
+			actorID: userID,
+			wantErr: ErrBookingNotFound,
+		},
+		{
+			name:    "Another user's booking",
+			status:  StatusConfirmed,
+			actorID: uuid.New(),
+			wantErr: ErrNotAuthorized,
+		},
+		{
+			name:    "Already cancelled",
+			status:  StatusCancelled,
+			actorID: userID,
+			wantErr: ErrInvalidTransition,
+		},
+		{
+			name:    "Completed booking",
+			status:  StatusCompleted,
+			actorID: userID,
+			wantErr: ErrInvalidTransition,
+		},
+		{
+			name:   "Status changed concurrently",
+			status: StatusConfirmed,
+			mockCancel: func(context.Context, db.CancelBookingParams) (db.Booking, error) {
+				return db.Booking{}, sql.ErrNoRows
 			},
-			ctxUserID: userID,
-			isAdmin:   false,
-			wantErr:   ErrBookingNotFound,
+			actorID: userID,
+			wantErr: ErrInvalidTransition,
 		},
 		{
-			name: "Unsuccessful deletion",
-			mockDelete: func(_ context.Context, arg db.DeleteBookingParams) error {
-				return errDeleting
+			name:   "Unsuccessful cancellation",
+			status: StatusConfirmed,
+			mockCancel: func(context.Context, db.CancelBookingParams) (db.Booking, error) {
+				return db.Booking{}, errCancelling
 			},
-			ctxUserID: userID,
-			isAdmin:   false,
-			wantErr:   errDeleting,
+			actorID: userID,
+			wantErr: errCancelling,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := &fakeBookingRepo{
-				DeleteBookingFn: tt.mockDelete,
+				GetBookingByIDFn: func(context.Context, uuid.UUID) (db.Booking, error) {
+					return db.Booking{ID: bookingID, UserID: userID, Status: tt.status}, tt.getErr
+				},
+				CancelBookingFn: func(ctx context.Context, arg db.CancelBookingParams) (db.Booking, error) {
+					if tt.mockCancel == nil {
+						t.Fatalf("CancelBooking should not have been called")
+					}
+					return tt.mockCancel(ctx, arg)
+				},
 			}
 
 			svc := NewBookingService(repo)
-			err := svc.DeleteBooking(context.Background(), bookingID, tt.ctxUserID, tt.isAdmin)
+			err := svc.CancelBooking(context.Background(), bookingID, tt.actorID, tt.isAdmin, tt.reason)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
 
 			if tt.wantErr != nil {
-				if err == nil {
-					t.Fatalf("expected error %v, got nil", tt.wantErr)
-				}
-				if !errors.Is(err, tt.wantErr) {
-					t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+				if len(repo.events) != 0 {
+					t.Errorf("expected no events, got %+v", repo.events)
 				}
 				return
 			}
-			if err != nil {
-				t.Fatalf("unexpected error: %v", err)
+			if len(repo.events) != 1 || repo.events[0].EventType != events.TypeBookingCancelled {
+				t.Fatalf("expected one %s event, got %+v", events.TypeBookingCancelled, repo.events)
+			}
+			var e events.BookingCancelled
+			if err := json.Unmarshal([]byte(repo.events[0].Payload), &e); err != nil {
+				t.Fatalf("decode event: %v", err)
+			}
+			// The cancellation invite is numbered from the updated booking.
+			if e.BookingID != bookingID || e.CancelledBy != tt.actorID || e.Reason != tt.reason || e.Sequence != 3 {
+				t.Errorf("unexpected event %+v", e)
 			}
+		})
+	}
+}
+
+func TestBookingService_MarkAttendance(t *testing.T) {
+	bookingID := uuid.New()
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
 
+	tests := []struct {
+		name      string
+		mark      func(*BookingService) (db.Booking, error)
+		status    string
+		start     time.Time
+		updateErr error
+		want      string
+		wantErr   error
+	}{
+		{
+			name:   "Complete",
+			mark:   func(s *BookingService) (db.Booking, error) { return s.CompleteBooking(context.Background(), bookingID) },
+			status: StatusConfirmed,
+			start:  past,
+			want:   StatusCompleted,
+		},
+		{
+			name:   "No-show",
+			mark:   func(s *BookingService) (db.Booking, error) { return s.MarkNoShow(context.Background(), bookingID) },
+			status: StatusConfirmed,
+			start:  past,
+			want:   StatusNoShow,
+		},
+		{
+			name:    "Not started",
+			mark:    func(s *BookingService) (db.Booking, error) { return s.CompleteBooking(context.Background(), bookingID) },
+			status:  StatusConfirmed,
+			start:   future,
+			wantErr: ErrBookingNotStarted,
+		},
+		{
+			name:    "Pending cannot be completed",
+			mark:    func(s *BookingService) (db.Booking, error) { return s.CompleteBooking(context.Background(), bookingID) },
+			status:  StatusPending,
+			start:   past,
+			wantErr: ErrInvalidTransition,
+		},
+		{
+			name:    "Cancelled cannot be a no-show",
+			mark:    func(s *BookingService) (db.Booking, error) { return s.MarkNoShow(context.Background(), bookingID) },
+			status:  StatusCancelled,
+			start:   past,
+			wantErr: ErrInvalidTransition,
+		},
+		{
+			name:    "Completed is final",
+			mark:    func(s *BookingService) (db.Booking, error) { return s.MarkNoShow(context.Background(), bookingID) },
+			status:  StatusCompleted,
+			start:   past,
+			wantErr: ErrInvalidTransition,
+		},
+		{
+			name:      "Status changed concurrently",
+			mark:      func(s *BookingService) (db.Booking, error) { return s.CompleteBooking(context.Background(), bookingID) },
+			status:    StatusConfirmed,
+			start:     past,
+			updateErr: sql.ErrNoRows,
+			wantErr:   ErrInvalidTransition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeBookingRepo{
+				GetBookingByIDFn: func(context.Context, uuid.UUID) (db.Booking, error) {
+					return db.Booking{ID: bookingID, Status: tt.status, AppointmentStart: tt.start}, nil
+				},
+				UpdateBookingStatusFn: func(_ context.Context, arg db.UpdateBookingStatusParams) (db.Booking, error) {
+					if arg.ID != bookingID || arg.CurrentStatus != tt.status {
+						t.Errorf("unexpected params %+v", arg)
+					}
+					return db.Booking{ID: arg.ID, Status: arg.Status}, tt.updateErr
+				},
+			}
+
+			got, err := tt.mark(NewBookingService(repo))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if tt.wantErr == nil && got.Status != tt.want {
+				t.Errorf("status = %q, want %q", got.Status, tt.want)
+			}
 		})
 	}
 }
@@ -222,112 +501,193 @@ func TestBookingService_RescheduleBooking(t *testing.T) {
 	now := time.Date(2025, 5, 14, 10, 0, 0, 0, time.UTC)
 	bookingID := uuid.New()
 	userID := uuid.New()
-	newStart := now.Add(2 * time.Hour)
+	oldSlotID := uuid.New()
+	target := db.Availability{
+		ID:         uuid.New(),
+		ProviderID: uuid.New(),
+		StartTime:  now.Add(15 * time.Minute),
+		EndTime:    now.Add(45 * time.Minute),
+		Capacity:   1,
+	}
+	moved := func(_ context.Context, arg db.RescheduleBookingParams) (db.Booking, error) {
+		return db.Booking{
+			ID:               arg.ID,
+			SlotID:           arg.SlotID,
+			AppointmentStart: arg.AppointmentStart,
+			DurationMinutes:  arg.DurationMinutes,
+		}, nil
+	}
+	notCalled := func(_ context.Context, arg db.RescheduleBookingParams) (db.Booking, error) {
+		t.Fatalf("reschedule should not be called")
+		return db.Booking{}, nil
+	}
 
 	tests := []struct {
 		name           string
 		ctxUser        uuid.UUID
 		ctxAdmin       bool
 		mockReschedule func(ctx context.Context, arg db.RescheduleBookingParams) (db.Booking, error)
+		status         string
+		bookedSlot     uuid.UUID
+		slotErr        error
+		slotBookings   int64
+		requestedStart time.Time
+		requestedMins  int32
 		overlaps       []db.Booking
 		overlapErr     error
 		wantBooking    db.Booking
 		wantErr        error
 	}{
 		{
-			name:     "Successful reschedule",
-			ctxUser:  userID,
-			ctxAdmin: false,
-			mockReschedule: func(ctx context.Context, arg db.RescheduleBookingParams) (db.Booking, error) {
-				if arg.ID != bookingID {
-					t.Errorf("expected bookingID=%v, got bookingID=%v", bookingID, arg.ID)
-				}
-				if !arg.AppointmentStart.Equal(newStart) {
-					t.Errorf("expected new start=%v, got %v", newStart, arg.AppointmentStart)
-				}
-
-				return db.Booking{
-					ID:               arg.ID,
-					AppointmentStart: arg.AppointmentStart,
-					DurationMinutes:  int32(30),
-				}, nil
+			name:           "Successful reschedule",
+			ctxUser:        userID,
+			mockReschedule: moved,
+			wantBooking: db.Booking{
+				ID:               bookingID,
+				SlotID:           target.ID,
+				AppointmentStart: target.StartTime,
+				DurationMinutes:  30,
 			},
+		},
+		{
+			name:           "Requested time matches slot",
+			ctxUser:        userID,
+			mockReschedule: moved,
+			requestedStart: target.StartTime,
+			requestedMins:  30,
 			wantBooking: db.Booking{
 				ID:               bookingID,
-				AppointmentStart: newStart,
+				SlotID:           target.ID,
+				AppointmentStart: target.StartTime,
 				DurationMinutes:  30,
 			},
-			wantErr: nil,
 		},
 		{
-			name:     "Successful admin reschedule of another user",
-			ctxUser:  uuid.Nil,
-			ctxAdmin: true,
-			mockReschedule: func(ctx context.Context, arg db.RescheduleBookingParams) (db.Booking, error) {
-				if arg.ID != bookingID {
-					t.Errorf("expected bookingID=%v, got bookingID=%v", bookingID, arg.ID)
-				}
-				if !arg.AppointmentStart.Equal(newStart) {
-					t.Errorf("expected new start=%v, got %v", newStart, arg.AppointmentStart)
-				}
-
-				return db.Booking{
-					ID:               arg.ID,
-					AppointmentStart: arg.AppointmentStart,
-					DurationMinutes:  int32(30),
-				}, nil
+			name:           "Successful admin reschedule of another user",
+			ctxUser:        uuid.New(),
+			ctxAdmin:       true,
+			mockReschedule: moved,
+			wantBooking: db.Booking{
+				ID:               bookingID,
+				SlotID:           target.ID,
+				AppointmentStart: target.StartTime,
+				DurationMinutes:  30,
 			},
+		},
+		{
+			name:           "Full slot the booking already holds",
+			ctxUser:        userID,
+			mockReschedule: moved,
+			bookedSlot:     target.ID,
+			slotBookings:   1,
 			wantBooking: db.Booking{
 				ID:               bookingID,
-				AppointmentStart: newStart,
+				SlotID:           target.ID,
+				AppointmentStart: target.StartTime,
 				DurationMinutes:  30,
 			},
-			wantErr: nil,
 		},
 		{
-			name:     "Reschedule returns DB error",
-			ctxUser:  userID,
-			ctxAdmin: false,
+			name:           "Another user's booking",
+			ctxUser:        uuid.New(),
+			mockReschedule: notCalled,
+			wantErr:        ErrNotAuthorized,
+		},
+		{
+			name:           "Target slot taken",
+			ctxUser:        userID,
+			mockReschedule: notCalled,
+			slotBookings:   1,
+			wantErr:        ErrSlotTaken,
+		},
+		{
+			name:           "Target slot not found",
+			ctxUser:        userID,
+			mockReschedule: notCalled,
+			slotErr:        sql.ErrNoRows,
+			wantErr:        ErrSlotNotFound,
+		},
+		{
+			name:           "Requested time outside slot",
+			ctxUser:        userID,
+			mockReschedule: notCalled,
+			requestedStart: target.StartTime.Add(time.Hour),
+			wantErr:        ErrOutsideAvailability,
+		},
+		{
+			name:    "Reschedule returns DB error",
+			ctxUser: userID,
 			mockReschedule: func(_ context.Context, arg db.RescheduleBookingParams) (db.Booking, error) {
 				return db.Booking{}, errReschedule
 			},
 			wantErr: errReschedule,
 		},
 		{
-			name:     "DB error fetching overlaps",
-			ctxUser:  userID,
-			ctxAdmin: false,
+			name:    "Booking changed status meanwhile",
+			ctxUser: userID,
 			mockReschedule: func(_ context.Context, arg db.RescheduleBookingParams) (db.Booking, error) {
-				t.Fatalf("reschedule could not be called on overlap error")
-				return db.Booking{}, nil
+				return db.Booking{}, sql.ErrNoRows
 			},
-			overlapErr: errSimulatedOverlap,
-			wantErr:    errSimulatedOverlap,
+			wantErr: ErrInvalidTransition,
 		},
 		{
-			name:     "Overlap booking",
-			ctxUser:  userID,
-			ctxAdmin: false,
-			mockReschedule: func(_ context.Context, arg db.RescheduleBookingParams) (db.Booking, error) {
-				t.Fatalf("reschedule could not be called due to conflict of scheduling")
-				return db.Booking{}, nil
-			},
-			overlaps: []db.Booking{{ID: uuid.New()}},
-			wantErr:  ErrBookingConflict,
+			name:           "DB error fetching overlaps",
+			ctxUser:        userID,
+			mockReschedule: notCalled,
+			overlapErr:     errSimulatedOverlap,
+			wantErr:        errSimulatedOverlap,
+		},
+		{
+			name:           "Overlap booking",
+			ctxUser:        userID,
+			mockReschedule: notCalled,
+			overlaps:       []db.Booking{{ID: uuid.New()}},
+			wantErr:        ErrBookingConflict,
+		},
+		{
+			name:           "Cancelled booking",
+			ctxUser:        userID,
+			mockReschedule: notCalled,
+			status:         StatusCancelled,
+			wantErr:        ErrInvalidTransition,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			status := tt.status
+			if status == "" {
+				status = StatusConfirmed
+			}
+			bookedSlot := tt.bookedSlot
+			if bookedSlot == uuid.Nil {
+				bookedSlot = oldSlotID
+			}
 
 			repo := &fakeBookingRepo{
 				RescheduleBookingFn: tt.mockReschedule,
 				overlaps:            tt.overlaps,
 				overlapErr:          tt.overlapErr,
+				slotBookings:        tt.slotBookings,
+				GetBookingByIDFn: func(_ context.Context, id uuid.UUID) (db.Booking, error) {
+					return db.Booking{ID: id, UserID: userID, SlotID: bookedSlot, Status: status}, nil
+				},
+				GetAvailabilityByIDFn: func(_ context.Context, id uuid.UUID) (db.Availability, error) {
+					if id != target.ID {
+						t.Errorf("looked up slot %v, want %v", id, target.ID)
+					}
+					return target, tt.slotErr
+				},
+				onOverlap: func(arg db.GetOverlappingBookingsParams) {
+					if arg.ExcludeBookingID != (uuid.NullUUID{UUID: bookingID, Valid: true}) {
+						t.Errorf("overlap check does not exclude the booking itself: %+v", arg.ExcludeBookingID)
+					}
+				},
 			}
 
 			svc := NewBookingService(repo)
-			got, err := svc.RescheduleBooking(context.Background(), bookingID, tt.ctxUser, newStart, 30, tt.ctxAdmin)
+			got, err := svc.RescheduleBooking(context.Background(), bookingID, tt.ctxUser, target.ID,
+				tt.requestedStart, tt.requestedMins, tt.ctxAdmin)
 
 			if tt.wantErr != nil {
 				if err == nil {
@@ -343,11 +703,11 @@ func TestBookingService_RescheduleBooking(t *testing.T) {
 			}
 
 			if got.ID != tt.wantBooking.ID ||
+				got.SlotID != tt.wantBooking.SlotID ||
 				!got.AppointmentStart.Equal(tt.wantBooking.AppointmentStart) ||
 				got.DurationMinutes != tt.wantBooking.DurationMinutes {
 				t.Errorf("got %+v, want %+v", got, tt.wantBooking)
 			}
-
 		})
 	}
 }
@@ -475,21 +835,52 @@ func TestListUserBookings(t *testing.T) {
 
 	tests := []struct {
 		name         string
-		mockList     func(ctx context.Context, id uuid.UUID) ([]db.Booking, error)
+		filter       BookingFilter
+		mockList     func(ctx context.Context, arg db.ListBookingsForUserParams) ([]db.Booking, error)
 		wantBookings []db.Booking
+		wantCursor   string
 		wantErr      error
 	}{
 		{
 			name: "Success",
-			mockList: func(_ context.Context, id uuid.UUID) ([]db.Booking, error) {
+			mockList: func(_ context.Context, arg db.ListBookingsForUserParams) ([]db.Booking, error) {
+				if arg.UserID != userID || arg.PageLimit != pagination.DefaultLimit+1 || arg.AfterStart.Valid {
+					t.Errorf("unexpected params %+v", arg)
+				}
 				return fakeBookings, nil
 			},
 			wantBookings: fakeBookings,
 			wantErr:      nil,
 		},
+		{
+			name:   "More rows than the limit",
+			filter: BookingFilter{Page: pagination.Params{Limit: 2}},
+			mockList: func(_ context.Context, arg db.ListBookingsForUserParams) ([]db.Booking, error) {
+				if arg.PageLimit != 3 {
+					t.Errorf("PageLimit = %d, want 3", arg.PageLimit)
+				}
+				return fakeBookings, nil
+			},
+			wantBookings: fakeBookings[:2],
+			wantCursor:   pagination.Cursor{Time: fakeBookings[1].AppointmentStart, ID: bookingID2}.String(),
+		},
+		{
+			name: "Continues after cursor",
+			filter: BookingFilter{
+				StartFrom: sql.NullTime{Time: now, Valid: true},
+				Page:      pagination.Params{After: &pagination.Cursor{Time: now, ID: bookingID}},
+			},
+			mockList: func(_ context.Context, arg db.ListBookingsForUserParams) ([]db.Booking, error) {
+				if !arg.StartFrom.Time.Equal(now) || !arg.AfterStart.Time.Equal(now) || arg.AfterID.UUID != bookingID {
+					t.Errorf("unexpected params %+v", arg)
+				}
+				return fakeBookings[2:], nil
+			},
+			wantBookings: fakeBookings[2:],
+		},
 		{
 			name: "DB error",
-			mockList: func(_ context.Context, id uuid.UUID) ([]db.Booking, error) {
+			mockList: func(_ context.Context, arg db.ListBookingsForUserParams) ([]db.Booking, error) {
 				return []db.Booking{}, otherErr
 			},
 			wantBookings: nil,
@@ -504,15 +895,17 @@ func TestListUserBookings(t *testing.T) {
 			}
 			svc := NewBookingService(repo)
 
-			got, err := svc.ListUserBookings(context.Background(), userID)
+			got, err := svc.ListUserBookings(context.Background(), userID, tt.filter)
 
 			if !errors.Is(err, tt.wantErr) {
 				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
 			}
-			if tt.wantErr == nil && !reflect.DeepEqual(got, tt.wantBookings) {
-				t.Errorf("got = %#v, want %#v", got, tt.wantBookings)
+			if tt.wantErr == nil && !reflect.DeepEqual(got.Items, tt.wantBookings) {
+				t.Errorf("got = %#v, want %#v", got.Items, tt.wantBookings)
+			}
+			if got.NextCursor != tt.wantCursor {
+				t.Errorf("next cursor = %q, want %q", got.NextCursor, tt.wantCursor)
 			}
-
 		})
 	}
 }
@@ -539,21 +932,37 @@ func TestListAllBookings(t *testing.T) {
 
 	tests := []struct {
 		name    string
-		mockFn  func(ctx context.Context) ([]db.Booking, error)
+		filter  BookingFilter
+		mockFn  func(ctx context.Context, arg db.ListAllBookingsForAdminParams) ([]db.Booking, error)
 		want    []db.Booking
 		wantErr bool
 	}{
 		{
 			name: "success returns bookings",
-			mockFn: func(ctx context.Context) ([]db.Booking, error) {
+			mockFn: func(ctx context.Context, arg db.ListAllBookingsForAdminParams) ([]db.Booking, error) {
 				return []db.Booking{b1, b2}, nil
 			},
 			want:    []db.Booking{b1, b2},
 			wantErr: false,
 		},
+		{
+			name: "filters are passed through",
+			filter: BookingFilter{
+				UserID:      uuid.NullUUID{UUID: b1.UserID, Valid: true},
+				ProviderID:  uuid.NullUUID{UUID: b1.SlotID, Valid: true},
+				StartBefore: sql.NullTime{Time: now, Valid: true},
+			},
+			mockFn: func(ctx context.Context, arg db.ListAllBookingsForAdminParams) ([]db.Booking, error) {
+				if arg.UserID.UUID != b1.UserID || arg.ProviderID.UUID != b1.SlotID || !arg.StartBefore.Time.Equal(now) || arg.StartFrom.Valid {
+					t.Errorf("unexpected params %+v", arg)
+				}
+				return []db.Booking{b1}, nil
+			},
+			want: []db.Booking{b1},
+		},
 		{
 			name: "error from queries bubbles up",
-			mockFn: func(ctx context.Context) ([]db.Booking, error) {
+			mockFn: func(ctx context.Context, arg db.ListAllBookingsForAdminParams) ([]db.Booking, error) {
 				return nil, errors.New("database failure")
 			},
 			want:    []db.Booking{},
@@ -568,10 +977,11 @@ func TestListAllBookings(t *testing.T) {
 			}
 			svc := NewBookingService(mockQ)
 
-			got, err := svc.ListAllBookings(context.Background())
+			page, err := svc.ListAllBookings(context.Background(), tt.filter)
 			if (err != nil) != tt.wantErr {
 				t.Fatalf("ListAllBookings() error = %v, wantErr %v", err, tt.wantErr)
 			}
+			got := page.Items
 
 			if len(got) != len(tt.want) {
 				t.Fatalf("ListAllBookings() returned %d bookings, want %d", len(got), len(tt.want))
@@ -585,3 +995,498 @@ func TestListAllBookings(t *testing.T) {
 		})
 	}
 }
+
+// memBookingStore is an in-memory store whose LockProviderSchedule behaves
+// like a transaction-scoped advisory lock, so concurrent CreateBooking calls
+// interleave the same way they would against Postgres.
+type memBookingStore struct {
+	db.Querier
+	mu       sync.Mutex
+	locks    map[uuid.UUID]*sync.Mutex
+	slots    map[uuid.UUID]db.Availability
+	bookings []db.Booking
+	events   []db.CreateOutboxEventParams
+}
+
+type memBookingTx struct {
+	*memBookingStore
+	held    []*sync.Mutex
+	pending []db.Booking
+	events  []db.CreateOutboxEventParams
+}
+
+func newMemBookingStore(slots ...db.Availability) *memBookingStore {
+	s := &memBookingStore{
+		locks: map[uuid.UUID]*sync.Mutex{},
+		slots: map[uuid.UUID]db.Availability{},
+	}
+	for _, slot := range slots {
+		s.slots[slot.ID] = slot
+	}
+	return s
+}
+
+func (s *memBookingStore) ExecTx(ctx context.Context, fn func(db.Querier) error) error {
+	tx := &memBookingTx{memBookingStore: s}
+	err := fn(tx)
+	if err == nil {
+		s.mu.Lock()
+		s.bookings = append(s.bookings, tx.pending...)
+		s.events = append(s.events, tx.events...)
+		s.mu.Unlock()
+	}
+	for _, l := range tx.held {
+		l.Unlock()
+	}
+	return err
+}
+
+func (tx *memBookingTx) CreateOutboxEvent(ctx context.Context, arg db.CreateOutboxEventParams) error {
+	tx.events = append(tx.events, arg)
+	return nil
+}
+
+func (tx *memBookingTx) CancelBookingReminders(ctx context.Context, bookingID uuid.UUID) error {
+	return nil
+}
+
+func (tx *memBookingTx) ScheduleBookingReminder(ctx context.Context, arg db.ScheduleBookingReminderParams) error {
+	return nil
+}
+
+func (tx *memBookingTx) GetAvailabilityByID(ctx context.Context, id uuid.UUID) (db.Availability, error) {
+	slot, ok := tx.slots[id]
+	if !ok {
+		return db.Availability{}, sql.ErrNoRows
+	}
+	return slot, nil
+}
+
+func (tx *memBookingTx) LockProviderSchedule(ctx context.Context, providerID uuid.UUID) error {
+	tx.mu.Lock()
+	l, ok := tx.locks[providerID]
+	if !ok {
+		l = &sync.Mutex{}
+		tx.locks[providerID] = l
+	}
+	tx.mu.Unlock()
+
+	l.Lock()
+	tx.held = append(tx.held, l)
+	return nil
+}
+
+func (tx *memBookingTx) GetOverlappingBookings(ctx context.Context, arg db.GetOverlappingBookingsParams) ([]db.Booking, error) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	var out []db.Booking
+	for _, b := range tx.bookings {
+		if tx.slots[b.SlotID].ProviderID != arg.ProviderID || b.Status == StatusCancelled {
+			continue
+		}
+		if arg.ExcludeSlotID.Valid && b.SlotID == arg.ExcludeSlotID.UUID {
+			continue
+		}
+		if arg.ExcludeBookingID.Valid && b.ID == arg.ExcludeBookingID.UUID {
+			continue
+		}
+		end := b.AppointmentStart.Add(time.Duration(b.DurationMinutes) * time.Minute)
+		if b.AppointmentStart.Before(arg.RangeEnd) && end.After(arg.RangeStart) {
+			out = append(out, b)
+		}
+	}
+	return out, nil
+}
+
+func (tx *memBookingTx) CountBookingsForSlot(ctx context.Context, slotID uuid.UUID) (int64, error) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	var n int64
+	for _, b := range tx.bookings {
+		if b.SlotID == slotID && b.Status != StatusCancelled {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (tx *memBookingTx) CreateBooking(ctx context.Context, arg db.CreateBookingParams) (db.Booking, error) {
+	b := db.Booking{
+		ID:               arg.ID,
+		AppointmentStart: arg.AppointmentStart,
+		DurationMinutes:  arg.DurationMinutes,
+		UserID:           arg.UserID,
+		SlotID:           arg.SlotID,
+		Status:           StatusConfirmed,
+	}
+	tx.pending = append(tx.pending, b)
+	return b, nil
+}
+
+func (tx *memBookingTx) GetBookingByID(ctx context.Context, id uuid.UUID) (db.Booking, error) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	for _, b := range tx.bookings {
+		if b.ID == id {
+			return b, nil
+		}
+	}
+	return db.Booking{}, sql.ErrNoRows
+}
+
+func (tx *memBookingTx) RescheduleBooking(ctx context.Context, arg db.RescheduleBookingParams) (db.Booking, error) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	for i, b := range tx.bookings {
+		if b.ID == arg.ID {
+			b.SlotID = arg.SlotID
+			b.AppointmentStart = arg.AppointmentStart
+			b.DurationMinutes = arg.DurationMinutes
+			b.Sequence++
+			tx.bookings[i] = b
+			return b, nil
+		}
+	}
+	return db.Booking{}, sql.ErrNoRows
+}
+
+func TestBookingService_CreateBookingConcurrent(t *testing.T) {
+	start := time.Date(2025, 6, 3, 9, 0, 0, 0, time.UTC)
+	slotA := db.Availability{ID: uuid.New(), ProviderID: uuid.New(), StartTime: start, EndTime: start.Add(time.Hour), Capacity: 1}
+	slotB := db.Availability{ID: uuid.New(), ProviderID: uuid.New(), StartTime: start, EndTime: start.Add(time.Hour), Capacity: 1}
+	group := db.Availability{ID: uuid.New(), ProviderID: uuid.New(), StartTime: start, EndTime: start.Add(time.Hour), Capacity: 2}
+
+	tests := []struct {
+		name          string
+		slots         [2]db.Availability
+		existing      []db.Booking
+		wantSuccesses int
+		wantConflicts int
+	}{
+		{
+			name:          "Same provider and slot",
+			slots:         [2]db.Availability{slotA, slotA},
+			wantSuccesses: 1,
+			wantConflicts: 1,
+		},
+		{
+			name:          "Different providers at the same time",
+			slots:         [2]db.Availability{slotA, slotB},
+			wantSuccesses: 2,
+			wantConflicts: 0,
+		},
+		{
+			name:          "Group slot with room for both",
+			slots:         [2]db.Availability{group, group},
+			wantSuccesses: 2,
+			wantConflicts: 0,
+		},
+		{
+			name:  "Group slot freed by a cancelled booking",
+			slots: [2]db.Availability{group, group},
+			existing: []db.Booking{
+				{ID: uuid.New(), SlotID: group.ID, AppointmentStart: start, DurationMinutes: 60, Status: StatusCancelled},
+			},
+			wantSuccesses: 2,
+			wantConflicts: 0,
+		},
+		{
+			name:  "Group slot with one place taken",
+			slots: [2]db.Availability{group, group},
+			existing: []db.Booking{
+				{ID: uuid.New(), SlotID: group.ID, AppointmentStart: start, DurationMinutes: 60, Status: StatusConfirmed},
+			},
+			wantSuccesses: 1,
+			wantConflicts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemBookingStore(slotA, slotB, group)
+			store.bookings = tt.existing
+			svc := NewBookingService(store)
+
+			var wg sync.WaitGroup
+			ready := make(chan struct{})
+			errs := make([]error, len(tt.slots))
+			for i, slot := range tt.slots {
+				wg.Add(1)
+				go func(i int, slot db.Availability) {
+					defer wg.Done()
+					<-ready
+					_, errs[i] = svc.CreateBooking(context.Background(), uuid.New(), uuid.New(), slot.ID, time.Time{}, 0)
+				}(i, slot)
+			}
+			close(ready)
+			wg.Wait()
+
+			var successes, conflicts int
+			for _, err := range errs {
+				switch {
+				case err == nil:
+					successes++
+				case errors.Is(err, ErrBookingConflict):
+					conflicts++
+				default:
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if successes != tt.wantSuccesses || conflicts != tt.wantConflicts {
+				t.Errorf("got %d successes and %d conflicts, want %d and %d",
+					successes, conflicts, tt.wantSuccesses, tt.wantConflicts)
+			}
+		})
+	}
+}
+
+func TestBookingService_RescheduleBookingMovesSlot(t *testing.T) {
+	start := time.Date(2025, 6, 3, 9, 0, 0, 0, time.UTC)
+	providerID := uuid.New()
+	slotAt := func(offset time.Duration) db.Availability {
+		return db.Availability{ID: uuid.New(), ProviderID: providerID, StartTime: start.Add(offset), EndTime: start.Add(offset + time.Hour), Capacity: 1}
+	}
+	current, later, taken := slotAt(0), slotAt(15*time.Minute), slotAt(2*time.Hour)
+
+	store := newMemBookingStore(current, later, taken)
+	userID := uuid.New()
+	svc := NewBookingService(store)
+	booking, err := svc.CreateBooking(context.Background(), uuid.New(), userID, current.ID, time.Time{}, 0)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := svc.CreateBooking(context.Background(), uuid.New(), uuid.New(), taken.ID, time.Time{}, 0); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// The new time overlaps the booking's current one, which must not count
+	// as a conflict.
+	moved, err := svc.RescheduleBooking(context.Background(), booking.ID, userID, later.ID, time.Time{}, 0, false)
+	if err != nil {
+		t.Fatalf("reschedule: %v", err)
+	}
+	if moved.SlotID != later.ID || !moved.AppointmentStart.Equal(later.StartTime) {
+		t.Errorf("moved booking = %+v, want it on slot %v at %v", moved, later.ID, later.StartTime)
+	}
+
+	tx := &memBookingTx{memBookingStore: store}
+	if n, _ := tx.CountBookingsForSlot(context.Background(), current.ID); n != 0 {
+		t.Errorf("old slot still holds %d bookings", n)
+	}
+
+	_, err = svc.RescheduleBooking(context.Background(), booking.ID, userID, taken.ID, time.Time{}, 0, false)
+	if !errors.Is(err, ErrSlotTaken) {
+		t.Fatalf("err = %v, want ErrSlotTaken", err)
+	}
+
+	// The failed reschedule rolled back without storing an event.
+	var types []string
+	for _, e := range store.events {
+		types = append(types, e.EventType)
+	}
+	want := []string{events.TypeBookingCreated, events.TypeBookingCreated, events.TypeBookingRescheduled}
+	if !reflect.DeepEqual(types, want) {
+		t.Fatalf("events = %v, want %v", types, want)
+	}
+	var e events.BookingRescheduled
+	if err := json.Unmarshal([]byte(store.events[2].Payload), &e); err != nil {
+		t.Fatalf("decode event: %v", err)
+	}
+	if e.BookingID != booking.ID || e.PreviousSlotID != current.ID || e.SlotID != later.ID || e.RescheduledBy != userID {
+		t.Errorf("unexpected event %+v", e)
+	}
+	// Each invite's sequence is the booking's after the change, so the
+	// reschedule outranks the confirmation and reminders match it.
+	var created events.BookingCreated
+	if err := json.Unmarshal([]byte(store.events[0].Payload), &created); err != nil {
+		t.Fatalf("decode event: %v", err)
+	}
+	if created.Sequence != booking.Sequence || e.Sequence != moved.Sequence || e.Sequence <= created.Sequence {
+		t.Errorf("sequences: created %d (booking %d), rescheduled %d (booking %d)",
+			created.Sequence, booking.Sequence, e.Sequence, moved.Sequence)
+	}
+}
+
+func TestBookingService_Reminders(t *testing.T) {
+	now := time.Now().Truncate(time.Minute)
+	bookingID, userID := uuid.New(), uuid.New()
+	slotStart := now.Add(3 * time.Hour)
+	repo := &fakeBookingRepo{
+		created: db.Booking{ID: bookingID, UserID: userID, AppointmentStart: slotStart, DurationMinutes: 30, Status: StatusConfirmed},
+		GetAvailabilityByIDFn: func(ctx context.Context, id uuid.UUID) (db.Availability, error) {
+			return db.Availability{ID: id, ProviderID: uuid.New(), StartTime: slotStart, EndTime: slotStart.Add(30 * time.Minute), Capacity: 1}, nil
+		},
+		RescheduleBookingFn: func(ctx context.Context, arg db.RescheduleBookingParams) (db.Booking, error) {
+			return db.Booking{ID: arg.ID, UserID: userID, SlotID: arg.SlotID, AppointmentStart: arg.AppointmentStart,
+				DurationMinutes: arg.DurationMinutes, Status: StatusConfirmed}, nil
+		},
+		CancelBookingFn: func(ctx context.Context, arg db.CancelBookingParams) (db.Booking, error) {
+			return db.Booking{ID: arg.ID, Status: StatusCancelled}, nil
+		},
+	}
+	svc := NewBookingService(repo, WithReminderOffsets(24*time.Hour, time.Hour, 30*time.Minute))
+
+	type reminder struct {
+		offset int32
+		at     time.Time
+	}
+	scheduled := func() []reminder {
+		var out []reminder
+		for _, r := range repo.reminders {
+			if r.BookingID != bookingID {
+				t.Errorf("reminder for booking %v, want %v", r.BookingID, bookingID)
+			}
+			out = append(out, reminder{r.OffsetMinutes, r.RemindAt})
+		}
+		return out
+	}
+
+	if _, err := svc.CreateBooking(context.Background(), bookingID, userID, uuid.New(), time.Time{}, 0); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	// The day-before reminder is already past.
+	want := []reminder{{60, slotStart.Add(-time.Hour)}, {30, slotStart.Add(-30 * time.Minute)}}
+	if got := scheduled(); !reflect.DeepEqual(got, want) {
+		t.Errorf("after create reminders = %v, want %v", got, want)
+	}
+
+	slotStart = now.Add(48 * time.Hour)
+	if _, err := svc.RescheduleBooking(context.Background(), bookingID, userID, uuid.New(), time.Time{}, 0, true); err != nil {
+		t.Fatalf("reschedule: %v", err)
+	}
+	want = []reminder{{24 * 60, slotStart.Add(-24 * time.Hour)}, {60, slotStart.Add(-time.Hour)}, {30, slotStart.Add(-30 * time.Minute)}}
+	if got := scheduled(); !reflect.DeepEqual(got, want) {
+		t.Errorf("after reschedule reminders = %v, want %v", got, want)
+	}
+
+	if err := svc.CancelBooking(context.Background(), bookingID, userID, true, ""); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if got := scheduled(); len(got) != 0 {
+		t.Errorf("after cancel reminders = %v, want none", got)
+	}
+	if len(repo.cancelledReminders) != 3 {
+		t.Errorf("reminders dropped %d times, want once per create, reschedule and cancel", len(repo.cancelledReminders))
+	}
+}
+
+type countingMetrics struct {
+	created, cancelled, rescheduled, conflicts int
+}
+
+func (m *countingMetrics) BookingCreated()     { m.created++ }
+func (m *countingMetrics) BookingCancelled()   { m.cancelled++ }
+func (m *countingMetrics) BookingRescheduled() { m.rescheduled++ }
+func (m *countingMetrics) BookingConflict()    { m.conflicts++ }
+
+func TestBookingService_Metrics(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	tests := []struct {
+		name string
+		repo *fakeBookingRepo
+		run  func(svc *BookingService) error
+		want countingMetrics
+	}{
+		{
+			name: "Created",
+			repo: &fakeBookingRepo{},
+			run: func(svc *BookingService) error {
+				_, err := svc.CreateBooking(ctx, uuid.New(), userID, uuid.New(), time.Time{}, 0)
+				return err
+			},
+			want: countingMetrics{created: 1},
+		},
+		{
+			name: "Create conflict",
+			repo: &fakeBookingRepo{slotBookings: 1},
+			run: func(svc *BookingService) error {
+				_, err := svc.CreateBooking(ctx, uuid.New(), userID, uuid.New(), time.Time{}, 0)
+				return err
+			},
+			want: countingMetrics{conflicts: 1},
+		},
+		{
+			name: "Create failure is not counted",
+			repo: &fakeBookingRepo{createErr: errSimulatedCreate},
+			run: func(svc *BookingService) error {
+				_, err := svc.CreateBooking(ctx, uuid.New(), userID, uuid.New(), time.Time{}, 0)
+				return err
+			},
+		},
+		{
+			name: "Cancelled",
+			repo: &fakeBookingRepo{CancelBookingFn: func(context.Context, db.CancelBookingParams) (db.Booking, error) {
+				return db.Booking{}, nil
+			}},
+			run: func(svc *BookingService) error {
+				return svc.CancelBooking(ctx, uuid.New(), userID, true, "")
+			},
+			want: countingMetrics{cancelled: 1},
+		},
+		{
+			name: "Rescheduled",
+			repo: &fakeBookingRepo{RescheduleBookingFn: func(context.Context, db.RescheduleBookingParams) (db.Booking, error) {
+				return db.Booking{}, nil
+			}},
+			run: func(svc *BookingService) error {
+				_, err := svc.RescheduleBooking(ctx, uuid.New(), userID, uuid.New(), time.Time{}, 0, true)
+				return err
+			},
+			want: countingMetrics{rescheduled: 1},
+		},
+		{
+			name: "Reschedule conflict",
+			repo: &fakeBookingRepo{overlaps: []db.Booking{{ID: uuid.New()}}},
+			run: func(svc *BookingService) error {
+				_, err := svc.RescheduleBooking(ctx, uuid.New(), userID, uuid.New(), time.Time{}, 0, true)
+				return err
+			},
+			want: countingMetrics{conflicts: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &countingMetrics{}
+			_ = tt.run(NewBookingService(tt.repo, WithBookingMetrics(m)))
+			if *m != tt.want {
+				t.Errorf("metrics = %+v, want %+v", *m, tt.want)
+			}
+		})
+	}
+}
+
+func TestBookingService_Spans(t *testing.T) {
+	exp := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp)))
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "request")
+	svc := NewBookingService(&fakeBookingRepo{slotBookings: 1})
+	_, err := svc.CreateBooking(ctx, uuid.New(), uuid.New(), uuid.New(), time.Time{}, 0)
+	parent.End()
+	if !errors.Is(err, ErrSlotTaken) {
+		t.Fatalf("err = %v, want ErrSlotTaken", err)
+	}
+
+	spans := exp.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(spans))
+	}
+	span := spans[0]
+	if span.Name != "BookingService.CreateBooking" {
+		t.Errorf("span name = %q", span.Name)
+	}
+	if span.Parent.SpanID() != parent.SpanContext().SpanID() {
+		t.Error("service span is not a child of the request span")
+	}
+	if span.Status.Code != codes.Error || span.Status.Description != ErrSlotTaken.Error() {
+		t.Errorf("span status = %+v", span.Status)
+	}
+}
@@ -0,0 +1,142 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/config"
+)
+
+func inMemory(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	exp := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+	return exp
+}
+
+func TestMiddleware(t *testing.T) {
+	const parent = "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+
+	tests := []struct {
+		name        string
+		path        string
+		traceparent string
+		status      int
+		wantName    string
+		wantError   bool
+	}{
+		{name: "Route template", path: "/api/bookings/42", status: http.StatusOK, wantName: "GET /api/bookings/{id}"},
+		{name: "Server error", path: "/api/bookings/42", status: http.StatusInternalServerError, wantName: "GET /api/bookings/{id}", wantError: true},
+		{name: "Client error is not a span error", path: "/api/bookings/42", status: http.StatusNotFound, wantName: "GET /api/bookings/{id}"},
+		{name: "Continues incoming trace", path: "/api/bookings/42", traceparent: parent, status: http.StatusOK, wantName: "GET /api/bookings/{id}"},
+		{name: "Unmatched", path: "/nowhere", status: http.StatusNotFound, wantName: "GET unmatched"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exp := inMemory(t)
+
+			var handlerSpan trace.SpanContext
+			r := mux.NewRouter()
+			r.Use(Middleware)
+			r.HandleFunc("/api/bookings/{id}", func(w http.ResponseWriter, r *http.Request) {
+				handlerSpan = trace.SpanContextFromContext(r.Context())
+				w.WriteHeader(tt.status)
+			})
+			r.NotFoundHandler = Middleware(http.NotFoundHandler())
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.traceparent != "" {
+				req.Header.Set("traceparent", tt.traceparent)
+			}
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			spans := exp.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(spans))
+			}
+			span := spans[0]
+			if span.Name != tt.wantName {
+				t.Errorf("span name = %q, want %q", span.Name, tt.wantName)
+			}
+			if span.SpanKind != trace.SpanKindServer {
+				t.Errorf("span kind = %v", span.SpanKind)
+			}
+			if (span.Status.Code == codes.Error) != tt.wantError {
+				t.Errorf("span status = %v, wantError %v", span.Status, tt.wantError)
+			}
+			var gotStatus int64
+			for _, a := range span.Attributes {
+				if a.Key == "http.response.status_code" {
+					gotStatus = a.Value.AsInt64()
+				}
+			}
+			if gotStatus != int64(tt.status) {
+				t.Errorf("status attribute = %d, want %d", gotStatus, tt.status)
+			}
+			if tt.traceparent != "" {
+				if span.Parent.TraceID().String() != "0af7651916cd43dd8448eb211c80319c" {
+					t.Errorf("span did not continue the incoming trace: parent %v", span.Parent)
+				}
+			}
+			if tt.path != "/nowhere" && handlerSpan.SpanID() != span.SpanContext.SpanID() {
+				t.Error("handler context does not carry the server span")
+			}
+		})
+	}
+}
+
+func TestSetup(t *testing.T) {
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	tests := []struct {
+		name     string
+		exporter string
+		wantErr  bool
+		wantOut  string
+	}{
+		{name: "None", exporter: "none"},
+		{name: "Stdout", exporter: "stdout", wantOut: `"Name":"check"`},
+		{name: "Unknown", exporter: "zipkin", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			cfg := config.Default().Tracing
+			cfg.Exporter = tt.exporter
+			shutdown, err := Setup(context.Background(), cfg, &buf)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			_, span := otel.Tracer("test").Start(context.Background(), "check")
+			span.End()
+			if err := shutdown(context.Background()); err != nil {
+				t.Fatalf("shutdown: %v", err)
+			}
+			if !strings.Contains(buf.String(), tt.wantOut) {
+				t.Errorf("exporter output %q does not contain %q", buf.String(), tt.wantOut)
+			}
+		})
+	}
+}
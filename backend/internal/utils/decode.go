@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/apperr"
+)
+
+// MaxRequestBodyBytes caps every JSON request body read by DecodeJSON.
+const MaxRequestBodyBytes = 1 << 20
+
+// ErrEmptyBody is returned by DecodeJSON when the request has no body.
+// Handlers whose body is optional can check for it with errors.Is.
+var ErrEmptyBody = apperr.Validation("Request body is required")
+
+// DecodeJSON reads r's body into dst, a pointer to a request struct, and
+// then runs Validate on it. The body must be a single JSON object of at most
+// MaxRequestBodyBytes sent as application/json, and may not contain fields
+// dst does not declare.
+//
+// Every failure is an *apperr.Error ready for RespondWithProblem.
+func DecodeJSON(w http.ResponseWriter, r *http.Request, dst any) error {
+	body := bufio.NewReader(http.MaxBytesReader(w, r.Body, MaxRequestBodyBytes))
+	if _, err := body.Peek(1); err == io.EOF {
+		return ErrEmptyBody
+	}
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		return apperr.New(apperr.KindUnsupportedMediaType, "Content-Type must be application/json")
+	}
+
+	dec := json.NewDecoder(body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		return decodeError(err)
+	}
+	if err := dec.Decode(&struct{}{}); err != io.EOF {
+		return apperr.Validation("Invalid request body: must contain a single JSON object")
+	}
+
+	return Validate(dst)
+}
+
+func decodeError(err error) error {
+	var (
+		syntaxErr   *json.SyntaxError
+		typeErr     *json.UnmarshalTypeError
+		maxBytesErr *http.MaxBytesError
+	)
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return apperr.Wrap(apperr.KindTooLarge,
+			fmt.Sprintf("Request body must not exceed %d bytes", maxBytesErr.Limit), err)
+	case errors.As(err, &syntaxErr):
+		return apperr.Wrap(apperr.KindValidation,
+			fmt.Sprintf("Invalid request body: malformed JSON at offset %d", syntaxErr.Offset), err)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return apperr.Wrap(apperr.KindValidation, "Invalid request body: malformed JSON", err)
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		e := apperr.Validation("Request validation failed", apperr.FieldError{
+			Field:   typeErr.Field,
+			Message: "must be " + jsonType(typeErr.Type),
+		})
+		e.Err = err
+		return e
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json has no typed error for DisallowUnknownFields.
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		e := apperr.Validation("Request validation failed", apperr.FieldError{
+			Field:   field,
+			Message: "is not allowed",
+		})
+		e.Err = err
+		return e
+	}
+	return apperr.Wrap(apperr.KindValidation, "Invalid request body", err)
+}
+
+// jsonType describes t the way a client sees it.
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	}
+	return "an object"
+}
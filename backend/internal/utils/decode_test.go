@@ -0,0 +1,239 @@
+package utils
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/apperr"
+)
+
+type testRequest struct {
+	Name     string    `json:"name" validate:"required,max=5"`
+	Email    string    `json:"email" validate:"omitempty,email"`
+	Role     string    `json:"role" validate:"omitempty,oneof=user admin"`
+	Slot     string    `json:"slot" validate:"omitempty,uuid"`
+	Day      int32     `json:"day" validate:"min=0,max=6"`
+	Duration *int32    `json:"duration" validate:"omitempty,gt=0"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end" validate:"omitempty,gtfield=Start"`
+	Zone     string    `json:"zone" validate:"omitempty,timezone"`
+	Hook     string    `json:"hook" validate:"omitempty,url"`
+}
+
+func TestValidate(t *testing.T) {
+	start := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	zero, neg := int32(0), int32(-5)
+
+	tests := []struct {
+		name string
+		req  testRequest
+		want []apperr.FieldError
+	}{
+		{
+			name: "Valid",
+			req: testRequest{
+				Name:  "Ann",
+				Email: "ann@example.com",
+				Role:  "admin",
+				Slot:  "3f1b1b0e-8c1d-4a36-9f41-6d3b2f0a6c11",
+				Day:   6,
+				Start: start,
+				End:   start.Add(time.Hour),
+				Zone:  "America/New_York",
+			},
+		},
+		{
+			name: "Required",
+			req:  testRequest{},
+			want: []apperr.FieldError{{Field: "name", Message: "is required"}},
+		},
+		{
+			name: "Every failure reported",
+			req: testRequest{
+				Name:     "Annabel",
+				Email:    "Ann <ann@example.com>",
+				Role:     "root",
+				Slot:     "12345",
+				Day:      7,
+				Duration: &neg,
+				Start:    start,
+				End:      start,
+				Zone:     "Mars/Olympus_Mons",
+			},
+			want: []apperr.FieldError{
+				{Field: "name", Message: "must be at most 5 characters"},
+				{Field: "email", Message: "must be a valid email address"},
+				{Field: "role", Message: "must be one of: user, admin"},
+				{Field: "slot", Message: "must be a valid UUID"},
+				{Field: "day", Message: "must be at most 6"},
+				{Field: "duration", Message: "must be greater than 0"},
+				{Field: "end", Message: "must be after start"},
+				{Field: "zone", Message: "must be an IANA timezone name"},
+			},
+		},
+		{
+			name: "Explicit zero pointer is checked",
+			req:  testRequest{Name: "Ann", Duration: &zero},
+			want: []apperr.FieldError{{Field: "duration", Message: "must be greater than 0"}},
+		},
+		{
+			name: "Negative number below min",
+			req:  testRequest{Name: "Ann", Day: -1},
+			want: []apperr.FieldError{{Field: "day", Message: "must be at least 0"}},
+		},
+		{
+			name: "Local is not a timezone name",
+			req:  testRequest{Name: "Ann", Zone: "Local"},
+			want: []apperr.FieldError{{Field: "zone", Message: "must be an IANA timezone name"}},
+		},
+		{
+			name: "URL without http scheme",
+			req:  testRequest{Name: "Ann", Hook: "ftp://example.com/hook"},
+			want: []apperr.FieldError{{Field: "hook", Message: "must be an http or https URL"}},
+		},
+		{
+			name: "HTTPS URL",
+			req:  testRequest{Name: "Ann", Hook: "https://crm.example.com/hooks/booking"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(&tt.req)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+
+			var appErr *apperr.Error
+			if !errors.As(err, &appErr) || appErr.Kind != apperr.KindValidation {
+				t.Fatalf("expected validation error, got %v", err)
+			}
+			if !reflect.DeepEqual(appErr.Fields, tt.want) {
+				t.Errorf("fields:\n got %+v\nwant %+v", appErr.Fields, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantKind    apperr.Kind
+		wantField   string
+		wantErr     bool
+	}{
+		{
+			name:        "Valid",
+			contentType: "application/json; charset=utf-8",
+			body:        `{"name":"Ann","day":1}`,
+		},
+		{
+			name:        "Empty body",
+			contentType: "application/json",
+			body:        "",
+			wantErr:     true,
+			wantKind:    apperr.KindValidation,
+		},
+		{
+			name:        "Wrong content type",
+			contentType: "text/plain",
+			body:        `{"name":"Ann"}`,
+			wantErr:     true,
+			wantKind:    apperr.KindUnsupportedMediaType,
+		},
+		{
+			name:     "Missing content type",
+			body:     `{"name":"Ann"}`,
+			wantErr:  true,
+			wantKind: apperr.KindUnsupportedMediaType,
+		},
+		{
+			name:        "Malformed",
+			contentType: "application/json",
+			body:        `{"name":`,
+			wantErr:     true,
+			wantKind:    apperr.KindValidation,
+		},
+		{
+			name:        "Unknown field",
+			contentType: "application/json",
+			body:        `{"name":"Ann","admin":true}`,
+			wantErr:     true,
+			wantKind:    apperr.KindValidation,
+			wantField:   "admin",
+		},
+		{
+			name:        "Wrong type",
+			contentType: "application/json",
+			body:        `{"name":"Ann","day":"monday"}`,
+			wantErr:     true,
+			wantKind:    apperr.KindValidation,
+			wantField:   "day",
+		},
+		{
+			name:        "Trailing object",
+			contentType: "application/json",
+			body:        `{"name":"Ann"}{"name":"Bob"}`,
+			wantErr:     true,
+			wantKind:    apperr.KindValidation,
+		},
+		{
+			name:        "Fails validation",
+			contentType: "application/json",
+			body:        `{"name":"Annabel"}`,
+			wantErr:     true,
+			wantKind:    apperr.KindValidation,
+			wantField:   "name",
+		},
+		{
+			name:        "Too large",
+			contentType: "application/json",
+			body:        `{"name":"` + strings.Repeat("a", MaxRequestBodyBytes) + `"}`,
+			wantErr:     true,
+			wantKind:    apperr.KindTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+
+			var dst testRequest
+			err := DecodeJSON(rec, req, &dst)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if dst.Name != "Ann" || dst.Day != 1 {
+					t.Errorf("decoded %+v", dst)
+				}
+				return
+			}
+
+			var appErr *apperr.Error
+			if !errors.As(err, &appErr) {
+				t.Fatalf("expected *apperr.Error, got %v", err)
+			}
+			if appErr.Kind != tt.wantKind {
+				t.Errorf("kind: got %v, want %v", appErr.Kind, tt.wantKind)
+			}
+			if tt.wantField != "" && (len(appErr.Fields) != 1 || appErr.Fields[0].Field != tt.wantField) {
+				t.Errorf("fields: got %+v, want one for %q", appErr.Fields, tt.wantField)
+			}
+		})
+	}
+}
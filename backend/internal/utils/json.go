@@ -2,35 +2,44 @@ package utils
 
 import (
 	"encoding/json"
-	"log"
 	"net/http"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/logging"
 )
 
+// RespondWithError writes msg as the detail of a problem document with the
+// given status. Server errors are logged with err; client errors are logged
+// only when err carries detail. Prefer RespondWithProblem when err already
+// says what went wrong.
 func RespondWithError(w http.ResponseWriter, code int, msg string, err error) {
-	if err != nil {
-		log.Println(err)
-	}
+	logger := logging.FromResponseWriter(w)
 	if code > 499 {
-		log.Printf("Responding with 5XX error: %s", msg)
+		logger.Error("Responding with 5XX error", "status", code, "msg", msg, "err", err)
+	} else if err != nil {
+		logger.Info("Responding with client error", "status", code, "msg", msg, "err", err)
 	}
-	type errorResponse struct {
-		Error string `json:"error"`
-	}
-	RespondWithJSON(w, code, errorResponse{
-		Error: msg,
+	writeProblem(w, Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(code),
+		Status: code,
+		Detail: msg,
 	})
 }
 
 func RespondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
-	w.Header().Set("Content-Type", "application/json")
+	writeJSON(w, code, "application/json", payload)
+}
+
+func writeJSON(w http.ResponseWriter, code int, contentType string, payload interface{}) {
+	w.Header().Set("Content-Type", contentType)
 	dat, err := json.Marshal(payload)
 	if err != nil {
-		log.Printf("Error marshalling JSON: %s", err)
+		logging.FromResponseWriter(w).Error("Error marshalling JSON", "err", err)
 		w.WriteHeader(500)
 		return
 	}
 	w.WriteHeader(code)
 	if _, err := w.Write(dat); err != nil {
-		log.Printf("Error writing response: %v", err)
+		logging.FromResponseWriter(w).Error("Error writing response", "err", err)
 	}
 }
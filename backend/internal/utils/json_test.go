@@ -4,11 +4,17 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/jackc/pgconn"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/apperr"
 )
 
 func TestRespondWithError(t *testing.T) {
@@ -38,14 +44,14 @@ func TestRespondWithError(t *testing.T) {
 			code:    http.StatusInternalServerError,
 			msg:     "oops",
 			err:     nil,
-			wantLog: []string{"Responding with 5XX error: oops"},
+			wantLog: []string{"Responding with 5XX error", "msg=oops"},
 		},
 		{
 			name:    "5xx with err",
 			code:    http.StatusInternalServerError,
 			msg:     "down",
 			err:     errors.New("timeout"),
-			wantLog: []string{"timeout", "Responding with 5XX error: down"},
+			wantLog: []string{"err=timeout", "Responding with 5XX error", "msg=down"},
 		},
 	}
 	for _, tt := range tests {
@@ -63,12 +69,15 @@ func TestRespondWithError(t *testing.T) {
 			if rec.Code != tt.code {
 				t.Errorf("status: got %d, want %d", rec.Code, tt.code)
 			}
-			var got struct{ Error string }
+			if ct := rec.Header().Get("Content-Type"); ct != ProblemContentType {
+				t.Errorf("content type: got %q, want %q", ct, ProblemContentType)
+			}
+			var got Problem
 			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
 				t.Fatalf("invalid JSON: %v", err)
 			}
-			if got.Error != tt.msg {
-				t.Errorf("body: got %#v, want %#v", got.Error, tt.msg)
+			if got.Detail != tt.msg || got.Status != tt.code || got.Title != http.StatusText(tt.code) {
+				t.Errorf("body: got %+v, want detail %q and status %d", got, tt.msg, tt.code)
 			}
 
 			logs := buf.String()
@@ -118,7 +127,7 @@ func TestRespondWithJSON(t *testing.T) {
 			useBrokenWriter: false,
 			key:             "FirstName",
 			value:           "John",
-			wantLog:         []string{"Error marshalling JSON: "},
+			wantLog:         []string{"Error marshalling JSON"},
 		},
 		{
 			name:            "Error writing response",
@@ -127,7 +136,7 @@ func TestRespondWithJSON(t *testing.T) {
 			useBrokenWriter: true,
 			key:             "FirstName",
 			value:           "John",
-			wantLog:         []string{"Error writing response: "},
+			wantLog:         []string{"Error writing response", "err=boom"},
 		},
 	}
 	for _, tt := range tests {
@@ -179,3 +188,70 @@ func TestRespondWithJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestRespondWithProblem(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantDetail string
+		wantFields []apperr.FieldError
+	}{
+		{
+			name:       "Validation with fields",
+			err:        apperr.Validation("Invalid request", apperr.FieldError{Field: "email", Message: "is required"}),
+			wantStatus: http.StatusBadRequest,
+			wantDetail: "Invalid request",
+			wantFields: []apperr.FieldError{{Field: "email", Message: "is required"}},
+		},
+		{
+			name:       "Forbidden",
+			err:        apperr.New(apperr.KindForbidden, "Not allowed"),
+			wantStatus: http.StatusForbidden,
+			wantDetail: "Not allowed",
+		},
+		{
+			name:       "Wrapped not found",
+			err:        fmt.Errorf("get: %w", apperr.New(apperr.KindNotFound, "Booking not found")),
+			wantStatus: http.StatusNotFound,
+			wantDetail: "Booking not found",
+		},
+		{
+			name:       "Unique violation",
+			err:        &pgconn.PgError{Code: "23505"},
+			wantStatus: http.StatusConflict,
+			wantDetail: "Resource already exists",
+		},
+		{
+			name:       "Internal detail withheld",
+			err:        errors.New("dial tcp 10.0.0.1:5432: connection refused"),
+			wantStatus: http.StatusInternalServerError,
+			wantDetail: "Internal server error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+
+			RespondWithProblem(rec, tt.err)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status: got %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != ProblemContentType {
+				t.Errorf("content type: got %q, want %q", ct, ProblemContentType)
+			}
+			var got Problem
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+			if got.Status != tt.wantStatus || got.Detail != tt.wantDetail || got.Type != "about:blank" {
+				t.Errorf("body: got %+v, want status %d and detail %q", got, tt.wantStatus, tt.wantDetail)
+			}
+			if !reflect.DeepEqual(got.Errors, tt.wantFields) {
+				t.Errorf("errors: got %+v, want %+v", got.Errors, tt.wantFields)
+			}
+		})
+	}
+}
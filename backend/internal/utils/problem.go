@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"net/http"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/apperr"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/logging"
+)
+
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem document. Errors lists the offending fields
+// of a validation failure.
+type Problem struct {
+	Type   string              `json:"type"`
+	Title  string              `json:"title"`
+	Status int                 `json:"status"`
+	Detail string              `json:"detail,omitempty"`
+	Errors []apperr.FieldError `json:"errors,omitempty"`
+}
+
+var kindStatus = map[apperr.Kind]int{
+	apperr.KindValidation:           http.StatusBadRequest,
+	apperr.KindUnauthorized:         http.StatusUnauthorized,
+	apperr.KindForbidden:            http.StatusForbidden,
+	apperr.KindNotFound:             http.StatusNotFound,
+	apperr.KindConflict:             http.StatusConflict,
+	apperr.KindTooLarge:             http.StatusRequestEntityTooLarge,
+	apperr.KindUnsupportedMediaType: http.StatusUnsupportedMediaType,
+}
+
+// StatusFor returns the HTTP status for an apperr kind.
+func StatusFor(kind apperr.Kind) int {
+	if status, ok := kindStatus[kind]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// RespondWithProblem classifies err with apperr.From and writes the matching
+// problem document. Internal errors are logged and their detail withheld.
+func RespondWithProblem(w http.ResponseWriter, err error) {
+	e := apperr.From(err)
+	if e == nil {
+		e = apperr.From(apperr.New(apperr.KindInternal, "Internal server error"))
+	}
+	status := StatusFor(e.Kind)
+
+	logger := logging.FromResponseWriter(w)
+	if status > 499 {
+		logger.Error("Responding with 5XX error", "status", status, "kind", e.Kind.String(), "err", err)
+	} else if e.Err != nil {
+		logger.Info("Responding with client error", "status", status, "kind", e.Kind.String(), "err", err)
+	}
+
+	writeProblem(w, Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: e.Message,
+		Errors: e.Fields,
+	})
+}
+
+func writeProblem(w http.ResponseWriter, p Problem) {
+	writeJSON(w, p.Status, ProblemContentType, p)
+}
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"database/sql"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/apperr"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/pagination"
+	"github.com/google/uuid"
+)
+
+// QueryParams reads optional URL query parameters, collecting every invalid
+// one so Err can report them together:
+//
+//	q := utils.NewQueryParams(r)
+//	from := q.Time("from")
+//	page := q.Page()
+//	if err := q.Err(); err != nil { ... }
+type QueryParams struct {
+	values url.Values
+	fields []apperr.FieldError
+}
+
+func NewQueryParams(r *http.Request) *QueryParams {
+	return &QueryParams{values: r.URL.Query()}
+}
+
+func (q *QueryParams) invalid(name, msg string) {
+	q.fields = append(q.fields, apperr.FieldError{Field: name, Message: msg})
+}
+
+// Time parses an RFC 3339 timestamp.
+func (q *QueryParams) Time(name string) sql.NullTime {
+	s := q.values.Get(name)
+	if s == "" {
+		return sql.NullTime{}
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		q.invalid(name, "must be an RFC 3339 timestamp")
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t, Valid: true}
+}
+
+func (q *QueryParams) UUID(name string) uuid.NullUUID {
+	s := q.values.Get(name)
+	if s == "" {
+		return uuid.NullUUID{}
+	}
+	id, err := uuid.Parse(s)
+	if err != nil {
+		q.invalid(name, "must be a valid UUID")
+		return uuid.NullUUID{}
+	}
+	return uuid.NullUUID{UUID: id, Valid: true}
+}
+
+// OneOf reads a parameter that must be one of allowed.
+func (q *QueryParams) OneOf(name string, allowed ...string) sql.NullString {
+	s := q.values.Get(name)
+	if s == "" {
+		return sql.NullString{}
+	}
+	for _, a := range allowed {
+		if s == a {
+			return sql.NullString{String: s, Valid: true}
+		}
+	}
+	q.invalid(name, "must be one of: "+strings.Join(allowed, ", "))
+	return sql.NullString{}
+}
+
+// Page reads the limit and cursor parameters.
+func (q *QueryParams) Page() pagination.Params {
+	var p pagination.Params
+	if s := q.values.Get("limit"); s != "" {
+		n, err := strconv.ParseInt(s, 10, 32)
+		if err != nil || n < 1 || n > pagination.MaxLimit {
+			q.invalid("limit", "must be between 1 and "+strconv.Itoa(pagination.MaxLimit))
+		} else {
+			p.Limit = int32(n)
+		}
+	}
+	if s := q.values.Get("cursor"); s != "" {
+		c, err := pagination.ParseCursor(s)
+		if err != nil {
+			q.invalid("cursor", "is not a cursor returned by this endpoint")
+		} else {
+			p.After = &c
+		}
+	}
+	return p
+}
+
+// Err returns a validation error listing every invalid parameter read so
+// far, or nil.
+func (q *QueryParams) Err() error {
+	if len(q.fields) == 0 {
+		return nil
+	}
+	return apperr.Validation("Invalid query parameters", q.fields...)
+}
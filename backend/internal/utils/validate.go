@@ -0,0 +1,197 @@
+package utils
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/apperr"
+	"github.com/google/uuid"
+)
+
+// Validate checks the `validate` struct tags of v, a struct or a pointer to
+// one, and reports every failing field at once as an apperr validation
+// error. Fields are named by their json tag.
+//
+// Rules are comma separated and run in order; the first failure for a field
+// wins:
+//
+//	required       non-zero value (non-nil pointer, non-empty string)
+//	omitempty      skip the remaining rules when the value is zero
+//	email          a single address, no display name
+//	uuid           a string that parses as a UUID
+//	min=N, max=N   string length in characters, or numeric bounds
+//	gt=N           numeric value strictly greater than N
+//	oneof=a b c    string is one of the space separated values
+//	gtfield=F      time or number strictly greater than sibling field F
+//	timezone       an IANA timezone name such as America/New_York
+//	url            an absolute http or https URL with a host
+//
+// Pointers are dereferenced before the value rules are applied. Validate
+// panics on an unknown rule, which is a programming error.
+func Validate(v any) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	rt := rv.Type()
+
+	var fields []apperr.FieldError
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag := sf.Tag.Get("validate")
+		if tag == "" || tag == "-" {
+			continue
+		}
+		if msg := checkField(rv, rv.Field(i), tag); msg != "" {
+			fields = append(fields, apperr.FieldError{Field: jsonName(sf), Message: msg})
+		}
+	}
+	if len(fields) > 0 {
+		return apperr.Validation("Request validation failed", fields...)
+	}
+	return nil
+}
+
+func checkField(parent, fv reflect.Value, tag string) string {
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			if fv.IsZero() {
+				return "is required"
+			}
+			continue
+		case "omitempty":
+			if fv.IsZero() {
+				return ""
+			}
+			continue
+		}
+
+		v := reflect.Indirect(fv)
+		if !v.IsValid() {
+			// Nil pointer without omitempty; nothing to compare against.
+			continue
+		}
+		if msg := checkRule(parent, v, name, param); msg != "" {
+			return msg
+		}
+	}
+	return ""
+}
+
+func checkRule(parent, v reflect.Value, name, param string) string {
+	switch name {
+	case "email":
+		addr, err := mail.ParseAddress(v.String())
+		if err != nil || addr.Address != v.String() {
+			return "must be a valid email address"
+		}
+	case "uuid":
+		if _, err := uuid.Parse(v.String()); err != nil {
+			return "must be a valid UUID"
+		}
+	case "min":
+		n := mustInt(name, param)
+		if v.Kind() == reflect.String {
+			if utf8.RuneCountInString(v.String()) < int(n) {
+				return fmt.Sprintf("must be at least %d characters", n)
+			}
+		} else if numeric(v) < float64(n) {
+			return fmt.Sprintf("must be at least %d", n)
+		}
+	case "max":
+		n := mustInt(name, param)
+		if v.Kind() == reflect.String {
+			if utf8.RuneCountInString(v.String()) > int(n) {
+				return fmt.Sprintf("must be at most %d characters", n)
+			}
+		} else if numeric(v) > float64(n) {
+			return fmt.Sprintf("must be at most %d", n)
+		}
+	case "gt":
+		n := mustInt(name, param)
+		if numeric(v) <= float64(n) {
+			return fmt.Sprintf("must be greater than %d", n)
+		}
+	case "oneof":
+		allowed := strings.Fields(param)
+		for _, a := range allowed {
+			if v.String() == a {
+				return ""
+			}
+		}
+		return "must be one of: " + strings.Join(allowed, ", ")
+	case "timezone":
+		// LoadLocation also accepts "" and "Local", which name no zone.
+		if tz := v.String(); tz == "" || tz == "Local" {
+			return "must be an IANA timezone name"
+		} else if _, err := time.LoadLocation(tz); err != nil {
+			return "must be an IANA timezone name"
+		}
+	case "url":
+		u, err := url.Parse(v.String())
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "must be an http or https URL"
+		}
+	case "gtfield":
+		sf, ok := parent.Type().FieldByName(param)
+		if !ok {
+			panic(fmt.Sprintf("validate: gtfield references unknown field %q", param))
+		}
+		other := reflect.Indirect(parent.FieldByIndex(sf.Index))
+		if !other.IsValid() {
+			return ""
+		}
+		if !greater(v, other) {
+			return "must be after " + jsonName(sf)
+		}
+	default:
+		panic(fmt.Sprintf("validate: unknown rule %q", name))
+	}
+	return ""
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func greater(a, b reflect.Value) bool {
+	if a.Type() == timeType && b.Type() == timeType {
+		return a.Interface().(time.Time).After(b.Interface().(time.Time))
+	}
+	return numeric(a) > numeric(b)
+}
+
+func numeric(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	}
+	panic(fmt.Sprintf("validate: %s is not numeric", v.Type()))
+}
+
+func mustInt(rule, param string) int64 {
+	n, err := strconv.ParseInt(param, 10, 64)
+	if err != nil {
+		panic(fmt.Sprintf("validate: %s needs an integer, got %q", rule, param))
+	}
+	return n
+}
+
+// jsonName returns the name a field has on the wire.
+func jsonName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return sf.Name
+	}
+	return name
+}
@@ -0,0 +1,222 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/google/uuid"
+)
+
+// maxResponseBytes is how much of a response body is read before the
+// connection is reused; the body itself is ignored.
+const maxResponseBytes = 64 << 10
+
+// DispatchStore is what a Dispatcher reads and updates deliveries through.
+// *db.Store implements it.
+type DispatchStore interface {
+	ClaimWebhookDeliveries(ctx context.Context, arg db.ClaimWebhookDeliveriesParams) ([]db.WebhookDelivery, error)
+	GetWebhookEndpointByID(ctx context.Context, id uuid.UUID) (db.WebhookEndpoint, error)
+	MarkWebhookDeliveryDelivered(ctx context.Context, arg db.MarkWebhookDeliveryDeliveredParams) error
+	MarkWebhookDeliveryFailed(ctx context.Context, arg db.MarkWebhookDeliveryFailedParams) error
+}
+
+// Dispatcher sends queued deliveries to their endpoints. Several
+// dispatchers may run against the same table.
+type Dispatcher struct {
+	store       DispatchStore
+	client      *http.Client
+	batchSize   int32
+	maxAttempts int32
+	lease       time.Duration
+	logger      *slog.Logger
+	now         func() time.Time
+}
+
+type Option func(*Dispatcher)
+
+// WithHTTPClient sets the client requests are sent with. The default has a
+// ten second timeout.
+func WithHTTPClient(c *http.Client) Option {
+	return func(d *Dispatcher) { d.client = c }
+}
+
+// WithBatchSize sets how many deliveries are claimed at a time. The default
+// is 20.
+func WithBatchSize(n int) Option {
+	return func(d *Dispatcher) { d.batchSize = int32(n) }
+}
+
+// WithMaxAttempts sets how many times a delivery is tried before it is
+// marked dead. The default is 10.
+func WithMaxAttempts(n int) Option {
+	return func(d *Dispatcher) { d.maxAttempts = int32(n) }
+}
+
+// WithLease sets how long claimed deliveries are hidden from other
+// dispatchers. It must outlast sending a whole batch at the client timeout,
+// or another replica may send the same delivery again. The default is five
+// minutes.
+func WithLease(lease time.Duration) Option {
+	return func(d *Dispatcher) { d.lease = lease }
+}
+
+// WithLogger sets where failed deliveries are logged. The default is
+// slog.Default.
+func WithLogger(l *slog.Logger) Option {
+	return func(d *Dispatcher) { d.logger = l }
+}
+
+func NewDispatcher(store DispatchStore, opts ...Option) *Dispatcher {
+	d := &Dispatcher{
+		store:       store,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		batchSize:   20,
+		maxAttempts: 10,
+		lease:       5 * time.Minute,
+		logger:      slog.Default(),
+		now:         time.Now,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Result counts the deliveries one Flush handled.
+type Result struct {
+	Delivered int
+	// Retrying deliveries failed and will be sent again.
+	Retrying int
+	// Dead deliveries ran out of attempts.
+	Dead int
+}
+
+// Flush sends due deliveries batch by batch until none are left. Failed
+// sends are logged and scheduled for retry; only database errors are
+// returned.
+//
+// Once ctx is cancelled Flush finishes the delivery in hand, so it is
+// recorded rather than sent again, and returns; the rest of the batch is
+// retried when its lease runs out.
+func (d *Dispatcher) Flush(ctx context.Context) (Result, error) {
+	var result Result
+	endpoints := map[uuid.UUID]db.WebhookEndpoint{}
+	for ctx.Err() == nil {
+		batch, err := d.store.ClaimWebhookDeliveries(ctx, db.ClaimWebhookDeliveriesParams{
+			LeaseUntil: d.now().Add(d.lease),
+			BatchLimit: d.batchSize,
+		})
+		if err != nil {
+			return result, fmt.Errorf("claim webhook deliveries: %w", err)
+		}
+
+		for _, delivery := range batch {
+			if ctx.Err() != nil {
+				break
+			}
+			endpoint, ok := endpoints[delivery.EndpointID]
+			if !ok {
+				endpoint, err = d.store.GetWebhookEndpointByID(ctx, delivery.EndpointID)
+				if errors.Is(err, sql.ErrNoRows) {
+					// Deleted since the claim, taking its deliveries with it.
+					continue
+				}
+				if err != nil {
+					return result, fmt.Errorf("load webhook endpoint %s: %w", delivery.EndpointID, err)
+				}
+				endpoints[endpoint.ID] = endpoint
+			}
+			if err := d.dispatch(context.WithoutCancel(ctx), endpoint, delivery, &result); err != nil {
+				return result, fmt.Errorf("record webhook delivery %s: %w", delivery.ID, err)
+			}
+		}
+		if len(batch) < int(d.batchSize) {
+			break
+		}
+	}
+	return result, ctx.Err()
+}
+
+// dispatch sends one delivery and records the outcome.
+func (d *Dispatcher) dispatch(ctx context.Context, endpoint db.WebhookEndpoint, delivery db.WebhookDelivery, result *Result) error {
+	code, sendErr := d.send(ctx, endpoint, delivery)
+	var status *int32
+	if code != 0 {
+		status = &code
+	}
+
+	if sendErr == nil {
+		result.Delivered++
+		return d.store.MarkWebhookDeliveryDelivered(ctx, db.MarkWebhookDeliveryDeliveredParams{
+			ID:             delivery.ID,
+			ResponseStatus: status,
+		})
+	}
+
+	msg := sendErr.Error()
+	attempts := delivery.Attempts + 1
+	next := StatusPending
+	if attempts >= d.maxAttempts {
+		next = StatusDead
+		result.Dead++
+	} else {
+		result.Retrying++
+	}
+	d.logger.Warn("Webhook delivery failed",
+		"delivery_id", delivery.ID, "endpoint_id", endpoint.ID, "event_type", delivery.EventType,
+		"attempt", attempts, "status", next, "err", sendErr)
+
+	return d.store.MarkWebhookDeliveryFailed(ctx, db.MarkWebhookDeliveryFailedParams{
+		ID:             delivery.ID,
+		Status:         next,
+		ResponseStatus: status,
+		LastError:      &msg,
+		NextAttemptAt:  d.now().Add(backoff(attempts)),
+	})
+}
+
+// send POSTs the delivery and returns the response status, or 0 if there
+// was no response. Anything but a 2xx is an error.
+func (d *Dispatcher) send(ctx context.Context, endpoint db.WebhookEndpoint, delivery db.WebhookDelivery) (int32, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "booking-app-webhooks")
+	req.Header.Set(HeaderID, delivery.ID.String())
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderSignature, Sign(endpoint.Secret, d.now(), body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBytes))
+
+	code := int32(resp.StatusCode)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return code, fmt.Errorf("endpoint responded %s", resp.Status)
+	}
+	return code, nil
+}
+
+// backoff is the wait before attempt n+1: thirty seconds doubling with
+// every attempt, capped at six hours.
+func backoff(n int32) time.Duration {
+	d := 30 * time.Second
+	for i := int32(1); i < n && d < 6*time.Hour; i++ {
+		d *= 2
+	}
+	return min(d, 6*time.Hour)
+}
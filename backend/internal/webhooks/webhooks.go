@@ -0,0 +1,157 @@
+// Package webhooks delivers domain events to admin-managed HTTP endpoints.
+//
+// Enqueue subscribes to the event bus and queues one delivery per event for
+// every active endpoint subscribed to its type. A Dispatcher then POSTs each
+// delivery, signed with the endpoint's secret, and retries failures with
+// exponential backoff until they succeed or run out of attempts and are
+// marked dead. Dead deliveries stay in the log until an admin redelivers
+// them.
+//
+// Every request carries these headers:
+//
+//	X-Webhook-ID         the delivery ID, the same on every retry
+//	X-Webhook-Event      the event type
+//	X-Webhook-Signature  t=<unix seconds>,v1=<hex HMAC-SHA256>
+//
+// The signature covers "<t>.<body>", so a receiver can check both that the
+// body came from us and that the request is recent; see Verify.
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/events"
+	"github.com/google/uuid"
+)
+
+const (
+	HeaderID        = "X-Webhook-ID"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Delivery statuses, as stored in webhook_deliveries.status.
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	StatusDead      = "dead"
+)
+
+// Payload is the body POSTed to endpoints. ID is the event's ID, so a
+// receiver can drop an event it has already seen.
+type Payload struct {
+	ID         uuid.UUID       `json:"id"`
+	Type       string          `json:"type"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// NewSecret returns a random signing secret for a new endpoint.
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// Sign returns the X-Webhook-Signature value for body sent at t.
+func Sign(secret string, t time.Time, body []byte) string {
+	return fmt.Sprintf("t=%d,v1=%s", t.Unix(), hex.EncodeToString(mac(secret, t.Unix(), body)))
+}
+
+func mac(secret string, unix int64, body []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(h, "%d.", unix)
+	h.Write(body)
+	return h.Sum(nil)
+}
+
+var (
+	ErrMalformedSignature = errors.New("malformed webhook signature")
+	ErrBadSignature       = errors.New("webhook signature does not match")
+	ErrStaleSignature     = errors.New("webhook signature is too old")
+)
+
+// Verify checks a signature header against body the way a receiver should:
+// the MAC must match and the timestamp must be within tolerance of now.
+func Verify(secret, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	var unix int64
+	var sig []byte
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(part, "=")
+		var err error
+		switch k {
+		case "t":
+			unix, err = strconv.ParseInt(v, 10, 64)
+		case "v1":
+			sig, err = hex.DecodeString(v)
+		}
+		if err != nil {
+			return ErrMalformedSignature
+		}
+	}
+	if unix == 0 || sig == nil {
+		return ErrMalformedSignature
+	}
+	if !hmac.Equal(sig, mac(secret, unix, body)) {
+		return ErrBadSignature
+	}
+	if d := now.Sub(time.Unix(unix, 0)); d > tolerance || d < -tolerance {
+		return ErrStaleSignature
+	}
+	return nil
+}
+
+// EnqueueStore is what Enqueue reads endpoints from and queues deliveries
+// through. *db.Store implements it.
+type EnqueueStore interface {
+	ListWebhookEndpointsForEvent(ctx context.Context, eventType string) ([]db.WebhookEndpoint, error)
+	CreateWebhookDelivery(ctx context.Context, arg db.CreateWebhookDeliveryParams) error
+}
+
+// Enqueue returns a bus handler that queues each event for every active
+// endpoint subscribed to its type. An event offered again is not queued
+// twice.
+func Enqueue(store EnqueueStore) events.Handler {
+	return func(ctx context.Context, m events.Message) error {
+		endpoints, err := store.ListWebhookEndpointsForEvent(ctx, m.Type)
+		if err != nil || len(endpoints) == 0 {
+			return err
+		}
+
+		body, err := json.Marshal(Payload{
+			ID:         m.ID,
+			Type:       m.Type,
+			OccurredAt: m.OccurredAt,
+			Data:       m.Payload,
+		})
+		if err != nil {
+			return err
+		}
+		for _, e := range endpoints {
+			err := store.CreateWebhookDelivery(ctx, db.CreateWebhookDeliveryParams{
+				ID:         uuid.New(),
+				EndpointID: e.ID,
+				EventID:    m.ID,
+				EventType:  m.Type,
+				Payload:    string(body),
+			})
+			if err != nil {
+				return fmt.Errorf("queue for endpoint %s: %w", e.ID, err)
+			}
+		}
+		return nil
+	}
+}
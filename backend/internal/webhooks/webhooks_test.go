@@ -0,0 +1,425 @@
+package webhooks
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/db"
+	"github.com/WarrenPaschetto/fullstack-booking-app/backend/internal/events"
+	"github.com/google/uuid"
+)
+
+// memStore keeps endpoints and deliveries in memory and claims due
+// deliveries the way ClaimWebhookDeliveries does.
+type memStore struct {
+	now           time.Time
+	endpoints     []db.WebhookEndpoint
+	subscriptions map[uuid.UUID][]string
+	deliveries    []db.WebhookDelivery
+}
+
+func newMemStore(now time.Time) *memStore {
+	return &memStore{now: now, subscriptions: map[uuid.UUID][]string{}}
+}
+
+func (m *memStore) addEndpoint(url string, types ...string) db.WebhookEndpoint {
+	e := db.WebhookEndpoint{ID: uuid.New(), URL: url, Secret: "whsec_test", Active: true}
+	m.endpoints = append(m.endpoints, e)
+	m.subscriptions[e.ID] = types
+	return e
+}
+
+func (m *memStore) ListWebhookEndpointsForEvent(ctx context.Context, eventType string) ([]db.WebhookEndpoint, error) {
+	var out []db.WebhookEndpoint
+	for _, e := range m.endpoints {
+		if e.Active && slices.Contains(m.subscriptions[e.ID], eventType) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (m *memStore) CreateWebhookDelivery(ctx context.Context, arg db.CreateWebhookDeliveryParams) error {
+	for _, d := range m.deliveries {
+		if d.EndpointID == arg.EndpointID && d.EventID == arg.EventID {
+			return nil
+		}
+	}
+	m.deliveries = append(m.deliveries, db.WebhookDelivery{
+		ID:            arg.ID,
+		EndpointID:    arg.EndpointID,
+		EventID:       arg.EventID,
+		EventType:     arg.EventType,
+		Payload:       arg.Payload,
+		Status:        StatusPending,
+		NextAttemptAt: m.now,
+		CreatedAt:     m.now,
+	})
+	return nil
+}
+
+func (m *memStore) ClaimWebhookDeliveries(ctx context.Context, arg db.ClaimWebhookDeliveriesParams) ([]db.WebhookDelivery, error) {
+	var out []db.WebhookDelivery
+	for i, d := range m.deliveries {
+		if len(out) == int(arg.BatchLimit) {
+			break
+		}
+		e, _ := m.GetWebhookEndpointByID(ctx, d.EndpointID)
+		if d.Status == StatusPending && !d.NextAttemptAt.After(m.now) && e.Active {
+			m.deliveries[i].NextAttemptAt = arg.LeaseUntil
+			out = append(out, m.deliveries[i])
+		}
+	}
+	return out, nil
+}
+
+func (m *memStore) GetWebhookEndpointByID(ctx context.Context, id uuid.UUID) (db.WebhookEndpoint, error) {
+	for _, e := range m.endpoints {
+		if e.ID == id {
+			return e, nil
+		}
+	}
+	return db.WebhookEndpoint{}, sql.ErrNoRows
+}
+
+func (m *memStore) MarkWebhookDeliveryDelivered(ctx context.Context, arg db.MarkWebhookDeliveryDeliveredParams) error {
+	d := m.find(arg.ID)
+	d.Status = StatusDelivered
+	d.Attempts++
+	d.ResponseStatus = arg.ResponseStatus
+	d.LastError = nil
+	d.DeliveredAt = &m.now
+	return nil
+}
+
+func (m *memStore) MarkWebhookDeliveryFailed(ctx context.Context, arg db.MarkWebhookDeliveryFailedParams) error {
+	d := m.find(arg.ID)
+	d.Status = arg.Status
+	d.Attempts++
+	d.ResponseStatus = arg.ResponseStatus
+	d.LastError = arg.LastError
+	d.NextAttemptAt = arg.NextAttemptAt
+	return nil
+}
+
+func (m *memStore) find(id uuid.UUID) *db.WebhookDelivery {
+	for i := range m.deliveries {
+		if m.deliveries[i].ID == id {
+			return &m.deliveries[i]
+		}
+	}
+	panic("no webhook delivery " + id.String())
+}
+
+func quietDispatcher(store DispatchStore, now func() time.Time, opts ...Option) *Dispatcher {
+	opts = append(opts, WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil))))
+	d := NewDispatcher(store, opts...)
+	d.now = now
+	return d
+}
+
+// receiver is an httptest endpoint that records what it is sent and
+// answers with the next status in its script, then 200.
+type receiver struct {
+	mu       sync.Mutex
+	script   []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.requests = append(rc.requests, r)
+	rc.bodies = append(rc.bodies, body)
+	status := http.StatusOK
+	if len(rc.script) > 0 {
+		status, rc.script = rc.script[0], rc.script[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func message(t *testing.T, e events.Event, at time.Time) events.Message {
+	t.Helper()
+	payload, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return events.Message{ID: uuid.New(), Type: e.EventType(), AggregateID: e.AggregateID(), OccurredAt: at, Payload: payload}
+}
+
+func TestEnqueueAndDispatch(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 6, 3, 9, 0, 0, 0, time.UTC)
+	store := newMemStore(now)
+
+	crm := &receiver{}
+	crmServer := httptest.NewServer(crm)
+	defer crmServer.Close()
+	billing := &receiver{}
+	billingServer := httptest.NewServer(billing)
+	defer billingServer.Close()
+
+	crmEndpoint := store.addEndpoint(crmServer.URL, events.TypeBookingCreated, events.TypeUserRegistered)
+	store.addEndpoint(billingServer.URL, events.TypeBookingCancelled)
+
+	booking := events.BookingCreated{BookingID: uuid.New(), UserID: uuid.New(), AppointmentStart: now.Add(time.Hour), DurationMinutes: 30}
+	m := message(t, booking, now)
+	enqueue := Enqueue(store)
+	// The relay delivers at least once; the second offer queues nothing.
+	for i := 0; i < 2; i++ {
+		if err := enqueue(ctx, m); err != nil {
+			t.Fatalf("enqueue: %v", err)
+		}
+	}
+	if len(store.deliveries) != 1 || store.deliveries[0].EndpointID != crmEndpoint.ID {
+		t.Fatalf("deliveries = %+v, want one for the CRM", store.deliveries)
+	}
+
+	result, err := quietDispatcher(store, func() time.Time { return store.now }).Flush(ctx)
+	if err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if result != (Result{Delivered: 1}) {
+		t.Errorf("result = %+v", result)
+	}
+	if len(billing.requests) != 0 {
+		t.Errorf("billing received %d requests for an event it did not subscribe to", len(billing.requests))
+	}
+	if len(crm.requests) != 1 {
+		t.Fatalf("CRM received %d requests, want 1", len(crm.requests))
+	}
+
+	req, body := crm.requests[0], crm.bodies[0]
+	delivery := store.deliveries[0]
+	if got := req.Header.Get(HeaderID); got != delivery.ID.String() {
+		t.Errorf("%s = %q, want %q", HeaderID, got, delivery.ID)
+	}
+	if got := req.Header.Get(HeaderEvent); got != events.TypeBookingCreated {
+		t.Errorf("%s = %q", HeaderEvent, got)
+	}
+	if err := Verify(crmEndpoint.Secret, req.Header.Get(HeaderSignature), body, now, time.Minute); err != nil {
+		t.Errorf("signature: %v", err)
+	}
+
+	var p Payload
+	if err := json.Unmarshal(body, &p); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	var data events.BookingCreated
+	if err := json.Unmarshal(p.Data, &data); err != nil {
+		t.Fatalf("decode data: %v", err)
+	}
+	if p.ID != m.ID || p.Type != events.TypeBookingCreated || data.BookingID != booking.BookingID {
+		t.Errorf("payload = %+v with data %+v", p, data)
+	}
+
+	if delivery.Status != StatusDelivered || delivery.Attempts != 1 || delivery.ResponseStatus == nil || *delivery.ResponseStatus != 200 {
+		t.Errorf("delivery = %+v", delivery)
+	}
+}
+
+func TestDispatchRetriesThenDeadLetters(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 6, 3, 9, 0, 0, 0, time.UTC)
+	store := newMemStore(now)
+
+	rc := &receiver{script: []int{http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusBadGateway}}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+	store.addEndpoint(server.URL, events.TypeUserRegistered)
+
+	if err := Enqueue(store)(ctx, message(t, events.UserRegistered{UserID: uuid.New()}, now)); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	dispatcher := quietDispatcher(store, func() time.Time { return store.now }, WithMaxAttempts(3))
+
+	var total Result
+	for i, wait := range []time.Duration{30 * time.Second, time.Minute, 2 * time.Minute} {
+		result, err := dispatcher.Flush(ctx)
+		if err != nil {
+			t.Fatalf("flush %d: %v", i+1, err)
+		}
+		total.Retrying += result.Retrying
+		total.Dead += result.Dead
+
+		d := store.deliveries[0]
+		if want := store.now.Add(wait); !d.NextAttemptAt.Equal(want) {
+			t.Errorf("attempt %d: next attempt at %v, want %v", i+1, d.NextAttemptAt, want)
+		}
+		// Nothing is sent again until the backoff has passed.
+		if result, _ := dispatcher.Flush(ctx); result != (Result{}) {
+			t.Errorf("attempt %d: early retry: %+v", i+1, result)
+		}
+		store.now = store.now.Add(wait)
+	}
+
+	if total != (Result{Retrying: 2, Dead: 1}) {
+		t.Errorf("results = %+v", total)
+	}
+	d := store.deliveries[0]
+	if d.Status != StatusDead || d.Attempts != 3 || d.ResponseStatus == nil || *d.ResponseStatus != http.StatusBadGateway ||
+		d.LastError == nil || !strings.Contains(*d.LastError, "502") {
+		t.Errorf("delivery = %+v", d)
+	}
+	if len(rc.requests) != 3 {
+		t.Errorf("receiver saw %d requests, want 3", len(rc.requests))
+	}
+	// Every retry carries the same delivery ID.
+	for _, r := range rc.requests {
+		if r.Header.Get(HeaderID) != d.ID.String() {
+			t.Errorf("%s = %q, want %q", HeaderID, r.Header.Get(HeaderID), d.ID)
+		}
+	}
+
+	// A dead delivery is left alone.
+	store.now = store.now.Add(24 * time.Hour)
+	if result, _ := dispatcher.Flush(ctx); result != (Result{}) {
+		t.Errorf("dead delivery was retried: %+v", result)
+	}
+}
+
+func TestDispatchUnreachableEndpoint(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 6, 3, 9, 0, 0, 0, time.UTC)
+	store := newMemStore(now)
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	store.addEndpoint(server.URL, events.TypeBookingCreated)
+	if err := Enqueue(store)(ctx, message(t, events.BookingCreated{BookingID: uuid.New()}, now)); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+
+	result, err := quietDispatcher(store, func() time.Time { return store.now }).Flush(ctx)
+	if err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if result != (Result{Retrying: 1}) {
+		t.Errorf("result = %+v", result)
+	}
+	if d := store.deliveries[0]; d.Status != StatusPending || d.ResponseStatus != nil || d.LastError == nil {
+		t.Errorf("delivery = %+v", d)
+	}
+}
+
+// leaseStore records the lease each claim asks for.
+type leaseStore struct {
+	*memStore
+	leases []time.Time
+}
+
+func (s *leaseStore) ClaimWebhookDeliveries(ctx context.Context, arg db.ClaimWebhookDeliveriesParams) ([]db.WebhookDelivery, error) {
+	s.leases = append(s.leases, arg.LeaseUntil)
+	return s.memStore.ClaimWebhookDeliveries(ctx, arg)
+}
+
+func TestDispatchLease(t *testing.T) {
+	now := time.Date(2025, 6, 3, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		opts []Option
+		want time.Duration
+	}{
+		{name: "Default", want: 5 * time.Minute},
+		{name: "Configured", opts: []Option{WithLease(time.Hour)}, want: time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &leaseStore{memStore: newMemStore(now)}
+			if _, err := quietDispatcher(store, func() time.Time { return now }, tt.opts...).Flush(context.Background()); err != nil {
+				t.Fatalf("flush: %v", err)
+			}
+			if len(store.leases) != 1 || !store.leases[0].Equal(now.Add(tt.want)) {
+				t.Errorf("leases = %v, want one until %v", store.leases, now.Add(tt.want))
+			}
+		})
+	}
+}
+
+func TestDispatchSkipsInactiveEndpoints(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 6, 3, 9, 0, 0, 0, time.UTC)
+	store := newMemStore(now)
+
+	rc := &receiver{}
+	server := httptest.NewServer(rc)
+	defer server.Close()
+	store.addEndpoint(server.URL, events.TypeBookingCreated)
+	if err := Enqueue(store)(ctx, message(t, events.BookingCreated{BookingID: uuid.New()}, now)); err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	store.endpoints[0].Active = false
+
+	dispatcher := quietDispatcher(store, func() time.Time { return store.now })
+	if result, _ := dispatcher.Flush(ctx); result != (Result{}) || len(rc.requests) != 0 {
+		t.Fatalf("paused endpoint was sent %d requests: %+v", len(rc.requests), result)
+	}
+
+	// Re-enabling the endpoint sends what queued up meanwhile.
+	store.endpoints[0].Active = true
+	if result, _ := dispatcher.Flush(ctx); result != (Result{Delivered: 1}) {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Date(2025, 6, 3, 9, 0, 0, 0, time.UTC)
+	body := []byte(`{"id":"1"}`)
+	valid := Sign("whsec_a", now, body)
+
+	tests := []struct {
+		name    string
+		secret  string
+		header  string
+		body    []byte
+		at      time.Time
+		wantErr error
+	}{
+		{"Valid", "whsec_a", valid, body, now.Add(30 * time.Second), nil},
+		{"Wrong secret", "whsec_b", valid, body, now, ErrBadSignature},
+		{"Tampered body", "whsec_a", valid, []byte(`{"id":"2"}`), now, ErrBadSignature},
+		{"Too old", "whsec_a", valid, body, now.Add(10 * time.Minute), ErrStaleSignature},
+		{"Missing timestamp", "whsec_a", strings.Split(valid, ",")[1], body, now, ErrMalformedSignature},
+		{"Not hex", "whsec_a", "t=1,v1=zz", body, now, ErrMalformedSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, tt.header, tt.body, tt.at, 5*time.Minute)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int32
+		want    time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{6, 16 * time.Minute},
+		{10, 256 * time.Minute},
+		{11, 6 * time.Hour},
+		{40, 6 * time.Hour},
+	}
+	for _, tt := range tests {
+		if got := backoff(tt.attempt); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
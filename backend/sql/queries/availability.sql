@@ -1,16 +1,41 @@
+-- name: CloseAvailability :exec
+-- Takes a slot with booking history out of its pattern and stops it taking
+-- bookings, instead of deleting it and its bookings with it.
+UPDATE availability
+SET capacity = 0, pattern_id = NULL, updated_at = now()
+WHERE id = $1;
+
 -- name: CreateAvailability :exec
-INSERT INTO availability (id, provider_id, start_time, end_time)
+INSERT INTO availability (id, provider_id, start_time, end_time, capacity, pattern_id)
 VALUES (
     $1,
     $2,
     $3,
-    $4
+    $4,
+    $5,
+    $6
 );
 
+-- name: CreatePatternSlot :execrows
+-- Skips a slot the provider already has at that start, so pattern slots can
+-- be materialized more than once. A closed slot there is reopened instead.
+INSERT INTO availability (id, provider_id, start_time, end_time, capacity, pattern_id)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (provider_id, start_time) DO UPDATE
+SET end_time = EXCLUDED.end_time,
+    capacity = EXCLUDED.capacity,
+    pattern_id = EXCLUDED.pattern_id,
+    updated_at = now()
+WHERE availability.capacity = 0;
+
 -- name: DeleteAvailability :exec
 DELETE FROM availability WHERE id = $1
 AND provider_id = $2;
 
+-- name: GetAvailabilityByID :one
+SELECT * FROM availability
+WHERE id = $1;
+
 -- name: ListAvailabilityByProvider :many
 SELECT
   id,
@@ -18,9 +43,17 @@ SELECT
   start_time,
   end_time,
   created_at,
-  updated_at
+  updated_at,
+  capacity,
+  pattern_id
 FROM availability
-WHERE provider_id = $1 ORDER BY start_time;
+WHERE provider_id = sqlc.arg(provider_id)
+  AND (sqlc.narg(start_from)::timestamptz IS NULL OR start_time >= sqlc.narg(start_from))
+  AND (sqlc.narg(start_before)::timestamptz IS NULL OR start_time < sqlc.narg(start_before))
+  AND (sqlc.narg(after_start)::timestamptz IS NULL
+       OR (start_time, id) > (sqlc.narg(after_start)::timestamptz, sqlc.narg(after_id)::uuid))
+ORDER BY start_time, id
+LIMIT sqlc.arg(page_limit);
 
 -- name: ListAvailabilityInRange :many
 SELECT
@@ -38,14 +71,36 @@ ORDER BY start_time;
 SELECT
 s.id,
 s.start_time,
-s.end_time
+s.end_time,
+(s.capacity - COUNT(b.id))::integer AS remaining
 FROM availability AS s
 LEFT JOIN bookings AS b
   ON b.slot_id = s.id
-WHERE b.slot_id is NULL
-  AND s.provider_id = $1
+  AND b.status <> 'cancelled'
+WHERE s.provider_id = $1
   AND s.start_time >= $2
   AND s.end_time <= $3
+GROUP BY s.id
+HAVING COUNT(b.id) < s.capacity
 ORDER BY s.start_time;
 
+-- name: ListPatternSlotsFrom :many
+SELECT
+  s.id,
+  s.start_time,
+  s.end_time,
+  s.capacity,
+  (COUNT(b.id) FILTER (WHERE b.status <> 'cancelled'))::integer AS booked,
+  COUNT(b.id)::integer AS total_bookings
+FROM availability AS s
+LEFT JOIN bookings AS b
+  ON b.slot_id = s.id
+WHERE s.pattern_id = sqlc.arg(pattern_id)
+  AND s.start_time >= sqlc.arg(start_from)
+GROUP BY s.id
+ORDER BY s.start_time;
 
+-- name: UpdateAvailabilityCapacity :exec
+UPDATE availability
+SET capacity = $1, updated_at = now()
+WHERE id = $2;
@@ -1,15 +1,24 @@
 -- name: CreateAvailabilityPattern :exec
-INSERT INTO availability_pattern (id, provider_id, day_of_week, start_time, end_time)
-VALUES ($1, $2, $3, $4, $5);
+INSERT INTO availability_pattern (
+  id, provider_id, day_of_week, start_time, end_time,
+  slot_minutes, buffer_before_minutes, buffer_after_minutes, capacity,
+  generated_until, ends_on
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);
 
--- name: UpdateAvailabilityPattern :exec
+-- name: UpdateAvailabilityPattern :one
 UPDATE availability_pattern
 SET 
   day_of_week = $1,
   start_time = $2,
   end_time = $3,
+  slot_minutes = $4,
+  buffer_before_minutes = $5,
+  buffer_after_minutes = $6,
+  capacity = $7,
   updated_at = CURRENT_TIMESTAMP
-WHERE id = $4;
+WHERE id = $8
+RETURNING *;
 
 -- name: DeleteAvailabilityPattern :exec
 DELETE FROM availability_pattern
@@ -23,12 +32,40 @@ SELECT
   start_time,
   end_time,
   created_at,
-  updated_at
+  updated_at,
+  slot_minutes,
+  buffer_before_minutes,
+  buffer_after_minutes,
+  capacity
 FROM availability_pattern
 WHERE provider_id = $1
 ORDER BY day_of_week, start_time;
 
 -- name: GetAvailabilityPatternByID :one
-SELECT id, provider_id, day_of_week, start_time, end_time, created_at, updated_at
+SELECT id, provider_id, day_of_week, start_time, end_time, created_at, updated_at,
+  slot_minutes, buffer_before_minutes, buffer_after_minutes, capacity, generated_until, ends_on
 FROM availability_pattern
 WHERE id = $1;
+
+-- name: ListPatternsToExtend :many
+-- Lists the patterns whose slots stop short of both horizon and the end of
+-- their ends_on in the provider's timezone.
+SELECT p.id, p.provider_id
+FROM availability_pattern AS p
+JOIN users AS u ON u.id = p.provider_id
+WHERE p.generated_until < sqlc.arg(horizon)::timestamptz
+  AND p.generated_until < ((p.ends_on + 1)::timestamp AT TIME ZONE u.timezone)
+ORDER BY p.id;
+
+-- name: SetPatternGeneratedUntil :exec
+UPDATE availability_pattern
+SET generated_until = $1
+WHERE id = $2;
+
+-- name: TryLockSlotMaterializer :one
+-- Takes a session lock, held until UnlockSlotMaterializer or the connection
+-- closes, so it must be run on a connection set aside for the run.
+SELECT pg_try_advisory_lock(hashtext('availability_materializer'));
+
+-- name: UnlockSlotMaterializer :one
+SELECT pg_advisory_unlock(hashtext('availability_materializer'));
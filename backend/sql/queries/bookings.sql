@@ -11,36 +11,93 @@ VALUES (
 )
 RETURNING *;
 
--- name: DeleteBooking :exec
-DELETE FROM bookings 
-WHERE id = $1 
-AND (user_id = $2 or $3::boolean);
+-- name: CancelBooking :one
+UPDATE bookings
+SET status = 'cancelled',
+    cancellation_reason = sqlc.narg(cancellation_reason),
+    cancelled_by = sqlc.arg(cancelled_by),
+    cancelled_at = now(),
+    status_changed_at = now(),
+    updated_at = now(),
+    sequence = sequence + 1
+WHERE id = sqlc.arg(id)
+  AND status = sqlc.arg(current_status)
+RETURNING *;
 
--- name: RescheduleBooking :one
+-- name: UpdateBookingStatus :one
 UPDATE bookings
-SET appointment_start = $2,
-    duration_minutes = $3,
+SET status = sqlc.arg(status),
+    status_changed_at = now(),
     updated_at = now()
+WHERE id = sqlc.arg(id)
+  AND status = sqlc.arg(current_status)
+RETURNING *;
+
+-- name: RescheduleBooking :one
+-- Moves an active booking onto another slot. The caller checks ownership,
+-- overlap and the target slot's capacity in the same transaction.
+UPDATE bookings
+SET slot_id = $2,
+    appointment_start = $3,
+    duration_minutes = $4,
+    updated_at = now(),
+    sequence = sequence + 1
 WHERE id = $1
-AND (user_id = $4 or $5::boolean)
+  AND status IN ('pending', 'confirmed')
 RETURNING *;
 
 -- name: ListBookingsForUser :many
 SELECT * FROM bookings
-WHERE user_id = $1
-ORDER BY appointment_start;
+WHERE user_id = sqlc.arg(user_id)
+  AND (sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status))
+  AND (sqlc.narg(start_from)::timestamptz IS NULL OR appointment_start >= sqlc.narg(start_from))
+  AND (sqlc.narg(start_before)::timestamptz IS NULL OR appointment_start < sqlc.narg(start_before))
+  AND (sqlc.narg(after_start)::timestamptz IS NULL
+       OR (appointment_start, id) > (sqlc.narg(after_start)::timestamptz, sqlc.narg(after_id)::uuid))
+ORDER BY appointment_start, id
+LIMIT sqlc.arg(page_limit);
 
 -- name: ListAllBookingsForAdmin :many
-SELECT * From bookings
-ORDER BY appointment_start;
+SELECT b.* FROM bookings AS b
+JOIN availability AS a
+  ON a.id = b.slot_id
+WHERE (sqlc.narg(user_id)::uuid IS NULL OR b.user_id = sqlc.narg(user_id))
+  AND (sqlc.narg(provider_id)::uuid IS NULL OR a.provider_id = sqlc.narg(provider_id))
+  AND (sqlc.narg(status)::text IS NULL OR b.status = sqlc.narg(status))
+  AND (sqlc.narg(start_from)::timestamptz IS NULL OR b.appointment_start >= sqlc.narg(start_from))
+  AND (sqlc.narg(start_before)::timestamptz IS NULL OR b.appointment_start < sqlc.narg(start_before))
+  AND (sqlc.narg(after_start)::timestamptz IS NULL
+       OR (b.appointment_start, b.id) > (sqlc.narg(after_start)::timestamptz, sqlc.narg(after_id)::uuid))
+ORDER BY b.appointment_start, b.id
+LIMIT sqlc.arg(page_limit);
 
 -- name: GetOverlappingBookings :many
-SELECT *
-FROM bookings
-WHERE 
-  appointment_start < $1
-  AND appointment_start + (duration_minutes || ' minutes')::interval > $2;
+SELECT b.*
+FROM bookings AS b
+JOIN availability AS a
+  ON a.id = b.slot_id
+WHERE a.provider_id = sqlc.arg(provider_id)
+  AND b.status <> 'cancelled'
+  AND (sqlc.narg(exclude_slot_id)::uuid IS NULL OR b.slot_id <> sqlc.narg(exclude_slot_id))
+  AND (sqlc.narg(exclude_booking_id)::uuid IS NULL OR b.id <> sqlc.narg(exclude_booking_id))
+  AND b.appointment_start < sqlc.arg(range_end)
+  AND b.appointment_start + (b.duration_minutes || ' minutes')::interval > sqlc.arg(range_start);
+
+-- name: LockProviderSchedule :exec
+SELECT pg_advisory_xact_lock(hashtext(sqlc.arg(provider_id)::uuid::text));
 
 -- name: GetBookingByID :one
 SELECT * FROM bookings
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: CountBookingsForSlot :one
+SELECT COUNT(*) FROM bookings
+WHERE slot_id = $1
+  AND status <> 'cancelled';
+
+-- name: SlotHasBookings :one
+-- Counts cancelled bookings too: a slot that has ever been booked keeps its
+-- booking history and must not be deleted.
+SELECT EXISTS (
+    SELECT 1 FROM bookings WHERE slot_id = $1
+) AS has_bookings;
@@ -0,0 +1,45 @@
+-- name: CreateOutboxEvent :exec
+INSERT INTO outbox (id, event_type, aggregate_id, payload)
+VALUES ($1, $2, $3, $4);
+
+-- name: ClaimOutboxEvents :many
+-- Leases up to batch_limit due events until lease_until, so concurrent
+-- relays skip them and a crashed relay's events come back afterwards.
+UPDATE outbox
+SET next_attempt_at = sqlc.arg(lease_until)
+WHERE id IN (
+    SELECT id FROM outbox
+    WHERE status = 'pending'
+      AND next_attempt_at <= now()
+    ORDER BY occurred_at
+    LIMIT sqlc.arg(batch_limit)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: ListOutboxDeliveries :many
+SELECT subscriber FROM outbox_deliveries
+WHERE event_id = $1;
+
+-- name: RecordOutboxDelivery :exec
+INSERT INTO outbox_deliveries (event_id, subscriber)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: MarkOutboxEventDelivered :exec
+UPDATE outbox
+SET status = 'delivered',
+    attempts = attempts + 1,
+    last_error = NULL,
+    delivered_at = now()
+WHERE id = $1;
+
+-- name: MarkOutboxEventFailed :exec
+-- Records a failed attempt. The status stays pending, with next_attempt_at
+-- pushed back, until the relay gives up and marks it failed.
+UPDATE outbox
+SET status = $2,
+    attempts = attempts + 1,
+    last_error = $3,
+    next_attempt_at = $4
+WHERE id = $1;
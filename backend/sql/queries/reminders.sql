@@ -0,0 +1,82 @@
+-- name: ScheduleBookingReminder :exec
+-- Schedules the reminder offset_minutes before a booking, replacing one
+-- already sent at that offset for an earlier time.
+INSERT INTO booking_reminders (id, booking_id, offset_minutes, remind_at, next_attempt_at)
+VALUES ($1, $2, $3, $4, $4)
+ON CONFLICT (booking_id, offset_minutes) DO UPDATE
+SET remind_at = EXCLUDED.remind_at,
+    status = 'pending',
+    attempts = 0,
+    next_attempt_at = EXCLUDED.remind_at,
+    last_error = NULL,
+    sent_at = NULL;
+
+-- name: CancelBookingReminders :exec
+-- Drops a booking's reminders that have not been sent, including any being
+-- sent: their dispatcher then finds nothing to mark.
+DELETE FROM booking_reminders
+WHERE booking_id = $1 AND status IN ('pending', 'sending');
+
+-- name: ClaimBookingReminders :many
+-- Leases up to batch_limit due reminders until lease_until, so concurrent
+-- dispatchers skip them. Reminders left in sending by a dispatcher that
+-- stopped mid-send are claimed again once their lease runs out.
+UPDATE booking_reminders
+SET next_attempt_at = sqlc.arg(lease_until)
+WHERE id IN (
+    SELECT id FROM booking_reminders
+    WHERE status IN ('pending', 'sending')
+      AND next_attempt_at <= now()
+    ORDER BY next_attempt_at
+    LIMIT sqlc.arg(batch_limit)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: MarkBookingReminderSending :execrows
+-- Counts an attempt at a claimed reminder just before it is sent. No row is
+-- updated if another dispatcher has claimed it since, or if its booking is
+-- no longer confirmed or has been rescheduled: the booking is checked in
+-- the same statement, so a cancellation that commits first stops the send.
+UPDATE booking_reminders AS r
+SET status = 'sending',
+    attempts = r.attempts + 1
+FROM bookings AS b
+WHERE r.id = sqlc.arg(id)
+  AND r.status IN ('pending', 'sending')
+  AND r.next_attempt_at = sqlc.arg(lease_until)
+  AND b.id = r.booking_id
+  AND b.status = 'confirmed'
+  AND b.sequence = sqlc.arg(sequence);
+
+-- name: MarkBookingReminderSent :exec
+UPDATE booking_reminders
+SET status = 'sent',
+    last_error = NULL,
+    sent_at = now()
+WHERE id = sqlc.arg(id)
+  AND status = 'sending'
+  AND next_attempt_at = sqlc.arg(lease_until);
+
+-- name: MarkBookingReminderFailed :exec
+-- Records a failed attempt. The status goes back to pending, with
+-- next_attempt_at pushed back, until the dispatcher gives up and marks it
+-- failed.
+UPDATE booking_reminders
+SET status = sqlc.arg(status),
+    last_error = sqlc.narg(last_error),
+    next_attempt_at = sqlc.arg(next_attempt_at)
+WHERE id = sqlc.arg(id)
+  AND status = 'sending'
+  AND next_attempt_at = sqlc.arg(lease_until);
+
+-- name: SkipBookingReminder :execrows
+-- Closes a claimed reminder that is no longer worth sending, such as one
+-- for an appointment that has already started. No row is updated if
+-- another dispatcher has claimed it since.
+UPDATE booking_reminders
+SET status = 'skipped',
+    last_error = sqlc.narg(last_error)
+WHERE id = sqlc.arg(id)
+  AND status IN ('pending', 'sending')
+  AND next_attempt_at = sqlc.arg(lease_until);
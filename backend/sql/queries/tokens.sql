@@ -0,0 +1,59 @@
+-- name: CreateRefreshToken :exec
+INSERT INTO refresh_tokens (id, user_id, token_hash, created_at, expires_at)
+VALUES (
+    $1,
+    $2,
+    $3,
+    now(),
+    $4
+);
+
+-- name: GetRefreshTokenByHash :one
+SELECT * FROM refresh_tokens
+WHERE token_hash = $1;
+
+-- name: RevokeRefreshToken :execrows
+UPDATE refresh_tokens
+SET revoked_at = now()
+WHERE id = $1 AND revoked_at IS NULL;
+
+-- name: RevokeRefreshTokensForUser :exec
+UPDATE refresh_tokens
+SET revoked_at = now()
+WHERE user_id = $1 AND revoked_at IS NULL;
+
+-- name: RevokeAccessToken :exec
+INSERT INTO revoked_tokens (jti, user_id, revoked_at, expires_at)
+VALUES ($1, $2, now(), $3)
+ON CONFLICT (jti) DO NOTHING;
+
+-- name: IsAccessTokenRevoked :one
+-- Reports whether the token was revoked on logout, or issued no later than
+-- an admin revoked its user's tokens.
+SELECT EXISTS (
+    SELECT 1 FROM revoked_tokens WHERE jti = sqlc.arg(jti)
+) OR EXISTS (
+    SELECT 1 FROM user_token_revocations
+    WHERE user_id = sqlc.arg(user_id) AND revoked_at >= sqlc.arg(issued_at)
+) AS revoked;
+
+-- name: RevokeUserTokens :execrows
+-- Rejects every access token issued to the user so far. expires_at is when
+-- the last of them expires. Affects no rows for an unknown user.
+INSERT INTO user_token_revocations (user_id, revoked_at, expires_at)
+SELECT id, now(), sqlc.arg(expires_at) FROM users WHERE id = sqlc.arg(user_id)
+ON CONFLICT (user_id) DO UPDATE
+SET revoked_at = EXCLUDED.revoked_at,
+    expires_at = EXCLUDED.expires_at;
+
+-- name: DeleteExpiredRefreshTokens :execrows
+DELETE FROM refresh_tokens
+WHERE expires_at < now();
+
+-- name: DeleteExpiredRevokedTokens :execrows
+DELETE FROM revoked_tokens
+WHERE expires_at < now();
+
+-- name: DeleteExpiredUserTokenRevocations :execrows
+DELETE FROM user_token_revocations
+WHERE expires_at < now();
@@ -15,13 +15,36 @@ VALUES (
 SELECT * FROM users
 WHERE email = $1;
 
+-- name: GetUserByID :one
+SELECT * FROM users
+WHERE id = $1;
+
 -- name: UpdateUser :exec
 UPDATE users 
 SET first_name = $1, last_name = $2, email = $3, password_hash = $4, updated_at = now()
 WHERE id = $5;
 
+-- name: UpdateUserRole :execrows
+UPDATE users
+SET user_role = $1, updated_at = now()
+WHERE id = $2;
+
+-- name: UpdateUserTimezone :execrows
+UPDATE users
+SET timezone = $1, updated_at = now()
+WHERE id = $2;
+
+-- name: GetUserTimezone :one
+SELECT timezone FROM users
+WHERE id = $1;
+
 -- name: DeleteUser :exec
 DELETE FROM users WHERE id = $1;
 
 -- name: ListUsers :many
-SELECT * FROM users ORDER BY last_name ASC, first_name ASC;
\ No newline at end of file
+SELECT * FROM users
+WHERE (sqlc.narg(user_role)::text IS NULL OR user_role = sqlc.narg(user_role))
+  AND (sqlc.narg(after_created_at)::timestamptz IS NULL
+       OR (created_at, id) > (sqlc.narg(after_created_at)::timestamptz, sqlc.narg(after_id)::uuid))
+ORDER BY created_at, id
+LIMIT sqlc.arg(page_limit);
\ No newline at end of file
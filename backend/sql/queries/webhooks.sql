@@ -0,0 +1,112 @@
+-- name: CreateWebhookEndpoint :one
+INSERT INTO webhook_endpoints (id, url, secret, description)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetWebhookEndpointByID :one
+SELECT * FROM webhook_endpoints
+WHERE id = $1;
+
+-- name: ListWebhookEndpoints :many
+SELECT * FROM webhook_endpoints
+ORDER BY created_at, id;
+
+-- name: UpdateWebhookEndpoint :one
+UPDATE webhook_endpoints
+SET url = $2, description = $3, active = $4, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteWebhookEndpoint :execrows
+DELETE FROM webhook_endpoints
+WHERE id = $1;
+
+-- name: AddWebhookSubscription :exec
+INSERT INTO webhook_subscriptions (endpoint_id, event_type)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteWebhookSubscriptions :exec
+DELETE FROM webhook_subscriptions
+WHERE endpoint_id = $1;
+
+-- name: ListWebhookSubscriptions :many
+-- Lists the subscriptions of one endpoint, or of every endpoint when
+-- endpoint_id is null.
+SELECT * FROM webhook_subscriptions
+WHERE sqlc.narg(endpoint_id)::uuid IS NULL OR endpoint_id = sqlc.narg(endpoint_id)
+ORDER BY endpoint_id, event_type;
+
+-- name: ListWebhookEndpointsForEvent :many
+SELECT e.* FROM webhook_endpoints e
+JOIN webhook_subscriptions s ON s.endpoint_id = e.id
+WHERE s.event_type = $1 AND e.active
+ORDER BY e.created_at, e.id;
+
+-- name: CreateWebhookDelivery :exec
+-- Queues an event for an endpoint. Relaying the same event again is a no-op.
+INSERT INTO webhook_deliveries (id, endpoint_id, event_id, event_type, payload)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (endpoint_id, event_id) DO NOTHING;
+
+-- name: ClaimWebhookDeliveries :many
+-- Leases up to batch_limit due deliveries to active endpoints until
+-- lease_until, so concurrent dispatchers skip them.
+UPDATE webhook_deliveries
+SET next_attempt_at = sqlc.arg(lease_until)
+WHERE id IN (
+    SELECT d.id FROM webhook_deliveries d
+    JOIN webhook_endpoints e ON e.id = d.endpoint_id
+    WHERE d.status = 'pending'
+      AND d.next_attempt_at <= now()
+      AND e.active
+    ORDER BY d.next_attempt_at
+    LIMIT sqlc.arg(batch_limit)
+    FOR UPDATE OF d SKIP LOCKED
+)
+RETURNING *;
+
+-- name: MarkWebhookDeliveryDelivered :exec
+UPDATE webhook_deliveries
+SET status = 'delivered',
+    attempts = attempts + 1,
+    response_status = $2,
+    last_error = NULL,
+    delivered_at = now()
+WHERE id = $1;
+
+-- name: MarkWebhookDeliveryFailed :exec
+-- Records a failed attempt. The status stays pending, with next_attempt_at
+-- pushed back, until the dispatcher gives up and marks it dead.
+UPDATE webhook_deliveries
+SET status = $2,
+    attempts = attempts + 1,
+    response_status = $3,
+    last_error = $4,
+    next_attempt_at = $5
+WHERE id = $1;
+
+-- name: GetWebhookDeliveryByID :one
+SELECT * FROM webhook_deliveries
+WHERE id = $1;
+
+-- name: ListWebhookDeliveries :many
+-- Pages through an endpoint's deliveries, newest first.
+SELECT * FROM webhook_deliveries
+WHERE endpoint_id = sqlc.arg(endpoint_id)
+  AND (sqlc.narg(status)::text IS NULL OR status = sqlc.narg(status))
+  AND (sqlc.narg(after_created_at)::timestamptz IS NULL
+       OR (created_at, id) < (sqlc.narg(after_created_at)::timestamptz, sqlc.narg(after_id)::uuid))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(page_limit);
+
+-- name: RedeliverWebhookDelivery :one
+-- Queues a delivery to be sent again straight away with a fresh set of
+-- attempts, whatever its status.
+UPDATE webhook_deliveries
+SET status = 'pending',
+    attempts = 0,
+    next_attempt_at = now(),
+    delivered_at = NULL
+WHERE id = $1
+RETURNING *;
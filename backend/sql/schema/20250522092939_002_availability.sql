@@ -28,7 +28,7 @@ CREATE TRIGGER check_admin
 
 -- +goose Down
 
-DROP TRIGGER IF EXISTS check_admin;
-DROP TRIGGER IF EXISTS no_overlap;
+DROP TRIGGER IF EXISTS check_admin ON availability;
+DROP FUNCTION IF EXISTS check_availability_provider_is_admin();
 
 DROP TABLE IF EXISTS availability;
@@ -0,0 +1,32 @@
+-- +goose Up
+
+ALTER TABLE users
+  ADD CONSTRAINT users_user_role_check CHECK (user_role IN ('user', 'provider', 'admin'));
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION check_availability_provider_is_admin()
+  RETURNS trigger AS $check_avail$
+BEGIN
+  IF COALESCE((SELECT user_role FROM users WHERE id = NEW.provider_id), '') NOT IN ('provider', 'admin') THEN
+    RAISE EXCEPTION 'provider is not a provider or admin';
+  END IF;
+  RETURN NEW;
+END;
+$check_avail$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose Down
+
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION check_availability_provider_is_admin()
+  RETURNS trigger AS $check_avail$
+BEGIN
+  IF (SELECT user_role FROM users WHERE id = NEW.provider_id) IS DISTINCT FROM 'admin' THEN
+    RAISE EXCEPTION 'provider is not an admin';
+  END IF;
+  RETURN NEW;
+END;
+$check_avail$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_user_role_check;
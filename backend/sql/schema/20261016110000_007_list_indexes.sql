@@ -0,0 +1,15 @@
+-- +goose Up
+
+-- Keyset pagination walks these in (time, id) order.
+CREATE INDEX bookings_appointment_start_id_idx ON bookings (appointment_start, id);
+CREATE INDEX bookings_user_id_appointment_start_id_idx ON bookings (user_id, appointment_start, id);
+CREATE INDEX bookings_slot_id_idx ON bookings (slot_id);
+CREATE INDEX availability_provider_id_start_time_id_idx ON availability (provider_id, start_time, id);
+CREATE INDEX users_created_at_id_idx ON users (created_at, id);
+
+-- +goose Down
+DROP INDEX IF EXISTS users_created_at_id_idx;
+DROP INDEX IF EXISTS availability_provider_id_start_time_id_idx;
+DROP INDEX IF EXISTS bookings_slot_id_idx;
+DROP INDEX IF EXISTS bookings_user_id_appointment_start_id_idx;
+DROP INDEX IF EXISTS bookings_appointment_start_id_idx;
@@ -0,0 +1,23 @@
+-- +goose Up
+
+ALTER TABLE bookings
+  ADD COLUMN status TEXT NOT NULL DEFAULT 'confirmed'
+    CONSTRAINT bookings_status_check
+    CHECK (status IN ('pending', 'confirmed', 'cancelled', 'completed', 'no_show')),
+  ADD COLUMN status_changed_at TIMESTAMP NOT NULL DEFAULT now(),
+  ADD COLUMN cancellation_reason TEXT,
+  ADD COLUMN cancelled_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  ADD COLUMN cancelled_at TIMESTAMP;
+
+-- Overlap and free-slot checks only look at bookings that still hold time.
+CREATE INDEX bookings_slot_id_active_idx ON bookings (slot_id) WHERE status <> 'cancelled';
+
+-- +goose Down
+DROP INDEX IF EXISTS bookings_slot_id_active_idx;
+
+ALTER TABLE bookings
+  DROP COLUMN cancelled_at,
+  DROP COLUMN cancelled_by,
+  DROP COLUMN cancellation_reason,
+  DROP COLUMN status_changed_at,
+  DROP COLUMN status;
@@ -0,0 +1,27 @@
+-- +goose Up
+
+ALTER TABLE availability_pattern
+  ADD COLUMN slot_minutes INTEGER NOT NULL DEFAULT 60
+    CHECK (slot_minutes BETWEEN 5 AND 1440),
+  ADD COLUMN buffer_before_minutes INTEGER NOT NULL DEFAULT 0
+    CHECK (buffer_before_minutes BETWEEN 0 AND 1440),
+  ADD COLUMN buffer_after_minutes INTEGER NOT NULL DEFAULT 0
+    CHECK (buffer_after_minutes BETWEEN 0 AND 1440),
+  ADD COLUMN capacity INTEGER NOT NULL DEFAULT 1
+    CHECK (capacity >= 1);
+
+-- How many active bookings a slot takes; more than one is a group session.
+ALTER TABLE availability
+  ADD COLUMN capacity INTEGER NOT NULL DEFAULT 1
+    CHECK (capacity >= 1);
+
+-- +goose Down
+
+ALTER TABLE availability
+  DROP COLUMN capacity;
+
+ALTER TABLE availability_pattern
+  DROP COLUMN capacity,
+  DROP COLUMN buffer_after_minutes,
+  DROP COLUMN buffer_before_minutes,
+  DROP COLUMN slot_minutes;
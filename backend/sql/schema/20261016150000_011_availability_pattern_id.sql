@@ -0,0 +1,13 @@
+-- +goose Up
+
+-- Slots generated from a pattern point back at it so they can be
+-- regenerated when it changes. Manually created slots have no pattern.
+ALTER TABLE availability
+  ADD COLUMN pattern_id UUID REFERENCES availability_pattern(id) ON DELETE SET NULL;
+
+CREATE INDEX availability_pattern_id_start_time_idx ON availability (pattern_id, start_time);
+
+-- +goose Down
+DROP INDEX IF EXISTS availability_pattern_id_start_time_idx;
+
+ALTER TABLE availability DROP COLUMN pattern_id;
@@ -0,0 +1,35 @@
+-- +goose Up
+
+-- A provider has at most one slot starting at a given instant, so pattern
+-- slots can be materialized repeatedly: CreatePatternSlot uses ON CONFLICT
+-- (provider_id, start_time) DO UPDATE ... WHERE capacity = 0, skipping an
+-- open slot and reopening a closed one. Unbooked duplicates are dropped
+-- first, keeping a booked copy or else the oldest.
+DELETE FROM availability AS a
+USING availability AS b
+WHERE a.provider_id = b.provider_id
+  AND a.start_time = b.start_time
+  AND a.id <> b.id
+  AND NOT EXISTS (SELECT 1 FROM bookings WHERE slot_id = a.id)
+  AND (
+    EXISTS (SELECT 1 FROM bookings WHERE slot_id = b.id)
+    OR (a.created_at, a.id) > (b.created_at, b.id)
+  );
+
+CREATE UNIQUE INDEX availability_provider_id_start_time_key ON availability (provider_id, start_time);
+
+-- Slots starting before generated_until have been generated for the pattern.
+ALTER TABLE availability_pattern
+  ADD COLUMN generated_until TIMESTAMPTZ NOT NULL DEFAULT now();
+
+UPDATE availability_pattern AS p
+SET generated_until = COALESCE(
+  (SELECT MAX(a.end_time) FROM availability AS a WHERE a.pattern_id = p.id),
+  p.created_at
+);
+
+-- +goose Down
+
+ALTER TABLE availability_pattern DROP COLUMN generated_until;
+
+DROP INDEX IF EXISTS availability_provider_id_start_time_key;
@@ -0,0 +1,31 @@
+-- +goose Up
+
+-- Domain events, written in the same transaction as the change they
+-- describe and relayed to subscribers after it commits.
+CREATE TABLE outbox (
+    id UUID PRIMARY KEY NOT NULL,
+    event_type TEXT NOT NULL,
+    aggregate_id UUID NOT NULL,
+    payload JSONB NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    status TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'delivered', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_error TEXT,
+    delivered_at TIMESTAMPTZ
+);
+
+CREATE INDEX outbox_pending_idx ON outbox (next_attempt_at) WHERE status = 'pending';
+
+-- The subscribers that have handled an event, so a retry skips them.
+CREATE TABLE outbox_deliveries (
+    event_id UUID NOT NULL REFERENCES outbox(id) ON DELETE CASCADE,
+    subscriber TEXT NOT NULL,
+    delivered_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (event_id, subscriber)
+);
+
+-- +goose Down
+DROP TABLE outbox_deliveries;
+DROP TABLE outbox;
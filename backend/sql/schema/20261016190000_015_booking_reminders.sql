@@ -0,0 +1,35 @@
+-- +goose Up
+
+-- Counts the changes a booking's calendar invites have announced, for the
+-- iCalendar SEQUENCE of every invite and reminder sent for it. Rescheduling
+-- and cancelling bump it.
+ALTER TABLE bookings
+  ADD COLUMN sequence BIGINT NOT NULL DEFAULT 0;
+
+-- Reminders due before each booking, one per configured offset. The booking
+-- service rewrites a booking's pending reminders whenever its time changes
+-- and removes them when it is cancelled. Reminders are claimed with a lease
+-- and marked sending just before they are handed to the mail transport; one
+-- whose dispatcher stops mid-send is claimed again when the lease runs out.
+CREATE TABLE booking_reminders (
+    id UUID PRIMARY KEY NOT NULL,
+    booking_id UUID NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    offset_minutes INTEGER NOT NULL CHECK (offset_minutes > 0),
+    remind_at TIMESTAMPTZ NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'sending', 'sent', 'skipped', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    sent_at TIMESTAMPTZ,
+    UNIQUE (booking_id, offset_minutes)
+);
+
+CREATE INDEX booking_reminders_due_idx ON booking_reminders (next_attempt_at) WHERE status IN ('pending', 'sending');
+
+-- +goose Down
+DROP TABLE booking_reminders;
+
+ALTER TABLE bookings
+  DROP COLUMN sequence;
@@ -0,0 +1,16 @@
+-- +goose Up
+
+-- An admin revoking a user's tokens rejects every access token issued to
+-- them up to revoked_at. The row is only needed until expires_at, when the
+-- last of those tokens has expired anyway.
+CREATE TABLE user_token_revocations (
+    user_id UUID PRIMARY KEY NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    revoked_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX refresh_tokens_expires_at_idx ON refresh_tokens (expires_at);
+
+-- +goose Down
+DROP INDEX refresh_tokens_expires_at_idx;
+DROP TABLE user_token_revocations;
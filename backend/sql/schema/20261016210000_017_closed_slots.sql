@@ -0,0 +1,16 @@
+-- +goose Up
+
+-- A slot dropped from its pattern after it was booked is kept, so its
+-- cancelled bookings survive, but closed: capacity 0 takes no bookings.
+ALTER TABLE availability
+  DROP CONSTRAINT availability_capacity_check,
+  ADD CONSTRAINT availability_capacity_check CHECK (capacity >= 0);
+
+-- +goose Down
+
+-- Closed slots reopen for one booking rather than lose their history.
+UPDATE availability SET capacity = 1 WHERE capacity = 0;
+
+ALTER TABLE availability
+  DROP CONSTRAINT availability_capacity_check,
+  ADD CONSTRAINT availability_capacity_check CHECK (capacity >= 1);
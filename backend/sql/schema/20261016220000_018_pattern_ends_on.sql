@@ -0,0 +1,26 @@
+-- +goose Up
+
+-- The last day, in the provider's timezone, a pattern has slots on. The
+-- materializer extends a pattern up to its horizon or the end of this day,
+-- whichever comes first.
+ALTER TABLE availability_pattern
+  ADD COLUMN ends_on DATE;
+
+-- The end date given for existing patterns was not kept; they end on the
+-- day of their last slot, or the day they were created if they have none.
+UPDATE availability_pattern AS p
+SET ends_on = COALESCE(
+  (SELECT MAX((a.start_time AT TIME ZONE u.timezone)::date)
+   FROM availability AS a
+   WHERE a.pattern_id = p.id),
+  (p.created_at AT TIME ZONE u.timezone)::date
+)
+FROM users AS u
+WHERE u.id = p.provider_id;
+
+ALTER TABLE availability_pattern
+  ALTER COLUMN ends_on SET NOT NULL;
+
+-- +goose Down
+
+ALTER TABLE availability_pattern DROP COLUMN ends_on;
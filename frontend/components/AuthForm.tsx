@@ -1,4 +1,5 @@
 import { FormEvent, useState } from "react";
+import { saveRefreshToken } from "../utils/auth";
 
 type AuthMode = "login" | "register";
 
@@ -7,17 +8,16 @@ interface AuthFormProps {
     onSuccess: (token: string) => void;
 }
 
-// Helper: given an unknown value, return `message` if that property is a string.
+// Helper: given an unknown value, return `detail` (problem+json) or `message` if either is a string.
 function extractMessage(body: unknown): string | undefined {
-    if (
-        body !== null &&
-        typeof body === "object" &&
-        // “in” check ensures a “message” key exists
-        "message" in (body as Record<string, unknown>) &&
-        typeof (body as Record<string, unknown>).message === "string"
-    ) {
-        // Now it’s safe to cast to Record<string, string> for that property
-        return (body as Record<string, string>).message;
+    if (body === null || typeof body !== "object") {
+        return undefined;
+    }
+    const record = body as Record<string, unknown>;
+    for (const key of ["detail", "message"]) {
+        if (typeof record[key] === "string") {
+            return record[key] as string;
+        }
     }
     return undefined;
 }
@@ -59,6 +59,7 @@ export default function AuthForm({ mode, onSuccess }: AuthFormProps) {
                 // On success, backend should return { token: "<JWT>" }
                 const data = await resp.json();
                 localStorage.setItem("booking_app_token", data.token);
+                saveRefreshToken(data.refresh_token);
                 onSuccess(data.token);
 
             } else {
@@ -83,6 +84,7 @@ export default function AuthForm({ mode, onSuccess }: AuthFormProps) {
 
                 const data = await resp.json();
                 localStorage.setItem("booking_app_token", data.token);
+                saveRefreshToken(data.refresh_token);
                 onSuccess(data.token);
 
             }
@@ -3,7 +3,7 @@
 import { useState, useEffect } from "react";
 import Link from "next/link";
 import { useRouter } from "next/router";
-import { clearToken, getDecodedToken, isAuthenticated } from "../utils/auth";
+import { getDecodedToken, isAuthenticated, logout, refreshSession } from "../utils/auth";
 
 export default function Navbar() {
     const router = useRouter();
@@ -11,13 +11,17 @@ export default function Navbar() {
     const [decoded, setDecoded] = useState<{ firstName: string } | null>(null);
 
     useEffect(() => {
-        const ok = isAuthenticated();
-        setAuth(ok);
-        if (ok) setDecoded(getDecodedToken());
+        async function load() {
+            let ok = isAuthenticated();
+            if (!ok) ok = (await refreshSession()) !== null;
+            setAuth(ok);
+            if (ok) setDecoded(getDecodedToken());
+        }
+        load();
     }, []);
 
-    function handleLogout() {
-        clearToken();
+    async function handleLogout() {
+        await logout();
         router.push("/login");
     }
 
@@ -156,9 +156,7 @@ const UserCalendar: React.FC<UserCalendarProps> = ({ onBack, onBookingSuccess })
                                                 await toast.promise(
                                                     createBooking(
                                                         {
-                                                            id: slot.id,
-                                                            appointmentStart: new Date(slot.startTime),
-                                                            durationMinutes: 60,
+                                                            slotId: slot.id,
                                                         },
                                                         token
                                                     ),
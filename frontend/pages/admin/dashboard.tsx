@@ -43,7 +43,7 @@ export default function AdminDashboard() {
     const fetchBookings = useCallback(async () => {
         try {
             const data = await fetchAllBookings(token);
-            setAllBookings(data);
+            setAllBookings(data.items);
         } catch (err) {
             setErrorMsg(formatError(err));
         }
@@ -60,7 +60,7 @@ export default function AdminDashboard() {
             setErrorMsg("");
             try {
                 const data = await fetchAllUsers(token);
-                setAllUsers(data);
+                setAllUsers(data.items);
             } catch (err) {
                 setErrorMsg(formatError(err));
             }
@@ -12,7 +12,7 @@ export async function post<T>(path: string, body: any, token?: string): Promise<
 
     if (!res.ok) {
         const errorBody = await res.json().catch(() => ({}));
-        throw new Error(errorBody.message || `API POST ${path} failed with status ${res.status}`);
+        throw new Error(errorBody.detail || errorBody.message || `API POST ${path} failed with status ${res.status}`);
     }
 
     return (await res.json()) as T;
@@ -28,7 +28,7 @@ export async function get<T>(path: string, token?: string): Promise<T> {
 
     if (!res.ok) {
         const errorBody = await res.json().catch(() => ({}));
-        throw new Error(errorBody.message || `API GET ${path} failed with status ${res.status}`);
+        throw new Error(errorBody.detail || errorBody.message || `API GET ${path} failed with status ${res.status}`);
     }
 
     return (await res.json()) as T;
@@ -1,6 +1,8 @@
 import { jwtDecode } from "jwt-decode";
 
 const TOKEN_KEY = "booking_app_token";
+const REFRESH_KEY = "booking_app_refresh_token";
+const BACKEND_URL = process.env.NEXT_PUBLIC_BACKEND_URL || "http://localhost:8080";
 
 export function saveToken(token: string) {
     if (typeof window !== "undefined") {
@@ -13,15 +15,66 @@ export function getToken(): string | null {
     return localStorage.getItem(TOKEN_KEY);
 }
 
+export function saveRefreshToken(token: string) {
+    if (typeof window !== "undefined") {
+        localStorage.setItem(REFRESH_KEY, token);
+    }
+}
+
+export function getRefreshToken(): string | null {
+    if (typeof window === "undefined") return null;
+    return localStorage.getItem(REFRESH_KEY);
+}
+
 export function clearToken() {
     if (typeof window !== "undefined") {
         localStorage.removeItem(TOKEN_KEY);
+        localStorage.removeItem(REFRESH_KEY);
+    }
+}
+
+// Exchanges the stored refresh token for a new token pair. Returns the new
+// access token, or null (and clears the session) if the refresh was refused.
+export async function refreshSession(): Promise<string | null> {
+    const refreshToken = getRefreshToken();
+    if (!refreshToken) return null;
+
+    const resp = await fetch(`${BACKEND_URL}/api/token/refresh`, {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify({ refresh_token: refreshToken }),
+    }).catch(() => null);
+
+    if (!resp || !resp.ok) {
+        clearToken();
+        return null;
+    }
+
+    const data = await resp.json();
+    saveToken(data.token);
+    saveRefreshToken(data.refresh_token);
+    return data.token;
+}
+
+// Revokes the current session on the server, then clears it locally.
+export async function logout() {
+    const token = getToken();
+    if (token) {
+        await fetch(`${BACKEND_URL}/api/logout`, {
+            method: "POST",
+            headers: {
+                "Content-Type": "application/json",
+                Authorization: `Bearer ${token}`,
+            },
+            body: JSON.stringify({ refresh_token: getRefreshToken() ?? "" }),
+        }).catch(() => undefined);
     }
+    clearToken();
 }
 
 export interface DecodedJWT {
     sub: string;
-    user_role: "user" | "admin";
+    user_role: "user" | "provider" | "admin";
     firstName: string;
     exp: number;
     iat: number;
@@ -44,7 +97,7 @@ export function isAuthenticated(): boolean {
     return decoded.exp * 1000 > Date.now();
 }
 
-export function userRole(): "user" | "admin" | null {
+export function userRole(): "user" | "provider" | "admin" | null {
     const decoded = getDecodedToken();
     return decoded?.user_role || null;
 }
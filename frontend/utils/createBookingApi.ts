@@ -1,7 +1,5 @@
 export async function createBooking(params: {
-    id: string
-    appointmentStart: Date
-    durationMinutes: 60
+    slotId: string
 }, token: string) {
     const url = `${process.env.NEXT_PUBLIC_BACKEND_URL}/api/bookings/create`
     const res = await fetch(url, {
@@ -11,13 +9,11 @@ export async function createBooking(params: {
             "Authorization": `Bearer ${token}`,
         },
         body: JSON.stringify({
-            id: params.id,
-            appointment_start: params.appointmentStart.toISOString(),
-            duration_minutes: params.durationMinutes,
+            slot_id: params.slotId,
         }),
     })
     if (!res.ok) {
         const text = await res.text()
         throw new Error(`Failed to create booking: ${res.status} ${text}`)
     }
-}
\ No newline at end of file
+}
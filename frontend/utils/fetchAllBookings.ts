@@ -1,11 +1,13 @@
+import { Page, withCursor } from "./page";
+
 export interface Booking {
     ID: string;
     AppointmentStart: string;
     DurationMinutes: number;
 }
 
-export async function fetchAllBookings(token: string): Promise<Booking[]> {
-    const resp = await fetch(`${process.env.NEXT_PUBLIC_BACKEND_URL}/api/admin/bookings/all`, {
+export async function fetchAllBookings(token: string, cursor?: string): Promise<Page<Booking>> {
+    const resp = await fetch(withCursor(`${process.env.NEXT_PUBLIC_BACKEND_URL}/api/admin/bookings/all`, cursor), {
         headers: { Authorization: `Bearer ${token}` },
     });
     if (!resp.ok) throw new Error("Failed to fetch bookings");
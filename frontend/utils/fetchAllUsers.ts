@@ -1,3 +1,5 @@
+import { Page, withCursor } from "./page";
+
 export interface User {
     id: string;
     first_name: string;
@@ -6,8 +8,8 @@ export interface User {
     user_role: string;
 }
 
-export async function fetchAllUsers(token: string): Promise<User[]> {
-    const resp = await fetch(`${process.env.NEXT_PUBLIC_BACKEND_URL}/api/admin/users/all`, {
+export async function fetchAllUsers(token: string, cursor?: string): Promise<Page<User>> {
+    const resp = await fetch(withCursor(`${process.env.NEXT_PUBLIC_BACKEND_URL}/api/admin/users/all`, cursor), {
         headers: { Authorization: `Bearer ${token}` },
     });
     if (!resp.ok) {
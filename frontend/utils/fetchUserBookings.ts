@@ -1,7 +1,8 @@
 import { Booking } from "./fetchAllBookings";
+import { Page, withCursor } from "./page";
 
-export async function fetchUserBookings(token: string): Promise<Booking[]> {
-    const resp = await fetch(`${process.env.NEXT_PUBLIC_BACKEND_URL}/api/bookings/user`, {
+export async function fetchUserBookings(token: string, cursor?: string): Promise<Page<Booking>> {
+    const resp = await fetch(withCursor(`${process.env.NEXT_PUBLIC_BACKEND_URL}/api/bookings/user`, cursor), {
         headers: { Authorization: `Bearer ${token}` },
     });
 
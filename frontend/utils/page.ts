@@ -0,0 +1,10 @@
+// A page of a list endpoint. Pass next_cursor back as ?cursor= to get the
+// following page; it is absent on the last page.
+export interface Page<T> {
+    items: T[];
+    next_cursor?: string;
+}
+
+export function withCursor(url: string, cursor?: string): string {
+    return cursor ? `${url}?cursor=${encodeURIComponent(cursor)}` : url;
+}